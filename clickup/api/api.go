@@ -5,9 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"go.uber.org/zap"
@@ -21,6 +23,15 @@ func clickupBaseURL() *url.URL {
 	}
 }
 
+// NOTE: some of the methods are available only in API v3 (eg move the task to another list)
+func clickupBaseURLv3() *url.URL {
+	return &url.URL{
+		Scheme: "https",
+		Host:   "api.clickup.com",
+		Path:   "api/v3",
+	}
+}
+
 type httpClientLogger struct {
 	*zap.Logger
 }
@@ -42,6 +53,15 @@ func NewAPI(accessToken string) *API {
 	}
 }
 
+// NewAPIWithClient returns the client of ClickUp API with the custom HTTP client (eg the client of the fake server in the tests).
+func NewAPIWithClient(accessToken string, client *http.Client) *API {
+	return &API{
+		token:  accessToken,
+		client: client,
+		log:    zap.L().Named("api"),
+	}
+}
+
 type API struct {
 	token  string
 	client *http.Client
@@ -62,6 +82,23 @@ var _ ResponseMetadata = (*SpaceByIDResponse)(nil)
 var _ ResponseMetadata = (*ListMembersResponse)(nil)
 var _ ResponseMetadata = (*SearchTasksInTeamResponse)(nil)
 var _ ResponseMetadata = (*ListTeamsResponse)(nil)
+var _ ResponseMetadata = (*AddTagToTaskResponse)(nil)
+var _ ResponseMetadata = (*RemoveTagFromTaskResponse)(nil)
+var _ ResponseMetadata = (*DeleteTaskResponse)(nil)
+var _ ResponseMetadata = (*CreateChecklistResponse)(nil)
+var _ ResponseMetadata = (*EditChecklistResponse)(nil)
+var _ ResponseMetadata = (*DeleteChecklistResponse)(nil)
+var _ ResponseMetadata = (*CreateChecklistItemResponse)(nil)
+var _ ResponseMetadata = (*EditChecklistItemResponse)(nil)
+var _ ResponseMetadata = (*DeleteChecklistItemResponse)(nil)
+var _ ResponseMetadata = (*CreateTaskAttachmentResponse)(nil)
+var _ ResponseMetadata = (*SearchTimeEntriesResponse)(nil)
+var _ ResponseMetadata = (*CreateTimeEntryResponse)(nil)
+var _ ResponseMetadata = (*UpdateTimeEntryResponse)(nil)
+var _ ResponseMetadata = (*DeleteTimeEntryResponse)(nil)
+var _ ResponseMetadata = (*SetCustomFieldValueResponse)(nil)
+var _ ResponseMetadata = (*MoveTaskResponse)(nil)
+var _ ResponseMetadata = (*SearchCommentsInTaskResponse)(nil)
 
 func (a *API) CreateTask(ctx context.Context, newTask *CreateTaskRequest) *CreateTaskResponse {
 	res := &CreateTaskResponse{}
@@ -75,12 +112,155 @@ func (a *API) UpdateTask(ctx context.Context, updTask *UpdateTaskRequest) *Updat
 	return res
 }
 
+func (a *API) DeleteTask(ctx context.Context, taskID string) *DeleteTaskResponse {
+	req := &DeleteTaskRequest{TaskID: taskID}
+	res := &DeleteTaskResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
 func (a *API) AddCommentToTask(ctx context.Context, newComment *AddCommentToTaskRequest) *AddCommentToTaskResponse {
 	res := &AddCommentToTaskResponse{}
 	a.doRequest(ctx, newComment, res)
 	return res
 }
 
+func (a *API) AddTagToTask(ctx context.Context, taskID, tagName string) *AddTagToTaskResponse {
+	req := &AddTagToTaskRequest{TaskID: taskID, TagName: tagName}
+	res := &AddTagToTaskResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) RemoveTagFromTask(ctx context.Context, taskID, tagName string) *RemoveTagFromTaskResponse {
+	req := &RemoveTagFromTaskRequest{TaskID: taskID, TagName: tagName}
+	res := &RemoveTagFromTaskResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) CreateChecklist(ctx context.Context, taskID, name string) *CreateChecklistResponse {
+	req := &CreateChecklistRequest{TaskID: taskID, Name: name}
+	res := &CreateChecklistResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) EditChecklist(ctx context.Context, checklistID, name string) *EditChecklistResponse {
+	req := &EditChecklistRequest{ChecklistID: checklistID, Name: name}
+	res := &EditChecklistResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) DeleteChecklist(ctx context.Context, checklistID string) *DeleteChecklistResponse {
+	req := &DeleteChecklistRequest{ChecklistID: checklistID}
+	res := &DeleteChecklistResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) CreateChecklistItem(ctx context.Context, checklistID, name string) *CreateChecklistItemResponse {
+	req := &CreateChecklistItemRequest{ChecklistID: checklistID, Name: name}
+	res := &CreateChecklistItemResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) EditChecklistItem(ctx context.Context, req *EditChecklistItemRequest) *EditChecklistItemResponse {
+	res := &EditChecklistItemResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) DeleteChecklistItem(ctx context.Context, checklistID, itemID string) *DeleteChecklistItemResponse {
+	req := &DeleteChecklistItemRequest{ChecklistID: checklistID, ItemID: itemID}
+	res := &DeleteChecklistItemResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+// NOTE: ClickUp API returns the attachments only in the task (GET /task/<TaskID>)
+func (a *API) ListTaskAttachments(ctx context.Context, taskID string) ([]Attachment, bool) {
+	res := a.TaskByID(ctx, taskID)
+	return res.Attachments, res.StatusOK()
+}
+
+func (a *API) CreateTaskAttachment(ctx context.Context, req *CreateTaskAttachmentRequest) *CreateTaskAttachmentResponse {
+	res := &CreateTaskAttachmentResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+// DownloadAttachment returns the content of the attachment by URL.
+// Returns ErrAttachmentTooLarge if the content is larger than maxSize bytes.
+func (a *API) DownloadAttachment(ctx context.Context, attachmentURL string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, attachmentURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := a.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed download attachment: got status %q", res.Status)
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(res.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	return content, nil
+}
+
+var ErrAttachmentTooLarge = errors.New("attachment too large")
+
+func (a *API) SearchTimeEntries(ctx context.Context, req *SearchTimeEntriesRequest) *SearchTimeEntriesResponse {
+	res := &SearchTimeEntriesResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) CreateTimeEntry(ctx context.Context, req *CreateTimeEntryRequest) *CreateTimeEntryResponse {
+	res := &CreateTimeEntryResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) UpdateTimeEntry(ctx context.Context, req *UpdateTimeEntryRequest) *UpdateTimeEntryResponse {
+	res := &UpdateTimeEntryResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) DeleteTimeEntry(ctx context.Context, teamID, timeEntryID string) *DeleteTimeEntryResponse {
+	req := &DeleteTimeEntryRequest{TeamID: teamID, TimeEntryID: timeEntryID}
+	res := &DeleteTimeEntryResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+func (a *API) SetCustomFieldValue(ctx context.Context, taskID, fieldID string, value interface{}) *SetCustomFieldValueResponse {
+	req := &SetCustomFieldValueRequest{TaskID: taskID, FieldID: fieldID, Value: value}
+	res := &SetCustomFieldValueResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
+// MoveTask moves the task to another list (the home list of the task).
+func (a *API) MoveTask(ctx context.Context, teamID, taskID, listID string) *MoveTaskResponse {
+	req := &MoveTaskRequest{TeamID: teamID, TaskID: taskID, ListID: listID}
+	res := &MoveTaskResponse{}
+	a.doRequest(ctx, req, res)
+	return res
+}
+
 func (a *API) TaskByID(ctx context.Context, taskID string) *TaskByIDResponse {
 	req := &TaskByIDRequest{TaskID: taskID}
 	res := &TaskByIDResponse{}
@@ -157,13 +337,13 @@ func (a *API) SearchTasksInTeam(ctx context.Context, req *SearchTasksInTeamReque
 	return res
 }
 
-func (a *API) SearchCommentsInTask(ctx context.Context, taskID string, startTaskID string, startTaskTs int64) *ListTeamsResponse {
+func (a *API) SearchCommentsInTask(ctx context.Context, taskID string, startTaskID string, startTaskTs int64) *SearchCommentsInTaskResponse {
 	req := &SearchCommentsInTaskRequest{
 		TaskID:      taskID,
 		StartTaskID: startTaskID,
 		StartTimeTS: startTaskTs,
 	}
-	res := &ListTeamsResponse{}
+	res := &SearchCommentsInTaskResponse{}
 	a.doRequest(ctx, req, res)
 	return res
 }
@@ -173,7 +353,9 @@ func (a *API) doRequest(ctx context.Context, reqFactory requestBuilder, model se
 	req := reqFactory.buildRequest()
 
 	req.Header.Set("Authorization", a.token)
-	req.Header.Set("Content-Type", "application/json")
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	req = req.WithContext(ctx)
 
@@ -208,7 +390,8 @@ func (a *API) doRequest(ctx context.Context, reqFactory requestBuilder, model se
 
 	contentType := req.Header.Get("Content-type")
 
-	if contentType == "application/json" {
+	// NOTE: the response to the multipart request (upload files) is json
+	if contentType == "application/json" || strings.HasPrefix(contentType, "multipart/form-data") {
 		if err := decodeFromJsonTo(res.Body, model); err != nil {
 			a.log.Warn(fmt.Sprintf("Failed deocode json to model %T", model), zap.String("uri", req.URL.String()), zap.String("method", req.Method), zap.String("body_raw", string(body.String())), zap.String("content_type", contentType),
 				zap.Error(err),
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testToken = "test-token"
+
+// sends all requests to the host of the test server
+type rewriteHostTransport struct {
+	target *url.URL
+}
+
+func (t rewriteHostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newTestAPI(t *testing.T, handler http.HandlerFunc) *API {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return NewAPIWithClient(testToken, &http.Client{Transport: rewriteHostTransport{target}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, in interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(in)
+}
+
+func TestAPI_DownloadAttachment(t *testing.T) {
+	ctx := context.Background()
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/report.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("0123456789"))
+	})
+
+	content, err := api.DownloadAttachment(ctx, "https://attachments.clickup.com/files/report.txt", 10)
+	if err != nil {
+		t.Fatalf("DownloadAttachment(): %v", err)
+	}
+	if string(content) != "0123456789" {
+		t.Errorf("DownloadAttachment() = %q", content)
+	}
+
+	if _, err := api.DownloadAttachment(ctx, "https://attachments.clickup.com/files/report.txt", 9); !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Errorf("DownloadAttachment() for the large content: %v, want %v", err, ErrAttachmentTooLarge)
+	}
+	if _, err := api.DownloadAttachment(ctx, "https://attachments.clickup.com/files/removed.txt", 10); err == nil {
+		t.Error("DownloadAttachment() for the not found attachment must return error")
+	}
+}
+
+func TestAPI_CreateTaskAttachment(t *testing.T) {
+	ctx := context.Background()
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/task/t1/attachment" || r.Header.Get("Authorization") != testToken {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the preset content type (with the boundary) is not replaced by the default
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data; boundary=") {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"err": "unexpected content type " + r.Header.Get("Content-Type")})
+			return
+		}
+		file, header, err := r.FormFile("attachment")
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"err": err.Error()})
+			return
+		}
+		content, _ := ioutil.ReadAll(file)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":        "a1",
+			"title":     header.Filename,
+			"extension": "txt",
+			"url":       "https://attachments.clickup.com/files/" + header.Filename,
+			"size":      len(content),
+			"date":      "1643709600000",
+		})
+	})
+
+	res := api.CreateTaskAttachment(ctx, &CreateTaskAttachmentRequest{
+		TaskID:   "t1",
+		FileName: "report.txt",
+		Content:  []byte("0123456789"),
+	})
+	if !res.StatusOK() {
+		t.Fatalf("CreateTaskAttachment() status %d", res.responseStatusCode)
+	}
+	// the JSON response to the multipart request is decoded
+	if !res.DecodeOK() {
+		t.Fatalf("CreateTaskAttachment() decode: %v", res.decodeErr)
+	}
+	want := Attachment{
+		ID:        "a1",
+		Title:     "report.txt",
+		Extension: "txt",
+		URL:       "https://attachments.clickup.com/files/report.txt",
+		Size:      10,
+		DateTs:    1643709600000,
+	}
+	if res.Attachment != want {
+		t.Errorf("CreateTaskAttachment() = %+v, want %+v", res.Attachment, want)
+	}
+}
+
+func TestAPI_doRequest(t *testing.T) {
+	ctx := context.Background()
+	contentType := ""
+	api := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		switch r.URL.Path {
+		case "/api/v2/task/t1/checklist":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"checklist": map[string]string{"id": "c1", "name": "Todo"}})
+		case "/api/v2/task/t2/checklist":
+			w.Write([]byte("<html></html>"))
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"err": "Task not found"})
+		}
+	})
+
+	// the JSON request by default
+	res := api.CreateChecklist(ctx, "t1", "Todo")
+	if contentType != "application/json" {
+		t.Errorf("the content type of the request = %q, want application/json", contentType)
+	}
+	if !res.StatusOK() || !res.DecodeOK() || res.Checklist.ID != "c1" {
+		t.Errorf("CreateChecklist() = %+v", res)
+	}
+
+	// not JSON response
+	res = api.CreateChecklist(ctx, "t2", "Todo")
+	if !res.StatusOK() || res.DecodeOK() {
+		t.Errorf("CreateChecklist() for not JSON response: status %d, decode error %v", res.responseStatusCode, res.decodeErr)
+	}
+
+	// the error response
+	res = api.CreateChecklist(ctx, "t3", "Todo")
+	if res.StatusOK() || !res.NotFound() {
+		t.Errorf("CreateChecklist() for the not found task: status %d", res.responseStatusCode)
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+)
+
+type Attachment struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Extension string `json:"extension"`
+	URL       string `json:"url"`
+	Size      int64  `json:"size"`
+	DateTs    int64  `json:"date,string"`
+}
+
+//////////////////////
+// Create Task Attachment
+//////////////////////
+
+type CreateTaskAttachmentRequest struct {
+	TaskID   string
+	FileName string
+	Content  []byte
+}
+
+func (r *CreateTaskAttachmentRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/task/" + r.TaskID + "/attachment"
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("attachment", r.FileName)
+	part.Write(r.Content)
+	writer.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, reqURL.String(), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+type CreateTaskAttachmentResponse struct {
+	responseMetadata
+	Attachment
+}
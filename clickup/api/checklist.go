@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+type Checklist struct {
+	ID         string          `json:"id"`
+	TaskID     string          `json:"task_id"`
+	Name       string          `json:"name"`
+	Orderindex int             `json:"orderindex"`
+	Resolved   int             `json:"resolved"`
+	Unresolved int             `json:"unresolved"`
+	Items      []ChecklistItem `json:"items"`
+}
+
+type ChecklistItem struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Orderindex int     `json:"orderindex"`
+	Resolved   bool    `json:"resolved"`
+	Parent     *string `json:"parent"`
+	Assignee   *Member `json:"assignee"`
+}
+
+//////////////////////
+// Create Checklist
+//////////////////////
+
+type CreateChecklistRequest struct {
+	TaskID string
+	Name   string
+}
+
+func (r *CreateChecklistRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/task/" + r.TaskID + "/checklist"
+
+	datBytes, _ := json.Marshal(map[string]interface{}{
+		"name": r.Name,
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, reqURL.String(), bytes.NewReader(datBytes))
+	return req
+}
+
+type CreateChecklistResponse struct {
+	responseMetadata
+	Checklist Checklist `json:"checklist"`
+}
+
+//////////////////////
+// Edit Checklist
+//////////////////////
+
+type EditChecklistRequest struct {
+	ChecklistID string
+	Name        string
+}
+
+func (r *EditChecklistRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/checklist/" + r.ChecklistID
+
+	datBytes, _ := json.Marshal(map[string]interface{}{
+		"name": r.Name,
+	})
+
+	req, _ := http.NewRequest(http.MethodPut, reqURL.String(), bytes.NewReader(datBytes))
+	return req
+}
+
+type EditChecklistResponse struct {
+	responseMetadata
+}
+
+//////////////////////
+// Delete Checklist
+//////////////////////
+
+type DeleteChecklistRequest struct {
+	ChecklistID string
+}
+
+func (r *DeleteChecklistRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/checklist/" + r.ChecklistID
+
+	req, _ := http.NewRequest(http.MethodDelete, reqURL.String(), nil)
+	return req
+}
+
+type DeleteChecklistResponse struct {
+	responseMetadata
+}
+
+//////////////////////
+// Create Checklist Item
+//////////////////////
+
+type CreateChecklistItemRequest struct {
+	ChecklistID string
+	Name        string
+}
+
+func (r *CreateChecklistItemRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/checklist/" + r.ChecklistID + "/checklist_item"
+
+	datBytes, _ := json.Marshal(map[string]interface{}{
+		"name": r.Name,
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, reqURL.String(), bytes.NewReader(datBytes))
+	return req
+}
+
+type CreateChecklistItemResponse struct {
+	responseMetadata
+	Checklist Checklist `json:"checklist"`
+}
+
+//////////////////////
+// Edit Checklist Item
+//////////////////////
+
+type EditChecklistItemRequest struct {
+	ChecklistID string
+	ItemID      string
+	Name        string
+	Resolved    *bool
+}
+
+func (r *EditChecklistItemRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/checklist/" + r.ChecklistID + "/checklist_item/" + r.ItemID
+
+	dat := map[string]interface{}{}
+	if r.Name != "" {
+		dat["name"] = r.Name
+	}
+	if r.Resolved != nil {
+		dat["resolved"] = *r.Resolved
+	}
+
+	datBytes, _ := json.Marshal(dat)
+
+	req, _ := http.NewRequest(http.MethodPut, reqURL.String(), bytes.NewReader(datBytes))
+	return req
+}
+
+type EditChecklistItemResponse struct {
+	responseMetadata
+	Checklist Checklist `json:"checklist"`
+}
+
+//////////////////////
+// Delete Checklist Item
+//////////////////////
+
+type DeleteChecklistItemRequest struct {
+	ChecklistID string
+	ItemID      string
+}
+
+func (r *DeleteChecklistItemRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/checklist/" + r.ChecklistID + "/checklist_item/" + r.ItemID
+
+	req, _ := http.NewRequest(http.MethodDelete, reqURL.String(), nil)
+	return req
+}
+
+type DeleteChecklistItemResponse struct {
+	responseMetadata
+}
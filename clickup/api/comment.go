@@ -35,6 +35,7 @@ func (r *SearchCommentsInTaskRequest) buildRequest() *http.Request {
 }
 
 type SearchCommentsInTaskResponse struct {
+	responseMetadata
 	Comments []struct {
 		ID          string `json:"id"`
 		CommentText string `json:"comment_text"`
@@ -96,4 +97,6 @@ func (r *AddCommentToTaskRequest) buildRequest() *http.Request {
 
 type AddCommentToTaskResponse struct {
 	responseMetadata
+	// ClickUp API returns the number ID of the new comment (but the string IDs in the list of the comments)
+	ID json.Number `json:"id"`
 }
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+//////////////////////
+// Set Custom Field Value
+//////////////////////
+
+type SetCustomFieldValueRequest struct {
+	TaskID  string
+	FieldID string
+	Value   interface{}
+}
+
+func (r *SetCustomFieldValueRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/task/" + r.TaskID + "/field/" + r.FieldID
+
+	datBytes, _ := json.Marshal(map[string]interface{}{
+		"value": r.Value,
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, reqURL.String(), bytes.NewReader(datBytes))
+	return req
+}
+
+type SetCustomFieldValueResponse struct {
+	responseMetadata
+}
@@ -0,0 +1,45 @@
+package api
+
+import "net/http"
+
+//////////////////////
+// Add Tag To Task
+//////////////////////
+
+type AddTagToTaskRequest struct {
+	TaskID  string
+	TagName string
+}
+
+func (r *AddTagToTaskRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/task/" + r.TaskID + "/tag/" + r.TagName
+
+	req, _ := http.NewRequest(http.MethodPost, reqURL.String(), nil)
+	return req
+}
+
+type AddTagToTaskResponse struct {
+	responseMetadata
+}
+
+//////////////////////
+// Remove Tag From Task
+//////////////////////
+
+type RemoveTagFromTaskRequest struct {
+	TaskID  string
+	TagName string
+}
+
+func (r *RemoveTagFromTaskRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/task/" + r.TaskID + "/tag/" + r.TagName
+
+	req, _ := http.NewRequest(http.MethodDelete, reqURL.String(), nil)
+	return req
+}
+
+type RemoveTagFromTaskResponse struct {
+	responseMetadata
+}
@@ -25,6 +25,8 @@ type SearchTasksInTeamRequest struct {
 	AssignUserIDs   []string
 	IncludeClosed   bool
 	IncludeSubtasks bool
+	// returns the description in the markdown format (see Task.MarkdownDescription)
+	IncludeMarkdownDescription bool
 }
 
 func (r *SearchTasksInTeamRequest) buildRequest() *http.Request {
@@ -53,6 +55,9 @@ func (r *SearchTasksInTeamRequest) buildRequest() *http.Request {
 	if r.IncludeSubtasks {
 		q.Add("subtasks", "true")
 	}
+	if r.IncludeMarkdownDescription {
+		q.Add("include_markdown_description", "true")
+	}
 	if len(r.FolderIDs) > 0 {
 		q["project_ids[]"] = r.FolderIDs
 	}
@@ -111,6 +116,9 @@ type Task struct {
 		Name string `json:"name"`
 	} `json:"tags"`
 
+	// returned if requested with include_markdown_description
+	MarkdownDescription string `json:"markdown_description"`
+
 	LinkedTasks []struct {
 		TaskID      string `json:"task_id"`
 		LinkID      string `json:"link_id"`
@@ -140,6 +148,9 @@ type Task struct {
 		ID   int    `json:"id,string"`
 		Name string `json:"priority"`
 	} `json:"priority"`
+
+	Checklists  []Checklist  `json:"checklists"`
+	Attachments []Attachment `json:"attachments"`
 }
 
 func (r *Task) ListLinkedTaskIDs() []string {
@@ -172,6 +183,11 @@ type UpdateTaskRequest struct {
 	AssigneeRemoves []int64
 	DueDate         int64
 	StartDate       int64
+	// moves the subtask to another parent task
+	ParentTaskID string
+	// sets the description in the markdown format (used instead of Description)
+	MarkdownDescription string
+	Archived            *bool
 }
 
 func (r *UpdateTaskRequest) buildRequest() *http.Request {
@@ -186,6 +202,10 @@ func (r *UpdateTaskRequest) buildRequest() *http.Request {
 	if r.Description != "" {
 		dat["description"] = r.Description
 	}
+	if r.MarkdownDescription != "" {
+		delete(dat, "description")
+		dat["markdown_description"] = r.MarkdownDescription
+	}
 
 	if r.StatusName != "" {
 		dat["status"] = r.StatusName
@@ -222,10 +242,18 @@ func (r *UpdateTaskRequest) buildRequest() *http.Request {
 	if r.StartDate > 0 {
 		dat["start_date"] = fmt.Sprint(r.StartDate)
 	}
-	if r.DueDate == -1 {
+	if r.StartDate == -1 {
 		dat["start_date"] = nil
 	}
 
+	if r.ParentTaskID != "" {
+		dat["parent"] = r.ParentTaskID
+	}
+
+	if r.Archived != nil {
+		dat["archived"] = *r.Archived
+	}
+
 	datBytes, _ := json.Marshal(dat)
 
 	req, _ := http.NewRequest(http.MethodPut, reqURL.String(), bytes.NewReader(datBytes))
@@ -250,6 +278,9 @@ type CreateTaskRequest struct {
 	RefTaskID           string
 	AssignIDs           []string
 	PriorityID          *int
+	// creates the subtask of the task
+	ParentTaskID   string
+	TimeEstimateMs *int64
 }
 
 func (r *CreateTaskRequest) buildRequest() *http.Request {
@@ -275,6 +306,12 @@ func (r *CreateTaskRequest) buildRequest() *http.Request {
 	if r.PriorityID != nil {
 		dat["priority"] = *r.PriorityID
 	}
+	if r.ParentTaskID != "" {
+		dat["parent"] = r.ParentTaskID
+	}
+	if r.TimeEstimateMs != nil {
+		dat["time_estimate"] = *r.TimeEstimateMs
+	}
 
 	datBytes, _ := json.Marshal(dat)
 
@@ -301,6 +338,8 @@ type SearchTasksRequest struct {
 
 	IncludeClosed   bool
 	IncludeSubtasks bool
+	// returns the description in the markdown format (see Task.MarkdownDescription)
+	IncludeMarkdownDescription bool
 }
 
 func (r *SearchTasksRequest) buildRequest() *http.Request {
@@ -329,6 +368,9 @@ func (r *SearchTasksRequest) buildRequest() *http.Request {
 	if r.IncludeSubtasks {
 		q.Add("subtasks", "true")
 	}
+	if r.IncludeMarkdownDescription {
+		q.Add("include_markdown_description", "true")
+	}
 	reqURL.RawQuery = q.Encode()
 
 	req, _ := http.NewRequest(http.MethodGet, reqURL.String(), nil)
@@ -351,6 +393,10 @@ func (r *TaskByIDRequest) buildRequest() *http.Request {
 	reqURL := clickupBaseURL()
 	reqURL.Path += "/task/" + r.TaskID
 
+	q := reqURL.Query()
+	q.Add("include_markdown_description", "true")
+	reqURL.RawQuery = q.Encode()
+
 	req, _ := http.NewRequest(http.MethodGet, reqURL.String(), nil)
 	return req
 }
@@ -359,3 +405,50 @@ type TaskByIDResponse struct {
 	responseMetadata
 	Task
 }
+
+//////////////////////
+// Delete Task
+//////////////////////
+
+type DeleteTaskRequest struct {
+	TaskID string
+}
+
+func (r *DeleteTaskRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/task/" + r.TaskID
+
+	req, _ := http.NewRequest(http.MethodDelete, reqURL.String(), nil)
+	return req
+}
+
+type DeleteTaskResponse struct {
+	responseMetadata
+}
+
+// NOTE: ClickUp API returns 204 (no content) for the deleted task
+func (r DeleteTaskResponse) Deleted() bool {
+	return r.StatusOK() || r.IsStatus(http.StatusNoContent)
+}
+
+//////////////////////
+// Move Task (API v3)
+//////////////////////
+
+type MoveTaskRequest struct {
+	TeamID string
+	TaskID string
+	ListID string
+}
+
+func (r *MoveTaskRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURLv3()
+	reqURL.Path += "/workspaces/" + r.TeamID + "/tasks/" + r.TaskID + "/home_list/" + r.ListID
+
+	req, _ := http.NewRequest(http.MethodPut, reqURL.String(), nil)
+	return req
+}
+
+type MoveTaskResponse struct {
+	responseMetadata
+}
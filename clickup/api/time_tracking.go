@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type TimeEntry struct {
+	ID   string `json:"id"`
+	Task *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"task"`
+	User        Member `json:"user"`
+	Billable    bool   `json:"billable"`
+	StartTs     int64  `json:"start,string"`
+	DurationMs  int64  `json:"duration,string"`
+	Description string `json:"description"`
+}
+
+// Running returns true if the time entry is running (the timer is not stopped).
+// NOTE: ClickUp API returns negative duration for the running time entry
+func (e *TimeEntry) Running() bool {
+	return e.DurationMs < 0
+}
+
+//////////////////////
+// Search Time Entries
+//////////////////////
+
+type SearchTimeEntriesRequest struct {
+	TeamID string
+	TaskID string
+	// by default ClickUp API returns the time entries of the authorized user only
+	// (the time entries of other users are available for the owners and the admins of the team)
+	AssigneeIDs []string
+	StartTs     int64
+	EndTs       int64
+}
+
+func (r *SearchTimeEntriesRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/team/" + r.TeamID + "/time_entries"
+
+	q := reqURL.Query()
+	if r.TaskID != "" {
+		q.Add("task_id", r.TaskID)
+	}
+	if len(r.AssigneeIDs) > 0 {
+		q.Add("assignee", strings.Join(r.AssigneeIDs, ","))
+	}
+	if r.StartTs > 0 {
+		q.Add("start_date", fmt.Sprint(r.StartTs))
+	}
+	if r.EndTs > 0 {
+		q.Add("end_date", fmt.Sprint(r.EndTs))
+	}
+	reqURL.RawQuery = q.Encode()
+
+	req, _ := http.NewRequest(http.MethodGet, reqURL.String(), nil)
+	return req
+}
+
+type SearchTimeEntriesResponse struct {
+	responseMetadata
+	TimeEntries []TimeEntry `json:"data"`
+}
+
+//////////////////////
+// Create Time Entry
+//////////////////////
+
+type CreateTimeEntryRequest struct {
+	TeamID      string
+	TaskID      string
+	Description string
+	StartTs     int64
+	DurationMs  int64
+	Billable    bool
+	AssigneeID  int64
+}
+
+func (r *CreateTimeEntryRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/team/" + r.TeamID + "/time_entries"
+
+	dat := map[string]interface{}{
+		"tid":      r.TaskID,
+		"start":    r.StartTs,
+		"duration": r.DurationMs,
+		"billable": r.Billable,
+	}
+	if r.Description != "" {
+		dat["description"] = r.Description
+	}
+	if r.AssigneeID > 0 {
+		dat["assignee"] = r.AssigneeID
+	}
+
+	datBytes, _ := json.Marshal(dat)
+
+	req, _ := http.NewRequest(http.MethodPost, reqURL.String(), bytes.NewReader(datBytes))
+	return req
+}
+
+type CreateTimeEntryResponse struct {
+	responseMetadata
+	TimeEntry struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+//////////////////////
+// Update Time Entry
+//////////////////////
+
+type UpdateTimeEntryRequest struct {
+	TeamID      string
+	TimeEntryID string
+	TaskID      string
+	Description string
+	StartTs     int64
+	DurationMs  int64
+	Billable    bool
+}
+
+func (r *UpdateTimeEntryRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/team/" + r.TeamID + "/time_entries/" + r.TimeEntryID
+
+	datBytes, _ := json.Marshal(map[string]interface{}{
+		"tid":         r.TaskID,
+		"description": r.Description,
+		"start":       r.StartTs,
+		"end":         r.StartTs + r.DurationMs,
+		"duration":    r.DurationMs,
+		"billable":    r.Billable,
+	})
+
+	req, _ := http.NewRequest(http.MethodPut, reqURL.String(), bytes.NewReader(datBytes))
+	return req
+}
+
+type UpdateTimeEntryResponse struct {
+	responseMetadata
+}
+
+//////////////////////
+// Delete Time Entry
+//////////////////////
+
+type DeleteTimeEntryRequest struct {
+	TeamID      string
+	TimeEntryID string
+}
+
+func (r *DeleteTimeEntryRequest) buildRequest() *http.Request {
+	reqURL := clickupBaseURL()
+	reqURL.Path += "/team/" + r.TeamID + "/time_entries/" + r.TimeEntryID
+
+	req, _ := http.NewRequest(http.MethodDelete, reqURL.String(), nil)
+	return req
+}
+
+type DeleteTimeEntryResponse struct {
+	responseMetadata
+}
@@ -7,14 +7,18 @@ import (
 
 	"cloud.google.com/go/firestore"
 	"github.com/gebv/asap-tools/clickup/api"
+	"github.com/gebv/asap-tools/notify"
+	"github.com/gebv/asap-tools/tracker"
+	"github.com/gebv/asap-tools/webhook"
 	"go.uber.org/zap"
 )
 
 func NewChangeManager(api *api.API, s *Storage) *ChangeManager {
 	return &ChangeManager{
-		api:   api,
-		store: s,
-		log:   zap.L().Named("clickup_sync"),
+		api:       api,
+		store:     s,
+		log:       zap.L().Named("clickup_sync"),
+		providers: tracker.NewRegistry(newStoredProvider(api, s)),
 	}
 }
 
@@ -23,11 +27,51 @@ type ChangeManager struct {
 	store         *Storage
 	log           *zap.Logger
 	webhookSecret string
+	// the providers of the tasks of the mirror pairs and the issues of the external trackers (ClickUp by default)
+	providers *tracker.Registry
+	// the notifiers of the messengers by name (see notify of the rules)
+	notifiers map[string]*notify.Notifier
+	// the dispatcher of the webhooks (see webhooks of the rules)
+	webhooks *webhook.Dispatcher
+}
+
+// RegisterProvider adds the provider of the tasks (replaces the provider with the same name, eg ClickUp).
+func (s *ChangeManager) RegisterProvider(provider tracker.Provider) {
+	s.providers.Register(provider)
+}
+
+// RegisterNotifier adds the notifier of the messenger (see notify of the rules, eg telegram).
+func (s *ChangeManager) RegisterNotifier(messenger string, notifier *notify.Notifier) {
+	if s.notifiers == nil {
+		s.notifiers = map[string]*notify.Notifier{}
+	}
+	s.notifiers[messenger] = notifier
+}
+
+// RegisterWebhooks sets the dispatcher of the webhooks (see webhooks of the rules).
+func (s *ChangeManager) RegisterWebhooks(dispatcher *webhook.Dispatcher) {
+	s.webhooks = dispatcher
+}
+
+// FlushNotifications sends the digests of the notifications and the queued deliveries of the webhooks.
+func (s *ChangeManager) FlushNotifications(ctx context.Context) {
+	for messenger, notifier := range s.notifiers {
+		err := notifier.Flush(ctx)
+		s.warnErrorIf(err, "failed to send the digests of the notifications", "messenger", messenger)
+	}
+	if s.webhooks != nil {
+		err := s.webhooks.Flush(ctx)
+		s.warnErrorIf(err, "failed to deliver the webhooks")
+	}
 }
 
 func (s *ChangeManager) Sync(ctx context.Context, opts *SyncPreferences, oldTask, task *Task, changed bool) {
+	notifier := newTaskNotifier(opts, s.store, s.notifiers, s.webhooks)
+	mirrorSyncer := MirrorTaskSyncer(s.api, s.store, s.providers)
+	mirrorSyncer.notifier = notifier
 	list := []taskSyncer{
-		MirrorTaskSyncer(s.api, s.store),
+		mirrorSyncer,
+		notifier,
 	}
 
 	for _, syncer := range list {
@@ -56,9 +100,13 @@ func (s *ChangeManager) ForceSyncForAllTasks(ctx context.Context, opts *SyncPref
 		// ClickUp API returns 404 if the task is not found
 		if res.IsStatus(http.StatusNotFound) {
 			// task was deleted
-			oldTask.Deleted = true
-			s.store.UpsertTask(ctx, oldTask)
-			// TODO: add special logic for terminated tasks
+			deletedTask := s.store.GetTask(ctx, oldTask.ID)
+			deletedTask.Deleted = true
+			ModelTaskSetupLazyload(ctx, s.store, deletedTask)
+			err := s.store.UpsertTask(ctx, deletedTask)
+			s.warnErrorIf(err, "failed to upsert the deleted task", "task_id", oldTask.ID)
+
+			s.Sync(ctx, opts, oldTask, deletedTask, true)
 		}
 
 		if !res.StatusOK() && !res.IsStatus(http.StatusNotFound) {
@@ -90,11 +138,12 @@ func (s *ChangeManager) ApplyChangesInTeam(ctx context.Context, opts *SyncPrefer
 
 nextPage:
 	req := &api.SearchTasksInTeamRequest{
-		TeamID:          teamID,
-		OrderBy:         "updated",
-		IncludeClosed:   true,
-		IncludeSubtasks: true,
-		Page:            page,
+		TeamID:                     teamID,
+		OrderBy:                    "updated",
+		IncludeClosed:              true,
+		IncludeSubtasks:            true,
+		IncludeMarkdownDescription: true,
+		Page:                       page,
 	}
 	if cursor.Exists() && cursor.LastTaskUpdatedAt > 0 {
 		// NOTE: cursor (order_by=updated, date_updated_gt and fetch condition) should not change, only update the page number
@@ -152,34 +201,35 @@ func ModelTaskSetupLazyload(ctx context.Context, store *Storage, model *Task) {
 	}
 	model.lazyLoadSubTasks = func() {
 		model.lazyLoadSubTasksOnce.Do(func() {
-			model.LinkedTasks = store.FetchListTasks(ctx, model.LinkedTasksRef)
+			store.LoadSubTasks(ctx, model)
 		})
 	}
 	model.lazyLoadLinkedTasks = func() {
 		model.lazyLoadLinkedTasksOnce.Do(func() {
-			store.LoadSubTasks(ctx, model)
+			model.LinkedTasks = store.FetchListTasks(ctx, model.LinkedTasksRef)
 		})
 	}
 }
 
 func ModelTaskFromAPI(ctx context.Context, store *Storage, taskAPI *api.Task) *Task {
 	model := &Task{
-		StdStoreModel:  NewWithID(TaskModel, taskAPI.ID).(*Task).StdStoreModel,
-		Name:           taskAPI.Name,
-		CustomID:       taskAPI.CustomID,
-		Description:    taskAPI.Description,
-		TextContent:    taskAPI.TextContent,
-		StatusType:     taskAPI.Status.Type,
-		StatusName:     taskAPI.Status.Status,
-		DateCreatedAt:  TimestampFromTimestampWithMilliseconds(&taskAPI.DateCreatedTs),
-		DateUpdatedAt:  TimestampFromTimestampWithMilliseconds(&taskAPI.DateUpdatedTs),
-		DateClosedAt:   TimestampFromTimestampWithMilliseconds(taskAPI.DateClosedTs),
-		DueDateAt:      TimestampFromTimestampWithMilliseconds(taskAPI.DueDate),
-		StartDateAt:    TimestampFromTimestampWithMilliseconds(taskAPI.StartDate),
-		TimeEstimateMs: taskAPI.TimeEstimateMs,
-		Archived:       taskAPI.Archived,
-		URL:            taskAPI.URL,
-		Tags:           taskAPI.ListTags(),
+		StdStoreModel:       NewWithID(TaskModel, taskAPI.ID).(*Task).StdStoreModel,
+		Name:                taskAPI.Name,
+		CustomID:            taskAPI.CustomID,
+		Description:         taskAPI.Description,
+		MarkdownDescription: taskAPI.MarkdownDescription,
+		TextContent:         taskAPI.TextContent,
+		StatusType:          taskAPI.Status.Type,
+		StatusName:          taskAPI.Status.Status,
+		DateCreatedAt:       TimestampFromTimestampWithMilliseconds(&taskAPI.DateCreatedTs),
+		DateUpdatedAt:       TimestampFromTimestampWithMilliseconds(&taskAPI.DateUpdatedTs),
+		DateClosedAt:        TimestampFromTimestampWithMilliseconds(taskAPI.DateClosedTs),
+		DueDateAt:           TimestampFromTimestampWithMilliseconds(taskAPI.DueDate),
+		StartDateAt:         TimestampFromTimestampWithMilliseconds(taskAPI.StartDate),
+		TimeEstimateMs:      taskAPI.TimeEstimateMs,
+		Archived:            taskAPI.Archived,
+		URL:                 taskAPI.URL,
+		Tags:                taskAPI.ListTags(),
 
 		// TODO: Load from the API if there is not exists in the database?
 		TeamRef: store.DocRef(NewWithID(TeamModel, taskAPI.TeamID)),
@@ -203,6 +253,9 @@ func ModelTaskFromAPI(ctx context.Context, store *Storage, taskAPI *api.Task) *T
 			store.DocRef(NewWithID(TaskModel, taskID)),
 		)
 	}
+	for _, checklistAPI := range taskAPI.Checklists {
+		model.Checklists = append(model.Checklists, ModelTaskChecklistFromAPI(&checklistAPI))
+	}
 	for idx := range taskAPI.Assignees {
 		assign := taskAPI.Assignees[idx]
 
@@ -216,6 +269,21 @@ func ModelTaskFromAPI(ctx context.Context, store *Storage, taskAPI *api.Task) *T
 	return model
 }
 
+func ModelTaskChecklistFromAPI(checklistAPI *api.Checklist) TaskChecklist {
+	checklist := TaskChecklist{
+		ID:   checklistAPI.ID,
+		Name: checklistAPI.Name,
+	}
+	for _, itemAPI := range checklistAPI.Items {
+		checklist.Items = append(checklist.Items, TaskChecklistItem{
+			ID:       itemAPI.ID,
+			Name:     itemAPI.Name,
+			Resolved: itemAPI.Resolved,
+		})
+	}
+	return checklist
+}
+
 func (s *ChangeManager) AuthorizeTask(ctx context.Context, task *Task) (_ *Task, changed bool) {
 	oldTask := s.store.GetTask(ctx, task.ID)
 
@@ -0,0 +1,87 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gebv/asap-tools/tracker"
+	"go.uber.org/zap"
+)
+
+// Location returns the location of the issues of the target in the provider.
+func (t *SyncRule_SpecOfExternalTarget) Location() tracker.Location {
+	location := tracker.Location{Provider: t.Tracker, ID: t.Target, Properties: map[string]string{}}
+	for field, value := range t.Properties {
+		location.Properties[field] = value
+	}
+	return location
+}
+
+var (
+	legacyExternalMirrorTaskModel            = (*legacyExternalMirrorTask)(nil)
+	_                             StoreModel = (*legacyExternalMirrorTask)(nil)
+)
+
+// legacyExternalMirrorTask the pair of the original task and the issue in the external tracker saved by the previous versions
+// (the pairs are migrated to MirrorTask, see ChangeManager.migrateExternalMirrorTasks).
+type legacyExternalMirrorTask struct {
+	StoreModelCustomID
+	TaskID, Tracker, IssueID string `firestore:"-"`
+	Destroyed                bool
+	DestroyedReason          string
+	DestroyedAt              *Timestamp
+
+	Target    string
+	IssueKey  string
+	IssueURL  string
+	RuleName  string
+	CreatedAt *Timestamp
+	// the IDs of the comments of the original task => the IDs of the comments of the issue
+	CommentIDs map[string]string
+}
+
+func (*legacyExternalMirrorTask) NewModel() StoreModel {
+	return &legacyExternalMirrorTask{}
+}
+
+func (t *legacyExternalMirrorTask) CollectionName() string {
+	return "clickup_external_mirror_tasks"
+}
+
+func (t *legacyExternalMirrorTask) SetModelID(in string) {
+	args := strings.Split(in, ":")
+	if len(args) != 5 || args[0] != "src" || args[2] != "ext" {
+		panic(fmt.Errorf("invalid format ID %q", in))
+	}
+	t.TaskID, t.Tracker, t.IssueID = args[1], args[3], args[4]
+}
+
+func (t *legacyExternalMirrorTask) ModelID() string {
+	return fmt.Sprintf("src:%s:ext:%s:%s", t.TaskID, t.Tracker, t.IssueID)
+}
+
+// migrateExternalMirrorTasks moves the pairs of the issues saved by the previous versions to the mirror tasks.
+// The values of the issues are taken as synced on the next pull (see mirrorTaskSyncer.syncExternalMirrorTask).
+func (s *ChangeManager) migrateExternalMirrorTasks(ctx context.Context) {
+	iter := s.store.FirestoreClient().Collection(legacyExternalMirrorTaskModel.CollectionName()).Documents(ctx)
+	for _, model := range s.store.Iterate(iter, legacyExternalMirrorTaskModel) {
+		legacy := model.(*legacyExternalMirrorTask)
+		mirror := s.store.ModelMirrorTaskOf(legacy.TaskID, tracker.TaskRef{Provider: legacy.Tracker, ID: legacy.IssueID})
+		mirror.MirrorLocation = tracker.Location{Provider: legacy.Tracker, ID: legacy.Target}
+		mirror.MirrorKey = legacy.IssueKey
+		mirror.MirrorURL = legacy.IssueURL
+		mirror.RuleName = legacy.RuleName
+		mirror.CreatedAt = legacy.CreatedAt
+		mirror.CommentIDs = legacy.CommentIDs
+		mirror.Destroyed = legacy.Destroyed
+		mirror.DestroyedReason = legacy.DestroyedReason
+		mirror.DestroyedAt = legacy.DestroyedAt
+		if err := s.store.UpsertMirrorTask(ctx, mirror); err != nil {
+			s.log.Warn("failed to migrate the pair of the issue", zap.Error(err), zap.String("model_id", legacy.ModelID()))
+			continue
+		}
+		err := s.store.DeleteModel(ctx, legacy)
+		warnErrorIf(s.log, err, "failed to delete the migrated pair of the issue", "model_id", legacy.ModelID())
+	}
+}
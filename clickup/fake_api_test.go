@@ -0,0 +1,792 @@
+package clickup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gebv/asap-tools/clickup/api"
+)
+
+const (
+	testTeamID   = "100"
+	testFolderID = "200"
+)
+
+// fakeClickUp the in-memory ClickUp API (the tasks, the comments and the tags of the tasks).
+type fakeClickUp struct {
+	mu       sync.Mutex
+	nextID   int
+	clock    int64
+	tasks    map[string]*fakeTask
+	comments map[string][]fakeComment
+	// the content of the attachments by ID of the attachment
+	files       map[string][]byte
+	timeEntries map[string]*fakeTimeEntry
+	// the requests in the format "<METHOD> <path>" (without the prefix of the version of API)
+	requests []string
+}
+
+type fakeTask struct {
+	ID             string
+	Name           string
+	Description    string
+	Status         string
+	StatusType     string
+	ListID         string
+	Parent         string
+	Archived       bool
+	Tags           []string
+	Assignees      []int64
+	PriorityID     *int
+	TimeEstimateMs *int64
+	DueDate        *int64
+	StartDate      *int64
+	Links          []string
+	Checklists     []api.Checklist
+	Attachments    []api.Attachment
+	UpdatedAt      int64
+}
+
+type fakeTimeEntry struct {
+	ID          string
+	TaskID      string
+	Description string
+	StartTs     int64
+	DurationMs  int64
+	Billable    bool
+	Username    string
+}
+
+type fakeComment struct {
+	ID       string
+	Text     string
+	Assignee string
+	Date     int64
+}
+
+func newFakeClickUp() *fakeClickUp {
+	return &fakeClickUp{
+		clock:       1643709600000,
+		tasks:       map[string]*fakeTask{},
+		comments:    map[string][]fakeComment{},
+		files:       map[string][]byte{},
+		timeEntries: map[string]*fakeTimeEntry{},
+	}
+}
+
+// returns the client of ClickUp API for the fake server
+func newTestAPI(t *testing.T, fake *fakeClickUp) *api.API {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return api.NewAPIWithClient("test-token", &http.Client{Transport: rewriteHostTransport{target}})
+}
+
+// sends all requests to the host of the fake server
+type rewriteHostTransport struct {
+	target *url.URL
+}
+
+func (t rewriteHostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// addTask adds the task to the list and returns the task (the zero fields are populated by the defaults).
+func (f *fakeClickUp) addTask(task *fakeTask) *fakeTask {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if task.ID == "" {
+		f.nextID++
+		task.ID = fmt.Sprint("t", f.nextID)
+	}
+	if task.Status == "" {
+		task.Status, task.StatusType = "open", "open"
+	}
+	f.clock++
+	task.UpdatedAt = f.clock
+	f.tasks[task.ID] = task
+	return task
+}
+
+// apiTask returns the task in the format of ClickUp API.
+func (f *fakeClickUp) apiTask(taskID string) *api.Task {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.renderTask(f.tasks[taskID])
+}
+
+func (f *fakeClickUp) task(taskID string) *fakeTask {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tasks[taskID]
+}
+
+// subtasks returns IDs of the subtasks of the task (sorted).
+func (f *fakeClickUp) subtasks(parentID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := []string{}
+	for _, task := range f.tasks {
+		if task.Parent == parentID {
+			res = append(res, task.ID)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// tasksInList returns the tasks in the list (sorted by ID).
+func (f *fakeClickUp) tasksInList(listID string) []*fakeTask {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := []*fakeTask{}
+	for _, task := range f.tasks {
+		if task.ListID == listID {
+			res = append(res, task)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+func (f *fakeClickUp) taskComments(taskID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := []string{}
+	for _, comment := range f.comments[taskID] {
+		res = append(res, comment.Text)
+	}
+	return res
+}
+
+// countRequests returns the number of the requests with the prefix (eg "PUT /task/").
+func (f *fakeClickUp) countRequests(prefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := 0
+	for _, req := range f.requests {
+		if strings.HasPrefix(req, prefix) {
+			res++
+		}
+	}
+	return res
+}
+
+func (f *fakeClickUp) renderTask(task *fakeTask) *api.Task {
+	if task == nil {
+		return nil
+	}
+	dat := map[string]interface{}{
+		"id":                   task.ID,
+		"name":                 task.Name,
+		"description":          task.Description,
+		"markdown_description": task.Description,
+		"status":               map[string]string{"status": task.Status, "type": task.StatusType},
+		"date_created":         fmt.Sprint(task.UpdatedAt),
+		"date_updated":         fmt.Sprint(task.UpdatedAt),
+		"archived":             task.Archived,
+		"team_id":              testTeamID,
+		"url":                  "https://app.clickup.com/t/" + task.ID,
+		"list":                 map[string]string{"id": task.ListID, "name": "List " + task.ListID},
+		"folder":               map[string]string{"id": testFolderID},
+		"time_estimate":        task.TimeEstimateMs,
+	}
+	if task.Parent != "" {
+		dat["parent"] = task.Parent
+	}
+	if task.PriorityID != nil {
+		dat["priority"] = map[string]string{"id": fmt.Sprint(*task.PriorityID)}
+	}
+	if task.DueDate != nil {
+		dat["due_date"] = fmt.Sprint(*task.DueDate)
+	}
+	if task.StartDate != nil {
+		dat["start_date"] = fmt.Sprint(*task.StartDate)
+	}
+	if task.StatusType == "closed" {
+		dat["date_closed"] = fmt.Sprint(task.UpdatedAt)
+	}
+	tags := []map[string]string{}
+	for _, tag := range task.Tags {
+		tags = append(tags, map[string]string{"name": tag})
+	}
+	dat["tags"] = tags
+	assignees := []map[string]interface{}{}
+	for _, memberID := range task.Assignees {
+		assignees = append(assignees, map[string]interface{}{"id": memberID, "email": fmt.Sprint("member", memberID, "@example.com")})
+	}
+	dat["assignees"] = assignees
+	links := []map[string]string{}
+	for _, taskID := range task.Links {
+		links = append(links, map[string]string{"task_id": taskID})
+	}
+	dat["linked_tasks"] = links
+	checklists := []api.Checklist{}
+	checklists = append(checklists, task.Checklists...)
+	dat["checklists"] = checklists
+	attachments := []api.Attachment{}
+	attachments = append(attachments, task.Attachments...)
+	dat["attachments"] = attachments
+
+	raw, _ := json.Marshal(dat)
+	res := &api.Task{}
+	if err := json.Unmarshal(raw, res); err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func (f *fakeClickUp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// NOTE: the content of the attachments is downloaded without the token
+	if r.Header.Get("Authorization") != "test-token" && !strings.HasPrefix(r.URL.Path, "/files/") {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/v2"), "/api/v3")
+	f.requests = append(f.requests, r.Method+" "+path)
+	args := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	body := map[string]interface{}{}
+	if r.Header.Get("Content-Type") == "application/json" {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+	f.clock++
+
+	switch {
+	// POST /list/{list_id}/task
+	case len(args) == 3 && args[0] == "list" && args[2] == "task" && r.Method == http.MethodPost:
+		f.nextID++
+		task := &fakeTask{
+			ID:          fmt.Sprint("t", f.nextID),
+			ListID:      args[1],
+			Status:      "open",
+			StatusType:  "open",
+			Name:        stringOf(body["name"]),
+			Description: stringOf(body["markdown_description"]),
+			Parent:      stringOf(body["parent"]),
+			UpdatedAt:   f.clock,
+		}
+		if status := stringOf(body["status"]); status != "" {
+			task.Status = status
+		}
+		if linkTo := stringOf(body["links_to"]); linkTo != "" {
+			task.Links = append(task.Links, linkTo)
+		}
+		for _, tag := range listOf(body["tags"]) {
+			task.Tags = append(task.Tags, fmt.Sprint(tag))
+		}
+		for _, memberID := range listOf(body["assignees"]) {
+			id, _ := strconv.ParseInt(fmt.Sprint(memberID), 10, 64)
+			task.Assignees = append(task.Assignees, id)
+		}
+		if priority, ok := body["priority"].(float64); ok {
+			id := int(priority)
+			task.PriorityID = &id
+		}
+		if estimate, ok := body["time_estimate"].(float64); ok {
+			ms := int64(estimate)
+			task.TimeEstimateMs = &ms
+		}
+		f.tasks[task.ID] = task
+		writeFakeJSON(w, http.StatusOK, map[string]string{"id": task.ID})
+
+	// GET|PUT|DELETE /task/{task_id}
+	case len(args) == 2 && args[0] == "task":
+		task, exists := f.tasks[args[1]]
+		if !exists {
+			writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Task not found"})
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeFakeJSON(w, http.StatusOK, f.renderTask(task))
+		case http.MethodPut:
+			f.updateTask(task, body)
+			writeFakeJSON(w, http.StatusOK, f.renderTask(task))
+		case http.MethodDelete:
+			delete(f.tasks, task.ID)
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+	// GET|POST /task/{task_id}/comment
+	case len(args) == 3 && args[0] == "task" && args[2] == "comment":
+		if _, exists := f.tasks[args[1]]; !exists {
+			writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Task not found"})
+			return
+		}
+		if r.Method == http.MethodPost {
+			f.nextID++
+			comment := fakeComment{ID: fmt.Sprint(f.nextID), Text: stringOf(body["comment_text"]), Assignee: stringOf(body["assignee"]), Date: f.clock}
+			f.comments[args[1]] = append(f.comments[args[1]], comment)
+			writeFakeJSON(w, http.StatusOK, map[string]interface{}{"id": f.nextID})
+			return
+		}
+		list := []map[string]interface{}{}
+		// the newest first
+		for idx := len(f.comments[args[1]]) - 1; idx >= 0; idx-- {
+			comment := f.comments[args[1]][idx]
+			list = append(list, map[string]interface{}{
+				"id":           comment.ID,
+				"comment_text": comment.Text,
+				"user":         map[string]interface{}{"id": 1, "username": "bot"},
+				"date":         fmt.Sprint(comment.Date),
+			})
+		}
+		writeFakeJSON(w, http.StatusOK, map[string]interface{}{"comments": list})
+
+	// POST|DELETE /task/{task_id}/tag/{tag_name}
+	case len(args) == 4 && args[0] == "task" && args[2] == "tag":
+		task, exists := f.tasks[args[1]]
+		if !exists {
+			writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Task not found"})
+			return
+		}
+		tags := []string{}
+		for _, tag := range task.Tags {
+			if tag != args[3] {
+				tags = append(tags, tag)
+			}
+		}
+		if r.Method == http.MethodPost {
+			tags = append(tags, args[3])
+		}
+		task.Tags = tags
+		task.UpdatedAt = f.clock
+		writeFakeJSON(w, http.StatusOK, map[string]string{})
+
+	// PUT /workspaces/{team_id}/tasks/{task_id}/home_list/{list_id} (API v3)
+	case len(args) == 6 && args[0] == "workspaces" && args[4] == "home_list" && r.Method == http.MethodPut:
+		task, exists := f.tasks[args[3]]
+		if !exists {
+			writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Task not found"})
+			return
+		}
+		task.ListID = args[5]
+		task.UpdatedAt = f.clock
+		writeFakeJSON(w, http.StatusOK, map[string]string{})
+
+	// POST /task/{task_id}/attachment (multipart)
+	case len(args) == 3 && args[0] == "task" && args[2] == "attachment" && r.Method == http.MethodPost:
+		task, exists := f.tasks[args[1]]
+		if !exists {
+			writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Task not found"})
+			return
+		}
+		file, header, err := r.FormFile("attachment")
+		if err != nil {
+			writeFakeJSON(w, http.StatusBadRequest, map[string]string{"err": err.Error()})
+			return
+		}
+		content, _ := ioutil.ReadAll(file)
+		attachment := f.newAttachment(task, header.Filename, content)
+		writeFakeJSON(w, http.StatusOK, attachment)
+
+	// GET /files/{attachment_id}/{file_name} (the content of the attachment)
+	case len(args) == 3 && args[0] == "files" && r.Method == http.MethodGet:
+		content, exists := f.files[args[1]]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(content)
+
+	// GET /list/{list_id}/member
+	case len(args) == 3 && args[0] == "list" && args[2] == "member" && r.Method == http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, map[string]interface{}{"members": []api.Member{{ID: 1, Username: "bot"}}})
+
+	// GET|POST /team/{team_id}/time_entries
+	case len(args) == 3 && args[0] == "team" && args[2] == "time_entries":
+		if r.Method == http.MethodPost {
+			f.nextID++
+			entry := &fakeTimeEntry{
+				ID:          fmt.Sprint("te", f.nextID),
+				TaskID:      stringOf(body["tid"]),
+				Description: stringOf(body["description"]),
+				Billable:    body["billable"] == true,
+				Username:    "bot",
+			}
+			entry.StartTs = *int64PtrOf(body["start"])
+			entry.DurationMs = *int64PtrOf(body["duration"])
+			f.timeEntries[entry.ID] = entry
+			writeFakeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]string{"id": entry.ID}})
+			return
+		}
+		list := []map[string]interface{}{}
+		for _, entry := range f.sortedTimeEntries(r.URL.Query().Get("task_id")) {
+			list = append(list, map[string]interface{}{
+				"id":          entry.ID,
+				"task":        map[string]string{"id": entry.TaskID},
+				"user":        map[string]interface{}{"id": 1, "username": entry.Username},
+				"billable":    entry.Billable,
+				"start":       fmt.Sprint(entry.StartTs),
+				"duration":    fmt.Sprint(entry.DurationMs),
+				"description": entry.Description,
+			})
+		}
+		writeFakeJSON(w, http.StatusOK, map[string]interface{}{"data": list})
+
+	// PUT|DELETE /team/{team_id}/time_entries/{time_entry_id}
+	case len(args) == 4 && args[0] == "team" && args[2] == "time_entries":
+		entry, exists := f.timeEntries[args[3]]
+		if !exists {
+			writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Time entry not found"})
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.timeEntries, entry.ID)
+			writeFakeJSON(w, http.StatusOK, map[string]interface{}{"data": []string{}})
+			return
+		}
+		entry.Description = stringOf(body["description"])
+		entry.Billable = body["billable"] == true
+		entry.StartTs = *int64PtrOf(body["start"])
+		entry.DurationMs = *int64PtrOf(body["duration"])
+		writeFakeJSON(w, http.StatusOK, map[string]interface{}{"data": []string{}})
+
+	// POST /task/{task_id}/checklist
+	case len(args) == 3 && args[0] == "task" && args[2] == "checklist" && r.Method == http.MethodPost:
+		task, exists := f.tasks[args[1]]
+		if !exists {
+			writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Task not found"})
+			return
+		}
+		f.nextID++
+		task.Checklists = append(task.Checklists, api.Checklist{ID: fmt.Sprint("c", f.nextID), TaskID: task.ID, Name: stringOf(body["name"])})
+		writeFakeJSON(w, http.StatusOK, map[string]interface{}{"checklist": task.Checklists[len(task.Checklists)-1]})
+
+	// PUT|DELETE /checklist/{checklist_id}[/checklist_item[/{item_id}]]
+	case len(args) >= 2 && args[0] == "checklist":
+		task, idx := f.checklistByID(args[1])
+		if task == nil {
+			writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Checklist not found"})
+			return
+		}
+		checklist := &task.Checklists[idx]
+		switch {
+		case len(args) == 2 && r.Method == http.MethodPut:
+			checklist.Name = stringOf(body["name"])
+			writeFakeJSON(w, http.StatusOK, map[string]string{})
+		case len(args) == 2 && r.Method == http.MethodDelete:
+			task.Checklists = append(task.Checklists[:idx], task.Checklists[idx+1:]...)
+			writeFakeJSON(w, http.StatusOK, map[string]string{})
+		case len(args) == 3 && r.Method == http.MethodPost:
+			f.nextID++
+			checklist.Items = append(checklist.Items, api.ChecklistItem{ID: fmt.Sprint("i", f.nextID), Name: stringOf(body["name"])})
+			writeFakeJSON(w, http.StatusOK, map[string]interface{}{"checklist": checklist})
+		case len(args) == 4:
+			items := []api.ChecklistItem{}
+			for _, item := range checklist.Items {
+				if item.ID == args[3] {
+					if r.Method == http.MethodDelete {
+						continue
+					}
+					if name, ok := body["name"]; ok {
+						item.Name = stringOf(name)
+					}
+					if resolved, ok := body["resolved"].(bool); ok {
+						item.Resolved = resolved
+					}
+				}
+				items = append(items, item)
+			}
+			checklist.Items = items
+			writeFakeJSON(w, http.StatusOK, map[string]interface{}{"checklist": checklist})
+		default:
+			writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Route not found"})
+		}
+		task.UpdatedAt = f.clock
+
+	default:
+		writeFakeJSON(w, http.StatusNotFound, map[string]string{"err": "Route not found"})
+	}
+}
+
+// addAttachment adds the attachment to the task and returns the attachment.
+func (f *fakeClickUp) addAttachment(taskID, fileName string, content []byte) api.Attachment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.newAttachment(f.tasks[taskID], fileName, content)
+}
+
+func (f *fakeClickUp) newAttachment(task *fakeTask, fileName string, content []byte) api.Attachment {
+	f.nextID++
+	attachment := api.Attachment{
+		ID:     fmt.Sprint("a", f.nextID),
+		Title:  fileName,
+		Size:   int64(len(content)),
+		DateTs: f.clock,
+	}
+	attachment.URL = "https://attachments.clickup.com/files/" + attachment.ID + "/" + fileName
+	f.files[attachment.ID] = content
+	task.Attachments = append(task.Attachments, attachment)
+	task.UpdatedAt = f.clock
+	return attachment
+}
+
+// attachments returns the names of the attachments of the task.
+func (f *fakeClickUp) attachments(taskID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := []string{}
+	for _, attachment := range f.tasks[taskID].Attachments {
+		res = append(res, attachment.Title+":"+string(f.files[attachment.ID]))
+	}
+	return res
+}
+
+// addTimeEntry adds the time entry to the task and returns the time entry.
+func (f *fakeClickUp) addTimeEntry(entry *fakeTimeEntry) *fakeTimeEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	entry.ID = fmt.Sprint("te", f.nextID)
+	f.timeEntries[entry.ID] = entry
+	return entry
+}
+
+func (f *fakeClickUp) timeEntry(entryID string) *fakeTimeEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.timeEntries[entryID]
+}
+
+func (f *fakeClickUp) removeTimeEntry(entryID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.timeEntries, entryID)
+}
+
+// timeEntriesOf returns the time entries of the task in the format "<duration ms>:<description>" (sorted by the start).
+func (f *fakeClickUp) timeEntriesOf(taskID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := []string{}
+	for _, entry := range f.sortedTimeEntries(taskID) {
+		res = append(res, fmt.Sprintf("%d:%s", entry.DurationMs, entry.Description))
+	}
+	return res
+}
+
+func (f *fakeClickUp) sortedTimeEntries(taskID string) []*fakeTimeEntry {
+	res := []*fakeTimeEntry{}
+	for _, entry := range f.timeEntries {
+		if entry.TaskID == taskID {
+			res = append(res, entry)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].StartTs != res[j].StartTs {
+			return res[i].StartTs < res[j].StartTs
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// returns the task and the index of the checklist in the task (or nil)
+func (f *fakeClickUp) checklistByID(checklistID string) (*fakeTask, int) {
+	for _, task := range f.tasks {
+		for idx := range task.Checklists {
+			if task.Checklists[idx].ID == checklistID {
+				return task, idx
+			}
+		}
+	}
+	return nil, 0
+}
+
+// applies the fields of the update request to the task
+func (f *fakeClickUp) updateTask(task *fakeTask, body map[string]interface{}) {
+	task.UpdatedAt = f.clock
+	for field, value := range body {
+		switch field {
+		case "name":
+			task.Name = stringOf(value)
+		case "markdown_description", "description":
+			task.Description = stringOf(value)
+		case "status":
+			task.Status, task.StatusType = stringOf(value), "custom"
+			if task.Status == "closed" || task.Status == "done" {
+				task.StatusType = "closed"
+			}
+			if task.Status == "open" {
+				task.StatusType = "open"
+			}
+		case "parent":
+			task.Parent = stringOf(value)
+		case "archived":
+			task.Archived, _ = value.(bool)
+		case "priority":
+			task.PriorityID = nil
+			if priority, ok := value.(float64); ok && priority > 0 {
+				id := int(priority)
+				task.PriorityID = &id
+			}
+		case "time_estimate":
+			task.TimeEstimateMs = int64PtrOf(value)
+		case "due_date":
+			task.DueDate = int64PtrOf(value)
+		case "start_date":
+			task.StartDate = int64PtrOf(value)
+		case "assignees":
+			changes, _ := value.(map[string]interface{})
+			for _, memberID := range listOf(changes["rem"]) {
+				list := []int64{}
+				for _, id := range task.Assignees {
+					if float64(id) != memberID.(float64) {
+						list = append(list, id)
+					}
+				}
+				task.Assignees = list
+			}
+			for _, memberID := range listOf(changes["add"]) {
+				task.Assignees = append(task.Assignees, int64(memberID.(float64)))
+			}
+		}
+	}
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, in interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(in)
+}
+
+func stringOf(in interface{}) string {
+	if in == nil {
+		return ""
+	}
+	return fmt.Sprint(in)
+}
+
+func listOf(in interface{}) []interface{} {
+	list, _ := in.([]interface{})
+	return list
+}
+
+func int64PtrOf(in interface{}) *int64 {
+	switch v := in.(type) {
+	case float64:
+		res := int64(v)
+		return &res
+	case string:
+		res, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil
+		}
+		return &res
+	}
+	return nil
+}
+
+// syncEnv the environment of the tests of the sync: the fake ClickUp API and the in-memory storage.
+type syncEnv struct {
+	t     *testing.T
+	ctx   context.Context
+	fake  *fakeClickUp
+	api   *api.API
+	store *Storage
+}
+
+func newSyncEnv(t *testing.T) *syncEnv {
+	fake := newFakeClickUp()
+	return &syncEnv{
+		t:     t,
+		ctx:   context.Background(),
+		fake:  fake,
+		api:   newTestAPI(t, fake),
+		store: newTestStorage(t),
+	}
+}
+
+// load returns the task from the fake API and saves the task (and the list of the task) to the storage (as pulled by the changes).
+func (e *syncEnv) load(taskID string) *Task {
+	e.t.Helper()
+	taskAPI := e.fake.apiTask(taskID)
+	if taskAPI == nil {
+		e.t.Fatalf("not found the task %q in the fake API", taskID)
+	}
+	list := NewWithID(ListModel, taskAPI.List.ID).(*List)
+	list.Name = taskAPI.List.Name
+	if err := e.store.UpsertList(e.ctx, list); err != nil {
+		e.t.Fatalf("failed to save the list %q: %v", taskAPI.List.ID, err)
+	}
+	task := ModelTaskFromAPI(e.ctx, e.store, taskAPI)
+	if err := e.store.UpsertTask(e.ctx, task); err != nil {
+		e.t.Fatalf("failed to save the task %q: %v", taskID, err)
+	}
+	return task
+}
+
+// change pulls the changed task from the fake API and syncs the mirror tasks of the task.
+func (e *syncEnv) change(opts *SyncPreferences, taskID string) {
+	e.t.Helper()
+	oldTask := e.stored(taskID)
+	task := e.load(taskID)
+	MirrorTaskSyncer(e.api, e.store, nil).Sync(e.ctx, opts, oldTask, task, true)
+}
+
+// remove deletes the task from the fake API and syncs the mirror tasks of the deleted task.
+func (e *syncEnv) remove(opts *SyncPreferences, taskID string) {
+	e.t.Helper()
+	e.fake.mu.Lock()
+	delete(e.fake.tasks, taskID)
+	e.fake.mu.Unlock()
+
+	oldTask := e.stored(taskID)
+	task := e.stored(taskID)
+	task.Deleted = true
+	if err := e.store.UpsertTask(e.ctx, task); err != nil {
+		e.t.Fatalf("failed to save the deleted task %q: %v", taskID, err)
+	}
+	MirrorTaskSyncer(e.api, e.store, nil).Sync(e.ctx, opts, oldTask, task, true)
+}
+
+// stored returns the task from the storage (or the not existing model).
+func (e *syncEnv) stored(taskID string) *Task {
+	task := e.store.GetTask(e.ctx, taskID)
+	ModelTaskSetupLazyload(e.ctx, e.store, task)
+	return task
+}
+
+// pairs returns the pairs in which the task is the original task.
+func (e *syncEnv) pairs(taskID string) []*MirrorTask {
+	list, _ := e.store.AllMatchesForMirrorTasks(e.ctx, taskID)
+	res := []*MirrorTask{}
+	for _, mirror := range list {
+		if mirror.Orig.ID == taskID {
+			res = append(res, mirror)
+		}
+	}
+	return res
+}
+
+// testListURL returns the URL of the list in the team of the tests.
+func testListURL(listID string) string {
+	return "https://app.clickup.com/" + testTeamID + "/v/li/" + listID
+}
+
+func mustParsePreferences(t *testing.T, in string) *SyncPreferences {
+	t.Helper()
+	prefs, err := ParseSyncPreferences(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseSyncPreferences(): %v", err)
+	}
+	return prefs
+}
@@ -34,6 +34,10 @@ func TimestampNow() *Timestamp {
 	return timestamppb.Now()
 }
 
+func TimestampFromTime(in time.Time) *Timestamp {
+	return timestamppb.New(in)
+}
+
 func getRefDoc(ctx context.Context, doc *DocRef, model StoreModel) error {
 	err := storage.LoadDocAndPopulate(ctx, doc, model)
 	if err != nil {
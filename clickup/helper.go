@@ -27,6 +27,15 @@ func warnIfFailedRequest(l *zap.Logger, res interface{ StatusOK() bool }) {
 	}
 }
 
+func containsString(list []string, in string) bool {
+	for _, item := range list {
+		if item == in {
+			return true
+		}
+	}
+	return false
+}
+
 func msHuman(in int64) string {
 	return time.Duration(in * int64(time.Millisecond)).String()
 }
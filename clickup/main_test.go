@@ -0,0 +1,13 @@
+package clickup
+
+import (
+	"testing"
+
+	"github.com/gebv/asap-tools/storage"
+	"github.com/gebv/asap-tools/storage/storagetest"
+)
+
+// returns the storage on the new empty in-memory Firestore
+func newTestStorage(t *testing.T) *Storage {
+	return NewStorage(storage.NewStorage(storagetest.NewClient(t)))
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 
+	"cloud.google.com/go/firestore"
+	"github.com/gebv/asap-tools/tracker"
 	"go.uber.org/zap"
 )
 
@@ -13,26 +15,29 @@ var (
 	_               StoreModel = (*MirrorTask)(nil)
 )
 
-// ModelMirrorTaskFor returns prepared MirrorTask model with the corrects populate of the primary information.
+// ModelMirrorTaskFor returns prepared MirrorTask model of the task of ClickUp and the mirror task of ClickUp.
 // Because the user logic for preparing the storage model ID
 func (s *Storage) ModelMirrorTaskFor(taskID, mirrorTaskID string) *MirrorTask {
+	return s.ModelMirrorTaskOf(taskID, TaskRef(mirrorTaskID))
+}
+
+// ModelMirrorTaskOf returns prepared MirrorTask model of the task of ClickUp and the mirror task of any provider.
+func (s *Storage) ModelMirrorTaskOf(taskID string, mirror tracker.TaskRef) *MirrorTask {
 	return &MirrorTask{
-		TaskID:        taskID,
-		MirrorTaskID:  mirrorTaskID,
-		TaskRef:       s.DocRef(NewWithID(TaskModel, taskID)),
-		MirrorTaskRef: s.DocRef(NewWithID(TaskModel, mirrorTaskID)),
-		storage:       s,
+		Orig:    TaskRef(taskID),
+		Mirror:  mirror,
+		storage: s,
 	}
 }
 
 // alias to GetModel for custom model
 func (s *Storage) GetMirrorTask(ctx context.Context, modelID string) *MirrorTask {
-	taskID, mirrorTaskID, err := MirrorTaskModel.ParseID(modelID)
+	taskID, mirror, err := MirrorTaskModel.ParseID(modelID)
 	if err != nil {
 		s.log.Warn("invalid MirrorTask ID", zap.Error(err), zap.String("model_id", modelID))
 		return &MirrorTask{}
 	}
-	model := s.ModelMirrorTaskFor(taskID, mirrorTaskID)
+	model := s.ModelMirrorTaskOf(taskID, mirror)
 	s.GetModel(ctx, model)
 	return model
 }
@@ -48,59 +53,200 @@ func (s *Storage) UnlinkMirroredTask(ctx context.Context, taskID, mirrorTaskID s
 	return s.DeleteModel(ctx, model)
 }
 
-// AllMatchesForMirrorTasks returns union list mirror tasks by task ID and by mirror task ID.
+// AllMatchesForMirrorTasks returns union list mirror tasks by task ID and by mirror task ID (the tasks of ClickUp).
 func (s *Storage) AllMatchesForMirrorTasks(ctx context.Context, taskID string) (_ []*MirrorTask, _ bool) {
+	asMirror := s.mirrorTasksByRef(ctx, "Mirror", "MirrorTaskRef", taskID)
+	asOrig := s.mirrorTasksByRef(ctx, "Orig", "TaskRef", taskID)
+
+	crossedSync := len(asMirror) > 0 && len(asOrig) > 0
+
+	return append(asMirror, asOrig...), crossedSync
+}
+
+// returns the mirror tasks by the reference to the task of ClickUp.
+// The pairs saved before the references of the providers are found by the legacy reference to the document of the task.
+func (s *Storage) mirrorTasksByRef(ctx context.Context, field, legacyField, taskID string) []*MirrorTask {
 	cname := MirrorTaskModel.CollectionName()
+	queries := []firestore.Query{
+		s.FirestoreClient().Collection(cname).
+			Where(field+".Provider", "==", ProviderName).
+			Where(field+".ID", "==", taskID),
+		s.FirestoreClient().Collection(cname).
+			Where(legacyField, "==", s.DocRef(NewWithID(TaskModel, taskID))),
+	}
 
 	list := []*MirrorTask{}
-	{
-		iter := s.FirestoreClient().Collection(cname).
-			Where("MirrorTaskRef", "==", s.DocRef(NewWithID(TaskModel, taskID))).Documents(ctx)
-		res := s.Iterate(iter, MirrorTaskModel)
+	found := map[string]bool{}
+	for _, query := range queries {
+		res := s.Iterate(query.Documents(ctx), MirrorTaskModel)
 		for idx := range res {
 			task := res[idx].(*MirrorTask)
+			if found[task.ModelID()] {
+				continue
+			}
+			found[task.ModelID()] = true
 			task.storage = s
 			list = append(list, task)
 		}
 	}
-	len1 := len(list)
-	{
-		iter := s.FirestoreClient().Collection(cname).
-			Where("TaskRef", "==", s.DocRef(NewWithID(TaskModel, taskID))).Documents(ctx)
-		res := s.Iterate(iter, MirrorTaskModel)
+	return list
+}
 
-		for idx := range res {
-			task := res[idx].(*MirrorTask)
-			task.storage = s
-			list = append(list, task)
+// ActiveExternalMirrorTasks returns the pairs with the mirror tasks of other providers that are not destroyed (unlinked).
+func (s *Storage) ActiveExternalMirrorTasks(ctx context.Context) []*MirrorTask {
+	iter := s.FirestoreClient().Collection(MirrorTaskModel.CollectionName()).
+		Where("Destroyed", "==", false).Documents(ctx)
+	res := s.Iterate(iter, MirrorTaskModel)
+
+	list := []*MirrorTask{}
+	for idx := range res {
+		mirror := res[idx].(*MirrorTask)
+		if !mirror.external() {
+			continue
 		}
+		mirror.storage = s
+		list = append(list, mirror)
 	}
-	len2 := len(list)
-
-	crossedSync := len1 > 0 && len2 > len1
+	return list
+}
 
-	return list, crossedSync
+// ActiveMirrorTaskByRef returns the active pair of the mirror task of the provider or nil.
+func (s *Storage) ActiveMirrorTaskByRef(ctx context.Context, mirror tracker.TaskRef) *MirrorTask {
+	iter := s.FirestoreClient().Collection(MirrorTaskModel.CollectionName()).
+		Where("Mirror.Provider", "==", mirror.Provider).
+		Where("Mirror.ID", "==", mirror.ID).
+		Where("Destroyed", "==", false).
+		Limit(1).Documents(ctx)
+	res := s.Iterate(iter, MirrorTaskModel)
+	if len(res) == 0 {
+		return nil
+	}
+	model := res[0].(*MirrorTask)
+	model.storage = s
+	return model
 }
 
 type MirrorTask struct {
 	StoreModelCustomID
-	TaskID, MirrorTaskID string `firestore:"-"`
-	TaskRef              *DocRef
-	Task                 *Task `firestore:"-"`
-	MirrorTaskRef        *DocRef
-	MirrorTask           *Task    `firestore:"-"`
-	storage              *Storage `firestore:"-"`
-	Destroyed            bool
-	DestroyedReason      string
-	DestroyedAt          *Timestamp
+	// the original task (the task of ClickUp) and the mirror task (the task of any provider, see tracker.Registry)
+	Orig, Mirror tracker.TaskRef
+	Task         *Task    `firestore:"-"`
+	MirrorTask   *Task    `firestore:"-"`
+	storage      *Storage `firestore:"-"`
+	// the target of the mirror task of another provider (set by the syncer on the loading of the mirror task)
+	target *SyncRule_SpecOfExternalTarget `firestore:"-"`
+	// the task of another provider as loaded from the provider (set with the target)
+	issue           *tracker.Task `firestore:"-"`
+	Destroyed       bool
+	DestroyedReason string
+	DestroyedAt     *Timestamp
+	// the pair with the closed (archived) task will be destroyed after the time (see the grace period of the lifecycle spec)
+	PendingDestroyAt     *Timestamp
+	PendingDestroyReason string
+
+	// name of the rule by which the mirror task was created
+	RuleName string
+	// true if the pair is subtasks (the mirror task is a subtask of the mirror of the parent task)
+	Subtask   bool
+	CreatedAt *Timestamp
+	// the values of the original task last propagated to the mirror task
+	SyncedToMirror *MirrorTaskSnapshot
+	// the values of the mirror task last propagated to the original task
+	SyncedToOrig *MirrorTaskSnapshot
+
+	// the location (eg the GitHub repository) and the key (eg the number of the issue) of the mirror task of another provider
+	MirrorLocation tracker.Location
+	MirrorKey      string
+	MirrorURL      string
+	// the values of the mirror task of another provider at the last sync (the changes are found by the comparison)
+	MirrorState *MirrorTaskSnapshot
+	// the IDs of the comments of the original task => the IDs of the comments of the mirror task of another provider
+	// (the mirrored comments in both directions, the empty value for the comments that are not mirrored)
+	CommentIDs map[string]string
+
+	// the IDs of the checklists of the original task => the IDs of the checklists of the mirror task
+	ChecklistIDs map[string]string
+	// the IDs of the checklist items of the original task => the IDs of the checklist items of the mirror task
+	ChecklistItemIDs map[string]string
+	// the last synced resolved state of the checklist items (by the IDs of the checklist items of the original task)
+	ChecklistItemsResolved map[string]bool
+
+	// the IDs of the attachments of the original task => the IDs of the attachments of the mirror task
+	// (the copied attachments in both directions)
+	AttachmentIDs map[string]string
+
+	// the IDs of the time entries of the mirror task => the IDs of the time entries created in the original task
+	TimeEntryIDs map[string]string
+	// the last copied values of the time entries of the mirror task (by the IDs of the time entries of the mirror task)
+	TimeEntriesSynced map[string]MirrorTimeEntrySnapshot
+	// the last synced total tracked time of the mirror task
+	TimeTrackedMs int64
+}
+
+// MirrorTimeEntrySnapshot stores the values of the time entry of the mirror task at the time of the copying to the original task.
+type MirrorTimeEntrySnapshot struct {
+	Description string
+	StartTs     int64
+	DurationMs  int64
+	Billable    bool
+}
+
+// MirrorTaskSnapshot stores the values of the task fields at the time of the synchronization.
+type MirrorTaskSnapshot struct {
+	Name           string
+	Description    string
+	StatusName     string
+	PriorityID     *int
+	TimeEstimateMs *int64
+	DueDateAt      *Timestamp
+	StartDateAt    *Timestamp
+	AssigneeEmails []string
+	Tags           []string
+	// the task is closed, archived or deleted
+	Closed   bool
+	SyncedAt *Timestamp
+}
+
+func (s *MirrorTaskSnapshot) GetTags() []string {
+	if s == nil {
+		return nil
+	}
+	return s.Tags
+}
+
+func (s *MirrorTaskSnapshot) GetAssigneeEmails() []string {
+	if s == nil {
+		return nil
+	}
+	return s.AssigneeEmails
+}
+
+// NewMirrorTaskSnapshot returns snapshot of the fields of the task that are propagated to the other side.
+func NewMirrorTaskSnapshot(task *Task) *MirrorTaskSnapshot {
+	estimate := task.TotalEstimate()
+	snapshot := &MirrorTaskSnapshot{
+		Name:           task.Name,
+		Description:    task.Description,
+		StatusName:     task.StatusName,
+		PriorityID:     task.PriorityID,
+		DueDateAt:      task.DueDateAt,
+		StartDateAt:    task.StartDateAt,
+		AssigneeEmails: task.AssigneeEmails(),
+		Tags:           task.Tags,
+		Closed:         task.IsDeletedOrHidden(),
+		SyncedAt:       TimestampNow(),
+	}
+	if estimate > 0 {
+		snapshot.TimeEstimateMs = &estimate
+	}
+	return snapshot
 }
 
 func (t *MirrorTask) GetOrigTask(ctx context.Context) *Task {
 	if t.Task != nil {
 		return t.Task
 	}
-	t.Task = t.Task.NewModel().(*Task)
-	getRefDoc(ctx, t.TaskRef, t.Task)
+	t.Task = t.storage.GetTask(ctx, t.Orig.ID)
 	ModelTaskSetupLazyload(ctx, t.storage, t.Task)
 	return t.Task
 }
@@ -109,12 +255,35 @@ func (t *MirrorTask) GetMirrorTask(ctx context.Context) *Task {
 	if t.MirrorTask != nil {
 		return t.MirrorTask
 	}
-	t.MirrorTask = t.Task.NewModel().(*Task)
-	getRefDoc(ctx, t.MirrorTaskRef, t.MirrorTask)
+	if t.external() {
+		// the task of another provider is loaded by the syncer (see mirrorTaskSyncer.loadExternalMirrorTask),
+		// until then the values at the last sync are used
+		t.MirrorTask = detachedTaskFromSnapshot(t.Mirror.ID, t.MirrorURL, t.MirrorState)
+		return t.MirrorTask
+	}
+	t.MirrorTask = t.storage.GetTask(ctx, t.Mirror.ID)
 	ModelTaskSetupLazyload(ctx, t.storage, t.MirrorTask)
 	return t.MirrorTask
 }
 
+// returns true if the mirror task is the task of another provider (eg the issue of GitHub)
+func (t *MirrorTask) external() bool {
+	return t.Mirror.Provider != ProviderName
+}
+
+// returns the location and the key of the task of the pair for the provider of the task
+func (t *MirrorTask) locate(ref tracker.TaskRef) (tracker.Location, string) {
+	if ref == t.Mirror && t.external() {
+		return t.MirrorLocation, t.MirrorKey
+	}
+	return tracker.Location{Provider: ref.Provider}, ref.ID
+}
+
+// CreatedByRule returns true if the pair is created by the rule (or by any rule for the legacy pairs without the rule name).
+func (t *MirrorTask) CreatedByRule(ruleName string) bool {
+	return t.RuleName == "" || t.RuleName == ruleName
+}
+
 func (*MirrorTask) NewModel() StoreModel {
 	return &MirrorTask{}
 }
@@ -123,33 +292,38 @@ func (t *MirrorTask) CollectionName() string {
 	return "clickup_mirror_tasks"
 }
 
-func (t *MirrorTask) ParseID(in string) (taskID, mirrorTaskID string, err error) {
+// ParseID parses the ID in the format src:<task ID>:dst:<mirror task ID> for the mirror tasks of ClickUp
+// or src:<task ID>:dst:<provider>:<mirror task ID> for the mirror tasks of the other providers.
+func (t *MirrorTask) ParseID(in string) (taskID string, mirror tracker.TaskRef, err error) {
 	args := strings.Split(in, ":")
-	if len(args) != 4 {
-		return "", "", fmt.Errorf("invalid format ID %q", in)
+	switch {
+	case len(args) == 4:
+		mirror = TaskRef(args[3])
+	case len(args) == 5 && args[3] != ProviderName:
+		mirror = tracker.TaskRef{Provider: args[3], ID: args[4]}
+	default:
+		return "", tracker.TaskRef{}, fmt.Errorf("invalid format ID %q", in)
 	}
-	valid := args[0] == "src" && args[1] != "" && args[2] == "dst" && args[3] != ""
+	valid := args[0] == "src" && args[1] != "" && args[2] == "dst" && mirror.Provider != "" && mirror.ID != ""
 	if !valid {
-		return "", "", fmt.Errorf("invalid format ID %q", in)
+		return "", tracker.TaskRef{}, fmt.Errorf("invalid format ID %q", in)
 	}
-	return args[1], args[3], nil
+	return args[1], mirror, nil
 }
 
 func (t *MirrorTask) SetModelID(in string) {
-	var err error
-	t.TaskID, t.MirrorTaskID, err = t.ParseID(in)
+	taskID, mirror, err := t.ParseID(in)
 	if err != nil {
 		panic(err)
 	}
+	t.Orig, t.Mirror = TaskRef(taskID), mirror
 }
 
 func (t *MirrorTask) ModelID() string {
-	return fmt.Sprintf("src:%s:dst:%s", t.TaskID, t.MirrorTaskID)
-}
-
-func (t *Task) MirrorTaskName(ctx context.Context) string {
-	// <ListName>: <TaskName>
-	return t.GetList(ctx).Name + ": " + t.Name
+	if t.Mirror.Provider != ProviderName {
+		return fmt.Sprintf("src:%s:dst:%s:%s", t.Orig.ID, t.Mirror.Provider, t.Mirror.ID)
+	}
+	return fmt.Sprintf("src:%s:dst:%s", t.Orig.ID, t.Mirror.ID)
 }
 
 func (t *Task) MarkdownTaskID() string {
@@ -159,9 +333,10 @@ func (t *Task) MarkdownTaskID() string {
 	return "CU-" + t.ID
 }
 
-func (t *Task) MirrorTaskDescription() string {
-	return `Mirror task from ` + t.URL + ` ` + t.MarkdownTaskID() + `
-NOTE: DO NOT EDIT - description auto-update from original task
-* * *
-` + t.Description
+// returns the description in the markdown format (or the plain description if the markdown is not loaded)
+func (t *Task) DescriptionMarkdown() string {
+	if t.MarkdownDescription != "" {
+		return t.MarkdownDescription
+	}
+	return t.Description
 }
@@ -0,0 +1,184 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gebv/asap-tools/notify"
+	"go.uber.org/zap"
+)
+
+var (
+	MirrorTaskApprovalModel            = (*MirrorTaskApproval)(nil)
+	_                       StoreModel = (*MirrorTaskApproval)(nil)
+)
+
+// ModelMirrorTaskApprovalFor returns prepared MirrorTaskApproval model of the original task and the target list.
+func (s *Storage) ModelMirrorTaskApprovalFor(taskID, listID string) *MirrorTaskApproval {
+	return &MirrorTaskApproval{
+		TaskID: taskID,
+		ListID: listID,
+	}
+}
+
+// alias to GetModel for custom model
+func (s *Storage) GetMirrorTaskApproval(ctx context.Context, modelID string) *MirrorTaskApproval {
+	taskID, listID, err := MirrorTaskApprovalModel.ParseID(modelID)
+	if err != nil {
+		s.log.Warn("invalid MirrorTaskApproval ID", zap.Error(err), zap.String("model_id", modelID))
+		return &MirrorTaskApproval{}
+	}
+	model := s.ModelMirrorTaskApprovalFor(taskID, listID)
+	s.GetModel(ctx, model)
+	return model
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertMirrorTaskApproval(ctx context.Context, model *MirrorTaskApproval) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// MirrorTaskApproval the request of the creation of the mirror task in the target list
+// (for the rules with spec_add.require_approval).
+type MirrorTaskApproval struct {
+	StoreModelCustomID
+	TaskID, ListID string `firestore:"-"`
+
+	// name of the rule by which the mirror task is created
+	RuleName    string
+	RequestedAt *Timestamp
+	Approved    bool
+	// who approved the creation (eg the user of Slack)
+	ApprovedBy string
+	ApprovedAt *Timestamp
+}
+
+func (*MirrorTaskApproval) NewModel() StoreModel {
+	return &MirrorTaskApproval{}
+}
+
+func (m *MirrorTaskApproval) CollectionName() string {
+	return "clickup_mirror_task_approvals"
+}
+
+func (m *MirrorTaskApproval) ParseID(in string) (taskID, listID string, err error) {
+	args := strings.Split(in, ":")
+	if len(args) != 4 {
+		return "", "", fmt.Errorf("invalid format ID %q", in)
+	}
+	valid := args[0] == "src" && args[1] != "" && args[2] == "list" && args[3] != ""
+	if !valid {
+		return "", "", fmt.Errorf("invalid format ID %q", in)
+	}
+	return args[1], args[3], nil
+}
+
+func (m *MirrorTaskApproval) SetModelID(in string) {
+	var err error
+	m.TaskID, m.ListID, err = m.ParseID(in)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (m *MirrorTaskApproval) ModelID() string {
+	return fmt.Sprintf("src:%s:list:%s", m.TaskID, m.ListID)
+}
+
+// returns true if the creation of the mirror task in the target list is approved,
+// otherwise requests the approval by the notification (once per the original task and the target list)
+func (s *mirrorTaskSyncer) approvedMirrorTask(ctx context.Context, rule *MirrorTaskSpecification, spec *SyncRule_SpecOfAddTarget, task *Task) bool {
+	approval := s.store.GetMirrorTaskApproval(ctx, s.store.ModelMirrorTaskApprovalFor(task.ID, spec.GetAddToListID()).ModelID())
+	if approval.Approved {
+		return true
+	}
+	if approval.Exists() {
+		s.log.Debug("the mirror task awaits approval", zap.String("task_id", task.ID), zap.String("model_id", approval.ModelID()))
+		return false
+	}
+
+	approval = s.store.ModelMirrorTaskApprovalFor(task.ID, spec.GetAddToListID())
+	approval.RuleName = rule.Name
+	approval.RequestedAt = TimestampNow()
+	if err := s.store.UpsertMirrorTaskApproval(ctx, approval); err != nil {
+		warnErrorIf(s.log, err, "failed to request the approval of the mirror task", "model_id", approval.ModelID())
+		return false
+	}
+
+	s.notifier.notify(ctx, rule, task, &notify.Event{
+		Kind:    notify.EventApproval,
+		Details: spec.AddToList,
+		Actions: []notify.Action{{Kind: notify.ActionApprove, Value: approval.ModelID()}},
+	})
+	return false
+}
+
+// HandleNotificationAction applies the action from the interactive message (see notify.Action) on behalf of the user.
+func (s *ChangeManager) HandleNotificationAction(ctx context.Context, opts *SyncPreferences, action, value, user string) error {
+	switch action {
+	case notify.ActionApprove:
+		return s.ApproveMirrorTask(ctx, opts, value, user)
+	case notify.ActionUnlink:
+		return s.UnlinkMirrorTask(ctx, opts, value, "unlinked by "+user)
+	case notify.ActionResync:
+		return s.ResyncMirrorTask(ctx, opts, value)
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
+
+// ApproveMirrorTask approves the creation of the mirror task and syncs the original task (the mirror task is created).
+func (s *ChangeManager) ApproveMirrorTask(ctx context.Context, opts *SyncPreferences, modelID, user string) error {
+	approval := s.store.GetMirrorTaskApproval(ctx, modelID)
+	if !approval.Exists() {
+		return fmt.Errorf("not found approval %q", modelID)
+	}
+	if approval.Approved {
+		return fmt.Errorf("mirror task %q is already approved by %s", modelID, approval.ApprovedBy)
+	}
+
+	approval.Approved = true
+	approval.ApprovedBy = user
+	approval.ApprovedAt = TimestampNow()
+	if err := s.store.UpsertMirrorTaskApproval(ctx, approval); err != nil {
+		return fmt.Errorf("failed to approve mirror task %q: %w", modelID, err)
+	}
+
+	oldTask := s.store.GetTask(ctx, approval.TaskID)
+	task, err := s.fetchTask(ctx, approval.TaskID)
+	if err != nil {
+		return err
+	}
+	err = s.store.UpsertTask(ctx, task)
+	s.warnErrorIf(err, "failed to upsert the task", "task_id", approval.TaskID)
+	s.Sync(ctx, opts, oldTask, task, true)
+	return nil
+}
+
+// UnlinkMirrorTask destroys (unlinks) the pair with the reason.
+func (s *ChangeManager) UnlinkMirrorTask(ctx context.Context, opts *SyncPreferences, modelID, reason string) error {
+	mirror := s.store.GetMirrorTask(ctx, modelID)
+	if !mirror.Exists() {
+		return fmt.Errorf("not found mirror task %q", modelID)
+	}
+	if mirror.Destroyed {
+		return fmt.Errorf("mirror task %q is already unlinked", modelID)
+	}
+
+	syncer := MirrorTaskSyncer(s.api, s.store, s.providers)
+	syncer.notifier = newTaskNotifier(opts, s.store, s.notifiers, s.webhooks)
+	syncer.destroyMirrorTask(ctx, mirror, reason)
+	return nil
+}
+
+// ResyncMirrorTask syncs the pair again (the actual tasks are loaded from ClickUp API).
+func (s *ChangeManager) ResyncMirrorTask(ctx context.Context, opts *SyncPreferences, modelID string) error {
+	mirror := s.store.GetMirrorTask(ctx, modelID)
+	if !mirror.Exists() {
+		return fmt.Errorf("not found mirror task %q", modelID)
+	}
+	if mirror.Destroyed {
+		return fmt.Errorf("mirror task %q is unlinked - restore the pair", modelID)
+	}
+	return s.syncPair(ctx, opts, nil, mirror)
+}
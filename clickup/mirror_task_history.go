@@ -0,0 +1,88 @@
+package clickup
+
+import (
+	"context"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+)
+
+var (
+	MirrorTaskHistoryModel            = (*MirrorTaskHistory)(nil)
+	_                      StoreModel = (*MirrorTaskHistory)(nil)
+)
+
+// kinds of actions in the history of the mirror task
+const (
+	MirrorTaskActionCreated     = "created"
+	MirrorTaskActionUpdated     = "updated"
+	MirrorTaskActionCommentSent = "comment_sent"
+	MirrorTaskActionUnlinked    = "unlinked"
+	MirrorTaskActionMoved       = "moved"
+	MirrorTaskActionRestored    = "restored"
+	MirrorTaskActionLinked      = "linked"
+)
+
+// directions of the synchronization
+const (
+	SyncDirectionToMirror = "orig_to_mirror"
+	SyncDirectionToOrig   = "mirror_to_orig"
+	SyncDirectionBoth     = "both"
+)
+
+// returns true if the configured direction covers the direction of the synchronization
+func allowedSyncDirection(configured, direction string) bool {
+	configured = strings.ToLower(configured)
+	return configured == SyncDirectionBoth || configured == direction
+}
+
+// AppendMirrorTaskHistory adds the entry to the history (append-only subcollection) of the mirror task.
+func (s *Storage) AppendMirrorTaskHistory(ctx context.Context, mirror *MirrorTask, entry *MirrorTaskHistory) error {
+	entry.MirrorTaskModelID = mirror.ModelID()
+	if entry.CreatedAt == nil {
+		entry.CreatedAt = TimestampNow()
+	}
+	return s.UpsertModel(ctx, entry)
+}
+
+// ListMirrorTaskHistory returns the history of the mirror task (the oldest first).
+func (s *Storage) ListMirrorTaskHistory(ctx context.Context, mirror *MirrorTask) []*MirrorTaskHistory {
+	factory := &MirrorTaskHistory{MirrorTaskModelID: mirror.ModelID()}
+
+	iter := s.FirestoreClient().Collection(factory.CollectionName()).
+		OrderBy("CreatedAt", firestore.Asc).Documents(ctx)
+	res := s.Iterate(iter, factory)
+
+	list := []*MirrorTaskHistory{}
+	for idx := range res {
+		entry := res[idx].(*MirrorTaskHistory)
+		entry.MirrorTaskModelID = mirror.ModelID()
+		list = append(list, entry)
+	}
+	return list
+}
+
+// MirrorTaskHistory the entry of the actions taken on the mirror task.
+// Stored in the subcollection of the mirror task.
+type MirrorTaskHistory struct {
+	StdStoreModel
+	MirrorTaskModelID string `firestore:"-"`
+
+	Action    string
+	Direction string
+	// the task on which the action was taken
+	TaskID string
+	// the names of the updated fields
+	Fields    []string
+	Comment   string
+	Reason    string
+	CreatedAt *Timestamp
+}
+
+func (*MirrorTaskHistory) NewModel() StoreModel {
+	return &MirrorTaskHistory{}
+}
+
+func (m *MirrorTaskHistory) CollectionName() string {
+	return MirrorTaskModel.CollectionName() + "/" + m.MirrorTaskModelID + "/history"
+}
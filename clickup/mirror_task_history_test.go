@@ -0,0 +1,56 @@
+package clickup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStorage_MirrorTaskHistory(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	mirror := store.ModelMirrorTaskFor("orig1", "mirror1")
+	other := store.ModelMirrorTaskFor("orig2", "mirror2")
+
+	startedAt := time.Now().Add(-time.Hour)
+	entries := []*MirrorTaskHistory{
+		{Action: MirrorTaskActionCreated, Direction: SyncDirectionToMirror, TaskID: "mirror1", CreatedAt: TimestampFromTime(startedAt)},
+		{Action: MirrorTaskActionCommentSent, Direction: SyncDirectionToMirror, TaskID: "mirror1", Comment: "text", CreatedAt: TimestampFromTime(startedAt.Add(2 * time.Minute))},
+		{Action: MirrorTaskActionUpdated, Direction: SyncDirectionToOrig, TaskID: "orig1", Fields: []string{"status"}, CreatedAt: TimestampFromTime(startedAt.Add(time.Minute))},
+	}
+	for _, entry := range entries {
+		if err := store.AppendMirrorTaskHistory(ctx, mirror, entry); err != nil {
+			t.Fatalf("AppendMirrorTaskHistory(): %v", err)
+		}
+	}
+	if err := store.AppendMirrorTaskHistory(ctx, other, &MirrorTaskHistory{Action: MirrorTaskActionUnlinked, Reason: "deleted"}); err != nil {
+		t.Fatalf("AppendMirrorTaskHistory(): %v", err)
+	}
+
+	list := store.ListMirrorTaskHistory(ctx, mirror)
+	got := []string{}
+	for _, entry := range list {
+		got = append(got, entry.Action+":"+entry.Direction+":"+entry.TaskID)
+		if entry.MirrorTaskModelID != mirror.ModelID() {
+			t.Errorf("MirrorTaskModelID = %q, want %q", entry.MirrorTaskModelID, mirror.ModelID())
+		}
+	}
+	want := []string{
+		"created:orig_to_mirror:mirror1",
+		"updated:mirror_to_orig:orig1",
+		"comment_sent:orig_to_mirror:mirror1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListMirrorTaskHistory() = %v, want %v (the oldest first)", got, want)
+	}
+	if list[1].Fields[0] != "status" || list[2].Comment != "text" {
+		t.Errorf("ListMirrorTaskHistory() lost the details: %+v, %+v", list[1], list[2])
+	}
+
+	otherList := store.ListMirrorTaskHistory(ctx, other)
+	if len(otherList) != 1 || otherList[0].Reason != "deleted" || otherList[0].CreatedAt == nil {
+		t.Errorf("ListMirrorTaskHistory() of another mirror task = %+v", otherList)
+	}
+}
@@ -0,0 +1,141 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// ListDestroyedMirrorTasks returns all destroyed (unlinked) pairs.
+func (s *Storage) ListDestroyedMirrorTasks(ctx context.Context) []*MirrorTask {
+	iter := s.FirestoreClient().Collection(MirrorTaskModel.CollectionName()).
+		Where("Destroyed", "==", true).Documents(ctx)
+	res := s.Iterate(iter, MirrorTaskModel)
+
+	list := []*MirrorTask{}
+	for idx := range res {
+		mirror := res[idx].(*MirrorTask)
+		mirror.storage = s
+		list = append(list, mirror)
+	}
+	return list
+}
+
+// RestoreMirrorTask clears the destroyed state of the pair and syncs the pair.
+// NOTE: the pair can be destroyed again if the task is still closed or archived (see the lifecycle spec of the rule).
+func (s *ChangeManager) RestoreMirrorTask(ctx context.Context, opts *SyncPreferences, modelID string) error {
+	mirror := s.store.GetMirrorTask(ctx, modelID)
+	if !mirror.Exists() {
+		return fmt.Errorf("not found mirror task %q", modelID)
+	}
+	if !mirror.Destroyed {
+		return fmt.Errorf("mirror task %q is not destroyed", modelID)
+	}
+
+	reason := mirror.DestroyedReason
+	mirror.Destroyed = false
+	mirror.DestroyedAt = nil
+	mirror.DestroyedReason = ""
+	mirror.PendingDestroyAt = nil
+	mirror.PendingDestroyReason = ""
+	if err := s.store.UpsertMirrorTask(ctx, mirror); err != nil {
+		return fmt.Errorf("failed to restore mirror task %q: %w", modelID, err)
+	}
+	err := s.store.AppendMirrorTaskHistory(ctx, mirror, &MirrorTaskHistory{
+		Action: MirrorTaskActionRestored,
+		Reason: "destroyed with reason: " + reason,
+	})
+	s.warnErrorIf(err, "failed to append the history of the mirror task", "model_id", modelID)
+
+	return s.syncPair(ctx, opts, nil, mirror)
+}
+
+// LinkMirrorTask links the existing task as the mirror task of the original task (by the rule if specified).
+func (s *ChangeManager) LinkMirrorTask(ctx context.Context, opts *SyncPreferences, taskID, mirrorTaskID, ruleName string) error {
+	if ruleName != "" && opts.RuleByName(ruleName) == nil {
+		return fmt.Errorf("not found rule %q", ruleName)
+	}
+
+	mirror := s.store.GetMirrorTask(ctx, s.store.ModelMirrorTaskFor(taskID, mirrorTaskID).ModelID())
+	if mirror.Exists() && !mirror.Destroyed {
+		return fmt.Errorf("tasks %q and %q are already linked", taskID, mirrorTaskID)
+	}
+	if mirror.Exists() && mirror.Destroyed {
+		return fmt.Errorf("tasks %q and %q were linked before - restore the pair %q", taskID, mirrorTaskID, mirror.ModelID())
+	}
+
+	// the tasks are checked before the pair is saved
+	origOldTask, err := s.fetchTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.fetchTask(ctx, mirrorTaskID); err != nil {
+		return err
+	}
+
+	mirror = s.store.ModelMirrorTaskFor(taskID, mirrorTaskID)
+	mirror.RuleName = ruleName
+	mirror.CreatedAt = TimestampNow()
+	if err := s.store.UpsertMirrorTask(ctx, mirror); err != nil {
+		return fmt.Errorf("failed to link tasks %q and %q: %w", taskID, mirrorTaskID, err)
+	}
+	err = s.store.AppendMirrorTaskHistory(ctx, mirror, &MirrorTaskHistory{
+		Action:    MirrorTaskActionLinked,
+		Direction: SyncDirectionToMirror,
+		TaskID:    mirrorTaskID,
+	})
+	s.warnErrorIf(err, "failed to append the history of the mirror task", "model_id", mirror.ModelID())
+
+	// nothing has been pushed to the linked mirror task yet - the name and the description are pushed as changed
+	origOldTask.Name, origOldTask.Description, origOldTask.MarkdownDescription = "", "", ""
+	return s.syncPair(ctx, opts, origOldTask, mirror)
+}
+
+// syncPair loads the actual tasks of the pair from ClickUp API and processing (the original task first).
+// The task of another provider is loaded by the provider and its changes are pulled after the original task.
+// origOldTask - the previous state of the original task (the stored task if nil).
+func (s *ChangeManager) syncPair(ctx context.Context, opts *SyncPreferences, origOldTask *Task, mirror *MirrorTask) error {
+	ids := []string{mirror.Orig.ID}
+	if !mirror.external() {
+		ids = append(ids, mirror.Mirror.ID)
+	}
+	for _, id := range ids {
+		oldTask := s.store.GetTask(ctx, id)
+		if id == mirror.Orig.ID && origOldTask != nil {
+			oldTask = origOldTask
+		}
+		task, err := s.fetchTask(ctx, id)
+		if err != nil {
+			return err
+		}
+		err = s.store.UpsertTask(ctx, task)
+		s.warnErrorIf(err, "failed to upsert the task", "task_id", id)
+		s.Sync(ctx, opts, oldTask, task, true)
+	}
+	if mirror.external() {
+		// the pair is reloaded with the changes saved by the processing of the original task
+		mirror = s.store.GetMirrorTask(ctx, mirror.ModelID())
+		if mirror.Destroyed {
+			return nil
+		}
+		syncer := MirrorTaskSyncer(s.api, s.store, s.providers)
+		syncer.notifier = newTaskNotifier(opts, s.store, s.notifiers, s.webhooks)
+		syncer.syncExternalMirrorTask(ctx, opts, mirror)
+	}
+	return nil
+}
+
+// loads the task (and the related data of the list) from ClickUp API
+// NOTE: the task is not saved to the database (the stored task is the old task for the processing)
+func (s *ChangeManager) fetchTask(ctx context.Context, taskID string) (*Task, error) {
+	res := s.api.TaskByID(ctx, taskID)
+	warnIfFailedRequest(s.log, res)
+	if !res.StatusOK() {
+		return nil, fmt.Errorf("failed to get task %q from ClickUp API", taskID)
+	}
+	s.fetchAndUpdateListAndListRelatedData(ctx, res.List.ID)
+
+	s.log.Debug("loaded the task of the pair", zap.String("task_id", taskID))
+	return ModelTaskFromAPI(ctx, s.store, &res.Task), nil
+}
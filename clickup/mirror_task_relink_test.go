@@ -0,0 +1,106 @@
+package clickup
+
+import (
+	"strings"
+	"testing"
+)
+
+const testRelinkRule = `
+mirror_task_rules:
+  - name: rule
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    spec_add:
+      add_to_list: https://app.clickup.com/100/v/li/20
+`
+
+func TestChangeManager_LinkMirrorTask(t *testing.T) {
+	env := newSyncEnv(t)
+	opts := mustParsePreferences(t, testRelinkRule)
+	manage := NewChangeManager(env.api, env.store)
+
+	// the tasks are already in the database (processed before the link)
+	orig := env.fake.addTask(&fakeTask{Name: "Task", Description: "the description", ListID: "10"})
+	other := env.fake.addTask(&fakeTask{Name: "Other", ListID: "20"})
+	env.load(orig.ID)
+	env.load(other.ID)
+
+	if err := manage.LinkMirrorTask(env.ctx, opts, orig.ID, other.ID, "unknown"); err == nil {
+		t.Error("LinkMirrorTask() by the unknown rule must return error")
+	}
+	if err := manage.LinkMirrorTask(env.ctx, opts, orig.ID, "not-found", "rule"); err == nil {
+		t.Error("LinkMirrorTask() with the not found task must return error")
+	}
+	if pairs := env.pairs(orig.ID); len(pairs) != 0 {
+		t.Fatalf("the pairs after the failed link = %+v, want none", pairs)
+	}
+
+	if err := manage.LinkMirrorTask(env.ctx, opts, orig.ID, other.ID, "rule"); err != nil {
+		t.Fatalf("LinkMirrorTask(): %v", err)
+	}
+	pairs := env.pairs(orig.ID)
+	if len(pairs) != 1 || pairs[0].RuleName != "rule" || pairs[0].Destroyed {
+		t.Fatalf("the pairs of the linked task = %+v, want the only pair by the rule", pairs)
+	}
+	mirrorTask := env.fake.task(other.ID)
+	if mirrorTask.Name != "List 10: Task" {
+		t.Errorf("the name of the linked mirror task = %q, want the name of the original task", mirrorTask.Name)
+	}
+	if !strings.Contains(mirrorTask.Description, "CU-"+orig.ID) || !strings.Contains(mirrorTask.Description, "the description") {
+		t.Errorf("the description of the linked mirror task = %q, want the description of the original task", mirrorTask.Description)
+	}
+	history := env.store.ListMirrorTaskHistory(env.ctx, pairs[0])
+	if len(history) == 0 || history[0].Action != MirrorTaskActionLinked {
+		t.Errorf("the history of the linked pair = %+v, want linked first", history)
+	}
+
+	if err := manage.LinkMirrorTask(env.ctx, opts, orig.ID, other.ID, "rule"); err == nil {
+		t.Error("LinkMirrorTask() for the linked tasks must return error")
+	}
+}
+
+func TestChangeManager_RestoreMirrorTask(t *testing.T) {
+	env := newSyncEnv(t)
+	opts := mustParsePreferences(t, testRelinkRule)
+	manage := NewChangeManager(env.api, env.store)
+
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+	env.change(opts, orig.ID)
+	mirrorID := mirrorTaskIDOf(t, env, orig.ID)
+	loadList(env, "20")
+	mirror := env.pairs(orig.ID)[0]
+
+	if err := manage.RestoreMirrorTask(env.ctx, opts, mirror.ModelID()); err == nil {
+		t.Error("RestoreMirrorTask() for the linked pair must return error")
+	}
+	if err := manage.RestoreMirrorTask(env.ctx, opts, env.store.ModelMirrorTaskFor(orig.ID, "not-found").ModelID()); err == nil {
+		t.Error("RestoreMirrorTask() for the not found pair must return error")
+	}
+
+	MirrorTaskSyncer(env.api, env.store, nil).destroyMirrorTask(env.ctx, mirror, "unlinked by the test")
+	// changed while the pair is unlinked
+	env.fake.task(orig.ID).Name = "Renamed"
+
+	if err := manage.RestoreMirrorTask(env.ctx, opts, mirror.ModelID()); err != nil {
+		t.Fatalf("RestoreMirrorTask(): %v", err)
+	}
+	mirror = env.store.GetMirrorTask(env.ctx, mirror.ModelID())
+	if mirror.Destroyed || mirror.DestroyedAt != nil || mirror.DestroyedReason != "" {
+		t.Errorf("the restored pair = %+v, want the linked pair", mirror)
+	}
+	if got := env.fake.task(mirrorID).Name; got != "List 10: Renamed" {
+		t.Errorf("the name of the mirror task after the restore = %q, want the synced name", got)
+	}
+	history := env.store.ListMirrorTaskHistory(env.ctx, mirror)
+	restored := false
+	for _, entry := range history {
+		if entry.Action == MirrorTaskActionRestored && entry.Reason == "destroyed with reason: unlinked by the test" {
+			restored = true
+		}
+	}
+	if !restored {
+		t.Errorf("the history of the restored pair = %+v, want the restored entry", history)
+	}
+}
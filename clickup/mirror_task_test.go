@@ -0,0 +1,90 @@
+package clickup
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+func TestMirrorTask_ParseID(t *testing.T) {
+	tests := []struct {
+		in         string
+		wantTaskID string
+		wantMirror tracker.TaskRef
+		wantErr    bool
+	}{
+		{in: "src:orig1:dst:mirror1", wantTaskID: "orig1", wantMirror: TaskRef("mirror1")},
+		{in: "src:orig1:dst:github:42", wantTaskID: "orig1", wantMirror: tracker.TaskRef{Provider: "github", ID: "42"}},
+		{in: "src:orig1:dst:clickup:mirror1", wantErr: true},
+		{in: "src:orig1:dst:", wantErr: true},
+		{in: "orig1:mirror1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			taskID, mirror, err := MirrorTaskModel.ParseID(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if taskID != tt.wantTaskID || mirror != tt.wantMirror {
+				t.Errorf("ParseID() = %q, %v, want %q, %v", taskID, mirror, tt.wantTaskID, tt.wantMirror)
+			}
+			model := (&Storage{}).ModelMirrorTaskOf(taskID, mirror)
+			if model.ModelID() != tt.in {
+				t.Errorf("ModelID() = %q, want %q", model.ModelID(), tt.in)
+			}
+		})
+	}
+}
+
+func TestStorage_AllMatchesForMirrorTasks(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	for _, mirror := range []*MirrorTask{
+		store.ModelMirrorTaskFor("orig1", "mirror1"),
+		store.ModelMirrorTaskOf("orig1", tracker.TaskRef{Provider: "github", ID: "42"}),
+		store.ModelMirrorTaskFor("mirror1", "mirror2"),
+		store.ModelMirrorTaskFor("orig2", "mirror3"),
+	} {
+		if err := store.UpsertMirrorTask(ctx, mirror); err != nil {
+			t.Fatalf("UpsertMirrorTask(): %v", err)
+		}
+	}
+	// the pair saved with the references to the documents of the tasks
+	_, err := store.FirestoreClient().Collection(MirrorTaskModel.CollectionName()).Doc("src:orig3:dst:mirror1").Set(ctx, map[string]interface{}{
+		"TaskRef":       store.DocRef(NewWithID(TaskModel, "orig3")),
+		"MirrorTaskRef": store.DocRef(NewWithID(TaskModel, "mirror1")),
+	})
+	if err != nil {
+		t.Fatalf("Set(): %v", err)
+	}
+
+	list, crossed := store.AllMatchesForMirrorTasks(ctx, "mirror1")
+	got := []string{}
+	for _, mirror := range list {
+		got = append(got, mirror.ModelID())
+	}
+	sort.Strings(got)
+	want := []string{"src:mirror1:dst:mirror2", "src:orig1:dst:mirror1", "src:orig3:dst:mirror1"}
+	if len(got) != len(want) {
+		t.Fatalf("AllMatchesForMirrorTasks() = %v, want %v", got, want)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Fatalf("AllMatchesForMirrorTasks() = %v, want %v", got, want)
+		}
+	}
+	if !crossed {
+		t.Errorf("AllMatchesForMirrorTasks() crossed = false, want true")
+	}
+
+	list, crossed = store.AllMatchesForMirrorTasks(ctx, "orig1")
+	if len(list) != 2 || crossed {
+		t.Errorf("AllMatchesForMirrorTasks() = %d pairs (crossed %v), want 2 pairs (not crossed)", len(list), crossed)
+	}
+}
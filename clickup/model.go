@@ -22,14 +22,15 @@ var (
 
 type Task struct {
 	StdStoreModel
-	Name          string
-	CustomID      *string
-	Description   string
-	TextContent   string
-	StatusType    string
-	StatusName    string
-	DateCreatedAt *Timestamp
-	DateUpdatedAt *Timestamp
+	Name                string
+	CustomID            *string
+	Description         string
+	MarkdownDescription string
+	TextContent         string
+	StatusType          string
+	StatusName          string
+	DateCreatedAt       *Timestamp
+	DateUpdatedAt       *Timestamp
 
 	DateClosedAt     *Timestamp
 	DueDateAt        *Timestamp
@@ -44,6 +45,7 @@ type Task struct {
 	PriorityID       *int
 	Tags             []string
 	LinkedTasksRef   []*DocRef
+	Checklists       []TaskChecklist
 
 	TeamRef   *DocRef
 	ListRef   *DocRef
@@ -67,6 +69,9 @@ type Task struct {
 	LinkedTasks             []*Task   `firestore:"-"`
 	lazyLoadLinkedTasks     func()    `firestore:"-" json:"-"`
 	lazyLoadLinkedTasksOnce sync.Once `firestore:"-"`
+
+	// the task of another provider (eg the issue of GitHub) as the mirror task (not stored, see newDetachedTask)
+	detached bool `firestore:"-"`
 }
 
 func (t *Task) TeamID() string {
@@ -155,6 +160,18 @@ func (t *Task) GetLinkedTasks() []*Task {
 	return t.LinkedTasks
 }
 
+// AssigneeEmails returns emails of the assignees (in lower case).
+func (t *Task) AssigneeEmails() []string {
+	res := []string{}
+	for _, member := range t.GetAssignees() {
+		if member.Email == "" {
+			continue
+		}
+		res = append(res, strings.ToLower(member.Email))
+	}
+	return res
+}
+
 func (t *Task) AssignedByEmail(in string) bool {
 	members := t.GetAssignees()
 	for idx := range members {
@@ -165,6 +182,51 @@ func (t *Task) AssignedByEmail(in string) bool {
 	return false
 }
 
+type TaskChecklist struct {
+	ID    string
+	Name  string
+	Items []TaskChecklistItem
+}
+
+type TaskChecklistItem struct {
+	ID       string
+	Name     string
+	Resolved bool
+}
+
+// ChecklistByID returns the checklist of the task by ID or nil.
+func (t *Task) ChecklistByID(checklistID string) *TaskChecklist {
+	for idx := range t.Checklists {
+		if t.Checklists[idx].ID == checklistID {
+			return &t.Checklists[idx]
+		}
+	}
+	return nil
+}
+
+// returns the checklist which contains the item or nil
+func (t *Task) checklistOfItem(itemID string) *TaskChecklist {
+	for idx := range t.Checklists {
+		if t.Checklists[idx].ItemByID(itemID) != nil {
+			return &t.Checklists[idx]
+		}
+	}
+	return nil
+}
+
+// ItemByID returns the item of the checklist by ID or nil.
+func (c *TaskChecklist) ItemByID(itemID string) *TaskChecklistItem {
+	if c == nil {
+		return nil
+	}
+	for idx := range c.Items {
+		if c.Items[idx].ID == itemID {
+			return &c.Items[idx]
+		}
+	}
+	return nil
+}
+
 type List struct {
 	StdStoreModel
 	Name      string
@@ -0,0 +1,396 @@
+package clickup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/clickup/api"
+	"github.com/gebv/asap-tools/tracker"
+	"go.uber.org/zap"
+)
+
+// ProviderName name of ClickUp in the task references (see tracker.TaskRef).
+const ProviderName = "clickup"
+
+// NewProvider returns ClickUp as the provider of the tasks (the locations are the lists, the members are the users of ClickUp).
+func NewProvider(api *api.API) *Provider {
+	return &Provider{api: api}
+}
+
+// returns the provider which saves the created and the updated tasks to the storage (as pulled by the changes)
+func newStoredProvider(api *api.API, store *Storage) *Provider {
+	return &Provider{api: api, store: store}
+}
+
+type Provider struct {
+	api *api.API
+	// the storage of the tasks (nothing is saved if nil)
+	store *Storage
+}
+
+var _ tracker.Provider = (*Provider)(nil)
+
+// TaskRef returns the reference to the task of ClickUp.
+func TaskRef(taskID string) tracker.TaskRef {
+	return tracker.TaskRef{Provider: ProviderName, ID: taskID}
+}
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+func (p *Provider) Capabilities() tracker.Capability {
+	return tracker.CapComments | tracker.CapAssignedComments | tracker.CapTags | tracker.CapAssignees | tracker.CapStatus |
+		tracker.CapPriority | tracker.CapDueDate | tracker.CapStartDate | tracker.CapEstimate | tracker.CapSubtasks |
+		tracker.CapChecklists | tracker.CapAttachments | tracker.CapTimeTracking | tracker.CapMarkdown | tracker.CapLinks
+}
+
+// Fields returns the fields of the tasks in any list.
+func (p *Provider) Fields(location tracker.Location) []string {
+	return []string{
+		tracker.FieldName,
+		tracker.FieldDescription,
+		tracker.FieldStatus,
+		tracker.FieldPriority,
+		tracker.FieldTags,
+		tracker.FieldAssignees,
+		tracker.FieldDueDate,
+		tracker.FieldStartDate,
+		tracker.FieldEstimate,
+		tracker.FieldParent,
+		tracker.FieldArchived,
+	}
+}
+
+// GetTask returns the task by the ID (the key) in any list.
+func (p *Provider) GetTask(ctx context.Context, location tracker.Location, taskID string) (*tracker.Task, error) {
+	res := p.api.TaskByID(ctx, taskID)
+	if res.NotFound() {
+		return nil, tracker.ErrNotFound
+	}
+	if !res.StatusOK() {
+		return nil, failedRequest(res)
+	}
+	return providerTaskFromAPI(&res.Task), nil
+}
+
+// saves the task to the storage
+func (p *Provider) saveTask(ctx context.Context, taskAPI *api.Task) {
+	if p.store == nil {
+		return
+	}
+	err := p.store.UpsertTask(ctx, ModelTaskFromAPI(ctx, p.store, taskAPI))
+	warnErrorIf(zap.L().Named("clickup_provider"), err, "failed to save the task updated by the provider", "task_id", taskAPI.ID)
+}
+
+// CreateTask creates the task in the list (the location ID) and links it with the first task of ClickUp from the links.
+func (p *Provider) CreateTask(ctx context.Context, location tracker.Location, task *tracker.Task) (*tracker.Task, error) {
+	req := &api.CreateTaskRequest{
+		ListID:              location.ID,
+		Name:                task.Name,
+		StatusName:          task.Status,
+		DescriptionMarkdown: task.Description,
+		Tags:                task.Tags,
+		PriorityID:          task.PriorityID,
+		TimeEstimateMs:      task.TimeEstimateMs,
+	}
+	for _, member := range task.Assignees {
+		req.AssignIDs = append(req.AssignIDs, member.ID)
+	}
+	for _, link := range task.Links {
+		if link.Provider == ProviderName {
+			req.RefTaskID = link.ID
+			break
+		}
+	}
+	if task.Parent != nil && task.Parent.Provider == ProviderName {
+		req.ParentTaskID = task.Parent.ID
+	}
+
+	res := p.api.CreateTask(ctx, req)
+	if !res.StatusOK() || res.TaskID == "" {
+		return nil, failedRequest(res)
+	}
+	created := *task
+	created.Ref = TaskRef(res.TaskID)
+	created.Key = res.TaskID
+	created.Location = tracker.Location{Provider: ProviderName, ID: location.ID, Name: location.Name}
+	return &created, nil
+}
+
+// UpdateTask updates the fields of the task (the tags are updated by the separate requests).
+func (p *Provider) UpdateTask(ctx context.Context, location tracker.Location, task *tracker.Task, fields []string) (*tracker.Task, error) {
+	var current *tracker.Task
+	if containsString(fields, tracker.FieldAssignees) || containsString(fields, tracker.FieldTags) {
+		var err error
+		if current, err = p.GetTask(ctx, location, task.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	updTask := &api.UpdateTaskRequest{TaskID: task.Key}
+	for _, field := range fields {
+		switch field {
+		case tracker.FieldName:
+			updTask.Name = task.Name
+		case tracker.FieldDescription:
+			updTask.MarkdownDescription = task.Description
+		case tracker.FieldStatus:
+			updTask.StatusName = task.Status
+		case tracker.FieldPriority:
+			// the zero priority removes the priority of the task
+			zero := 0
+			updTask.Priority = &zero
+			if task.PriorityID != nil {
+				updTask.Priority = task.PriorityID
+			}
+		case tracker.FieldDueDate:
+			updTask.DueDate = unixMillisOrRemove(task.DueDate)
+		case tracker.FieldStartDate:
+			updTask.StartDate = unixMillisOrRemove(task.StartDate)
+		case tracker.FieldEstimate:
+			updTask.TimeEstimateMs = -1
+			if task.TimeEstimateMs != nil && *task.TimeEstimateMs > 0 {
+				updTask.TimeEstimateMs = *task.TimeEstimateMs
+			}
+		case tracker.FieldAssignees:
+			adds, removes := diffMemberIDs(current.Assignees, task.Assignees)
+			var err error
+			if updTask.AssigneeAdds, err = parseMemberIDs(adds); err != nil {
+				return nil, err
+			}
+			if updTask.AssigneeRemoves, err = parseMemberIDs(removes); err != nil {
+				return nil, err
+			}
+		case tracker.FieldParent:
+			if task.Parent == nil || task.Parent.Provider != ProviderName {
+				return nil, fmt.Errorf("the parent of the task of ClickUp must be the task of ClickUp")
+			}
+			updTask.ParentTaskID = task.Parent.ID
+		case tracker.FieldArchived:
+			archived := task.Archived
+			updTask.Archived = &archived
+		case tracker.FieldTags:
+			// the tags are updated by the separate requests
+		default:
+			return nil, fmt.Errorf("not supported field %q", field)
+		}
+	}
+
+	var updated *api.Task
+	if len(fields) > 1 || !containsString(fields, tracker.FieldTags) {
+		res := p.api.UpdateTask(ctx, updTask)
+		if res.NotFound() {
+			return nil, tracker.ErrNotFound
+		}
+		if !res.StatusOK() {
+			return nil, failedRequest(res)
+		}
+		updated = &res.Task
+	}
+
+	if containsString(fields, tracker.FieldTags) {
+		changed := false
+		for _, tag := range task.Tags {
+			if !containsString(current.Tags, tag) {
+				if res := p.api.AddTagToTask(ctx, task.Key, tag); !res.StatusOK() {
+					return nil, failedRequest(res)
+				}
+				changed = true
+			}
+		}
+		for _, tag := range current.Tags {
+			if !containsString(task.Tags, tag) {
+				if res := p.api.RemoveTagFromTask(ctx, task.Key, tag); !res.StatusOK() {
+					return nil, failedRequest(res)
+				}
+				changed = true
+			}
+		}
+		if changed || updated == nil {
+			// the response of the update does not contain the changed tags
+			res := p.api.TaskByID(ctx, task.Key)
+			if !res.StatusOK() {
+				return nil, failedRequest(res)
+			}
+			updated = &res.Task
+		}
+	}
+
+	p.saveTask(ctx, updated)
+	return providerTaskFromAPI(updated), nil
+}
+
+func (p *Provider) ListComments(ctx context.Context, location tracker.Location, taskID string) ([]*tracker.Comment, error) {
+	res := p.api.SearchCommentsInTask(ctx, taskID, "", 0)
+	if res.NotFound() {
+		return nil, tracker.ErrNotFound
+	}
+	if !res.StatusOK() {
+		return nil, failedRequest(res)
+	}
+	list := []*tracker.Comment{}
+	// ClickUp API returns the newest comments first
+	for idx := len(res.Comments) - 1; idx >= 0; idx-- {
+		comment := res.Comments[idx]
+		item := &tracker.Comment{
+			ID: comment.ID,
+			Author: tracker.Member{
+				ID:    fmt.Sprint(comment.User.ID),
+				Email: comment.User.Email,
+				Name:  comment.User.Username,
+			},
+			Body:      comment.CommentText,
+			CreatedAt: time.Unix(0, comment.DateAt*int64(time.Millisecond)),
+		}
+		if comment.Assignee != nil {
+			item.AssignTo = &tracker.Member{
+				ID:    fmt.Sprint(comment.Assignee.ID),
+				Email: comment.Assignee.Email,
+				Name:  comment.Assignee.Username,
+			}
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+func (p *Provider) AddComment(ctx context.Context, location tracker.Location, taskID string, comment *tracker.Comment) (*tracker.Comment, error) {
+	req := &api.AddCommentToTaskRequest{
+		TaskID:      taskID,
+		CommentText: comment.Body,
+	}
+	if comment.AssignTo != nil {
+		req.AssignToMemberID = comment.AssignTo.ID
+	}
+	res := p.api.AddCommentToTask(ctx, req)
+	if res.NotFound() {
+		return nil, tracker.ErrNotFound
+	}
+	if !res.StatusOK() {
+		return nil, failedRequest(res)
+	}
+	created := *comment
+	created.ID = res.ID.String()
+	return &created, nil
+}
+
+// ListMembers returns the members with access to the list (the location ID).
+func (p *Provider) ListMembers(ctx context.Context, location tracker.Location) ([]*tracker.Member, error) {
+	res := p.api.ListMembersOfList(ctx, location.ID)
+	if !res.StatusOK() {
+		return nil, failedRequest(res)
+	}
+	list := []*tracker.Member{}
+	for _, member := range res.Members {
+		list = append(list, &tracker.Member{
+			ID:    member.IDString(),
+			Email: member.Email,
+			Name:  member.Username,
+		})
+	}
+	return list, nil
+}
+
+// returns the task of the provider by the task of ClickUp API
+func providerTaskFromAPI(in *api.Task) *tracker.Task {
+	res := &tracker.Task{
+		Ref:            TaskRef(in.ID),
+		Key:            in.ID,
+		Location:       tracker.Location{Provider: ProviderName, ID: in.List.ID, Name: in.List.Name},
+		Name:           in.Name,
+		Description:    in.MarkdownDescription,
+		Status:         in.Status.Status,
+		Closed:         in.Archived || in.Status.Type == "closed" || in.Status.Type == "done",
+		Archived:       in.Archived,
+		Tags:           in.ListTags(),
+		Assignees:      []tracker.Member{},
+		DueDate:        timeFromMilliseconds(in.DueDate),
+		StartDate:      timeFromMilliseconds(in.StartDate),
+		TimeEstimateMs: in.TimeEstimateMs,
+		Links:          []tracker.TaskRef{},
+		URL:            in.URL,
+		UpdatedAt:      time.Unix(0, in.DateUpdatedTs*int64(time.Millisecond)),
+	}
+	if res.Description == "" {
+		res.Description = in.Description
+	}
+	if in.Parent != nil {
+		parent := TaskRef(*in.Parent)
+		res.Parent = &parent
+	}
+	if in.Priority != nil {
+		priorityID := in.Priority.ID
+		res.PriorityID = &priorityID
+	}
+	for _, assignee := range in.Assignees {
+		res.Assignees = append(res.Assignees, tracker.Member{
+			ID:    fmt.Sprint(assignee.ID),
+			Email: assignee.Email,
+			Name:  assignee.Username,
+		})
+	}
+	for _, taskID := range in.ListLinkedTaskIDs() {
+		res.Links = append(res.Links, TaskRef(taskID))
+	}
+	return res
+}
+
+func timeFromMilliseconds(in *int64) *time.Time {
+	if in == nil {
+		return nil
+	}
+	res := time.Unix(0, *in*int64(time.Millisecond))
+	return &res
+}
+
+// returns the IDs of the added and the removed members
+func diffMemberIDs(current, desired []tracker.Member) (adds, removes []string) {
+	currentIDs, desiredIDs := []string{}, []string{}
+	for _, member := range current {
+		currentIDs = append(currentIDs, member.ID)
+	}
+	for _, member := range desired {
+		desiredIDs = append(desiredIDs, member.ID)
+	}
+	for _, memberID := range desiredIDs {
+		if !containsString(currentIDs, memberID) {
+			adds = append(adds, memberID)
+		}
+	}
+	for _, memberID := range currentIDs {
+		if !containsString(desiredIDs, memberID) {
+			removes = append(removes, memberID)
+		}
+	}
+	return adds, removes
+}
+
+func parseMemberIDs(in []string) ([]int64, error) {
+	res := []int64{}
+	for _, memberID := range in {
+		id, err := strconv.ParseInt(memberID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q of the member of ClickUp: %w", memberID, err)
+		}
+		res = append(res, id)
+	}
+	return res, nil
+}
+
+func failedRequest(res interface{}) error {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", res), "*api.")
+	return errors.New("failed request in ClickUp API: " + name)
+}
+
+// returns the unix time in milliseconds or -1 (the value for the removing of the date in the update request)
+func unixMillisOrRemove(in *time.Time) int64 {
+	if in == nil {
+		return -1
+	}
+	return in.UnixNano() / int64(time.Millisecond)
+}
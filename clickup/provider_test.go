@@ -0,0 +1,71 @@
+package clickup
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/clickup/api"
+	"github.com/gebv/asap-tools/tracker"
+)
+
+func TestProviderTaskFromAPI(t *testing.T) {
+	in := &api.Task{}
+	err := json.Unmarshal([]byte(`{
+		"id": "abc1",
+		"name": "Task",
+		"description": "plain",
+		"markdown_description": "**markdown**",
+		"status": {"status": "done", "type": "closed"},
+		"date_updated": "1643709600000",
+		"due_date": "1643709600000",
+		"parent": "abc0",
+		"assignees": [{"id": 10, "username": "John", "email": "john@example.com"}],
+		"tags": [{"name": "bug"}],
+		"linked_tasks": [{"task_id": "abc2"}],
+		"list": {"id": "list1", "name": "Backlog"},
+		"priority": {"id": "2", "priority": "high"},
+		"url": "https://app.clickup.com/t/abc1"
+	}`), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dueDate := time.Unix(1643709600, 0)
+	parent := TaskRef("abc0")
+	priority := 2
+	want := &tracker.Task{
+		Ref:            TaskRef("abc1"),
+		Key:            "abc1",
+		Location:       tracker.Location{Provider: ProviderName, ID: "list1", Name: "Backlog"},
+		Parent:         &parent,
+		Name:           "Task",
+		Description:    "**markdown**",
+		Status:         "done",
+		Closed:         true,
+		PriorityID:     &priority,
+		Tags:           []string{"bug"},
+		Assignees:      []tracker.Member{{ID: "10", Email: "john@example.com", Name: "John"}},
+		DueDate:        &dueDate,
+		TimeEstimateMs: nil,
+		Links:          []tracker.TaskRef{TaskRef("abc2")},
+		URL:            "https://app.clickup.com/t/abc1",
+		UpdatedAt:      dueDate,
+	}
+	if got := providerTaskFromAPI(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("providerTaskFromAPI() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffMemberIDs(t *testing.T) {
+	current := []tracker.Member{{ID: "1"}, {ID: "2"}}
+	desired := []tracker.Member{{ID: "2"}, {ID: "3"}}
+	adds, removes := diffMemberIDs(current, desired)
+	if !reflect.DeepEqual(adds, []string{"3"}) || !reflect.DeepEqual(removes, []string{"1"}) {
+		t.Errorf("diffMemberIDs() = %v, %v", adds, removes)
+	}
+	if _, err := parseMemberIDs([]string{"john"}); err == nil {
+		t.Error("parseMemberIDs() for not number ID must return error")
+	}
+}
@@ -27,7 +27,7 @@ func (s *Storage) LoadSubTasks(ctx context.Context, task *Task) {
 	model := (*Task)(nil)
 	cname := model.CollectionName()
 
-	iter := s.FirestoreClient().Collection(cname).Where("ParentTaskID", "==", s.DocRef(task)).Documents(ctx)
+	iter := s.FirestoreClient().Collection(cname).Where("ParentTaskRef", "==", s.DocRef(task)).Documents(ctx)
 	res := s.Iterate(iter, model)
 
 	for idx := range res {
@@ -0,0 +1,573 @@
+package clickup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+	"github.com/gebv/asap-tools/tracker"
+	"go.uber.org/zap"
+)
+
+// the issue in the external tracker (eg GitHub Issues) created from the original task as the mirror task.
+// The pair is synced as the pair of the mirror task (see spec_sync, spec_lifecycle, templates and notify of the rule).
+// The external trackers are destination-only: the issue can't be the original task of the pair.
+type SyncRule_SpecOfExternalTarget struct {
+	// name of the provider of the tracker (eg github)
+	Tracker string `yaml:"tracker"`
+	// the target in the tracker (eg owner/repo for GitHub)
+	Target string `yaml:"target"`
+	// sync direction of the fields (orig_to_mirror, mirror_to_orig, both) (both by default)
+	Direction string `yaml:"direction,omitempty"`
+	// mirrors the new comments in both directions
+	SyncComments bool `yaml:"sync_comments,omitempty"`
+	// the emails of the members of ClickUp => the users of the tracker (eg the logins of GitHub)
+	// the assignees without the mapping are not synced
+	MemberMap map[string]string `yaml:"member_map,omitempty"`
+	// the names of the properties of the target by the fields of the task
+	// (title, labels, assignees, status, due_date, start_date, estimate, priority, milestone)
+	// (eg the properties of Notion database or the attributes of GitLab issue)
+	Properties map[string]string `yaml:"properties,omitempty"`
+	// the statuses of the original task => the statuses of the target (the statuses without the mapping are the same)
+	StatusMap map[string]string `yaml:"status_map,omitempty"`
+	// the changes of the issue are applied to the original task as the changes of the mirror task (see global_mirror_task_statuses):
+	// the status of the issue (by status_map) sets orig_task_status, the due date and the estimate are synced if sync_estimate.
+	// The status is pushed to the issue only on create.
+	MirrorTaskStatuses bool `yaml:"mirror_task_statuses,omitempty"`
+}
+
+// Validate returns error if the tracker or the target is not specified, the tracker is ClickUp or the direction is unknown.
+func (t *SyncRule_SpecOfExternalTarget) Validate() error {
+	if t.Tracker == "" || t.Target == "" {
+		return fmt.Errorf("tracker and target are required")
+	}
+	if t.Tracker == ProviderName {
+		// the mirror tasks in ClickUp are created by add_to_list and targets
+		return fmt.Errorf("tracker %q is not external", t.Tracker)
+	}
+	for field := range t.Properties {
+		switch field {
+		case tracker.FieldName, tracker.FieldTags, tracker.FieldAssignees, tracker.FieldStatus,
+			tracker.FieldDueDate, tracker.FieldStartDate, tracker.FieldEstimate, tracker.FieldPriority, tracker.FieldMilestone:
+		default:
+			return fmt.Errorf("unknown field %q in properties", field)
+		}
+	}
+	switch t.GetDirection() {
+	case SyncDirectionToMirror, SyncDirectionToOrig, SyncDirectionBoth:
+		return nil
+	}
+	return fmt.Errorf("unknown direction %q", t.Direction)
+}
+
+// returns the status of the target by the status of the original task
+func (t *SyncRule_SpecOfExternalTarget) externalStatus(statusName string) string {
+	for taskStatus, status := range t.StatusMap {
+		if strings.EqualFold(taskStatus, statusName) {
+			return status
+		}
+	}
+	return statusName
+}
+
+// returns the status of the original task by the status of the target
+func (t *SyncRule_SpecOfExternalTarget) taskStatus(status string) string {
+	for taskStatus, mappedStatus := range t.StatusMap {
+		if strings.EqualFold(mappedStatus, status) {
+			return taskStatus
+		}
+	}
+	return status
+}
+
+func (t *SyncRule_SpecOfExternalTarget) GetDirection() string {
+	if t.Direction == "" {
+		return SyncDirectionBoth
+	}
+	return strings.ToLower(t.Direction)
+}
+
+// AllowedSync returns true if the fields are synced in the direction.
+func (t *SyncRule_SpecOfExternalTarget) AllowedSync(direction string) bool {
+	return t.GetDirection() == SyncDirectionBoth || t.GetDirection() == direction
+}
+
+// returns the users of the tracker by the emails of the members (the members without the mapping are skipped)
+func (t *SyncRule_SpecOfExternalTarget) usersFor(emails []string) []string {
+	res := []string{}
+	for _, email := range emails {
+		for memberEmail, user := range t.MemberMap {
+			if strings.EqualFold(memberEmail, email) {
+				res = append(res, user)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// returns the emails of the members by the users of the tracker (the users without the mapping are skipped)
+func (t *SyncRule_SpecOfExternalTarget) emailsFor(users []string) []string {
+	res := []string{}
+	for _, user := range users {
+		for memberEmail, mappedUser := range t.MemberMap {
+			if strings.EqualFold(mappedUser, user) {
+				res = append(res, strings.ToLower(memberEmail))
+				break
+			}
+		}
+	}
+	return res
+}
+
+func timeFromTimestamp(in *Timestamp) *time.Time {
+	if in == nil {
+		return nil
+	}
+	res := in.AsTime()
+	return &res
+}
+
+func timestampFromTime(in *time.Time) *Timestamp {
+	if in == nil {
+		return nil
+	}
+	return TimestampFromTime(*in)
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func equalTimestamp(a, b *Timestamp) bool {
+	return equalTime(timeFromTimestamp(a), timeFromTimestamp(b))
+}
+
+func equalInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalInt64(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// returns true if the lists have the same values (case insensitive, in any order)
+func equalValues(a, b []string) bool {
+	setA, setB := lowerSet(a), lowerSet(b)
+	if len(setA) != len(setB) {
+		return false
+	}
+	for value := range setA {
+		if !setB[value] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *SyncRule_SpecOfAdd) GetExternal() []SyncRule_SpecOfExternalTarget {
+	if s == nil {
+		return nil
+	}
+	return s.External
+}
+
+// ExternalTarget returns the spec of the issue by the tracker and the target or nil.
+func (s *SyncRule_SpecOfAdd) ExternalTarget(tracker, target string) *SyncRule_SpecOfExternalTarget {
+	if s == nil {
+		return nil
+	}
+	for idx := range s.External {
+		if s.External[idx].Tracker == tracker && s.External[idx].Target == target {
+			return &s.External[idx]
+		}
+	}
+	return nil
+}
+
+func (r *MirrorTaskSpecification) GetSpecAdd() *SyncRule_SpecOfAdd {
+	if r == nil {
+		return nil
+	}
+	return r.SpecAdd
+}
+
+// returns true if the mirror task is the issue of the target
+func (t *MirrorTask) inTarget(target *SyncRule_SpecOfExternalTarget) bool {
+	return t.Mirror.Provider == target.Tracker && t.MirrorLocation.ID == target.Target
+}
+
+// newDetachedTask returns the task of another provider (eg the issue of GitHub) as the mirror task.
+// The task is not stored in the database and has no location in ClickUp (no team, folder and list).
+func newDetachedTask(id, url string) *Task {
+	task := NewWithID(TaskModel, id).(*Task)
+	task.URL = url
+	task.TeamRef, task.FolderRef, task.ListRef = &DocRef{}, &DocRef{}, &DocRef{}
+	task.detached = true
+	noop := func() {}
+	task.lazyLoadAssignees, task.lazyLoadSubTasks, task.lazyLoadLinkedTasks = noop, noop, noop
+	return task
+}
+
+// detachedTaskOf returns the task of another provider as the mirror task:
+// the status is mapped by status_map and the assignees are mapped by member_map (the users without the mapping have no email).
+func detachedTaskOf(target *SyncRule_SpecOfExternalTarget, in *tracker.Task) *Task {
+	task := newDetachedTask(in.Ref.ID, in.URL)
+	task.Name = in.Name
+	task.Description, task.MarkdownDescription = in.Description, in.Description
+	if in.Status != "" {
+		task.StatusName = target.taskStatus(in.Status)
+	}
+	task.PriorityID = in.PriorityID
+	task.Tags = in.Tags
+	task.DueDateAt = timestampFromTime(in.DueDate)
+	task.StartDateAt = timestampFromTime(in.StartDate)
+	task.TimeEstimateMs = in.TimeEstimateMs
+	task.Archived = in.Archived
+	if in.Closed {
+		task.DateClosedAt = TimestampFromTime(in.UpdatedAt)
+	}
+	task.Assignees = []*Member{}
+	for _, user := range in.Assignees {
+		member := &Member{Username: user.Name}
+		member.ID = user.ID
+		if emails := target.emailsFor([]string{user.ID}); len(emails) > 0 {
+			member.Email = emails[0]
+		}
+		task.Assignees = append(task.Assignees, member)
+	}
+	return task
+}
+
+// detachedTaskFromSnapshot returns the task of another provider with the values of the snapshot (the empty task if nil).
+func detachedTaskFromSnapshot(id, url string, snapshot *MirrorTaskSnapshot) *Task {
+	task := newDetachedTask(id, url)
+	if snapshot == nil {
+		return task
+	}
+	task.Name = snapshot.Name
+	task.Description, task.MarkdownDescription = snapshot.Description, snapshot.Description
+	task.StatusName = snapshot.StatusName
+	task.PriorityID = snapshot.PriorityID
+	task.Tags = snapshot.Tags
+	task.DueDateAt = snapshot.DueDateAt
+	task.StartDateAt = snapshot.StartDateAt
+	task.TimeEstimateMs = snapshot.TimeEstimateMs
+	if snapshot.Closed {
+		task.DateClosedAt = snapshot.SyncedAt
+	}
+	task.Assignees = []*Member{}
+	for _, email := range snapshot.AssigneeEmails {
+		task.Assignees = append(task.Assignees, &Member{Email: email})
+	}
+	return task
+}
+
+// loadExternalMirrorTask loads the task of another provider of the pair by the target of the rule of the pair.
+// The pair with the deleted (or transferred) task is unlinked. Returns false if the task is not loaded.
+func (s *mirrorTaskSyncer) loadExternalMirrorTask(ctx context.Context, opts *SyncPreferences, mirror *MirrorTask) bool {
+	l := s.log.With(zap.String("model_id", mirror.ModelID()))
+
+	rule := opts.RuleByName(mirror.RuleName)
+	target := rule.GetSpecAdd().ExternalTarget(mirror.Mirror.Provider, mirror.MirrorLocation.ID)
+	if target == nil {
+		l.Warn("not found the target of the mirror task in the rule", zap.String("rule_name", mirror.RuleName))
+		return false
+	}
+	mirror.target = target
+	// the properties of the target may be changed in the rule
+	mirror.MirrorLocation = target.Location()
+
+	issue, err := s.providers.GetTask(ctx, mirror.MirrorLocation, mirror.MirrorKey)
+	if errors.Is(err, tracker.ErrNotFound) {
+		msgData := newMirrorTaskTemplateData(ctx, rule, mirror.GetOrigTask(ctx), mirror.GetMirrorTask(ctx))
+		msgData.Issue = &tracker.Task{Ref: mirror.Mirror, Key: mirror.MirrorKey, URL: mirror.MirrorURL}
+		s.sendMirrorComment(ctx, mirror, SyncDirectionToOrig, mirror.Orig, s.message(rule, MsgExternalIssueRemoved, msgData), "")
+		s.destroyMirrorTask(ctx, mirror, "mirror task has been DELETED or TRANSFERRED")
+		return false
+	}
+	if err != nil {
+		l.Warn("failed to get the mirror task", zap.Error(err))
+		return false
+	}
+	mirror.issue = issue
+	mirror.MirrorTask = detachedTaskOf(target, issue)
+	return true
+}
+
+// addExternalMirrorTask creates the mirror task of the original task in the target of another provider (eg the issue of GitHub).
+// The name and the description are rendered by the templates of the rule, the tags and the assignees are set by spec_sync.
+func (s *mirrorTaskSyncer) addExternalMirrorTask(ctx context.Context, opts *SyncPreferences, rule MirrorTaskSpecification,
+	target *SyncRule_SpecOfExternalTarget, task *Task) {
+
+	l := s.log.Named("add_external_mirror_task").With(zap.String("task_id", task.ID),
+		zap.String("tracker", target.Tracker), zap.String("target", target.Target))
+
+	mirrorTask := &tracker.Task{
+		Name:        s.mirrorTaskName(ctx, &rule, nil, false, task),
+		Description: s.mirrorTaskDescription(ctx, &rule, nil, task),
+		Status:      target.externalStatus(task.StatusName),
+		PriorityID:  task.PriorityID,
+		Tags:        rule.SpecSync.GetTags().NewMirrorTaskTags(),
+		Assignees:   []tracker.Member{},
+		DueDate:     timeFromTimestamp(task.DueDateAt),
+		StartDate:   timeFromTimestamp(task.StartDateAt),
+		Milestone:   task.GetList(ctx).Name,
+	}
+	if estimate := rule.SpecSync.estimateOf(task); estimate > 0 {
+		mirrorTask.TimeEstimateMs = &estimate
+	}
+	if tags := rule.SpecSync.GetTags(); tags.AllowedSync(SyncDirectionToMirror) {
+		for _, tag := range tags.MirrorTags(task.Tags) {
+			if !containsString(mirrorTask.Tags, tag) {
+				mirrorTask.Tags = append(mirrorTask.Tags, tag)
+			}
+		}
+	}
+	if rule.SpecSync.AllowedSyncAssignees(SyncDirectionToMirror) {
+		for _, user := range target.usersFor(task.AssigneeEmails()) {
+			mirrorTask.Assignees = append(mirrorTask.Assignees, tracker.Member{ID: user})
+		}
+	}
+
+	location := target.Location()
+	created, err := s.providers.CreateTask(ctx, location, mirrorTask)
+	if err != nil {
+		l.Error("aborted creation of a mirror task - failed to create the task", zap.Error(err))
+		return
+	}
+	mirror := s.store.ModelMirrorTaskOf(task.ID, created.Ref)
+	mirror.target = target
+	mirror.issue = created
+	mirror.MirrorTask = detachedTaskOf(target, created)
+	mirror.MirrorLocation = location
+	mirror.MirrorKey = created.Key
+	mirror.MirrorURL = created.URL
+	mirror.RuleName = rule.Name
+	mirror.CreatedAt = TimestampNow()
+	mirror.SyncedToMirror = NewMirrorTaskSnapshot(task)
+	mirror.MirrorState = NewMirrorTaskSnapshot(mirror.MirrorTask)
+	mirror.CommentIDs = map[string]string{}
+	if target.SyncComments {
+		// only the new comments are mirrored
+		location, key := mirror.locate(mirror.Orig)
+		comments, err := s.providers.ListComments(ctx, location, key)
+		warnErrorIf(s.log, err, "failed to get the comments of the original task", "task_id", task.ID)
+		for _, comment := range comments {
+			mirror.CommentIDs[comment.ID] = ""
+		}
+	}
+	err = s.store.UpsertMirrorTask(ctx, mirror)
+	if err != nil {
+		l.Error("failed add mirror task to database", zap.Error(err), zap.String("mirror_task", created.Ref.String()))
+		return
+	}
+	s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+		Action:    MirrorTaskActionCreated,
+		Direction: SyncDirectionToMirror,
+		TaskID:    created.Ref.ID,
+	})
+
+	msgData := newMirrorTaskTemplateData(ctx, &rule, task, mirror.MirrorTask)
+	msgData.Issue = created
+	s.sendMirrorComment(ctx, mirror, SyncDirectionToOrig, mirror.Orig, s.message(&rule, MsgExternalIssueCreated, msgData), "")
+
+	s.notifier.notify(ctx, &rule, task, &notify.Event{
+		Kind:      notify.EventMirrorAdded,
+		MirrorURL: created.URL,
+		Actions:   pairActions(mirror),
+	})
+	l.Info("created the mirror task", zap.String("mirror_task_url", created.URL))
+}
+
+// fills the changes of the original task which are pushed only to the task of another provider:
+// the status (unless mirror_task_statuses), the dates, the estimate and the milestone (the name of the list).
+// Returns the changed fields (see tracker.Field* constants).
+func (s *mirrorTaskSyncer) fillExternalChanges(ctx context.Context, spec *MirrorTaskSpecification, mirror *MirrorTask,
+	oldTask, task *Task, updTask *tracker.Task) []string {
+
+	fields := []string{}
+	if !mirror.target.MirrorTaskStatuses && !strings.EqualFold(oldTask.StatusName, task.StatusName) {
+		updTask.Status = mirror.target.externalStatus(task.StatusName)
+		fields = append(fields, tracker.FieldStatus)
+	}
+	if !equalTimestamp(oldTask.DueDateAt, task.DueDateAt) {
+		updTask.DueDate = timeFromTimestamp(task.DueDateAt)
+		fields = append(fields, tracker.FieldDueDate)
+	}
+	if !equalTimestamp(oldTask.StartDateAt, task.StartDateAt) {
+		updTask.StartDate = timeFromTimestamp(task.StartDateAt)
+		fields = append(fields, tracker.FieldStartDate)
+	}
+	if estimate := spec.SpecSync.estimateOf(task); estimate != spec.SpecSync.estimateOf(oldTask) {
+		// the estimate is removed by the nil value
+		if estimate > 0 {
+			updTask.TimeEstimateMs = &estimate
+		}
+		fields = append(fields, tracker.FieldEstimate)
+	}
+	if oldTask.ListRef == nil || oldTask.ListRef.ID != task.ListRef.ID {
+		updTask.Milestone = task.GetList(ctx).Name
+		fields = append(fields, tracker.FieldMilestone)
+	}
+	return fields
+}
+
+// returns the status for the original task by the changed status of the task of another provider (or "" if nothing needs to be done)
+func externalOrigTaskStatus(statuses MirrorTaskStatuses, mirror *MirrorTask, oldTask, task *Task) string {
+	if task.StatusName == "" || strings.EqualFold(oldTask.StatusName, task.StatusName) {
+		return ""
+	}
+	if mirror.target.MirrorTaskStatuses {
+		return statuses.SetStatusToOrigTaskIfExists(task.StatusName)
+	}
+	return strings.ToLower(task.StatusName)
+}
+
+// returns true if the field of the mirror task must be synced to the original task.
+// The fields of the task of another provider are synced only if changed since the last sync (the provider may store the values with less precision).
+func mirrorFieldChanged(mirror *MirrorTask, field string, oldTask, task *Task) bool {
+	if !mirror.external() {
+		return true
+	}
+	switch field {
+	case tracker.FieldEstimate:
+		return !equalInt64(oldTask.TimeEstimateMs, task.TimeEstimateMs)
+	case tracker.FieldDueDate:
+		return !equalTimestamp(oldTask.DueDateAt, task.DueDateAt)
+	case tracker.FieldStartDate:
+		return !equalTimestamp(oldTask.StartDateAt, task.StartDateAt)
+	}
+	return true
+}
+
+// changedFrom returns true if the values of the snapshot differ from the values of the snapshot in (the time of the sync is not compared).
+func (s *MirrorTaskSnapshot) changedFrom(in *MirrorTaskSnapshot) bool {
+	return s.Name != in.Name || s.Description != in.Description || !strings.EqualFold(s.StatusName, in.StatusName) ||
+		!equalInt(s.PriorityID, in.PriorityID) || !equalInt64(s.TimeEstimateMs, in.TimeEstimateMs) ||
+		!equalTimestamp(s.DueDateAt, in.DueDateAt) || !equalTimestamp(s.StartDateAt, in.StartDateAt) ||
+		!equalValues(s.AssigneeEmails, in.AssigneeEmails) || !equalValues(s.Tags, in.Tags) || s.Closed != in.Closed
+}
+
+// syncExternalMirrorTask pulls the changes of the task of another provider (eg the issue of GitHub) to the original task.
+// The changes are found by the comparison with the values at the last sync (see MirrorTask.MirrorState)
+// and applied as the changes of the mirror task. The original task is taken from the database.
+func (s *mirrorTaskSyncer) syncExternalMirrorTask(ctx context.Context, opts *SyncPreferences, mirror *MirrorTask) {
+	if !mirror.GetOrigTask(ctx).Exists() {
+		s.log.Warn("not found the original task of the pair", zap.String("model_id", mirror.ModelID()))
+		return
+	}
+	if !s.loadExternalMirrorTask(ctx, opts, mirror) {
+		return
+	}
+	rule := opts.RuleByName(mirror.RuleName)
+
+	task := mirror.GetMirrorTask(ctx)
+	// the first pull of the pair migrated from the previous versions takes the values as synced
+	if mirror.MirrorState != nil && NewMirrorTaskSnapshot(task).changedFrom(mirror.MirrorState) {
+		oldTask := detachedTaskFromSnapshot(mirror.Mirror.ID, mirror.MirrorURL, mirror.MirrorState)
+		s.applyChangesToMirrorTask(ctx, mirror, *rule, oldTask, task, opts)
+		if mirror.Destroyed {
+			return
+		}
+	}
+
+	s.syncExternalComments(ctx, rule, mirror)
+
+	// the values pushed back by the changes (eg the name of the mirror task) are the current values
+	mirror.MirrorState = NewMirrorTaskSnapshot(mirror.GetMirrorTask(ctx))
+	err := s.store.UpsertMirrorTask(ctx, mirror)
+	warnErrorIf(s.log, err, "failed to save the state of the mirror task", "model_id", mirror.ModelID())
+}
+
+// mirrors the new comments of the original task to the task of another provider and the new comments of the task back
+// (see sync_comments of the target). The comments sent by the sync are not mirrored.
+func (s *mirrorTaskSyncer) syncExternalComments(ctx context.Context, rule *MirrorTaskSpecification, mirror *MirrorTask) {
+	if !mirror.target.SyncComments {
+		return
+	}
+	if mirror.CommentIDs == nil {
+		mirror.CommentIDs = map[string]string{}
+	}
+	mirrored := map[string]bool{}
+	for _, commentID := range mirror.CommentIDs {
+		mirrored[commentID] = true
+	}
+
+	msgData := newMirrorTaskTemplateData(ctx, rule, mirror.GetOrigTask(ctx), mirror.GetMirrorTask(ctx))
+	msgData.Issue = mirror.issue
+
+	location, key := mirror.locate(mirror.Orig)
+	comments, err := s.providers.ListComments(ctx, location, key)
+	if err != nil {
+		s.log.Warn("failed to get the comments of the original task", zap.Error(err), zap.String("model_id", mirror.ModelID()))
+		return
+	}
+	for _, comment := range comments {
+		if _, exists := mirror.CommentIDs[comment.ID]; exists {
+			continue
+		}
+		msgData.Comment = comment
+		created := s.addComment(ctx, mirror, mirror.Mirror, &tracker.Comment{Body: s.message(rule, MsgExternalCommentToIssue, msgData)})
+		if created == nil {
+			return
+		}
+		mirror.CommentIDs[comment.ID] = created.ID
+		mirrored[created.ID] = true
+	}
+
+	location, key = mirror.locate(mirror.Mirror)
+	comments, err = s.providers.ListComments(ctx, location, key)
+	if err != nil {
+		s.log.Warn("failed to get the comments of the mirror task", zap.Error(err), zap.String("model_id", mirror.ModelID()))
+		return
+	}
+	for _, comment := range comments {
+		if mirrored[comment.ID] {
+			continue
+		}
+		msgData.Comment = comment
+		created := s.addComment(ctx, mirror, mirror.Orig, &tracker.Comment{Body: s.message(rule, MsgExternalCommentToTask, msgData)})
+		if created == nil {
+			return
+		}
+		mirror.CommentIDs[created.ID] = comment.ID
+	}
+}
+
+// ApplyExternalChanges pulls the changes of the mirror tasks of other providers (eg the issues of GitHub) to the original tasks.
+// The pairs of the previous versions (see legacyExternalMirrorTask) are migrated first.
+func (s *ChangeManager) ApplyExternalChanges(ctx context.Context, opts *SyncPreferences) {
+	s.migrateExternalMirrorTasks(ctx)
+
+	syncer := MirrorTaskSyncer(s.api, s.store, s.providers)
+	syncer.notifier = newTaskNotifier(opts, s.store, s.notifiers, s.webhooks)
+	for _, mirror := range s.store.ActiveExternalMirrorTasks(ctx) {
+		syncer.syncExternalMirrorTask(ctx, opts, mirror)
+	}
+}
+
+// ApplyExternalIssueChanges pulls the changes of the mirror task of the provider (eg by the webhook event) to the original task.
+// Returns false if the task is not the mirror task of any original task.
+func (s *ChangeManager) ApplyExternalIssueChanges(ctx context.Context, opts *SyncPreferences, trackerName, issueID string) bool {
+	mirror := s.store.ActiveMirrorTaskByRef(ctx, tracker.TaskRef{Provider: trackerName, ID: issueID})
+	if mirror == nil {
+		return false
+	}
+	syncer := MirrorTaskSyncer(s.api, s.store, s.providers)
+	syncer.notifier = newTaskNotifier(opts, s.store, s.notifiers, s.webhooks)
+	syncer.syncExternalMirrorTask(ctx, opts, mirror)
+	return true
+}
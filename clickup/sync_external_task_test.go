@@ -0,0 +1,434 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+// fakeTracker the in-memory provider of the issues (the issues are found by the key in any location).
+type fakeTracker struct {
+	nextID   int
+	fields   []string
+	issues   map[string]*tracker.Task
+	comments map[string][]*tracker.Comment
+}
+
+func newFakeTracker() *fakeTracker {
+	return &fakeTracker{
+		fields: []string{
+			tracker.FieldName, tracker.FieldDescription, tracker.FieldTags, tracker.FieldAssignees,
+			tracker.FieldState, tracker.FieldStatus,
+		},
+		issues:   map[string]*tracker.Task{},
+		comments: map[string][]*tracker.Comment{},
+	}
+}
+
+func (f *fakeTracker) Name() string { return "github" }
+
+func (f *fakeTracker) Capabilities() tracker.Capability {
+	return tracker.CapComments | tracker.CapTags | tracker.CapAssignees | tracker.CapStatus
+}
+
+func (f *fakeTracker) Fields(location tracker.Location) []string { return f.fields }
+
+func (f *fakeTracker) GetTask(ctx context.Context, location tracker.Location, key string) (*tracker.Task, error) {
+	issue, exists := f.issues[key]
+	if !exists {
+		return nil, tracker.ErrNotFound
+	}
+	res := *issue
+	return &res, nil
+}
+
+func (f *fakeTracker) CreateTask(ctx context.Context, location tracker.Location, task *tracker.Task) (*tracker.Task, error) {
+	f.nextID++
+	issue := *task
+	issue.Key = fmt.Sprint(f.nextID)
+	issue.Ref = tracker.TaskRef{Provider: f.Name(), ID: "I" + issue.Key}
+	issue.Location = location
+	issue.URL = "https://github.com/" + location.ID + "/issues/" + issue.Key
+	f.issues[issue.Key] = &issue
+	return f.GetTask(ctx, location, issue.Key)
+}
+
+func (f *fakeTracker) UpdateTask(ctx context.Context, location tracker.Location, task *tracker.Task, fields []string) (*tracker.Task, error) {
+	issue, exists := f.issues[task.Key]
+	if !exists {
+		return nil, tracker.ErrNotFound
+	}
+	for _, field := range fields {
+		switch field {
+		case tracker.FieldName:
+			issue.Name = task.Name
+		case tracker.FieldDescription:
+			issue.Description = task.Description
+		case tracker.FieldTags:
+			issue.Tags = task.Tags
+		case tracker.FieldAssignees:
+			issue.Assignees = task.Assignees
+		case tracker.FieldState:
+			issue.Closed = task.Closed
+		case tracker.FieldStatus:
+			issue.Status = task.Status
+		default:
+			return nil, fmt.Errorf("not supported field %q", field)
+		}
+	}
+	return f.GetTask(ctx, location, task.Key)
+}
+
+func (f *fakeTracker) ListComments(ctx context.Context, location tracker.Location, key string) ([]*tracker.Comment, error) {
+	return f.comments[key], nil
+}
+
+func (f *fakeTracker) AddComment(ctx context.Context, location tracker.Location, key string, comment *tracker.Comment) (*tracker.Comment, error) {
+	f.nextID++
+	created := *comment
+	created.ID = fmt.Sprint("C", f.nextID)
+	f.comments[key] = append(f.comments[key], &created)
+	return &created, nil
+}
+
+func (f *fakeTracker) ListMembers(ctx context.Context, location tracker.Location) ([]*tracker.Member, error) {
+	return nil, nil
+}
+
+// returns the bodies of the comments of the issue
+func (f *fakeTracker) commentBodies(key string) []string {
+	res := []string{}
+	for _, comment := range f.comments[key] {
+		res = append(res, comment.Body)
+	}
+	return res
+}
+
+const testExternalRule = `
+mirror_task_rules:
+  - name: rule
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    spec_add:
+      external:
+        - tracker: github
+          target: owner/repo
+          sync_comments: true
+          member_map:
+            member1@example.com: john-gh
+          status_map:
+            in progress: Doing
+    spec_sync:
+      assignees: both
+    spec_lifecycle:
+      on_orig_hidden: propagate
+      on_mirror_hidden: propagate
+`
+
+// returns the syncer with the fake tracker and the function which syncs the changed task by the syncer
+func newExternalSyncer(env *syncEnv, issues *fakeTracker) (*mirrorTaskSyncer, func(opts *SyncPreferences, taskID string)) {
+	syncer := MirrorTaskSyncer(env.api, env.store, tracker.NewRegistry(newStoredProvider(env.api, env.store), issues))
+	return syncer, func(opts *SyncPreferences, taskID string) {
+		env.t.Helper()
+		oldTask := env.stored(taskID)
+		task := env.load(taskID)
+		syncer.Sync(env.ctx, opts, oldTask, task, true)
+	}
+}
+
+// returns the only pair of the task
+func externalPairOf(t *testing.T, env *syncEnv, taskID string) *MirrorTask {
+	t.Helper()
+	pairs := env.pairs(taskID)
+	if len(pairs) != 1 {
+		t.Fatalf("the task %q has %d pairs, want 1", taskID, len(pairs))
+	}
+	return pairs[0]
+}
+
+func containsPrefix(list []string, prefix string) bool {
+	for _, item := range list {
+		if strings.HasPrefix(item, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMirrorTaskSyncer_ExternalMirrorTask(t *testing.T) {
+	env := newSyncEnv(t)
+	issues := newFakeTracker()
+	_, change := newExternalSyncer(env, issues)
+	opts := mustParsePreferences(t, testExternalRule)
+
+	member := NewWithID(MemberModel, "1").(*Member)
+	member.Email = "member1@example.com"
+	if err := env.store.UpsertMember(env.ctx, member); err != nil {
+		t.Fatalf("UpsertMember(): %v", err)
+	}
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10", Assignees: []int64{1}})
+	change(opts, orig.ID)
+
+	mirror := externalPairOf(t, env, orig.ID)
+	issue := issues.issues[mirror.MirrorKey]
+	if issue == nil || mirror.Mirror != issue.Ref || mirror.MirrorURL != issue.URL || mirror.MirrorState == nil {
+		t.Fatalf("the pair of the created issue = %+v", mirror)
+	}
+	if issue.Name != "List 10: Task" {
+		t.Errorf("the name of the issue = %q, want by the template of the name of the mirror task", issue.Name)
+	}
+	if !reflect.DeepEqual(issue.Assignees, []tracker.Member{{ID: "john-gh"}}) {
+		t.Errorf("the assignees of the issue = %+v, want john-gh", issue.Assignees)
+	}
+	if comments := env.fake.taskComments(orig.ID); !containsPrefix(comments, "The issue has been created "+issue.URL) {
+		t.Errorf("the comments of the original task = %q, want the comment about the created issue", comments)
+	}
+
+	// the changes of the original task are pushed to the issue
+	env.fake.task(orig.ID).Name = "Renamed"
+	env.fake.task(orig.ID).Status = "in progress"
+	change(opts, orig.ID)
+	if issue := issues.issues[mirror.MirrorKey]; issue.Name != "List 10: Renamed" || issue.Status != "Doing" {
+		t.Errorf("the issue after the changes of the original task = %+v", issue)
+	}
+
+	// the closing of the original task is propagated to the issue (see on_orig_hidden)
+	env.fake.task(orig.ID).Status, env.fake.task(orig.ID).StatusType = "closed", "closed"
+	change(opts, orig.ID)
+	if issue := issues.issues[mirror.MirrorKey]; !issue.Closed {
+		t.Errorf("the issue after the closing of the original task = %+v, want closed", issue)
+	}
+	if mirror := externalPairOf(t, env, orig.ID); mirror.Destroyed {
+		t.Errorf("the pair after the closing of the original task is destroyed, want kept by the lifecycle policy")
+	}
+}
+
+func TestMirrorTaskSyncer_syncExternalMirrorTask(t *testing.T) {
+	env := newSyncEnv(t)
+	issues := newFakeTracker()
+	syncer, change := newExternalSyncer(env, issues)
+	opts := mustParsePreferences(t, testExternalRule)
+
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+	change(opts, orig.ID)
+	key := externalPairOf(t, env, orig.ID).MirrorKey
+	pull := func() {
+		t.Helper()
+		mirror := env.store.ActiveMirrorTaskByRef(env.ctx, issues.issues[key].Ref)
+		if mirror == nil {
+			t.Fatalf("not found the active pair of the issue %q", key)
+		}
+		syncer.syncExternalMirrorTask(env.ctx, opts, mirror)
+	}
+
+	// the changes of the issue are applied to the original task as the changes of the mirror task
+	issues.issues[key].Status = "Doing"
+	issues.comments[key] = append(issues.comments[key], &tracker.Comment{ID: "C100", Author: tracker.Member{Name: "john"}, Body: "hello"})
+	pull()
+	if got := env.fake.task(orig.ID).Status; got != "in progress" {
+		t.Errorf("the status of the original task = %q, want in progress", got)
+	}
+	origComments := env.fake.taskComments(orig.ID)
+	if !containsPrefix(origComments, "john commented in "+issues.issues[key].URL) {
+		t.Errorf("the comments of the original task = %q, want the mirrored comment of the issue", origComments)
+	}
+
+	// the comments are mirrored once and the comments sent by the sync are not mirrored back
+	issueComments := issues.commentBodies(key)
+	pull()
+	if got := env.fake.taskComments(orig.ID); len(got) != len(origComments) {
+		t.Errorf("the comments of the original task after the second pull = %q, want %q", got, origComments)
+	}
+	if got := issues.commentBodies(key); len(got) != len(issueComments) {
+		t.Errorf("the comments of the issue after the second pull = %q, want %q", got, issueComments)
+	}
+
+	// the pair of the deleted issue is unlinked
+	delete(issues.issues, key)
+	mirror := externalPairOf(t, env, orig.ID)
+	syncer.syncExternalMirrorTask(env.ctx, opts, mirror)
+	if mirror := externalPairOf(t, env, orig.ID); !mirror.Destroyed {
+		t.Errorf("the pair of the deleted issue is not destroyed")
+	}
+	if comments := env.fake.taskComments(orig.ID); !containsPrefix(comments, "UNLINK ISSUE: the issue ") {
+		t.Errorf("the comments of the original task = %q, want the comment about the removed issue", comments)
+	}
+}
+
+func TestChangeManager_ApplyExternalChanges_legacyPairs(t *testing.T) {
+	env := newSyncEnv(t)
+	issues := newFakeTracker()
+	opts := mustParsePreferences(t, testExternalRule)
+	manager := NewChangeManager(env.api, env.store)
+	manager.RegisterProvider(issues)
+
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+	env.load(orig.ID)
+	issue, _ := issues.CreateTask(env.ctx, tracker.Location{Provider: "github", ID: "owner/repo"}, &tracker.Task{Name: "Renamed in GitHub"})
+	legacyID := "src:" + orig.ID + ":ext:github:" + issue.Ref.ID
+	_, err := env.store.FirestoreClient().Collection(legacyExternalMirrorTaskModel.CollectionName()).Doc(legacyID).Set(env.ctx, map[string]interface{}{
+		"TaskRef":   env.store.DocRef(NewWithID(TaskModel, orig.ID)),
+		"Target":    "owner/repo",
+		"IssueKey":  issue.Key,
+		"IssueURL":  issue.URL,
+		"RuleName":  "rule",
+		"Destroyed": false,
+	})
+	if err != nil {
+		t.Fatalf("Set(): %v", err)
+	}
+
+	manager.ApplyExternalChanges(env.ctx, opts)
+
+	legacy := env.store.FirestoreClient().Collection(legacyExternalMirrorTaskModel.CollectionName()).Documents(env.ctx)
+	if list := env.store.Iterate(legacy, legacyExternalMirrorTaskModel); len(list) != 0 {
+		t.Errorf("the legacy pairs after the migration = %d, want 0", len(list))
+	}
+	mirror := externalPairOf(t, env, orig.ID)
+	if mirror.Mirror != issue.Ref || mirror.MirrorKey != issue.Key || mirror.MirrorLocation.ID != "owner/repo" || mirror.RuleName != "rule" {
+		t.Errorf("the migrated pair = %+v", mirror)
+	}
+	if mirror.MirrorState == nil || mirror.MirrorState.Name != "Renamed in GitHub" {
+		t.Errorf("the state of the migrated pair = %+v, want the values of the issue", mirror.MirrorState)
+	}
+	if got := env.fake.task(orig.ID).Name; got != "Task" {
+		t.Errorf("the name of the original task = %q, want not changed by the first pull", got)
+	}
+}
+
+func TestDetachedTaskOf(t *testing.T) {
+	target := &SyncRule_SpecOfExternalTarget{
+		MemberMap: map[string]string{"John@example.com": "john-gh"},
+		StatusMap: map[string]string{"in progress": "Doing"},
+	}
+	dueDate := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	task := detachedTaskOf(target, &tracker.Task{
+		Ref:       tracker.TaskRef{Provider: "github", ID: "I1"},
+		Name:      "Issue",
+		Status:    "Doing",
+		Closed:    true,
+		Assignees: []tracker.Member{{ID: "john-gh", Name: "John"}, {ID: "alice"}},
+		DueDate:   &dueDate,
+		URL:       "https://github.com/owner/repo/issues/1",
+	})
+	if task.ID != "I1" || task.Name != "Issue" || task.StatusName != "in progress" || !task.IsDeletedOrHidden() {
+		t.Errorf("detachedTaskOf() = %+v", task)
+	}
+	if got := task.AssigneeEmails(); !reflect.DeepEqual(got, []string{"john@example.com"}) {
+		t.Errorf("detachedTaskOf() assignee emails = %v, want only the mapped users", got)
+	}
+	if task.Exists() || task.ListRef == nil || task.GetSubTasks() != nil {
+		t.Errorf("detachedTaskOf() must return the not stored task without the location in ClickUp")
+	}
+
+	snapshot := NewMirrorTaskSnapshot(task)
+	if snapshot.changedFrom(NewMirrorTaskSnapshot(detachedTaskFromSnapshot(task.ID, task.URL, snapshot))) {
+		t.Errorf("changedFrom() for the task from the same snapshot = true, want false")
+	}
+	changed := *snapshot
+	changed.StatusName = "done"
+	if !changed.changedFrom(snapshot) {
+		t.Errorf("changedFrom() for the changed status = false, want true")
+	}
+}
+
+func TestSyncRule_SpecOfExternalTarget_members(t *testing.T) {
+	target := &SyncRule_SpecOfExternalTarget{
+		MemberMap: map[string]string{"John@example.com": "john-gh"},
+	}
+	if got := target.usersFor([]string{"john@example.com", "alice@example.com"}); !reflect.DeepEqual(got, []string{"john-gh"}) {
+		t.Errorf("usersFor() = %v", got)
+	}
+	if got := target.emailsFor([]string{"John-GH", "alice"}); !reflect.DeepEqual(got, []string{"john@example.com"}) {
+		t.Errorf("emailsFor() = %v", got)
+	}
+	if err := target.Validate(); err == nil {
+		t.Error("Validate() without tracker and target must return error")
+	}
+	target.Tracker, target.Target = "github", "gebv/asap-tools"
+	if err := target.Validate(); err != nil {
+		t.Errorf("Validate(): %v", err)
+	}
+}
+
+func TestSyncPreferences_Validate_externalDestinationOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{"clickup lists", `
+mirror_task_rules:
+  - name: rule
+    cond_add:
+      if_in_folders: [https://app.clickup.com/100/v/f/10/100]
+      if_in_lists: [https://app.clickup.com/100/v/li/20]
+    spec_add:
+      external:
+        - tracker: jira
+          target: PRJ
+          properties:
+            name: Summary
+            tags: Labels
+`, ""},
+		{"the list of jira", `
+mirror_task_rules:
+  - name: rule
+    cond_add:
+      if_in_lists: [https://example.atlassian.net/browse/PRJ]
+    spec_add:
+      external:
+        - tracker: jira
+          target: PRJ
+`, "cond_add"},
+		{"the folder of another tracker", `
+mirror_task_rules:
+  - name: rule
+    cond_add:
+      if_in_folders: [https://github.com/gebv/asap-tools/issues]
+    spec_add:
+      external:
+        - tracker: github
+          target: gebv/asap-tools
+`, "cond_add"},
+		{"clickup as external", `
+mirror_task_rules:
+  - name: rule
+    spec_add:
+      external:
+        - tracker: clickup
+          target: https://app.clickup.com/100/v/li/20
+`, "spec_add.external"},
+		{"unknown property", `
+mirror_task_rules:
+  - name: rule
+    spec_add:
+      external:
+        - tracker: notion
+          target: db
+          properties:
+            labels: Tags
+`, "spec_add.external"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSyncPreferences(strings.NewReader(tt.in))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ParseSyncPreferences(): %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseSyncPreferences() = %v, want error of %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -8,21 +8,32 @@ import (
 	"time"
 
 	"github.com/gebv/asap-tools/clickup/api"
+	"github.com/gebv/asap-tools/notify"
+	"github.com/gebv/asap-tools/tracker"
 	"go.uber.org/zap"
 )
 
-func MirrorTaskSyncer(api *api.API, store *Storage) *mirrorTaskSyncer {
+// MirrorTaskSyncer returns the syncer of the mirror tasks.
+// The tasks are created and updated and the comments are sent through the providers (only ClickUp if nil).
+func MirrorTaskSyncer(api *api.API, store *Storage, providers *tracker.Registry) *mirrorTaskSyncer {
+	if providers == nil {
+		providers = tracker.NewRegistry(newStoredProvider(api, store))
+	}
 	return &mirrorTaskSyncer{
-		api:   api,
-		store: store,
-		log:   zap.L().Named("sync_mirror_task"),
+		api:       api,
+		store:     store,
+		providers: providers,
+		log:       zap.L().Named("sync_mirror_task"),
 	}
 }
 
 type mirrorTaskSyncer struct {
-	api   *api.API
-	store *Storage
-	log   *zap.Logger
+	api       *api.API
+	store     *Storage
+	providers *tracker.Registry
+	log       *zap.Logger
+	// the notifications about the new and the unlinked pairs (nothing if nil)
+	notifier *taskNotifier
 }
 
 func (s *mirrorTaskSyncer) Sync(ctx context.Context, opts *SyncPreferences, oldTask, task *Task, changed bool) {
@@ -44,7 +55,7 @@ func (s *mirrorTaskSyncer) Sync(ctx context.Context, opts *SyncPreferences, oldT
 		return
 	}
 
-	rules := s.matchedRules(opts.MirrorTaskRules, task)
+	rules := matchedRules(opts.MirrorTaskRules, task)
 
 	// каждую MirrorTask обрабтать
 	// - если это orig task то применить правило для orig task из mirror task
@@ -56,42 +67,93 @@ func (s *mirrorTaskSyncer) Sync(ctx context.Context, opts *SyncPreferences, oldT
 	// если входящая задача имеет listID отличный от (1) то обрабатываем как новую
 
 	listOfMirrorTaskLists := map[string]bool{}
+	// the targets of other providers (see SyncRule_SpecOfExternalTarget) in which the task is mirrored
+	linkedExternalTargets := map[string]bool{}
 	for idx := range mirrorList {
 		mirror := mirrorList[idx]
 		if mirror.Destroyed {
 			s.log.Warn("skipped the destroyed mirror-task", zap.String("mirror_task_id", mirror.ModelID()))
 			continue
 		}
+		if mirror.external() {
+			linkedExternalTargets[mirror.Mirror.Provider+":"+mirror.MirrorLocation.ID] = true
+			if !s.loadExternalMirrorTask(ctx, opts, mirror) {
+				continue
+			}
+		}
+
+		if mirror.Orig.ID == task.ID && mirror.Subtask {
+			if s.syncMirrorSubtaskHierarchy(ctx, opts, mirror, oldTask, task) {
+				continue
+			}
+		}
+
+		if mirror.Orig.ID == task.ID && !mirror.Subtask {
+			if s.syncMovedOrigTask(ctx, opts, mirror, mirrorList, rules, oldTask, task) {
+				continue
+			}
+		}
 
-		if mirror.TaskRef.ID == task.ID {
+		if mirror.Orig.ID == task.ID {
 			for idx := range rules.changedRules {
 				rule := rules.changedRules[idx]
-				s.applyChangesToOriginalTask(ctx, mirror, rule, oldTask, task)
+				if !mirror.CreatedByRule(rule.Name) {
+					continue
+				}
+				s.applyChangesToOriginalTask(ctx, mirror, rule, oldTask, task, opts)
 			}
 		}
 
-		if mirror.MirrorTaskRef.ID == task.ID {
+		if mirror.Mirror.ID == task.ID {
 			for idx := range rules.syncedRules {
 				rule := rules.syncedRules[idx]
-				s.applyChangesToMirrorTask(ctx, mirror, rule, oldTask, task, opts.GlobalMirrorTaskStatuses)
+				if !mirror.CreatedByRule(rule.Name) {
+					continue
+				}
+				s.applyChangesToMirrorTask(ctx, mirror, rule, oldTask, task, opts)
 			}
 		}
 
 		// если среди всех зеркальныйх заданий текущая задача является исходной то
 		// сохраняем listID в котром находится зеркальная задача
-		if task.ID == mirror.GetOrigTask(ctx).ID {
+		if task.ID == mirror.GetOrigTask(ctx).ID && !mirror.external() {
 			listOfMirrorTaskLists[mirror.GetMirrorTask(ctx).ListRef.ID] = true
 		}
 	}
 
 	if !task.IsDeletedOrHidden() {
+		if task.ParentTaskRef != nil {
+			s.addMirrorSubtasks(ctx, opts, task, listOfMirrorTaskLists)
+		}
+
 		for idx := range rules.addRules {
 			rule := rules.addRules[idx]
 
+			// the subtasks are mirrored only as subtasks of the mirror of the parent task
+			if task.ParentTaskRef != nil && rule.SpecSync.MirrorSubtasks() {
+				continue
+			}
+
 			// если среди всех листов не встречается лист для правила добавления
 			// тогда текущая задача кандидант на добавление в зеркало
-			if !listOfMirrorTaskLists[rule.SpecAdd.GetAddToListID()] {
-				s.addMirrorTask(ctx, rule.SpecAdd, task)
+			for _, target := range rule.SpecAdd.AllTargets() {
+				if !listOfMirrorTaskLists[target.GetAddToListID()] {
+					if rule.SpecAdd.RequireApproval && !s.approvedMirrorTask(ctx, &rule, target, task) {
+						continue
+					}
+					s.addMirrorTask(ctx, opts, rule, target, task)
+				}
+			}
+
+			// only the top-level tasks are mirrored to other providers
+			if task.ParentTaskRef != nil {
+				continue
+			}
+			for tidx := range rule.SpecAdd.GetExternal() {
+				target := &rule.SpecAdd.External[tidx]
+				if !linkedExternalTargets[target.Tracker+":"+target.Target] {
+					s.addExternalMirrorTask(ctx, opts, rule, target, task)
+				}
 			}
 		}
 	}
@@ -104,335 +166,497 @@ func (s *mirrorTaskSyncer) destroyMirrorTask(ctx context.Context, mirror *Mirror
 	mirror.DestroyedReason = reason
 	err := s.store.UpsertMirrorTask(ctx, mirror)
 	warnErrorIf(s.log, err, "failed destroy mirror task", "model_id", mirror.ModelID())
+
+	s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+		Action: MirrorTaskActionUnlinked,
+		Reason: reason,
+	})
+
+	if s.notifier != nil {
+		s.notifier.notifyByRuleName(ctx, mirror.RuleName, mirror.GetOrigTask(ctx), &notify.Event{
+			Kind:      notify.EventUnlinked,
+			MirrorURL: mirror.GetMirrorTask(ctx).URL,
+			Details:   reason,
+		})
+	}
+}
+
+// saves the snapshot of the propagated values and the history entry about the updated fields
+func (s *mirrorTaskSyncer) markSynced(ctx context.Context, mirror *MirrorTask, direction string, source *Task, targetTaskID string, fields []string) {
+	snapshot := NewMirrorTaskSnapshot(source)
+	switch direction {
+	case SyncDirectionToMirror:
+		mirror.SyncedToMirror = snapshot
+	case SyncDirectionToOrig:
+		mirror.SyncedToOrig = snapshot
+	}
+	err := s.store.UpsertMirrorTask(ctx, mirror)
+	warnErrorIf(s.log, err, "failed to save the snapshot of the synced values", "model_id", mirror.ModelID(), "direction", direction)
+
+	s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+		Action:    MirrorTaskActionUpdated,
+		Direction: direction,
+		TaskID:    targetTaskID,
+		Fields:    fields,
+	})
+}
+
+func (s *mirrorTaskSyncer) recordHistory(ctx context.Context, mirror *MirrorTask, entry *MirrorTaskHistory) {
+	err := s.store.AppendMirrorTaskHistory(ctx, mirror, entry)
+	warnErrorIf(s.log, err, "failed to append the history of the mirror task", "model_id", mirror.ModelID(), "action", entry.Action)
+}
+
+// sends comment to the task of the pair and records it in the history of the mirror task
+func (s *mirrorTaskSyncer) sendMirrorComment(ctx context.Context, mirror *MirrorTask, direction string, ref tracker.TaskRef, commentText string, assignToEmail string) {
+	if !s.sendComment(ctx, mirror, ref, commentText, assignToEmail) {
+		return
+	}
+	s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+		Action:    MirrorTaskActionCommentSent,
+		Direction: direction,
+		TaskID:    ref.ID,
+		Comment:   commentText,
+	})
+}
+
+// updates the fields of the task of the pair by the provider of the task (see tracker.Field* constants).
+// The fields not supported by the provider are skipped.
+// The updated task of ClickUp is saved to the storage by the provider,
+// the updated task of another provider is set to the pair as the values of the last sync. Returns nil if failed.
+func (s *mirrorTaskSyncer) updateTask(ctx context.Context, mirror *MirrorTask, ref tracker.TaskRef, task *tracker.Task, fields []string) *tracker.Task {
+	location, key := mirror.locate(ref)
+	task.Ref, task.Key = ref, key
+	provider, err := s.providers.ProviderFor(location)
+	if err != nil {
+		s.log.Warn("failed to update the task of the pair", zap.Error(err), zap.String("model_id", mirror.ModelID()))
+		return nil
+	}
+	fields = supportedFields(provider.Fields(location), fields)
+	if len(fields) == 0 {
+		if ref == mirror.Mirror && mirror.issue != nil {
+			return mirror.issue
+		}
+		return task
+	}
+	updated, err := provider.UpdateTask(ctx, location, task, fields)
+	warnErrorIf(s.log, err, "failed to update the task of the pair", "model_id", mirror.ModelID(), "task", ref.String(), "fields", fields)
+	if updated != nil && ref == mirror.Mirror && mirror.external() && mirror.target != nil {
+		mirror.issue = updated
+		mirror.MirrorTask = detachedTaskOf(mirror.target, updated)
+		mirror.MirrorState = NewMirrorTaskSnapshot(mirror.MirrorTask)
+		err := s.store.UpsertMirrorTask(ctx, mirror)
+		warnErrorIf(s.log, err, "failed to save the state of the mirror task", "model_id", mirror.ModelID())
+	}
+	return updated
+}
+
+// returns the fields supported by the provider of the task of the pair
+func (s *mirrorTaskSyncer) providerFields(mirror *MirrorTask, ref tracker.TaskRef, fields []string) []string {
+	location, _ := mirror.locate(ref)
+	provider, err := s.providers.ProviderFor(location)
+	if err != nil {
+		return fields
+	}
+	return supportedFields(provider.Fields(location), fields)
+}
+
+// returns the fields supported by the provider (in the same order)
+func supportedFields(supported, fields []string) []string {
+	res := []string{}
+	for _, field := range fields {
+		if containsString(supported, field) {
+			res = append(res, field)
+		}
+	}
+	return res
 }
 
 func (s *mirrorTaskSyncer) applyChangesToOriginalTask(ctx context.Context, mirror *MirrorTask,
-	spec MirrorTaskSpecification, oldTask, task *Task) {
+	spec MirrorTaskSpecification, oldTask, task *Task, opts *SyncPreferences) {
 
 	if oldTask == nil {
 		s.log.Error("handle task for orig task - old task was nil (not happen)", zap.String("task_id", task.ID))
 		return
 	}
 
-	if mirror.GetMirrorTask(ctx).IsDeletedOrHidden() {
-		s.sendComment(ctx, mirror.MirrorTaskRef.ID, "UNLINK MIRROR TASK: the mirror task has been DELETED or HIDDEN",
-			spec.CondAdd.IfAssignedToMemberEmail)
-		s.destroyMirrorTask(ctx, mirror, "mirror task has been DELETED or HIDDEN")
+	if s.syncLifecycle(ctx, &spec, mirror, oldTask, task, true) {
 		return
 	}
+	if mirror.external() && !mirror.target.AllowedSync(SyncDirectionToMirror) {
+		return
+	}
+	msgData := newMirrorTaskTemplateData(ctx, &spec, task, mirror.GetMirrorTask(ctx))
 
 	commentText := &bytes.Buffer{}
-	fmt.Fprintln(commentText, "The original task has chaned or differences with the mirror task:")
-	needToUpdateTask := false
+	fmt.Fprintln(commentText, s.message(&spec, MsgOrigChangesHeader, msgData))
 	needToSendComment := false
-	updTask := &api.UpdateTaskRequest{
-		TaskID: mirror.MirrorTaskRef.ID,
-	}
+	// the fields of the mirror task (see tracker.Field* constants)
+	updatedFields := []string{}
+	updTask := &tracker.Task{}
 
 	// track task name changes
 	if oldTask.Name != task.Name {
 		// fmt.Fprintf(commentText, "- changed name from %q to %q", oldTask.Name, task.Name)
-		needToUpdateTask = true
-		updTask.Name = task.MirrorTaskName(ctx)
+		updTask.Name = s.mirrorTaskName(ctx, &spec, msgData.Target, mirror.Subtask, task)
+		updatedFields = append(updatedFields, tracker.FieldName)
 	}
 	// track task description changes
-	if oldTask.Description != task.Description {
-		needToUpdateTask = true
-		updTask.Description = task.MirrorTaskDescription()
-	}
-	// track priority changes
-	origPriorityID := task.PriorityID
-	mirrorPriorityID := mirror.GetMirrorTask(ctx).PriorityID
-	if origPriorityID != nil && mirrorPriorityID == nil {
-		needToUpdateTask = true
-		updTask.Priority = origPriorityID
-	}
-	if origPriorityID != nil && mirrorPriorityID != nil &&
-		origPriorityID != mirrorPriorityID {
-		needToUpdateTask = true
-		updTask.Priority = origPriorityID
-	}
-	if origPriorityID == nil && mirrorPriorityID != nil {
-		needToUpdateTask = true
-		zero := 0
-		updTask.Priority = &zero
+	if oldTask.DescriptionMarkdown() != task.DescriptionMarkdown() {
+		updTask.Description = s.mirrorTaskDescription(ctx, &spec, msgData.Target, task)
+		updatedFields = append(updatedFields, tracker.FieldDescription)
+	}
+	// track priority changes (the priority is removed if the original task has no priority)
+	// the task of another provider may not support the priority so only the changes are tracked
+	priorityChanged := !equalInt(task.PriorityID, mirror.GetMirrorTask(ctx).PriorityID)
+	if mirror.external() {
+		priorityChanged = !equalInt(task.PriorityID, oldTask.PriorityID)
+	}
+	if priorityChanged {
+		updTask.PriorityID = task.PriorityID
+		updatedFields = append(updatedFields, tracker.FieldPriority)
+	}
+	// track assignees changes
+	if spec.SpecSync.AllowedSyncAssignees(SyncDirectionToMirror) {
+		source, lastSynced := task.AssigneeEmails(), mirror.SyncedToMirror.GetAssigneeEmails()
+		if !mirror.external() {
+			source, lastSynced = opts.MirrorMemberEmails(source), opts.MirrorMemberEmails(lastSynced)
+		}
+		if assignees, changed := s.assigneesChanges(ctx, mirror, mirror.Mirror, source, lastSynced, mirror.GetMirrorTask(ctx)); changed {
+			updTask.Assignees = assignees
+			updatedFields = append(updatedFields, tracker.FieldAssignees)
+		}
 	}
 
 	// track task status changes
 	if oldTask.StatusName != task.StatusName {
-		fmt.Fprintf(commentText, "- changed task status name from %q to %q\n", oldTask.StatusName, task.StatusName)
+		fmt.Fprintln(commentText, s.message(&spec, MsgOrigChangedStatus, msgData.with(oldTask.StatusName, task.StatusName)))
 		needToSendComment = true
 	}
 	// track task clsed at changes
 	if oldTask.DateClosedAt == nil && task.DateClosedAt != nil {
-		fmt.Fprintf(commentText, "- closed\n")
+		fmt.Fprintln(commentText, s.message(&spec, MsgOrigClosed, msgData))
 		needToSendComment = true
 	}
-	if oldTask.Archived == false && oldTask.Archived == true {
-		fmt.Fprintf(commentText, "- archived\n")
+	if !oldTask.Archived && task.Archived {
+		fmt.Fprintln(commentText, s.message(&spec, MsgOrigArchived, msgData))
 		needToSendComment = true
 	}
-	if oldTask.Deleted == false && oldTask.Deleted == true {
-		fmt.Fprintf(commentText, "- deleted\n")
+	if !oldTask.Deleted && task.Deleted {
+		fmt.Fprintln(commentText, s.message(&spec, MsgOrigDeleted, msgData))
 		needToSendComment = true
 	}
 
 	// track location of the task by folder
 	if oldTask.FolderRef.ID != task.FolderRef.ID {
-		fmt.Fprintf(commentText, "- moved to the folder %q\n", task.GetFolder(ctx).Name)
+		fmt.Fprintln(commentText, s.message(&spec, MsgOrigMovedToFolder, msgData))
 		needToSendComment = true
 	}
 	// track location of the task by list
 	if oldTask.ListRef.ID != task.ListRef.ID {
-		fmt.Fprintf(commentText, "- moved to the list %q\n", task.GetList(ctx).Name)
+		fmt.Fprintln(commentText, s.message(&spec, MsgOrigMovedToList, msgData))
 		needToSendComment = true
 	}
 
-	// track task estimate changes
-	totalEstimate := task.TotalEstimate()
-	miirorTotalEsimate := mirror.GetMirrorTask(ctx).TotalEstimate()
-	if miirorTotalEsimate > 0 && totalEstimate == 0 {
-		// removed time estimate
-		fmt.Fprintf(commentText, "- different time estimate - should be equal to %q but nil\n", msHuman(miirorTotalEsimate))
-		needToSendComment = true
-	}
-	if totalEstimate > 0 && miirorTotalEsimate > 0 && miirorTotalEsimate != totalEstimate {
-		// changed estimate from to
-		fmt.Fprintf(commentText, "- different time estimate  - equals %s but should be equal to %s\n", msHuman(totalEstimate), msHuman(miirorTotalEsimate))
-		needToSendComment = true
-	}
-	if miirorTotalEsimate == 0 && totalEstimate > 0 {
-		// added estimate
-		fmt.Fprintf(commentText, "- different time estimate - should be nil but equals to %q\n", msHuman(totalEstimate))
-		needToSendComment = true
-	}
+	// the values of the task of another provider are pushed (see fillExternalChanges) so only the mirror tasks of ClickUp are compared
+	if !mirror.external() {
+		// track task estimate changes
+		totalEstimate := spec.SpecSync.estimateOf(task)
+		miirorTotalEsimate := spec.SpecSync.estimateOf(mirror.GetMirrorTask(ctx))
+		if miirorTotalEsimate > 0 && totalEstimate == 0 {
+			// removed time estimate
+			fmt.Fprintln(commentText, s.message(&spec, MsgDiffEstimateRemoved, msgData.with("", msHuman(miirorTotalEsimate))))
+			needToSendComment = true
+		}
+		if totalEstimate > 0 && miirorTotalEsimate > 0 && miirorTotalEsimate != totalEstimate {
+			// changed estimate from to
+			fmt.Fprintln(commentText, s.message(&spec, MsgDiffEstimateChanged, msgData.with(msHuman(totalEstimate), msHuman(miirorTotalEsimate))))
+			needToSendComment = true
+		}
+		if miirorTotalEsimate == 0 && totalEstimate > 0 {
+			// added estimate
+			fmt.Fprintln(commentText, s.message(&spec, MsgDiffEstimateAdded, msgData.with(msHuman(totalEstimate), "")))
+			needToSendComment = true
+		}
 
-	// track task due date changes
-	origDueDate := task.DueDateAt
-	mirrorDueDate := mirror.GetMirrorTask(ctx).DueDateAt
-	if origDueDate != nil && mirrorDueDate == nil {
-		// removed duedate
-		fmt.Fprintln(commentText, "- different due date - should be nil")
-		needToSendComment = true
-	}
-	if origDueDate != nil && mirrorDueDate != nil &&
-		(*origDueDate).AsTime().Unix() != (*mirrorDueDate).AsTime().Unix() {
-		// changed duedate from to
-		fmt.Fprintf(commentText, "- different due date  - equals %s but should be equal to %s\n",
-			origDueDate.AsTime().Format(time.RFC3339),
-			mirrorDueDate.AsTime().Format(time.RFC3339))
-		needToSendComment = true
+		// track task due date changes
+		origDueDate := task.DueDateAt
+		mirrorDueDate := mirror.GetMirrorTask(ctx).DueDateAt
+		if origDueDate != nil && mirrorDueDate == nil {
+			// removed duedate
+			fmt.Fprintln(commentText, s.message(&spec, MsgDiffDueDateRemoved, msgData))
+			needToSendComment = true
+		}
+		if origDueDate != nil && mirrorDueDate != nil &&
+			(*origDueDate).AsTime().Unix() != (*mirrorDueDate).AsTime().Unix() {
+			// changed duedate from to
+			fmt.Fprintln(commentText, s.message(&spec, MsgDiffDueDateChanged, msgData.with(
+				origDueDate.AsTime().Format(time.RFC3339),
+				mirrorDueDate.AsTime().Format(time.RFC3339))))
+			needToSendComment = true
+		}
+		if origDueDate == nil && mirrorDueDate != nil {
+			// added duedate
+			fmt.Fprintln(commentText, s.message(&spec, MsgDiffDueDateAdded, msgData.with("", mirrorDueDate.AsTime().Format(time.RFC3339))))
+			needToSendComment = true
+		}
+
+		origStartDate := task.StartDateAt
+		mirrorStartDate := mirror.GetMirrorTask(ctx).StartDateAt
+		if origStartDate != nil && mirrorStartDate == nil {
+			// removed duedate
+			fmt.Fprintln(commentText, s.message(&spec, MsgDiffStartDateRemoved, msgData))
+			needToSendComment = true
+		}
+		if origStartDate != nil && mirrorStartDate != nil &&
+			(*origStartDate).AsTime().Unix() != (*mirrorStartDate).AsTime().Unix() {
+			// changed duedate from to
+			fmt.Fprintln(commentText, s.message(&spec, MsgDiffStartDateChanged, msgData.with(
+				origStartDate.AsTime().Format(time.RFC3339),
+				mirrorStartDate.AsTime().Format(time.RFC3339))))
+			needToSendComment = true
+		}
+		if origStartDate == nil && mirrorStartDate != nil {
+			// added duedate
+			fmt.Fprintln(commentText, s.message(&spec, MsgDiffStartDateAdded, msgData.with("", mirrorStartDate.AsTime().Format(time.RFC3339))))
+			needToSendComment = true
+		}
 	}
-	if origDueDate == nil && mirrorDueDate != nil {
-		// added duedate
-		fmt.Fprintln(commentText, "- different due date - should be equal to", mirrorDueDate.AsTime().Format(time.RFC3339))
+
+	if mirror.GetMirrorTask(ctx).IsDeletedOrHidden() {
+		fmt.Fprintln(commentText, s.message(&spec, MsgMirrorHiddenButOrigChanged, msgData))
 		needToSendComment = true
 	}
 
-	origStartDate := task.StartDateAt
-	mirrorStartDate := mirror.GetMirrorTask(ctx).StartDateAt
-	if origStartDate != nil && mirrorStartDate == nil {
-		// removed duedate
-		fmt.Fprintln(commentText, "- different due date - should be nil")
-		needToSendComment = true
+	if mirror.external() {
+		updatedFields = append(updatedFields, s.fillExternalChanges(ctx, &spec, mirror, oldTask, task, updTask)...)
+		updatedFields = s.providerFields(mirror, mirror.Mirror, updatedFields)
 	}
-	if origStartDate != nil && mirrorStartDate != nil &&
-		(*origStartDate).AsTime().Unix() != (*mirrorStartDate).AsTime().Unix() {
-		// changed duedate from to
-		fmt.Fprintf(commentText, "- different due date  - equals %s but should be equal to %s\n",
-			origStartDate.AsTime().Format(time.RFC3339),
-			mirrorStartDate.AsTime().Format(time.RFC3339))
-		needToSendComment = true
+	if len(updatedFields) > 0 && s.updateTask(ctx, mirror, mirror.Mirror, updTask, updatedFields) == nil {
+		updatedFields = nil
 	}
-	if origStartDate == nil && mirrorStartDate != nil {
-		// added duedate
-		fmt.Fprintln(commentText, "- different due date - should be equal to", mirrorStartDate.AsTime().Format(time.RFC3339))
-		needToSendComment = true
+
+	// track checklists changes
+	if spec.SpecSync.MirrorChecklists() && !mirror.external() {
+		s.syncChecklists(ctx, spec, mirror, task, mirror.GetMirrorTask(ctx))
 	}
 
-	if mirror.GetMirrorTask(ctx).IsDeletedOrHidden() {
-		fmt.Fprintln(commentText, "- mirror task is archived or closed but something has changed in original task", task.URL)
-		needToSendComment = true
+	// track attachments changes
+	if attachments := spec.SpecSync.GetAttachments(); attachments.AllowedSync(SyncDirectionToMirror) && !mirror.external() {
+		s.copyAttachments(ctx, attachments, mirror, SyncDirectionToMirror)
 	}
 
-	if needToUpdateTask {
-		updatedTaskAPI := s.api.UpdateTask(ctx, updTask)
-		warnIfFailedRequest(s.log, updatedTaskAPI)
-		if updatedTaskAPI.StatusOK() {
-			updatedTask := ModelTaskFromAPI(ctx, s.store, &updatedTaskAPI.Task)
-			err := s.store.UpsertTask(ctx, updatedTask)
-			warnErrorIf(s.log, err, "failed to update a mirror task after processing changes and apply changes", "task_id", updatedTask.ID)
+	// track tags changes
+	if tags := spec.SpecSync.GetTags(); tags.AllowedSync(SyncDirectionToMirror) {
+		if s.syncTags(ctx, mirror, mirror.Mirror, tags.MirrorTags(task.Tags),
+			tags.MirrorTags(mirror.SyncedToMirror.GetTags()), mirror.GetMirrorTask(ctx).Tags) {
+			updatedFields = append(updatedFields, tracker.FieldTags)
 		}
 	}
 
+	if len(updatedFields) > 0 {
+		s.markSynced(ctx, mirror, SyncDirectionToMirror, task, mirror.Mirror.ID, updatedFields)
+	}
+
 	if needToSendComment {
-		s.sendComment(ctx, mirror.MirrorTaskRef.ID, commentText.String(), spec.SpecAdd.AssignToMemberEmail)
+		s.sendMirrorComment(ctx, mirror, SyncDirectionToMirror, mirror.Mirror, commentText.String(), msgData.Target.GetAssignToMemberEmail())
 	}
 }
 
 func (s *mirrorTaskSyncer) applyChangesToMirrorTask(ctx context.Context, mirror *MirrorTask,
-	spec MirrorTaskSpecification, oldTask, task *Task, statuses MirrorTaskStatuses,
+	spec MirrorTaskSpecification, oldTask, task *Task, opts *SyncPreferences,
 ) {
-
-	if task.IsDeletedOrHidden() {
-		s.sendComment(ctx, task.ID, "FYI changes have been made to a mirror task that is DELETED or HIDDEN - nothing will be updated in original tasks and UNLINK MIRROR TASK",
-			spec.CondAdd.IfAssignedToMemberEmail)
-		s.destroyMirrorTask(ctx, mirror, "mirror task has been DELETED or HIDDEN")
+	statuses := opts.GlobalMirrorTaskStatuses
+	if s.syncLifecycle(ctx, &spec, mirror, oldTask, task, false) {
 		return
 	}
-
-	if mirror.GetOrigTask(ctx).IsDeletedOrHidden() {
-		s.sendComment(ctx, task.ID, "UNLINK MIRROR TASK: the original task has been DELETED or HIDDEN",
-			spec.CondAdd.IfAssignedToMemberEmail)
-		s.destroyMirrorTask(ctx, mirror, "original task has been DELETED or HIDDEN")
+	if mirror.external() && !mirror.target.AllowedSync(SyncDirectionToOrig) {
 		return
 	}
+	msgData := newMirrorTaskTemplateData(ctx, &spec, mirror.GetOrigTask(ctx), task)
 
 	origTask := mirror.GetOrigTask(ctx)
 	if !origTask.Exists() {
-		s.log.Warn("handle task for mirror task - orig task was nil (why?)", zap.String("orig_task_id", mirror.TaskRef.ID),
-			zap.String("mirror_task_id", mirror.TaskRef.ID),
+		s.log.Warn("handle task for mirror task - orig task was nil (why?)", zap.String("orig_task_id", mirror.Orig.ID),
+			zap.String("mirror_task_id", mirror.Orig.ID),
 			zap.String("task_id", task.ID),
 		)
 		return
 	}
 
 	commentText := &bytes.Buffer{}
-	fmt.Fprintln(commentText, "The mirror task has chaned or differences with the original task (by main fields - estimate, due date, start date):")
-	needToUpdateTask := false
+	fmt.Fprintln(commentText, s.message(&spec, MsgMirrorChangesHeader, msgData))
 	needToSendComment := false
-	updTask := &api.UpdateTaskRequest{
-		TaskID: mirror.TaskRef.ID,
-	}
-	updMirrorTask := &api.UpdateTaskRequest{
-		TaskID: mirror.MirrorTaskRef.ID,
-	}
-	needToUpdateMirrorTask := false
+	// the fields of the original task and of the mirror task (see tracker.Field* constants)
+	updatedFields := []string{}
+	updTask := &tracker.Task{}
+	updatedMirrorFields := []string{}
+	updMirrorTask := &tracker.Task{}
 
 	// task name
-	mirrorTaskName := origTask.MirrorTaskName(ctx)
+	mirrorTaskName := s.mirrorTaskName(ctx, &spec, msgData.Target, mirror.Subtask, origTask)
 	if mirrorTaskName != task.Name {
-		needToUpdateMirrorTask = true
 		updMirrorTask.Name = mirrorTaskName
+		updatedMirrorFields = append(updatedMirrorFields, tracker.FieldName)
 	}
 
 	// visibility status
 	if mirror.GetMirrorTask(ctx).IsDeletedOrHidden() && !mirror.GetOrigTask(ctx).IsDeletedOrHidden() {
-		fmt.Fprintf(commentText, "- mirror task has removed. TODO: remove from the mirror?\n")
+		// TODO: remove from the mirror?
+		fmt.Fprintln(commentText, s.message(&spec, MsgMirrorRemoved, msgData))
 		needToSendComment = true
 	}
 
 	// TODO: description change
 
-	if origTaskStatus := statuses.SetStatusToOrigTaskIfExists(mirror.GetMirrorTask(ctx).StatusName); origTaskStatus != "" {
+	// assignees
+	if spec.SpecSync.AllowedSyncAssignees(SyncDirectionToOrig) {
+		source, lastSynced := task.AssigneeEmails(), mirror.SyncedToOrig.GetAssigneeEmails()
+		if !mirror.external() {
+			source, lastSynced = opts.OrigMemberEmails(source), opts.OrigMemberEmails(lastSynced)
+		}
+		if assignees, changed := s.assigneesChanges(ctx, mirror, mirror.Orig, source, lastSynced, origTask); changed {
+			updTask.Assignees = assignees
+			updatedFields = append(updatedFields, tracker.FieldAssignees)
+		}
+	}
+
+	origTaskStatus := ""
+	if mirror.external() {
+		origTaskStatus = externalOrigTaskStatus(statuses, mirror, oldTask, task)
+	} else {
+		origTaskStatus = s.origTaskStatusFor(ctx, statuses, &spec, mirror, task)
+	}
+	if origTaskStatus != "" {
 		if strings.ToLower(mirror.GetOrigTask(ctx).StatusName) != origTaskStatus {
 			// will be set to original task the status
-			needToUpdateTask = true
-			updTask.StatusName = strings.ToLower(origTaskStatus)
+			updTask.Status = strings.ToLower(origTaskStatus)
+			updatedFields = append(updatedFields, tracker.FieldStatus)
 		}
 	}
 
-	if statuses.AllowedSyncEstimate(mirror.GetMirrorTask(ctx).StatusName) {
+	// the estimate and the dates of the task of another provider are synced by the statuses only with mirror_task_statuses
+	allowedSyncEstimate := statuses.AllowedSyncEstimate(mirror.GetMirrorTask(ctx).StatusName)
+	if mirror.external() && !mirror.target.MirrorTaskStatuses {
+		allowedSyncEstimate = true
+	}
+	if allowedSyncEstimate {
 		// estimate
-		totalEstimate := task.TotalEstimate()
-		if origTask.TimeEstimateMs != nil && totalEstimate == 0 {
-			// removed time estimate
-			needToUpdateTask = true
-			updTask.TimeEstimateMs = -1
-			fmt.Fprintf(commentText, "- orig task will be updated - time estimate will be removed\n")
-			needToSendComment = true
-		} else if origTask.TimeEstimateMs != nil && totalEstimate != 0 && totalEstimate != *origTask.TimeEstimateMs {
-			// changed estimate from to
-			needToUpdateTask = true
-			updTask.TimeEstimateMs = totalEstimate
-			fmt.Fprintf(commentText, "- orig task will be updated - time estimate will be sets to %s\n", msHuman(totalEstimate))
-			needToSendComment = true
-		}
-		if origTask.TimeEstimateMs == nil && totalEstimate > 0 {
-			// added estimate
-			needToUpdateTask = true
-			updTask.TimeEstimateMs = totalEstimate
-			fmt.Fprintf(commentText, "- orig task will be updated - time estimate will be sets to %s\n", msHuman(totalEstimate))
-			needToSendComment = true
+		if mirrorFieldChanged(mirror, tracker.FieldEstimate, oldTask, task) {
+			totalEstimate := spec.SpecSync.estimateOf(task)
+			if origTask.TimeEstimateMs != nil && totalEstimate == 0 {
+				// removed time estimate
+				updatedFields = append(updatedFields, tracker.FieldEstimate)
+				fmt.Fprintln(commentText, s.message(&spec, MsgOrigEstimateRemoved, msgData))
+				needToSendComment = true
+			} else if origTask.TimeEstimateMs != nil && totalEstimate != 0 && totalEstimate != *origTask.TimeEstimateMs {
+				// changed estimate from to
+				updTask.TimeEstimateMs = &totalEstimate
+				updatedFields = append(updatedFields, tracker.FieldEstimate)
+				fmt.Fprintln(commentText, s.message(&spec, MsgOrigEstimateChanged, msgData.with("", msHuman(totalEstimate))))
+				needToSendComment = true
+			}
+			if origTask.TimeEstimateMs == nil && totalEstimate > 0 {
+				// added estimate
+				updTask.TimeEstimateMs = &totalEstimate
+				updatedFields = append(updatedFields, tracker.FieldEstimate)
+				fmt.Fprintln(commentText, s.message(&spec, MsgOrigEstimateChanged, msgData.with("", msHuman(totalEstimate))))
+				needToSendComment = true
+			}
 		}
 
 		// due date
-		if origTask.DueDateAt != nil && task.DueDateAt == nil {
-			// removed duedate
-			needToUpdateTask = true
-			updTask.DueDate = -1
-			fmt.Fprintf(commentText, "- orig task will be updated - time due date will be removed\n")
-			needToSendComment = true
-		}
-		if origTask.DueDateAt != nil && task.DueDateAt != nil &&
-			(*origTask.DueDateAt).AsTime().Unix() != (*task.DueDateAt).AsTime().Unix() {
-			// changed duedate from to
-			needToUpdateTask = true
-			updTask.DueDate = (*task.DueDateAt).AsTime().Unix() * 1000
-			fmt.Fprintf(commentText, "- orig task will be updated - time due date will be sets to %s\n", (*task.DueDateAt).AsTime().Format(time.RFC3339))
-			needToSendComment = true
-		}
-		if origTask.DueDateAt == nil && task.DueDateAt != nil {
-			// added duedate
-			needToUpdateTask = true
-			updTask.DueDate = (*task.DueDateAt).AsTime().Unix() * 1000
-			fmt.Fprintf(commentText, "- orig task will be updated - time due date will be sets to %s\n", (*task.DueDateAt).AsTime().Format(time.RFC3339))
-			needToSendComment = true
+		if mirrorFieldChanged(mirror, tracker.FieldDueDate, oldTask, task) {
+			if origTask.DueDateAt != nil && task.DueDateAt == nil {
+				// removed duedate
+				updatedFields = append(updatedFields, tracker.FieldDueDate)
+				fmt.Fprintln(commentText, s.message(&spec, MsgOrigDueDateRemoved, msgData))
+				needToSendComment = true
+			}
+			if origTask.DueDateAt != nil && task.DueDateAt != nil &&
+				(*origTask.DueDateAt).AsTime().Unix() != (*task.DueDateAt).AsTime().Unix() {
+				// changed duedate from to
+				updTask.DueDate = timeFromTimestamp(task.DueDateAt)
+				updatedFields = append(updatedFields, tracker.FieldDueDate)
+				fmt.Fprintln(commentText, s.message(&spec, MsgOrigDueDateChanged, msgData.with("", (*task.DueDateAt).AsTime().Format(time.RFC3339))))
+				needToSendComment = true
+			}
+			if origTask.DueDateAt == nil && task.DueDateAt != nil {
+				// added duedate
+				updTask.DueDate = timeFromTimestamp(task.DueDateAt)
+				updatedFields = append(updatedFields, tracker.FieldDueDate)
+				fmt.Fprintln(commentText, s.message(&spec, MsgOrigDueDateChanged, msgData.with("", (*task.DueDateAt).AsTime().Format(time.RFC3339))))
+				needToSendComment = true
+			}
 		}
 
 		// start date
-		if origTask.StartDateAt != nil && task.StartDateAt == nil {
-			// removed startdate
-			needToUpdateTask = true
-			updTask.StartDate = -1
-			fmt.Fprintf(commentText, "- orig task will be updated - time start date will be removed\n")
-			needToSendComment = true
-		}
-		if origTask.StartDateAt != nil && task.StartDateAt != nil &&
-			(*origTask.StartDateAt).AsTime().Unix() != (*task.StartDateAt).AsTime().Unix() {
-			// changed startdate from to
-			needToUpdateTask = true
-			updTask.StartDate = (*task.StartDateAt).AsTime().Unix() * 1000
-			fmt.Fprintf(commentText, "- orig task will be updated - time start date will be sets to %s\n", (*task.StartDateAt).AsTime().Format(time.RFC3339))
-			needToSendComment = true
-		}
-		if origTask.StartDateAt == nil && task.StartDateAt != nil {
-			// added startdate
-			needToUpdateTask = true
-			updTask.StartDate = (*task.StartDateAt).AsTime().Unix() * 1000
-			fmt.Fprintf(commentText, "- orig task will be updated - time start date will be sets to %s\n", (*task.StartDateAt).AsTime().Format(time.RFC3339))
-			needToSendComment = true
+		if mirrorFieldChanged(mirror, tracker.FieldStartDate, oldTask, task) {
+			if origTask.StartDateAt != nil && task.StartDateAt == nil {
+				// removed startdate
+				updatedFields = append(updatedFields, tracker.FieldStartDate)
+				fmt.Fprintln(commentText, s.message(&spec, MsgOrigStartDateRemoved, msgData))
+				needToSendComment = true
+			}
+			if origTask.StartDateAt != nil && task.StartDateAt != nil &&
+				(*origTask.StartDateAt).AsTime().Unix() != (*task.StartDateAt).AsTime().Unix() {
+				// changed startdate from to
+				updTask.StartDate = timeFromTimestamp(task.StartDateAt)
+				updatedFields = append(updatedFields, tracker.FieldStartDate)
+				fmt.Fprintln(commentText, s.message(&spec, MsgOrigStartDateChanged, msgData.with("", (*task.StartDateAt).AsTime().Format(time.RFC3339))))
+				needToSendComment = true
+			}
+			if origTask.StartDateAt == nil && task.StartDateAt != nil {
+				// added startdate
+				updTask.StartDate = timeFromTimestamp(task.StartDateAt)
+				updatedFields = append(updatedFields, tracker.FieldStartDate)
+				fmt.Fprintln(commentText, s.message(&spec, MsgOrigStartDateChanged, msgData.with("", (*task.StartDateAt).AsTime().Format(time.RFC3339))))
+				needToSendComment = true
+			}
 		}
 	}
 
-	if needToUpdateMirrorTask {
-		updatedTaskAPI := s.api.UpdateTask(ctx, updMirrorTask)
-		warnIfFailedRequest(s.log, updatedTaskAPI)
+	if len(updatedMirrorFields) > 0 && s.updateTask(ctx, mirror, mirror.Mirror, updMirrorTask, updatedMirrorFields) != nil {
+		s.markSynced(ctx, mirror, SyncDirectionToMirror, origTask, mirror.Mirror.ID, updatedMirrorFields)
+	}
 
-		if updatedTaskAPI.StatusOK() {
-			updatedTask := ModelTaskFromAPI(ctx, s.store, &updatedTaskAPI.Task)
-			err := s.store.UpsertTask(ctx, updatedTask)
-			warnErrorIf(s.log, err, "failed to update a mirror task after processing changes and apply changes", "task_id", updatedTask.ID)
-		}
+	if len(updatedFields) > 0 && s.updateTask(ctx, mirror, mirror.Orig, updTask, updatedFields) == nil {
+		updatedFields = nil
+	}
+
+	// checklists
+	if spec.SpecSync.MirrorChecklists() && !mirror.external() {
+		s.syncChecklists(ctx, spec, mirror, origTask, task)
 	}
 
-	if needToUpdateTask {
-		updatedTaskAPI := s.api.UpdateTask(ctx, updTask)
-		warnIfFailedRequest(s.log, updatedTaskAPI)
+	// tracked time
+	if timeTracking := spec.SpecSync.GetTimeTracking(); timeTracking != nil && !mirror.external() &&
+		statuses.AllowedSyncTimeTracked(mirror.GetMirrorTask(ctx).StatusName) {
+		s.syncTimeTracked(ctx, opts, timeTracking, mirror, origTask, task)
+	}
+
+	// attachments
+	if attachments := spec.SpecSync.GetAttachments(); attachments.AllowedSync(SyncDirectionToOrig) && !mirror.external() {
+		s.copyAttachments(ctx, attachments, mirror, SyncDirectionToOrig)
+	}
 
-		if updatedTaskAPI.StatusOK() {
-			updatedTask := ModelTaskFromAPI(ctx, s.store, &updatedTaskAPI.Task)
-			err := s.store.UpsertTask(ctx, updatedTask)
-			warnErrorIf(s.log, err, "failed to update a original task after processing changes and apply changes", "task_id", updatedTask.ID)
+	// tags
+	if tags := spec.SpecSync.GetTags(); tags.AllowedSync(SyncDirectionToOrig) {
+		if s.syncTags(ctx, mirror, mirror.Orig, tags.OrigTags(task.Tags),
+			tags.OrigTags(mirror.SyncedToOrig.GetTags()), origTask.Tags) {
+			updatedFields = append(updatedFields, tracker.FieldTags)
 		}
 	}
 
+	if len(updatedFields) > 0 {
+		s.markSynced(ctx, mirror, SyncDirectionToOrig, task, mirror.Orig.ID, updatedFields)
+	}
+
 	if needToSendComment {
-		s.sendComment(ctx, mirror.MirrorTaskRef.ID, commentText.String(), spec.SpecAdd.AssignToMemberEmail)
+		s.sendMirrorComment(ctx, mirror, SyncDirectionToMirror, mirror.Mirror, commentText.String(), msgData.Target.GetAssignToMemberEmail())
 	}
 }
 
-func (s *mirrorTaskSyncer) addMirrorTask(ctx context.Context, spec *SyncRule_SpecOfAdd, task *Task) {
+func (s *mirrorTaskSyncer) addMirrorTask(ctx context.Context, opts *SyncPreferences, rule MirrorTaskSpecification, spec *SyncRule_SpecOfAddTarget, task *Task) {
 	taskID := task.ID
 	l := s.log.Named("add_mirror_task").With(zap.String("task_id", taskID))
 
@@ -441,68 +665,136 @@ func (s *mirrorTaskSyncer) addMirrorTask(ctx context.Context, spec *SyncRule_Spe
 		return
 	}
 
-	mirrorTask := &api.CreateTaskRequest{
-		ListID:              spec.GetAddToListID(),
-		Name:                task.MirrorTaskName(ctx),
-		RefTaskID:           task.ID,
-		Tags:                []string{"mirror"},
-		DescriptionMarkdown: task.MirrorTaskDescription(),
-		PriorityID:          task.PriorityID,
+	mirrorTask := &tracker.Task{
+		Name:        s.mirrorTaskName(ctx, &rule, spec, false, task),
+		Links:       []tracker.TaskRef{TaskRef(task.ID)},
+		Tags:        rule.SpecSync.GetTags().NewMirrorTaskTags(),
+		Description: s.mirrorTaskDescription(ctx, &rule, spec, task),
+		PriorityID:  task.PriorityID,
+		Assignees:   []tracker.Member{},
 	}
 	if spec.SetStatusName != "" {
-		mirrorTask.StatusName = spec.SetStatusName
+		mirrorTask.Status = spec.SetStatusName
 	}
 
-	commentText := &bytes.Buffer{}
-	fmt.Fprintln(commentText, "The mirror task from "+task.URL)
+	msgData := newMirrorTaskTemplateData(ctx, &rule, task, nil)
+	msgData.Target = spec
 
 	if spec.AssignToMemberEmail != "" {
 		member := s.store.MemberByEmail(ctx, spec.AssignToMemberEmail)
 		if member.Exists() {
-			mirrorTask.AssignIDs = []string{member.ID}
+			mirrorTask.Assignees = append(mirrorTask.Assignees, tracker.Member{ID: member.ID, Email: spec.AssignToMemberEmail})
 		} else {
 			l.Warn("failed find member by email", zap.String("email", spec.AssignToMemberEmail))
-			fmt.Fprintln(commentText, "Must be assigned to "+spec.AssignToMemberEmail)
+			msgData.AssignTo = spec.AssignToMemberEmail
+		}
+	}
+
+	if tags := rule.SpecSync.GetTags(); tags.AllowedSync(SyncDirectionToMirror) {
+		for _, tag := range tags.MirrorTags(task.Tags) {
+			if !containsString(mirrorTask.Tags, tag) {
+				mirrorTask.Tags = append(mirrorTask.Tags, tag)
+			}
+		}
+	}
+
+	if rule.SpecSync.AllowedSyncAssignees(SyncDirectionToMirror) {
+		for _, email := range opts.MirrorMemberEmails(task.AssigneeEmails()) {
+			member := s.store.MemberByEmail(ctx, email)
+			if !member.Exists() {
+				l.Warn("failed find member by email (sync assignees)", zap.String("email", email))
+				continue
+			}
+			if !hasMember(mirrorTask.Assignees, member.ID) {
+				mirrorTask.Assignees = append(mirrorTask.Assignees, tracker.Member{ID: member.ID, Email: email})
+			}
 		}
 	}
 
-	res := s.api.CreateTask(ctx, mirrorTask)
-	warnIfFailedRequest(s.log, res)
-	if res.TaskID == "" {
-		l.Error("aborted creation of a mirror task - no ID from a new mirror task (from API)")
+	created, err := s.providers.CreateTask(ctx, tracker.Location{Provider: ProviderName, ID: spec.GetAddToListID()}, mirrorTask)
+	if err != nil {
+		l.Error("aborted creation of a mirror task - failed to create the task", zap.Error(err))
 		return
 	}
-	err := s.store.UpsertMirrorTask(ctx, s.store.ModelMirrorTaskFor(taskID, res.TaskID))
+	mirror := s.store.ModelMirrorTaskOf(taskID, created.Ref)
+	mirror.RuleName = rule.Name
+	mirror.CreatedAt = TimestampNow()
+	mirror.SyncedToMirror = NewMirrorTaskSnapshot(task)
+	err = s.store.UpsertMirrorTask(ctx, mirror)
 	if err != nil {
-		l.Error("failed add mirror task to database", zap.Error(err), zap.String("mirror_task_id", res.TaskID))
+		l.Error("failed add mirror task to database", zap.Error(err), zap.String("mirror_task_id", created.Ref.ID))
 		return
 	}
+	s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+		Action:    MirrorTaskActionCreated,
+		Direction: SyncDirectionToMirror,
+		TaskID:    created.Ref.ID,
+	})
+
+	s.sendMirrorComment(ctx, mirror, SyncDirectionToMirror, created.Ref, s.message(&rule, MsgIntroComment, msgData), "")
+
+	if rule.SpecSync.MirrorSubtasks() {
+		s.addExistingMirrorSubtasks(ctx, &rule, spec, task, created.Ref.ID, spec.GetAddToListID())
+	}
+
+	s.notifier.notify(ctx, &rule, task, &notify.Event{
+		Kind:      notify.EventMirrorAdded,
+		MirrorURL: created.URL,
+		Actions:   pairActions(mirror),
+	})
+}
 
-	fmt.Fprintln(commentText, "A ready go.")
-	fmt.Fprintln(commentText)
-	fmt.Fprintln(commentText, "NOTES: ")
-	fmt.Fprintln(commentText, "- description without markdown formatting")
-	fmt.Fprintln(commentText, "- sets estimate in subtasks do not initiate a push into the original task (2022/01/18)")
-	fmt.Fprintln(commentText, "- not always the due date is pushed into the orig task")
-	s.sendComment(ctx, res.TaskID, commentText.String(), "")
+// returns true if the member is in the list
+func hasMember(list []tracker.Member, memberID string) bool {
+	for _, member := range list {
+		if member.ID == memberID {
+			return true
+		}
+	}
+	return false
 }
 
-func (s *mirrorTaskSyncer) sendComment(ctx context.Context, taskID string, commentText string, assignToEmail string) {
-	comment := &api.AddCommentToTaskRequest{
-		TaskID:      taskID,
-		CommentText: commentText,
+// returns true if the comment has been sent.
+// The comments of the pairs with the task of another provider are remembered as not to be mirrored (see syncExternalComments).
+func (s *mirrorTaskSyncer) sendComment(ctx context.Context, mirror *MirrorTask, ref tracker.TaskRef, commentText string, assignToEmail string) bool {
+	comment := &tracker.Comment{
+		Body: commentText,
 	}
 	if assignToEmail != "" {
-		member := s.store.MemberByEmail(ctx, assignToEmail)
-		if member.Exists() {
-			comment.AssignToMemberID = fmt.Sprint(member.ID)
-		} else {
+		comment.AssignTo = s.memberByEmail(ctx, mirror, ref, assignToEmail)
+		if comment.AssignTo == nil {
 			s.log.Warn("comment sending - not found member by email", zap.String("email", assignToEmail),
-				zap.String("task_id", taskID))
+				zap.String("task", ref.String()))
+		}
+	}
+	created := s.addComment(ctx, mirror, ref, comment)
+	if created == nil {
+		return false
+	}
+	if mirror.external() {
+		if mirror.CommentIDs == nil {
+			mirror.CommentIDs = map[string]string{}
+		}
+		if ref == mirror.Orig {
+			mirror.CommentIDs[created.ID] = ""
+		} else {
+			mirror.CommentIDs[ref.Provider+":"+created.ID] = created.ID
 		}
+		err := s.store.UpsertMirrorTask(ctx, mirror)
+		warnErrorIf(s.log, err, "failed to save the IDs of the comments", "model_id", mirror.ModelID())
+	}
+	return true
+}
+
+// adds the comment to the task of the pair by the provider of the task. Returns the created comment or nil if failed.
+func (s *mirrorTaskSyncer) addComment(ctx context.Context, mirror *MirrorTask, ref tracker.TaskRef, comment *tracker.Comment) *tracker.Comment {
+	location, key := mirror.locate(ref)
+	created, err := s.providers.AddComment(ctx, location, key, comment)
+	if err != nil {
+		s.log.Warn("failed to send the comment", zap.Error(err), zap.String("task", ref.String()))
+		return nil
 	}
-	res := s.api.AddCommentToTask(ctx, comment)
-	warnIfFailedRequest(s.log, res)
+	return created
 }
 
 type syncMirrorTasksMatchedRules struct {
@@ -511,7 +803,7 @@ type syncMirrorTasksMatchedRules struct {
 }
 
 // returns the rules that match the task
-func (r *mirrorTaskSyncer) matchedRules(rules []MirrorTaskSpecification, task *Task) *syncMirrorTasksMatchedRules {
+func matchedRules(rules []MirrorTaskSpecification, task *Task) *syncMirrorTasksMatchedRules {
 	res := &syncMirrorTasksMatchedRules{task: task}
 
 	if len(rules) == 0 {
@@ -561,10 +853,8 @@ func (r *mirrorTaskSyncer) matchedRules(rules []MirrorTaskSpecification, task *T
 	for idx := range rules {
 		rule := rules[idx]
 
-		if spec := rule.SpecAdd; spec != nil {
-			if spec.GetAddToListID() == listID {
-				res.syncedRules = append(res.syncedRules, rule)
-			}
+		if rule.SpecAdd.TargetByListID(listID) != nil {
+			res.syncedRules = append(res.syncedRules, rule)
 		}
 	}
 
@@ -582,6 +872,23 @@ type MirrorTaskSpecification struct {
 	CondAdd          *SyncRule_CondOfAdd        `yaml:"cond_add"`
 	CondTrackChanges *SyncRule_CondTrackChanges `yaml:"cond_track_changes"`
 	SpecAdd          *SyncRule_SpecOfAdd        `yaml:"spec_add"`
+	SpecSync         *SyncRule_SpecOfSync       `yaml:"spec_sync,omitempty"`
+	Templates        *SyncRule_SpecOfTemplates  `yaml:"templates,omitempty"`
+	// spec for the moves of the original task to another list
+	SpecMove *SyncRule_SpecOfMove `yaml:"spec_move,omitempty"`
+	// spec for the closed, archived or deleted tasks of the pair
+	SpecLifecycle *SyncRule_SpecOfLifecycle `yaml:"spec_lifecycle,omitempty"`
+	// the notifications about the changes of the tasks to the chats of the messengers
+	Notify []SyncRule_SpecOfNotify `yaml:"notify,omitempty"`
+	// the outbound webhooks of the events of the tasks
+	Webhooks []SyncRule_SpecOfWebhook `yaml:"webhooks,omitempty"`
+}
+
+func (r *MirrorTaskSpecification) GetSpecMove() *SyncRule_SpecOfMove {
+	if r == nil {
+		return nil
+	}
+	return r.SpecMove
 }
 
 func (r *MirrorTaskSpecification) existsRultesForTeamID(teamID string) bool {
@@ -625,10 +932,12 @@ func (r *MirrorTaskSpecification) UsedTeamIDs() []string {
 		}
 	}
 
-	id := teamIDFromURL(r.SpecAdd.AddToList)
-	if !uniq[id] {
-		uniq[id] = true
-		res = append(res, id)
+	for _, target := range r.SpecAdd.AllTargets() {
+		id := teamIDFromURL(target.AddToList)
+		if !uniq[id] {
+			uniq[id] = true
+			res = append(res, id)
+		}
 	}
 
 	return res
@@ -661,14 +970,98 @@ type SyncRule_SpecOfAdd struct {
 	AddToList           string `yaml:"add_to_list"`
 	SetStatusName       string `yaml:"set_status_name"`
 	AssignToMemberEmail string `yaml:"assign_to_member_email"`
+	// the additional target lists (fan-out of the original task to several lists)
+	Targets []SyncRule_SpecOfAddTarget `yaml:"targets,omitempty"`
+	// the aggregation of the statuses of the mirror tasks into the status of the original task (any, all) (any by default)
+	StatusAggregation string `yaml:"status_aggregation,omitempty"`
+	// the issues in the external trackers (eg GitHub Issues) created from the original tasks
+	External []SyncRule_SpecOfExternalTarget `yaml:"external,omitempty"`
+	// the mirror tasks are created only after the approval from the notification (see notify of the rule, eg Slack)
+	RequireApproval bool `yaml:"require_approval,omitempty"`
 	// TODO: add more flexible rules
 	// For eg.
 	// - send comment?
 	// - add tag?
 }
 
-func (s *SyncRule_SpecOfAdd) GetAddToListID() string {
-	return listIDFromURL(s.AddToList)
+// spec for the synchronization of the additional fields between the original and the mirror tasks
+type SyncRule_SpecOfSync struct {
+	// sync direction of the assignees (available orig_to_mirror, mirror_to_orig, both)
+	// by default the assignees are not synced
+	Assignees string `yaml:"assignees,omitempty"`
+	// sync of the tags
+	Tags *SyncRule_SpecOfTags `yaml:"tags,omitempty"`
+	// mirrors the subtasks of the original task as subtasks of the mirror task.
+	// Estimates are synced per subtask (not the total estimate of the task).
+	Subtasks bool `yaml:"subtasks,omitempty"`
+	// mirrors the checklists of the original task to the mirror task.
+	// The value is the sync direction of the resolved state of the checklist items (available orig_to_mirror, mirror_to_orig, both),
+	// the checklists and the checklist items are always created from the original task.
+	Checklists string `yaml:"checklists,omitempty"`
+	// copies the attachments between the original and the mirror tasks
+	Attachments *SyncRule_SpecOfAttachments `yaml:"attachments,omitempty"`
+	// aggregates the tracked time of the mirror task into the original task
+	TimeTracking *SyncRule_SpecOfTimeTracking `yaml:"time_tracking,omitempty"`
+}
+
+func (s *SyncRule_SpecOfSync) GetTimeTracking() *SyncRule_SpecOfTimeTracking {
+	if s == nil {
+		return nil
+	}
+	return s.TimeTracking
+}
+
+func (s *SyncRule_SpecOfSync) GetAttachments() *SyncRule_SpecOfAttachments {
+	if s == nil {
+		return nil
+	}
+	return s.Attachments
+}
+
+func (s *SyncRule_SpecOfSync) MirrorChecklists() bool {
+	if s == nil {
+		return false
+	}
+	return s.Checklists != ""
+}
+
+func (s *SyncRule_SpecOfSync) AllowedSyncChecklists(direction string) bool {
+	if s == nil {
+		return false
+	}
+	return allowedSyncDirection(s.Checklists, direction)
+}
+
+func (s *SyncRule_SpecOfSync) MirrorSubtasks() bool {
+	if s == nil {
+		return false
+	}
+	return s.Subtasks
+}
+
+// returns the estimate of the task is compared between the original and the mirror tasks
+func (s *SyncRule_SpecOfSync) estimateOf(task *Task) int64 {
+	if s.MirrorSubtasks() {
+		if task.TimeEstimateMs == nil {
+			return 0
+		}
+		return *task.TimeEstimateMs
+	}
+	return task.TotalEstimate()
+}
+
+func (s *SyncRule_SpecOfSync) GetTags() *SyncRule_SpecOfTags {
+	if s == nil {
+		return nil
+	}
+	return s.Tags
+}
+
+func (s *SyncRule_SpecOfSync) AllowedSyncAssignees(direction string) bool {
+	if s == nil {
+		return false
+	}
+	return allowedSyncDirection(s.Assignees, direction)
 }
 
 func (s *SyncRule_CondOfAdd) PassedCheckByStatus(in string) bool {
@@ -721,6 +1114,24 @@ func (s *SyncRule_CondOfAdd) PassedCheckByListID(in string) bool {
 	return false
 }
 
+// returns error if the folders or the lists are not the URLs of ClickUp.
+func (s *SyncRule_CondOfAdd) validateClickUpLocations() error {
+	if s == nil {
+		return nil
+	}
+	for _, folderURL := range s.IfInFolders {
+		if folderIDFromURL(folderURL) == "" {
+			return fmt.Errorf("if_in_folders %q is not the folder of ClickUp", folderURL)
+		}
+	}
+	for _, listURL := range s.IfInLists {
+		if listIDFromURL(listURL) == "" {
+			return fmt.Errorf("if_in_lists %q is not the list of ClickUp", listURL)
+		}
+	}
+	return nil
+}
+
 func (s *SyncRule_CondOfAdd) GetIfInFolderIDs() []string {
 	res := []string{}
 	for _, folderURL := range s.IfInFolders {
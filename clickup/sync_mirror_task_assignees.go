@@ -0,0 +1,109 @@
+package clickup
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gebv/asap-tools/tracker"
+	"go.uber.org/zap"
+)
+
+// assigneesChanges returns the assignees of the target task (the task ref of the pair) with the changes of the assignees.
+// The emails of source and lastSynced must be already mapped to the team of the target task.
+// Returns false if there are no changes.
+func (s *mirrorTaskSyncer) assigneesChanges(ctx context.Context, mirror *MirrorTask, ref tracker.TaskRef,
+	source, lastSynced []string, target *Task) ([]tracker.Member, bool) {
+	adds, removes := diffSyncedValues(source, lastSynced, target.AssigneeEmails())
+	removed := lowerSet(removes)
+
+	res := []tracker.Member{}
+	for _, member := range target.GetAssignees() {
+		if !removed[strings.ToLower(member.Email)] {
+			res = append(res, trackerMember(member))
+		}
+	}
+	changed := len(removes) > 0
+	for _, email := range adds {
+		member := s.memberByEmail(ctx, mirror, ref, email)
+		if member == nil {
+			s.log.Warn("sync assignees - not found member by email", zap.String("email", email))
+			continue
+		}
+		res = append(res, *member)
+		changed = true
+	}
+
+	return res, changed
+}
+
+// returns the member of the provider of the task of the pair by the email or nil
+// (the users of another provider are mapped by member_map of the target)
+func (s *mirrorTaskSyncer) memberByEmail(ctx context.Context, mirror *MirrorTask, ref tracker.TaskRef, email string) *tracker.Member {
+	if ref == mirror.Mirror && mirror.external() {
+		if mirror.target == nil {
+			return nil
+		}
+		if users := mirror.target.usersFor([]string{email}); len(users) > 0 {
+			return &tracker.Member{ID: users[0], Email: email}
+		}
+		return nil
+	}
+	member := s.store.MemberByEmail(ctx, email)
+	if !member.Exists() {
+		return nil
+	}
+	res := trackerMember(member)
+	return &res
+}
+
+// returns the member of ClickUp as the member of the provider
+func trackerMember(member *Member) tracker.Member {
+	return tracker.Member{
+		ID:    member.ID,
+		Email: member.Email,
+		Name:  member.Username,
+	}
+}
+
+// diffSyncedValues returns the values (case insensitive, eg emails or tags) to be added to and removed from the target task.
+// - added are the values of the source task which are not in the target task
+// - removed are the values which were synced previously, are removed from the source task and still in the target task
+// Values of the target task that have never been synced are not removed.
+func diffSyncedValues(source, lastSynced, target []string) (adds, removes []string) {
+	inSource := lowerSet(source)
+	inTarget := lowerSet(target)
+
+	for _, value := range source {
+		value = strings.ToLower(value)
+		if !inTarget[value] && !containsString(adds, value) {
+			adds = append(adds, value)
+		}
+	}
+	for _, value := range lastSynced {
+		value = strings.ToLower(value)
+		if !inSource[value] && inTarget[value] && !containsString(removes, value) {
+			removes = append(removes, value)
+		}
+	}
+	return adds, removes
+}
+
+// returns the list without the removed values (case insensitive) and with the added values
+func applySyncedValues(list, adds, removes []string) []string {
+	removed := lowerSet(removes)
+	res := []string{}
+	for _, value := range list {
+		if !removed[strings.ToLower(value)] {
+			res = append(res, value)
+		}
+	}
+	return append(res, adds...)
+}
+
+func lowerSet(list []string) map[string]bool {
+	res := map[string]bool{}
+	for _, value := range list {
+		res[strings.ToLower(value)] = true
+	}
+	return res
+}
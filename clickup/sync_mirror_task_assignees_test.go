@@ -0,0 +1,47 @@
+package clickup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_diffSyncedValues(t *testing.T) {
+	tests := []struct {
+		name                       string
+		source, lastSynced, target []string
+		wantAdds, wantRemoves      []string
+	}{
+		{"empty", nil, nil, nil, nil, nil},
+		{"added", []string{"a@x.com"}, nil, nil, []string{"a@x.com"}, nil},
+		{"already assigned", []string{"A@x.com"}, nil, []string{"a@x.com"}, nil, nil},
+		{"removed synced", nil, []string{"a@x.com"}, []string{"a@x.com"}, nil, []string{"a@x.com"}},
+		{"not synced is kept", nil, nil, []string{"b@x.com"}, nil, nil},
+		{"replaced", []string{"b@x.com"}, []string{"a@x.com"}, []string{"a@x.com", "c@x.com"}, []string{"b@x.com"}, []string{"a@x.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAdds, gotRemoves := diffSyncedValues(tt.source, tt.lastSynced, tt.target)
+			if !reflect.DeepEqual(gotAdds, tt.wantAdds) {
+				t.Errorf("diffSyncedValues() adds = %v, want %v", gotAdds, tt.wantAdds)
+			}
+			if !reflect.DeepEqual(gotRemoves, tt.wantRemoves) {
+				t.Errorf("diffSyncedValues() removes = %v, want %v", gotRemoves, tt.wantRemoves)
+			}
+		})
+	}
+}
+
+func TestSyncPreferences_MemberEmails(t *testing.T) {
+	opts := &SyncPreferences{
+		MemberMap: []MemberMapping{
+			{OrigEmail: "john@client.com", MirrorEmail: "john@agency.com"},
+		},
+	}
+
+	if got := opts.MirrorMemberEmails([]string{"John@client.com", "bob@x.com"}); !reflect.DeepEqual(got, []string{"john@agency.com", "bob@x.com"}) {
+		t.Errorf("MirrorMemberEmails() = %v", got)
+	}
+	if got := opts.OrigMemberEmails([]string{"john@agency.com", "bob@x.com"}); !reflect.DeepEqual(got, []string{"john@client.com", "bob@x.com"}) {
+		t.Errorf("OrigMemberEmails() = %v", got)
+	}
+}
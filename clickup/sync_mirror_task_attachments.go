@@ -0,0 +1,113 @@
+package clickup
+
+import (
+	"context"
+
+	"github.com/gebv/asap-tools/clickup/api"
+	"go.uber.org/zap"
+)
+
+const defaultAttachmentMaxSizeBytes = 10 << 20
+
+// spec of the copying of the attachments between the original and the mirror tasks
+type SyncRule_SpecOfAttachments struct {
+	// copy direction of the attachments (available orig_to_mirror, mirror_to_orig, both)
+	Direction string `yaml:"direction"`
+	// the attachments larger than the size are not copied (10MB by default)
+	MaxSizeBytes int64 `yaml:"max_size_bytes,omitempty"`
+}
+
+func (s *SyncRule_SpecOfAttachments) AllowedSync(direction string) bool {
+	if s == nil {
+		return false
+	}
+	return allowedSyncDirection(s.Direction, direction)
+}
+
+func (s *SyncRule_SpecOfAttachments) GetMaxSizeBytes() int64 {
+	if s == nil || s.MaxSizeBytes <= 0 {
+		return defaultAttachmentMaxSizeBytes
+	}
+	return s.MaxSizeBytes
+}
+
+// copyAttachments copies the new attachments (which have not been copied before) of the source task to the target task.
+// The attachments are loaded from the ClickUp API because they are not stored in the database.
+func (s *mirrorTaskSyncer) copyAttachments(ctx context.Context, spec *SyncRule_SpecOfAttachments, mirror *MirrorTask, direction string) {
+	sourceTaskID, targetTaskID := mirror.Orig.ID, mirror.Mirror.ID
+	if direction == SyncDirectionToOrig {
+		sourceTaskID, targetTaskID = targetTaskID, sourceTaskID
+	}
+	l := s.log.Named("copy_attachments").With(zap.String("model_id", mirror.ModelID()), zap.String("direction", direction))
+
+	attachments, ok := s.api.ListTaskAttachments(ctx, sourceTaskID)
+	if !ok {
+		l.Warn("failed to get the attachments of the task", zap.String("task_id", sourceTaskID))
+		return
+	}
+
+	if mirror.AttachmentIDs == nil {
+		mirror.AttachmentIDs = map[string]string{}
+	}
+	copied := map[string]bool{}
+	for origID, mirrorID := range mirror.AttachmentIDs {
+		copied[origID] = true
+		copied[mirrorID] = true
+	}
+
+	fields := []string{}
+	for _, attachment := range attachments {
+		if copied[attachment.ID] {
+			continue
+		}
+		if attachment.Size > spec.GetMaxSizeBytes() {
+			l.Debug("skipped too large attachment", zap.String("attachment_id", attachment.ID), zap.Int64("size", attachment.Size))
+			continue
+		}
+
+		content, err := s.api.DownloadAttachment(ctx, attachment.URL, spec.GetMaxSizeBytes())
+		if err != nil {
+			l.Warn("failed to download the attachment", zap.String("attachment_id", attachment.ID), zap.Error(err))
+			continue
+		}
+
+		res := s.api.CreateTaskAttachment(ctx, &api.CreateTaskAttachmentRequest{
+			TaskID:   targetTaskID,
+			FileName: attachmentFileName(attachment),
+			Content:  content,
+		})
+		warnIfFailedRequest(s.log, res)
+		if !res.StatusOK() || res.ID == "" {
+			continue
+		}
+
+		if direction == SyncDirectionToOrig {
+			mirror.AttachmentIDs[res.ID] = attachment.ID
+		} else {
+			mirror.AttachmentIDs[attachment.ID] = res.ID
+		}
+		fields = append(fields, "attachment:"+attachment.Title)
+	}
+
+	if len(fields) == 0 {
+		return
+	}
+	err := s.store.UpsertMirrorTask(ctx, mirror)
+	warnErrorIf(s.log, err, "failed to save the copied attachments of the mirror task", "model_id", mirror.ModelID())
+	s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+		Action:    MirrorTaskActionUpdated,
+		Direction: direction,
+		TaskID:    targetTaskID,
+		Fields:    fields,
+	})
+}
+
+func attachmentFileName(attachment api.Attachment) string {
+	if attachment.Title != "" {
+		return attachment.Title
+	}
+	if attachment.Extension != "" {
+		return attachment.ID + "." + attachment.Extension
+	}
+	return attachment.ID
+}
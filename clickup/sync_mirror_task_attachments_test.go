@@ -0,0 +1,51 @@
+package clickup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMirrorTaskSyncer_CopyAttachments(t *testing.T) {
+	env := newSyncEnv(t)
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+	mirrorTask := env.fake.addTask(&fakeTask{Name: "Task", ListID: "20"})
+	env.fake.addAttachment(orig.ID, "spec.txt", []byte("spec"))
+	env.fake.addAttachment(orig.ID, "video.mp4", []byte("too large video"))
+
+	spec := &SyncRule_SpecOfAttachments{Direction: SyncDirectionBoth, MaxSizeBytes: 10}
+	mirror := env.store.ModelMirrorTaskFor(orig.ID, mirrorTask.ID)
+	syncer := MirrorTaskSyncer(env.api, env.store, nil)
+	copyAttachments := func() {
+		syncer.copyAttachments(env.ctx, spec, mirror, SyncDirectionToMirror)
+		syncer.copyAttachments(env.ctx, spec, mirror, SyncDirectionToOrig)
+	}
+
+	copyAttachments()
+	if got, want := env.fake.attachments(mirrorTask.ID), []string{"spec.txt:spec"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("the attachments of the mirror task = %v, want %v", got, want)
+	}
+	// the copy is not copied back
+	if got, want := env.fake.attachments(orig.ID), []string{"spec.txt:spec", "video.mp4:too large video"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("the attachments of the original task = %v, want %v", got, want)
+	}
+
+	// the new attachment of the mirror task is copied to the original task once
+	env.fake.addAttachment(mirrorTask.ID, "logs.txt", []byte("logs"))
+	copyAttachments()
+	copyAttachments()
+	if got, want := env.fake.attachments(orig.ID), []string{"spec.txt:spec", "video.mp4:too large video", "logs.txt:logs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("the attachments of the original task = %v, want %v", got, want)
+	}
+	if got, want := env.fake.attachments(mirrorTask.ID), []string{"spec.txt:spec", "logs.txt:logs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("the attachments of the mirror task = %v, want %v", got, want)
+	}
+	if got := env.fake.countRequests("POST /task/"); got != 2 {
+		t.Errorf("uploaded %d attachments, want 2", got)
+	}
+
+	// the copied attachments are stored with the pair
+	stored := env.store.GetMirrorTask(env.ctx, mirror.ModelID())
+	if len(stored.AttachmentIDs) != 2 || !reflect.DeepEqual(stored.AttachmentIDs, mirror.AttachmentIDs) {
+		t.Errorf("the stored attachments of the pair = %v, want %v", stored.AttachmentIDs, mirror.AttachmentIDs)
+	}
+}
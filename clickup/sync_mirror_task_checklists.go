@@ -0,0 +1,197 @@
+package clickup
+
+import (
+	"context"
+
+	"github.com/gebv/asap-tools/clickup/api"
+	"go.uber.org/zap"
+)
+
+// syncChecklists replicates the checklists of the original task to the mirror task and
+// syncs the resolved state of the checklist items according to the direction in the rule.
+//
+// The resolved state is compared to the last synced state, so the side on which the item has been changed wins.
+func (s *mirrorTaskSyncer) syncChecklists(ctx context.Context, spec MirrorTaskSpecification, mirror *MirrorTask, origTask, mirrorTask *Task) {
+	l := s.log.Named("sync_checklists").With(zap.String("model_id", mirror.ModelID()))
+
+	if mirror.ChecklistIDs == nil {
+		mirror.ChecklistIDs = map[string]string{}
+	}
+	if mirror.ChecklistItemIDs == nil {
+		mirror.ChecklistItemIDs = map[string]string{}
+	}
+	if mirror.ChecklistItemsResolved == nil {
+		mirror.ChecklistItemsResolved = map[string]bool{}
+	}
+
+	changed := false
+	toMirror, toOrig := []string{}, []string{}
+
+	for _, origChecklist := range origTask.Checklists {
+		mirrorChecklist := mirrorTask.ChecklistByID(mirror.ChecklistIDs[origChecklist.ID])
+
+		if mirrorChecklist == nil {
+			res := s.api.CreateChecklist(ctx, mirror.Mirror.ID, origChecklist.Name)
+			warnIfFailedRequest(s.log, res)
+			if !res.StatusOK() {
+				l.Warn("failed to create checklist in the mirror task", zap.String("checklist_id", origChecklist.ID))
+				continue
+			}
+			created := ModelTaskChecklistFromAPI(&res.Checklist)
+			mirrorChecklist = &created
+			mirror.ChecklistIDs[origChecklist.ID] = created.ID
+			changed = true
+			toMirror = append(toMirror, "checklist")
+		} else if mirrorChecklist.Name != origChecklist.Name {
+			res := s.api.EditChecklist(ctx, mirrorChecklist.ID, origChecklist.Name)
+			warnIfFailedRequest(s.log, res)
+			if res.StatusOK() {
+				toMirror = append(toMirror, "checklist_name")
+			}
+		}
+
+		for _, origItem := range origChecklist.Items {
+			mirrorItem := mirrorChecklist.ItemByID(mirror.ChecklistItemIDs[origItem.ID])
+
+			if mirrorItem == nil {
+				res := s.api.CreateChecklistItem(ctx, mirrorChecklist.ID, origItem.Name)
+				warnIfFailedRequest(s.log, res)
+				if !res.StatusOK() {
+					l.Warn("failed to create checklist item in the mirror task", zap.String("checklist_item_id", origItem.ID))
+					continue
+				}
+				created := newChecklistItem(mirror.ChecklistItemIDs, ModelTaskChecklistFromAPI(&res.Checklist), origItem.Name)
+				if created == nil {
+					l.Warn("not found the created checklist item in the response", zap.String("checklist_item_id", origItem.ID))
+					continue
+				}
+				mirrorItem = created
+				mirror.ChecklistItemIDs[origItem.ID] = created.ID
+				changed = true
+				toMirror = append(toMirror, "checklist_item")
+			} else if mirrorItem.Name != origItem.Name {
+				res := s.api.EditChecklistItem(ctx, &api.EditChecklistItemRequest{
+					ChecklistID: mirrorChecklist.ID,
+					ItemID:      mirrorItem.ID,
+					Name:        origItem.Name,
+				})
+				warnIfFailedRequest(s.log, res)
+				if res.StatusOK() {
+					toMirror = append(toMirror, "checklist_item_name")
+				}
+			}
+
+			// resolved state
+			lastResolved, synced := mirror.ChecklistItemsResolved[origItem.ID]
+			resolved := origItem.Resolved
+			switch checklistItemResolvedDirection(spec.SpecSync, origItem.Resolved, mirrorItem.Resolved, lastResolved, synced) {
+			case SyncDirectionToMirror:
+				if s.setChecklistItemResolved(ctx, mirrorChecklist.ID, mirrorItem.ID, origItem.Resolved) {
+					toMirror = append(toMirror, "checklist_item_resolved")
+				}
+			case SyncDirectionToOrig:
+				if s.setChecklistItemResolved(ctx, origChecklist.ID, origItem.ID, mirrorItem.Resolved) {
+					resolved = mirrorItem.Resolved
+					toOrig = append(toOrig, "checklist_item_resolved")
+				}
+			}
+			if !synced || lastResolved != resolved {
+				mirror.ChecklistItemsResolved[origItem.ID] = resolved
+				changed = true
+			}
+		}
+	}
+
+	// removed from the original task
+	for origChecklistID, mirrorChecklistID := range mirror.ChecklistIDs {
+		if origTask.ChecklistByID(origChecklistID) != nil {
+			continue
+		}
+		res := s.api.DeleteChecklist(ctx, mirrorChecklistID)
+		warnIfFailedRequest(s.log, res)
+		if res.StatusOK() {
+			delete(mirror.ChecklistIDs, origChecklistID)
+			changed = true
+			toMirror = append(toMirror, "checklist_removed")
+		}
+	}
+	for origItemID, mirrorItemID := range mirror.ChecklistItemIDs {
+		if origTask.checklistOfItem(origItemID) != nil {
+			continue
+		}
+		if mirrorChecklist := mirrorTask.checklistOfItem(mirrorItemID); mirrorChecklist != nil {
+			res := s.api.DeleteChecklistItem(ctx, mirrorChecklist.ID, mirrorItemID)
+			warnIfFailedRequest(s.log, res)
+			if !res.StatusOK() {
+				continue
+			}
+			toMirror = append(toMirror, "checklist_item_removed")
+		}
+		delete(mirror.ChecklistItemIDs, origItemID)
+		delete(mirror.ChecklistItemsResolved, origItemID)
+		changed = true
+	}
+
+	if changed {
+		err := s.store.UpsertMirrorTask(ctx, mirror)
+		warnErrorIf(s.log, err, "failed to save the checklists of the mirror task", "model_id", mirror.ModelID())
+	}
+	if len(toMirror) > 0 {
+		s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+			Action:    MirrorTaskActionUpdated,
+			Direction: SyncDirectionToMirror,
+			TaskID:    mirror.Mirror.ID,
+			Fields:    toMirror,
+		})
+	}
+	if len(toOrig) > 0 {
+		s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+			Action:    MirrorTaskActionUpdated,
+			Direction: SyncDirectionToOrig,
+			TaskID:    mirror.Orig.ID,
+			Fields:    toOrig,
+		})
+	}
+}
+
+// checklistItemResolvedDirection returns the direction in which the resolved state of the checklist item is synced ("" if nothing).
+// The side on which the item has been changed since the last sync wins (the original task if both sides have been changed).
+// The not synced item takes the state of the original task (the items are created from the original task) whatever the direction.
+// lastResolved - the last synced state, synced - false if the item has not been synced yet.
+func checklistItemResolvedDirection(spec *SyncRule_SpecOfSync, origResolved, mirrorResolved, lastResolved, synced bool) string {
+	switch {
+	case origResolved == mirrorResolved:
+	case !synced && spec.MirrorChecklists():
+		return SyncDirectionToMirror
+	case origResolved != lastResolved && spec.AllowedSyncChecklists(SyncDirectionToMirror):
+		return SyncDirectionToMirror
+	case synced && mirrorResolved != lastResolved && spec.AllowedSyncChecklists(SyncDirectionToOrig):
+		return SyncDirectionToOrig
+	}
+	return ""
+}
+
+func (s *mirrorTaskSyncer) setChecklistItemResolved(ctx context.Context, checklistID, itemID string, resolved bool) bool {
+	res := s.api.EditChecklistItem(ctx, &api.EditChecklistItemRequest{
+		ChecklistID: checklistID,
+		ItemID:      itemID,
+		Resolved:    &resolved,
+	})
+	warnIfFailedRequest(s.log, res)
+	return res.StatusOK()
+}
+
+// returns the created item from the checklist (by name and not already mapped)
+func newChecklistItem(mapped map[string]string, checklist TaskChecklist, name string) *TaskChecklistItem {
+	known := map[string]bool{}
+	for _, itemID := range mapped {
+		known[itemID] = true
+	}
+	for idx := range checklist.Items {
+		item := &checklist.Items[idx]
+		if item.Name == name && !known[item.ID] {
+			return item
+		}
+	}
+	return nil
+}
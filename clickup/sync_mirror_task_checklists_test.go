@@ -0,0 +1,221 @@
+package clickup
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gebv/asap-tools/clickup/api"
+)
+
+func TestChecklistItemResolvedDirection(t *testing.T) {
+	both := &SyncRule_SpecOfSync{Checklists: SyncDirectionBoth}
+	toMirror := &SyncRule_SpecOfSync{Checklists: SyncDirectionToMirror}
+	toOrig := &SyncRule_SpecOfSync{Checklists: SyncDirectionToOrig}
+
+	tests := []struct {
+		name               string
+		spec               *SyncRule_SpecOfSync
+		orig, mirror, last bool
+		synced             bool
+		want               string
+	}{
+		{"not synced - the original task wins", both, true, false, false, false, SyncDirectionToMirror},
+		{"not synced - the same state", both, true, true, false, false, ""},
+		{"resolved in the original task", both, true, false, false, true, SyncDirectionToMirror},
+		{"unresolved in the original task", both, false, true, true, true, SyncDirectionToMirror},
+		{"resolved in the mirror task", both, false, true, false, true, SyncDirectionToOrig},
+		{"unresolved in the mirror task", both, true, false, true, true, SyncDirectionToOrig},
+		{"resolved in the mirror task - only to the mirror task", toMirror, false, true, false, true, ""},
+		{"resolved in the original task - only to the mirror task", toMirror, true, false, false, true, SyncDirectionToMirror},
+		{"resolved in the original task - only to the original task", toOrig, true, false, false, true, ""},
+		{"not synced - only to the original task", toOrig, true, false, false, false, SyncDirectionToMirror},
+		{"resolved in the mirror task - only to the original task", toOrig, false, true, false, true, SyncDirectionToOrig},
+		{"disabled", nil, true, false, false, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checklistItemResolvedDirection(tt.spec, tt.orig, tt.mirror, tt.last, tt.synced); got != tt.want {
+				t.Errorf("checklistItemResolvedDirection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// returns the checklists of the task in the format "Name[item:+ item:-]" (+ resolved item)
+func checklistsOf(task *fakeTask) []string {
+	res := []string{}
+	for _, checklist := range task.Checklists {
+		items := []string{}
+		for _, item := range checklist.Items {
+			state := "-"
+			if item.Resolved {
+				state = "+"
+			}
+			items = append(items, item.Name+":"+state)
+		}
+		res = append(res, fmt.Sprintf("%s[%s]", checklist.Name, strings.Join(items, " ")))
+	}
+	return res
+}
+
+func TestMirrorTaskSyncer_SyncChecklists(t *testing.T) {
+	tests := []struct {
+		name       string
+		direction  string
+		change     func(orig, mirror *fakeTask)
+		wantOrig   []string
+		wantMirror []string
+	}{
+		{
+			name:       "copied to the mirror task",
+			direction:  SyncDirectionBoth,
+			change:     func(orig, mirror *fakeTask) {},
+			wantOrig:   []string{"Todo[A:- B:+]"},
+			wantMirror: []string{"Todo[A:- B:+]"},
+		},
+		{
+			name:      "renamed in the original task",
+			direction: SyncDirectionBoth,
+			change: func(orig, mirror *fakeTask) {
+				orig.Checklists[0].Name = "Plan"
+				orig.Checklists[0].Items[0].Name = "A1"
+			},
+			wantOrig:   []string{"Plan[A1:- B:+]"},
+			wantMirror: []string{"Plan[A1:- B:+]"},
+		},
+		{
+			name:      "renamed in the mirror task - the name of the original task wins",
+			direction: SyncDirectionBoth,
+			change: func(orig, mirror *fakeTask) {
+				mirror.Checklists[0].Name = "Plan"
+				mirror.Checklists[0].Items[0].Name = "A1"
+			},
+			wantOrig:   []string{"Todo[A:- B:+]"},
+			wantMirror: []string{"Todo[A:- B:+]"},
+		},
+		{
+			name:      "added item in the original task",
+			direction: SyncDirectionBoth,
+			change: func(orig, mirror *fakeTask) {
+				orig.Checklists[0].Items = append(orig.Checklists[0].Items, api.ChecklistItem{ID: "orig-c", Name: "C", Resolved: true})
+			},
+			wantOrig:   []string{"Todo[A:- B:+ C:+]"},
+			wantMirror: []string{"Todo[A:- B:+ C:+]"},
+		},
+		{
+			name:      "added item in the mirror task is kept",
+			direction: SyncDirectionBoth,
+			change: func(orig, mirror *fakeTask) {
+				mirror.Checklists[0].Items = append(mirror.Checklists[0].Items, api.ChecklistItem{ID: "mirror-c", Name: "C"})
+			},
+			wantOrig:   []string{"Todo[A:- B:+]"},
+			wantMirror: []string{"Todo[A:- B:+ C:-]"},
+		},
+		{
+			name:      "deleted item in the original task",
+			direction: SyncDirectionBoth,
+			change: func(orig, mirror *fakeTask) {
+				orig.Checklists[0].Items = orig.Checklists[0].Items[1:]
+			},
+			wantOrig:   []string{"Todo[B:+]"},
+			wantMirror: []string{"Todo[B:+]"},
+		},
+		{
+			name:      "deleted checklist in the original task",
+			direction: SyncDirectionBoth,
+			change: func(orig, mirror *fakeTask) {
+				orig.Checklists = nil
+			},
+			wantOrig:   []string{},
+			wantMirror: []string{},
+		},
+		{
+			name:      "resolved in the original task",
+			direction: SyncDirectionBoth,
+			change: func(orig, mirror *fakeTask) {
+				orig.Checklists[0].Items[0].Resolved = true
+				orig.Checklists[0].Items[1].Resolved = false
+			},
+			wantOrig:   []string{"Todo[A:+ B:-]"},
+			wantMirror: []string{"Todo[A:+ B:-]"},
+		},
+		{
+			name:      "resolved in the mirror task",
+			direction: SyncDirectionBoth,
+			change: func(orig, mirror *fakeTask) {
+				mirror.Checklists[0].Items[0].Resolved = true
+				mirror.Checklists[0].Items[1].Resolved = false
+			},
+			wantOrig:   []string{"Todo[A:+ B:-]"},
+			wantMirror: []string{"Todo[A:+ B:-]"},
+		},
+		{
+			name:      "resolved on both sides - each side wins for own changes",
+			direction: SyncDirectionBoth,
+			change: func(orig, mirror *fakeTask) {
+				orig.Checklists[0].Items[0].Resolved = true
+				mirror.Checklists[0].Items[1].Resolved = false
+			},
+			wantOrig:   []string{"Todo[A:+ B:-]"},
+			wantMirror: []string{"Todo[A:+ B:-]"},
+		},
+		{
+			name:      "resolved in the mirror task - only to the mirror task",
+			direction: SyncDirectionToMirror,
+			change: func(orig, mirror *fakeTask) {
+				mirror.Checklists[0].Items[0].Resolved = true
+			},
+			wantOrig:   []string{"Todo[A:- B:+]"},
+			wantMirror: []string{"Todo[A:+ B:+]"},
+		},
+		{
+			name:      "resolved in the original task - only to the original task",
+			direction: SyncDirectionToOrig,
+			change: func(orig, mirror *fakeTask) {
+				orig.Checklists[0].Items[0].Resolved = true
+			},
+			wantOrig:   []string{"Todo[A:+ B:+]"},
+			wantMirror: []string{"Todo[A:- B:+]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newSyncEnv(t)
+			orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10", Checklists: []api.Checklist{{
+				ID:   "orig-todo",
+				Name: "Todo",
+				Items: []api.ChecklistItem{
+					{ID: "orig-a", Name: "A"},
+					{ID: "orig-b", Name: "B", Resolved: true},
+				},
+			}}})
+			mirrorTask := env.fake.addTask(&fakeTask{Name: "Task", ListID: "20"})
+
+			rule := MirrorTaskSpecification{Name: "client", SpecSync: &SyncRule_SpecOfSync{Checklists: tt.direction}}
+			mirror := env.store.ModelMirrorTaskFor(orig.ID, mirrorTask.ID)
+			syncer := MirrorTaskSyncer(env.api, env.store, nil)
+			sync := func() {
+				syncer.syncChecklists(env.ctx, rule, mirror, env.load(orig.ID), env.load(mirrorTask.ID))
+			}
+
+			sync()
+			tt.change(env.fake.task(orig.ID), env.fake.task(mirrorTask.ID))
+			sync()
+
+			if got := checklistsOf(env.fake.task(orig.ID)); !reflect.DeepEqual(got, tt.wantOrig) {
+				t.Errorf("the checklists of the original task = %v, want %v", got, tt.wantOrig)
+			}
+			if got := checklistsOf(env.fake.task(mirrorTask.ID)); !reflect.DeepEqual(got, tt.wantMirror) {
+				t.Errorf("the checklists of the mirror task = %v, want %v", got, tt.wantMirror)
+			}
+
+			// the state of the pair is stored
+			stored := env.store.GetMirrorTask(env.ctx, mirror.ModelID())
+			if !reflect.DeepEqual(stored.ChecklistIDs, mirror.ChecklistIDs) || !reflect.DeepEqual(stored.ChecklistItemsResolved, mirror.ChecklistItemsResolved) {
+				t.Errorf("the stored pair = %v %v, want %v %v", stored.ChecklistIDs, stored.ChecklistItemsResolved, mirror.ChecklistIDs, mirror.ChecklistItemsResolved)
+			}
+		})
+	}
+}
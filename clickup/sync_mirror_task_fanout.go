@@ -0,0 +1,137 @@
+package clickup
+
+import (
+	"context"
+	"strings"
+)
+
+// the modes of the aggregation of the statuses of the mirror tasks into the status of the original task
+const (
+	// the status of the original task is set by the changed mirror task
+	StatusAggregationAny = "any"
+	// the status of the original task is set by the least advanced mirror task (see the rank of the mirror task statuses)
+	StatusAggregationAll = "all"
+)
+
+// the target list of the mirror tasks of the rule
+type SyncRule_SpecOfAddTarget struct {
+	// name of the target (eg dev, qa, ops) (available in the templates as .Target.Name)
+	Name                string `yaml:"name,omitempty"`
+	AddToList           string `yaml:"add_to_list"`
+	SetStatusName       string `yaml:"set_status_name,omitempty"`
+	AssignToMemberEmail string `yaml:"assign_to_member_email,omitempty"`
+	// overrides the templates of the rule for the mirror tasks of the target
+	Templates *SyncRule_SpecOfTemplates `yaml:"templates,omitempty"`
+}
+
+func (t *SyncRule_SpecOfAddTarget) GetAddToListID() string {
+	return listIDFromURL(t.AddToList)
+}
+
+func (t *SyncRule_SpecOfAddTarget) GetAssignToMemberEmail() string {
+	if t == nil {
+		return ""
+	}
+	return t.AssignToMemberEmail
+}
+
+func (t *SyncRule_SpecOfAddTarget) GetTemplates() *SyncRule_SpecOfTemplates {
+	if t == nil {
+		return nil
+	}
+	return t.Templates
+}
+
+// AllTargets returns the target lists of the rule - the list from add_to_list (if specified) and the lists from targets.
+// The status and the assignee of the targets are inherited from the rule if not specified.
+func (s *SyncRule_SpecOfAdd) AllTargets() []*SyncRule_SpecOfAddTarget {
+	if s == nil {
+		return nil
+	}
+	res := []*SyncRule_SpecOfAddTarget{}
+	if s.AddToList != "" {
+		res = append(res, &SyncRule_SpecOfAddTarget{
+			AddToList:           s.AddToList,
+			SetStatusName:       s.SetStatusName,
+			AssignToMemberEmail: s.AssignToMemberEmail,
+		})
+	}
+	for idx := range s.Targets {
+		target := s.Targets[idx]
+		if target.SetStatusName == "" {
+			target.SetStatusName = s.SetStatusName
+		}
+		if target.AssignToMemberEmail == "" {
+			target.AssignToMemberEmail = s.AssignToMemberEmail
+		}
+		res = append(res, &target)
+	}
+	return res
+}
+
+// TargetByListID returns the target by the list of the mirror task or nil.
+func (s *SyncRule_SpecOfAdd) TargetByListID(listID string) *SyncRule_SpecOfAddTarget {
+	for _, target := range s.AllTargets() {
+		if target.GetAddToListID() == listID {
+			return target
+		}
+	}
+	return nil
+}
+
+func (s *SyncRule_SpecOfAdd) GetStatusAggregation() string {
+	if s == nil || s.StatusAggregation == "" {
+		return StatusAggregationAny
+	}
+	return s.StatusAggregation
+}
+
+// returns the target of the mirror task or nil
+func (s *mirrorTaskSyncer) targetOf(ctx context.Context, rule *MirrorTaskSpecification, mirror *MirrorTask) *SyncRule_SpecOfAddTarget {
+	if rule == nil {
+		return nil
+	}
+	return rule.SpecAdd.TargetByListID(mirror.GetMirrorTask(ctx).ListRef.ID)
+}
+
+// returns the status for the original task by the statuses of the mirror tasks (or "" if nothing needs to be done)
+func (s *mirrorTaskSyncer) origTaskStatusFor(ctx context.Context, statuses MirrorTaskStatuses, rule *MirrorTaskSpecification,
+	mirror *MirrorTask, mirrorTask *Task) string {
+
+	if rule.SpecAdd.GetStatusAggregation() != StatusAggregationAll {
+		return statuses.SetStatusToOrigTaskIfExists(mirrorTask.StatusName)
+	}
+
+	mirrorList, _ := s.store.AllMatchesForMirrorTasks(ctx, mirror.Orig.ID)
+	statusNames := []string{mirrorTask.StatusName}
+	for _, sibling := range mirrorList {
+		// the statuses of the tasks of other providers are not aggregated (see mirror_task_statuses of the external target)
+		if sibling.Destroyed || sibling.Subtask || sibling.external() || sibling.Orig.ID != mirror.Orig.ID ||
+			sibling.Mirror.ID == mirrorTask.ID || sibling.RuleName != mirror.RuleName {
+			continue
+		}
+		statusNames = append(statusNames, sibling.GetMirrorTask(ctx).StatusName)
+	}
+	return statuses.SetStatusToOrigTaskIfExists(statuses.LeastAdvanced(statusNames))
+}
+
+// LeastAdvanced returns the status with the lowest rank (or "" if any status has no rank).
+// The status without the rank (unknown or with the zero rank) blocks the aggregation, so nothing is propagated.
+func (s MirrorTaskStatuses) LeastAdvanced(statusNames []string) string {
+	res := ""
+	for _, statusName := range statusNames {
+		rank := s.Rank(statusName)
+		if rank <= 0 {
+			return ""
+		}
+		if res == "" || rank < s.Rank(res) {
+			res = statusName
+		}
+	}
+	return res
+}
+
+// Rank returns the rank of the status (0 for the unknown status).
+func (s MirrorTaskStatuses) Rank(statusName string) int {
+	return s[strings.ToLower(statusName)].Rank
+}
@@ -0,0 +1,191 @@
+package clickup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSyncRule_SpecOfAdd_AllTargets(t *testing.T) {
+	spec := &SyncRule_SpecOfAdd{
+		AddToList:           "https://app.clickup.com/1/v/li/10",
+		SetStatusName:       "open",
+		AssignToMemberEmail: "lead@example.com",
+		Targets: []SyncRule_SpecOfAddTarget{
+			{Name: "qa", AddToList: "https://app.clickup.com/1/v/li/20", SetStatusName: "todo"},
+			{Name: "ops", AddToList: "https://app.clickup.com/2/v/li/30", AssignToMemberEmail: "ops@example.com"},
+		},
+	}
+
+	targets := spec.AllTargets()
+	if len(targets) != 3 {
+		t.Fatalf("AllTargets() returns %d targets, want 3", len(targets))
+	}
+	tests := []struct {
+		listID, name, status, email string
+	}{
+		{"10", "", "open", "lead@example.com"},
+		{"20", "qa", "todo", "lead@example.com"},
+		{"30", "ops", "open", "ops@example.com"},
+	}
+	for idx, tt := range tests {
+		got := targets[idx]
+		if got.GetAddToListID() != tt.listID || got.Name != tt.name || got.SetStatusName != tt.status || got.AssignToMemberEmail != tt.email {
+			t.Errorf("target #%d = %+v, want %+v", idx, got, tt)
+		}
+	}
+
+	if got := spec.TargetByListID("20"); got == nil || got.Name != "qa" {
+		t.Errorf("TargetByListID(20) = %+v, want qa", got)
+	}
+	if got := spec.TargetByListID("40"); got != nil {
+		t.Errorf("TargetByListID(40) = %+v, want nil", got)
+	}
+	if got := (*SyncRule_SpecOfAdd)(nil).AllTargets(); len(got) != 0 {
+		t.Errorf("AllTargets() for nil spec = %v, want empty", got)
+	}
+	if spec.Targets[0].AssignToMemberEmail != "" {
+		t.Error("AllTargets() must not modify the spec")
+	}
+}
+
+func TestMirrorTaskStatuses_LeastAdvanced(t *testing.T) {
+	statuses := MirrorTaskStatuses{
+		"open": MirrorTaskStatus{Rank: 1, SetStatusToOriginalTask: "in progress"},
+		"wip":  MirrorTaskStatus{Rank: 2, SetStatusToOriginalTask: "in progress"},
+		"done": MirrorTaskStatus{Rank: 3, SetStatusToOriginalTask: "ready"},
+		// without the rank
+		"review": MirrorTaskStatus{SetStatusToOriginalTask: "in review"},
+	}
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{"all done", []string{"done", "Done"}, "done"},
+		{"one in progress", []string{"done", "wip", "done"}, "wip"},
+		{"unknown status", []string{"done", "blocked"}, ""},
+		{"unknown status first", []string{"blocked", "wip", "done"}, ""},
+		{"zero rank", []string{"done", "review"}, ""},
+		{"only unknown statuses", []string{"blocked", "review"}, ""},
+		{"single", []string{"open"}, "open"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statuses.LeastAdvanced(tt.statuses); got != tt.want {
+				t.Errorf("LeastAdvanced() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirrorTaskSyncer_origTaskStatusFor_all(t *testing.T) {
+	env := newSyncEnv(t)
+	opts := mustParsePreferences(t, `
+mirror_task_rules:
+  - name: rule
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    spec_add:
+      status_aggregation: all
+      targets:
+        - add_to_list: https://app.clickup.com/100/v/li/20
+        - add_to_list: https://app.clickup.com/100/v/li/30
+global_mirror_task_statuses:
+  open:
+    orig_task_status: in progress
+    rank: 1
+  done:
+    orig_task_status: ready
+    rank: 2
+`)
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+	env.change(opts, orig.ID)
+	loadList(env, "20")
+	loadList(env, "30")
+	dev, qa := env.fake.tasksInList("20")[0].ID, env.fake.tasksInList("30")[0].ID
+
+	env.fake.task(dev).Status = "open"
+	env.change(opts, dev)
+	if got := env.fake.task(orig.ID).Status; got != "in progress" {
+		t.Errorf("the status of the original task = %q, want in progress (by the open mirror task)", got)
+	}
+
+	// the status without the rank blocks the aggregation
+	env.fake.task(dev).Status = "blocked"
+	env.change(opts, dev)
+	env.fake.task(qa).Status = "done"
+	env.change(opts, qa)
+	if got := env.fake.task(orig.ID).Status; got != "in progress" {
+		t.Errorf("the status of the original task = %q, want in progress (the blocked mirror task is not done)", got)
+	}
+
+	env.fake.task(dev).Status = "done"
+	env.change(opts, dev)
+	if got := env.fake.task(orig.ID).Status; got != "ready" {
+		t.Errorf("the status of the original task = %q, want ready (all mirror tasks are done)", got)
+	}
+}
+
+func TestMirrorTaskSyncer_origTaskStatusFor_otherRule(t *testing.T) {
+	env := newSyncEnv(t)
+	opts := mustParsePreferences(t, `
+mirror_task_rules:
+  - name: teams
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    spec_add:
+      status_aggregation: all
+      targets:
+        - add_to_list: https://app.clickup.com/100/v/li/20
+        - add_to_list: https://app.clickup.com/100/v/li/30
+  - name: archive
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    spec_add:
+      add_to_list: https://app.clickup.com/100/v/li/40
+global_mirror_task_statuses:
+  done:
+    orig_task_status: ready
+    rank: 1
+`)
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+	env.change(opts, orig.ID)
+	loadList(env, "20")
+	loadList(env, "30")
+	loadList(env, "40")
+	dev, qa := env.fake.tasksInList("20")[0].ID, env.fake.tasksInList("30")[0].ID
+	if len(env.fake.tasksInList("40")) != 1 {
+		t.Fatal("the mirror task of the other rule is not created")
+	}
+
+	// the mirror task of the other rule (without the ranked status) is not aggregated
+	env.fake.task(dev).Status = "done"
+	env.change(opts, dev)
+	env.fake.task(qa).Status = "done"
+	env.change(opts, qa)
+	if got := env.fake.task(orig.ID).Status; got != "ready" {
+		t.Errorf("the status of the original task = %q, want ready (the mirror task of the other rule is skipped)", got)
+	}
+}
+
+func TestSyncPreferences_Validate_targetTemplates(t *testing.T) {
+	_, err := ParseSyncPreferences(strings.NewReader(`
+mirror_task_rules:
+  - name: rule
+    spec_add:
+      targets:
+        - add_to_list: https://app.clickup.com/100/v/li/20
+          templates:
+            messages:
+              name: "{{.Task"
+`))
+	if err == nil || !strings.Contains(err.Error(), "spec_add.targets") {
+		t.Errorf("ParseSyncPreferences() for the invalid templates of the target = %v, want error", err)
+	}
+}
@@ -0,0 +1,310 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+	"go.uber.org/zap"
+)
+
+// the policies if the task of the pair is closed or archived
+const (
+	// unlinks the pair (after the grace period)
+	LifecyclePolicyUnlink = "unlink"
+	// keeps the pair linked while the task is closed
+	LifecyclePolicyKeep = "keep"
+	// closes (archives) the other task of the pair and reopens it if the task is reopened, the pair remains linked
+	LifecyclePolicyPropagate = "propagate"
+)
+
+// spec of the lifecycle of the pair if the original or the mirror task is closed, archived or deleted.
+// NOTE: the pair with the deleted task is always unlinked immediately.
+type SyncRule_SpecOfLifecycle struct {
+	// the policy if the original task is closed or archived (unlink, keep, propagate) (unlink by default)
+	OnOrigHidden string `yaml:"on_orig_hidden,omitempty"`
+	// the policy if the mirror task is closed or archived (unlink, keep, propagate) (unlink by default)
+	OnMirrorHidden string `yaml:"on_mirror_hidden,omitempty"`
+
+	// the statuses for the propagated closing and reopening (closed and open by default)
+	OrigClosedStatusName   string `yaml:"orig_closed_status_name,omitempty"`
+	OrigReopenStatusName   string `yaml:"orig_reopen_status_name,omitempty"`
+	MirrorClosedStatusName string `yaml:"mirror_closed_status_name,omitempty"`
+	MirrorReopenStatusName string `yaml:"mirror_reopen_status_name,omitempty"`
+
+	// the pair is unlinked only if the task is still closed after the grace period (e.g. 72h) (unlinked immediately by default)
+	GracePeriod time.Duration `yaml:"grace_period,omitempty"`
+}
+
+func (r *MirrorTaskSpecification) GetSpecLifecycle() *SyncRule_SpecOfLifecycle {
+	if r == nil {
+		return nil
+	}
+	return r.SpecLifecycle
+}
+
+func (s *SyncRule_SpecOfLifecycle) GetOnOrigHidden() string {
+	if s == nil || s.OnOrigHidden == "" {
+		return LifecyclePolicyUnlink
+	}
+	return s.OnOrigHidden
+}
+
+func (s *SyncRule_SpecOfLifecycle) GetOnMirrorHidden() string {
+	if s == nil || s.OnMirrorHidden == "" {
+		return LifecyclePolicyUnlink
+	}
+	return s.OnMirrorHidden
+}
+
+func (s *SyncRule_SpecOfLifecycle) GetGracePeriod() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return s.GracePeriod
+}
+
+// returns the policy for the original (or the mirror) task
+func (s *SyncRule_SpecOfLifecycle) policyFor(orig bool) string {
+	if orig {
+		return s.GetOnOrigHidden()
+	}
+	return s.GetOnMirrorHidden()
+}
+
+// returns the status to close (or to reopen) the original (or the mirror) task
+func (s *SyncRule_SpecOfLifecycle) statusNameFor(orig, closed bool) string {
+	res := ""
+	if s != nil {
+		switch {
+		case orig && closed:
+			res = s.OrigClosedStatusName
+		case orig && !closed:
+			res = s.OrigReopenStatusName
+		case !orig && closed:
+			res = s.MirrorClosedStatusName
+		default:
+			res = s.MirrorReopenStatusName
+		}
+	}
+	if res != "" {
+		return res
+	}
+	if closed {
+		return "closed"
+	}
+	return "open"
+}
+
+// Validate returns error if the policy is unknown.
+func (s *SyncRule_SpecOfLifecycle) Validate() error {
+	for _, policy := range []string{s.GetOnOrigHidden(), s.GetOnMirrorHidden()} {
+		switch policy {
+		case LifecyclePolicyUnlink, LifecyclePolicyKeep, LifecyclePolicyPropagate:
+		default:
+			return fmt.Errorf("unknown lifecycle policy %q", policy)
+		}
+	}
+	if s.GetGracePeriod() < 0 {
+		return fmt.Errorf("negative grace period %s", s.GetGracePeriod())
+	}
+	return nil
+}
+
+// syncLifecycle applies the lifecycle policies of the rule if the task of the pair is closed, archived or deleted.
+// changedIsOrig - the changed task (task) is the original task of the pair.
+// Returns true if the pair is not synced further (unlinked or waiting to be unlinked).
+func (s *mirrorTaskSyncer) syncLifecycle(ctx context.Context, rule *MirrorTaskSpecification, mirror *MirrorTask,
+	oldTask, task *Task, changedIsOrig bool) bool {
+
+	if mirror.Destroyed {
+		return true
+	}
+
+	spec := rule.GetSpecLifecycle()
+	pairTasks := func() (origTask, mirrorTask *Task) {
+		if changedIsOrig {
+			return task, mirror.GetMirrorTask(ctx)
+		}
+		return mirror.GetOrigTask(ctx), task
+	}
+
+	// the changed task has been closed (archived) or reopened
+	if oldTask != nil && (oldTask.Exists() || oldTask.detached) && !task.Deleted &&
+		oldTask.IsDeletedOrHidden() != task.IsDeletedOrHidden() &&
+		spec.policyFor(changedIsOrig) == LifecyclePolicyPropagate {
+		origTask, mirrorTask := pairTasks()
+		otherTask := mirrorTask
+		if !changedIsOrig {
+			otherTask = origTask
+		}
+		s.propagateVisibility(ctx, spec, mirror, task, otherTask, changedIsOrig)
+	}
+
+	origTask, mirrorTask := pairTasks()
+	msgData := newMirrorTaskTemplateData(ctx, rule, origTask, mirrorTask)
+	assignTo := ""
+	if rule != nil && rule.CondAdd != nil {
+		assignTo = rule.CondAdd.IfAssignedToMemberEmail
+	}
+	mirrorHiddenMsg := MsgUnlinkMirrorHidden
+	if !changedIsOrig {
+		mirrorHiddenMsg = MsgUnlinkChangedHiddenMirror
+	}
+
+	if origTask.Deleted {
+		s.sendMirrorComment(ctx, mirror, SyncDirectionToMirror, mirror.Mirror, s.message(rule, MsgUnlinkOrigHidden, msgData), assignTo)
+		s.destroyMirrorTask(ctx, mirror, "original task has been DELETED")
+		return true
+	}
+	if mirrorTask.Deleted {
+		s.destroyMirrorTask(ctx, mirror, "mirror task has been DELETED")
+		return true
+	}
+
+	origHidden, mirrorHidden := origTask.IsDeletedOrHidden(), mirrorTask.IsDeletedOrHidden()
+	if origHidden && spec.GetOnOrigHidden() == LifecyclePolicyUnlink &&
+		!(mirrorHidden && spec.GetOnMirrorHidden() == LifecyclePolicyPropagate) {
+		return s.unlinkHiddenPair(ctx, spec, mirror, s.message(rule, MsgUnlinkOrigHidden, msgData), assignTo,
+			"original task has been CLOSED or ARCHIVED")
+	}
+	if mirrorHidden && spec.GetOnMirrorHidden() == LifecyclePolicyUnlink &&
+		!(origHidden && spec.GetOnOrigHidden() == LifecyclePolicyPropagate) {
+		return s.unlinkHiddenPair(ctx, spec, mirror, s.message(rule, mirrorHiddenMsg, msgData), assignTo,
+			"mirror task has been CLOSED or ARCHIVED")
+	}
+
+	if mirror.PendingDestroyAt != nil {
+		// reopened within the grace period
+		mirror.PendingDestroyAt = nil
+		mirror.PendingDestroyReason = ""
+		err := s.store.UpsertMirrorTask(ctx, mirror)
+		warnErrorIf(s.log, err, "failed to cancel the pending unlink of the mirror task", "model_id", mirror.ModelID())
+	}
+	return false
+}
+
+// unlinks the pair with the hidden task after the grace period (the pair waits for the unlink while the grace period)
+func (s *mirrorTaskSyncer) unlinkHiddenPair(ctx context.Context, spec *SyncRule_SpecOfLifecycle, mirror *MirrorTask,
+	commentText, assignTo, reason string) bool {
+
+	gracePeriod := spec.GetGracePeriod()
+	if gracePeriod > 0 && mirror.PendingDestroyAt == nil {
+		mirror.PendingDestroyAt = TimestampFromTime(time.Now().Add(gracePeriod))
+		mirror.PendingDestroyReason = reason
+		err := s.store.UpsertMirrorTask(ctx, mirror)
+		warnErrorIf(s.log, err, "failed to save the pending unlink of the mirror task", "model_id", mirror.ModelID())
+		return true
+	}
+	if gracePeriod > 0 && time.Now().Before(mirror.PendingDestroyAt.AsTime()) {
+		return true
+	}
+
+	s.sendMirrorComment(ctx, mirror, SyncDirectionToMirror, mirror.Mirror, commentText, assignTo)
+	s.destroyMirrorTask(ctx, mirror, reason)
+	return true
+}
+
+// ListExpiredPendingMirrorTasks returns the pairs waiting for the unlink whose grace period has expired by the time.
+func (s *Storage) ListExpiredPendingMirrorTasks(ctx context.Context, now time.Time) []*MirrorTask {
+	iter := s.FirestoreClient().Collection(MirrorTaskModel.CollectionName()).
+		Where("PendingDestroyAt", "<=", now).Documents(ctx)
+	res := s.Iterate(iter, MirrorTaskModel)
+
+	list := []*MirrorTask{}
+	for idx := range res {
+		mirror := res[idx].(*MirrorTask)
+		if mirror.Destroyed {
+			continue
+		}
+		mirror.storage = s
+		list = append(list, mirror)
+	}
+	return list
+}
+
+// DestroyExpiredMirrorTasks unlinks the pairs whose task is still closed (archived) after the grace period.
+// The pairs whose task has been reopened are kept (the pending unlink is canceled).
+func (s *ChangeManager) DestroyExpiredMirrorTasks(ctx context.Context, opts *SyncPreferences) {
+	mirrorSyncer := MirrorTaskSyncer(s.api, s.store, s.providers)
+	mirrorSyncer.notifier = newTaskNotifier(opts, s.store, s.notifiers, s.webhooks)
+	mirrorSyncer.destroyExpiredMirrorTasks(ctx, opts)
+}
+
+// applies the lifecycle policies to the pairs waiting for the unlink after the grace period
+// NOTE: the tasks of the pair are taken from the database (updated by the processing of the changes)
+func (s *mirrorTaskSyncer) destroyExpiredMirrorTasks(ctx context.Context, opts *SyncPreferences) {
+	for _, mirror := range s.store.ListExpiredPendingMirrorTasks(ctx, time.Now()) {
+		s.log.Debug("the grace period of the pending unlink has expired", zap.String("model_id", mirror.ModelID()),
+			zap.String("reason", mirror.PendingDestroyReason))
+		rule := s.ruleForMirrorTask(ctx, opts, mirror)
+		s.syncLifecycle(ctx, rule, mirror, nil, mirror.GetOrigTask(ctx), true)
+	}
+}
+
+// closes (archives) or reopens the other task of the pair the same as the changed task
+func (s *mirrorTaskSyncer) propagateVisibility(ctx context.Context, spec *SyncRule_SpecOfLifecycle, mirror *MirrorTask,
+	task, otherTask *Task, changedIsOrig bool) {
+
+	updTask := &tracker.Task{}
+	fields := []string{}
+	if task.IsDeletedOrHidden() {
+		if otherTask.IsDeletedOrHidden() {
+			return
+		}
+		if task.Archived {
+			updTask.Archived = true
+			fields = append(fields, tracker.FieldArchived)
+		} else {
+			updTask.Status = spec.statusNameFor(!changedIsOrig, true)
+			fields = append(fields, tracker.FieldStatus)
+		}
+	} else {
+		if !otherTask.IsDeletedOrHidden() {
+			return
+		}
+		if otherTask.Archived {
+			fields = append(fields, tracker.FieldArchived)
+		}
+		if otherTask.DateClosedAt != nil {
+			updTask.Status = spec.statusNameFor(!changedIsOrig, false)
+			fields = append(fields, tracker.FieldStatus)
+		}
+	}
+
+	direction, ref := SyncDirectionToOrig, mirror.Orig
+	if changedIsOrig {
+		direction, ref = SyncDirectionToMirror, mirror.Mirror
+	}
+	if changedIsOrig && mirror.external() {
+		// the task of another provider is closed instead of archived
+		hidden := task.IsDeletedOrHidden()
+		fields = externalVisibilityChanges(mirror, updTask, hidden, spec.statusNameFor(false, hidden))
+	}
+	if s.updateTask(ctx, mirror, ref, updTask, fields) == nil {
+		s.log.Warn("failed to propagate the closing (reopening) of the task", zap.String("model_id", mirror.ModelID()))
+		return
+	}
+	// the updated task of ClickUp is saved by the provider
+	if !changedIsOrig {
+		mirror.Task = nil
+	} else if !mirror.external() {
+		mirror.MirrorTask = nil
+	}
+	s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+		Action:    MirrorTaskActionUpdated,
+		Direction: direction,
+		TaskID:    ref.ID,
+		Fields:    fields,
+	})
+}
+
+// fills the closing (reopening) of the task of another provider by the state and the status (mapped by status_map).
+// Returns the changed fields (the fields not supported by the provider are skipped on the update).
+func externalVisibilityChanges(mirror *MirrorTask, updTask *tracker.Task, closed bool, statusName string) []string {
+	updTask.Archived = false
+	updTask.Closed = closed
+	updTask.Status = mirror.target.externalStatus(statusName)
+	return []string{tracker.FieldState, tracker.FieldStatus}
+}
@@ -0,0 +1,138 @@
+package clickup
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncRule_SpecOfLifecycle(t *testing.T) {
+	var spec *SyncRule_SpecOfLifecycle
+	if got := spec.policyFor(true); got != LifecyclePolicyUnlink {
+		t.Errorf("policyFor(orig) for nil spec = %q, want %q", got, LifecyclePolicyUnlink)
+	}
+	if got := spec.statusNameFor(false, true); got != "closed" {
+		t.Errorf("statusNameFor(mirror, closed) for nil spec = %q, want closed", got)
+	}
+	if got := spec.statusNameFor(true, false); got != "open" {
+		t.Errorf("statusNameFor(orig, reopen) for nil spec = %q, want open", got)
+	}
+
+	prefs, err := ParseSyncPreferences(strings.NewReader(`
+mirror_task_rules:
+- name: rule
+  spec_lifecycle:
+    on_orig_hidden: propagate
+    on_mirror_hidden: keep
+    mirror_closed_status_name: done
+    grace_period: 72h
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec = prefs.MirrorTaskRules[0].GetSpecLifecycle()
+	if got := spec.policyFor(true); got != LifecyclePolicyPropagate {
+		t.Errorf("policyFor(orig) = %q, want %q", got, LifecyclePolicyPropagate)
+	}
+	if got := spec.policyFor(false); got != LifecyclePolicyKeep {
+		t.Errorf("policyFor(mirror) = %q, want %q", got, LifecyclePolicyKeep)
+	}
+	if got := spec.statusNameFor(false, true); got != "done" {
+		t.Errorf("statusNameFor(mirror, closed) = %q, want done", got)
+	}
+	if got := spec.GetGracePeriod(); got != 72*time.Hour {
+		t.Errorf("GetGracePeriod() = %s, want 72h", got)
+	}
+
+	_, err = ParseSyncPreferences(strings.NewReader(`
+mirror_task_rules:
+- name: rule
+  spec_lifecycle:
+    on_orig_hidden: delete
+`))
+	if err == nil {
+		t.Error("expected error for unknown lifecycle policy")
+	}
+}
+
+func TestMirrorTaskSyncer_destroyExpiredMirrorTasks(t *testing.T) {
+	opts := mustParsePreferences(t, `
+mirror_task_rules:
+  - name: rule
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    spec_add:
+      add_to_list: https://app.clickup.com/100/v/li/20
+    spec_lifecycle:
+      grace_period: 72h
+`)
+	// closes the original task and returns the pair waiting for the unlink
+	setup := func(t *testing.T, env *syncEnv) (*MirrorTask, string) {
+		t.Helper()
+		orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+		env.change(opts, orig.ID)
+		mirrorID := mirrorTaskIDOf(t, env, orig.ID)
+		loadList(env, "20")
+
+		env.fake.task(orig.ID).Status, env.fake.task(orig.ID).StatusType = "closed", "closed"
+		env.change(opts, orig.ID)
+		pairs := env.pairs(orig.ID)
+		if len(pairs) != 1 || pairs[0].Destroyed || pairs[0].PendingDestroyAt == nil {
+			t.Fatalf("the pairs of the closed task = %+v, want the only pair waiting for the unlink", pairs)
+		}
+		return pairs[0], mirrorID
+	}
+	expire := func(t *testing.T, env *syncEnv, mirror *MirrorTask) {
+		t.Helper()
+		mirror.PendingDestroyAt = TimestampFromTime(time.Now().Add(-time.Minute))
+		if err := env.store.UpsertMirrorTask(env.ctx, mirror); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// sweeps the expired pairs and returns the actual state of the pair
+	sweep := func(env *syncEnv, mirror *MirrorTask) *MirrorTask {
+		MirrorTaskSyncer(env.api, env.store, nil).destroyExpiredMirrorTasks(env.ctx, opts)
+		return env.store.GetMirrorTask(env.ctx, mirror.ModelID())
+	}
+
+	t.Run("within the grace period", func(t *testing.T) {
+		env := newSyncEnv(t)
+		mirror, _ := setup(t, env)
+
+		if mirror := sweep(env, mirror); mirror.Destroyed || mirror.PendingDestroyAt == nil {
+			t.Errorf("the pair within the grace period = %+v, want the pair waiting for the unlink", mirror)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		env := newSyncEnv(t)
+		mirror, mirrorID := setup(t, env)
+		expire(t, env, mirror)
+
+		mirror = sweep(env, mirror)
+		if !mirror.Destroyed || mirror.DestroyedReason != "original task has been CLOSED or ARCHIVED" {
+			t.Errorf("the expired pair = %+v, want the destroyed pair", mirror)
+		}
+		if commentWith(env, mirrorID, "UNLINK MIRROR TASK") == "" {
+			t.Errorf("the mirror task has not the comment about the unlink: %v", env.fake.taskComments(mirrorID))
+		}
+		if got := len(env.store.ListExpiredPendingMirrorTasks(env.ctx, time.Now())); got != 0 {
+			t.Errorf("the destroyed pair is listed as pending: %d pairs", got)
+		}
+	})
+
+	t.Run("reopened", func(t *testing.T) {
+		env := newSyncEnv(t)
+		mirror, _ := setup(t, env)
+		expire(t, env, mirror)
+		// reopened, but the change has not been processed yet
+		env.fake.task(mirror.Orig.ID).Status, env.fake.task(mirror.Orig.ID).StatusType = "open", "open"
+		env.load(mirror.Orig.ID)
+
+		if mirror := sweep(env, mirror); mirror.Destroyed || mirror.PendingDestroyAt != nil {
+			t.Errorf("the pair of the reopened task = %+v, want the linked pair without the pending unlink", mirror)
+		}
+	})
+}
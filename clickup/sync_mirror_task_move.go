@@ -0,0 +1,197 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gebv/asap-tools/tracker"
+	"go.uber.org/zap"
+)
+
+// the policies for the mirror task if the original task has left all rules
+const (
+	// sends the comment to the mirror task (the pair remains linked)
+	RetirePolicyComment = "comment"
+	// closes the mirror task and unlinks the pair
+	RetirePolicyClose = "close"
+	// archives the mirror task and unlinks the pair
+	RetirePolicyArchive = "archive"
+	// unlinks the pair (the mirror task remains as is)
+	RetirePolicyUnlink = "unlink"
+)
+
+// spec of the handling of the moves of the original task to another list
+type SyncRule_SpecOfMove struct {
+	// do not move the mirror task to the target list of another rule (by default the mirror task is moved)
+	DisableMove bool `yaml:"disable_move,omitempty"`
+	// what to do with the mirror task if the original task has left all rules (comment, close, archive, unlink) (comment by default)
+	Retire string `yaml:"retire,omitempty"`
+	// the status of the closed mirror task (for retire close) (closed by default)
+	RetireStatusName string `yaml:"retire_status_name,omitempty"`
+}
+
+// Validate returns error if the retire policy is unknown.
+func (s *SyncRule_SpecOfMove) Validate() error {
+	switch s.GetRetire() {
+	case RetirePolicyComment, RetirePolicyClose, RetirePolicyArchive, RetirePolicyUnlink:
+		return nil
+	}
+	return fmt.Errorf("unknown retire policy %q", s.GetRetire())
+}
+
+func (s *SyncRule_SpecOfMove) AllowedMove() bool {
+	return s == nil || !s.DisableMove
+}
+
+func (s *SyncRule_SpecOfMove) GetRetire() string {
+	if s == nil || s.Retire == "" {
+		return RetirePolicyComment
+	}
+	return s.Retire
+}
+
+func (s *SyncRule_SpecOfMove) GetRetireStatusName() string {
+	if s == nil || s.RetireStatusName == "" {
+		return "closed"
+	}
+	return s.RetireStatusName
+}
+
+// syncMovedOrigTask handles the move of the original task to another list:
+// - if the rule of the mirror task still tracks the original task then nothing to do
+// - if the new location maps to the rule with another target list then moves the mirror task to that list
+// - if the original task has left all rules then retires the mirror task by the policy of the rule
+// Returns true if the pair is no longer synced (retired).
+func (s *mirrorTaskSyncer) syncMovedOrigTask(ctx context.Context, opts *SyncPreferences, mirror *MirrorTask,
+	mirrorList []*MirrorTask, rules *syncMirrorTasksMatchedRules, oldTask, task *Task) bool {
+
+	if !oldTask.Exists() || oldTask.ListRef == nil || task.ListRef == nil || oldTask.ListRef.ID == task.ListRef.ID {
+		return false
+	}
+
+	l := s.log.Named("sync_moved_orig_task").With(zap.String("model_id", mirror.ModelID()))
+	current := s.ruleForMirrorTask(ctx, opts, mirror)
+	for _, rule := range rules.changedRules {
+		if current != nil && rule.Name == current.Name {
+			// still tracked by the rule
+			return false
+		}
+	}
+
+	mirrorTask := mirror.GetMirrorTask(ctx)
+	for idx := range rules.addRules {
+		if mirror.external() {
+			// the task of another provider is not moved (the milestone follows the list of the original task)
+			break
+		}
+		rule := rules.addRules[idx]
+		for _, target := range rule.SpecAdd.AllTargets() {
+			listID := target.GetAddToListID()
+
+			if listID != mirrorTask.ListRef.ID {
+				if !current.GetSpecMove().AllowedMove() {
+					continue
+				}
+				if mirroredInList(ctx, mirrorList, task.ID, listID) {
+					continue
+				}
+				res := s.api.MoveTask(ctx, mirrorTask.TeamID(), mirrorTask.ID, listID)
+				warnIfFailedRequest(s.log, res)
+				if !res.StatusOK() {
+					l.Warn("failed to move the mirror task to the list", zap.String("list_id", listID))
+					return false
+				}
+				s.reloadMirrorTask(ctx, mirror)
+			}
+
+			l.Info("the mirror task follows the original task to another rule", zap.String("rule_name", rule.Name))
+			mirror.RuleName = rule.Name
+			err := s.store.UpsertMirrorTask(ctx, mirror)
+			warnErrorIf(s.log, err, "failed to save the rule of the moved mirror task", "model_id", mirror.ModelID())
+			s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+				Action:    MirrorTaskActionMoved,
+				Direction: SyncDirectionToMirror,
+				TaskID:    mirrorTask.ID,
+				Fields:    []string{"list"},
+				Reason:    "rule " + rule.Name,
+			})
+			s.sendMirrorComment(ctx, mirror, SyncDirectionToMirror, mirror.Mirror,
+				s.message(&rule, MsgMirrorMoved, newMirrorTaskTemplateData(ctx, &rule, task, mirror.GetMirrorTask(ctx))), "")
+			return false
+		}
+	}
+
+	if len(rules.addRules) > 0 || len(rules.changedRules) > 0 {
+		// tracked by other rules
+		return false
+	}
+
+	s.retireMirrorTask(ctx, current, mirror, task)
+	return current.GetSpecMove().GetRetire() != RetirePolicyComment
+}
+
+// retires the mirror task by the policy of the rule (the original task has left all rules)
+func (s *mirrorTaskSyncer) retireMirrorTask(ctx context.Context, rule *MirrorTaskSpecification, mirror *MirrorTask, task *Task) {
+	spec := rule.GetSpecMove()
+	policy := spec.GetRetire()
+
+	updTask := &tracker.Task{}
+	fields := []string{}
+	switch policy {
+	case RetirePolicyClose:
+		updTask.Status = spec.GetRetireStatusName()
+		fields = append(fields, tracker.FieldStatus)
+	case RetirePolicyArchive:
+		updTask.Archived = true
+		fields = append(fields, tracker.FieldArchived)
+	case RetirePolicyComment, RetirePolicyUnlink:
+	default:
+		s.log.Warn("unknown retire policy of the mirror task", zap.String("policy", policy), zap.String("model_id", mirror.ModelID()))
+		return
+	}
+	if len(fields) > 0 && mirror.external() {
+		// the task of another provider is closed instead of archived
+		fields = externalVisibilityChanges(mirror, updTask, true, spec.GetRetireStatusName())
+	}
+	if len(fields) > 0 {
+		if s.updateTask(ctx, mirror, mirror.Mirror, updTask, fields) == nil {
+			return
+		}
+		if !mirror.external() {
+			// the updated task is saved by the provider
+			mirror.MirrorTask = nil
+		}
+	}
+
+	msgData := newMirrorTaskTemplateData(ctx, rule, task, mirror.GetMirrorTask(ctx)).with("", policy)
+	s.sendMirrorComment(ctx, mirror, SyncDirectionToMirror, mirror.Mirror, s.message(rule, MsgMirrorRetired, msgData), "")
+	if policy != RetirePolicyComment {
+		s.destroyMirrorTask(ctx, mirror, "original task has left all rules (retire "+policy+")")
+	}
+}
+
+// returns true if the task already has the mirror task in the list
+func mirroredInList(ctx context.Context, mirrorList []*MirrorTask, taskID, listID string) bool {
+	for _, mirror := range mirrorList {
+		if mirror.Destroyed || mirror.Orig.ID != taskID {
+			continue
+		}
+		if mirror.GetMirrorTask(ctx).ListRef.ID == listID {
+			return true
+		}
+	}
+	return false
+}
+
+// reloads the mirror task from API after the changes that are not returned by API (eg the move)
+func (s *mirrorTaskSyncer) reloadMirrorTask(ctx context.Context, mirror *MirrorTask) {
+	res := s.api.TaskByID(ctx, mirror.Mirror.ID)
+	warnIfFailedRequest(s.log, res)
+	if !res.StatusOK() {
+		return
+	}
+	updatedTask := ModelTaskFromAPI(ctx, s.store, &res.Task)
+	err := s.store.UpsertTask(ctx, updatedTask)
+	warnErrorIf(s.log, err, "failed to update a reloaded mirror task", "task_id", updatedTask.ID)
+	mirror.MirrorTask = updatedTask
+}
@@ -0,0 +1,213 @@
+package clickup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSyncRule_SpecOfMove(t *testing.T) {
+	var spec *SyncRule_SpecOfMove
+	if !spec.AllowedMove() {
+		t.Error("AllowedMove() for nil spec must be true")
+	}
+	if got := spec.GetRetire(); got != RetirePolicyComment {
+		t.Errorf("GetRetire() = %q, want %q", got, RetirePolicyComment)
+	}
+	if err := spec.Validate(); err != nil {
+		t.Errorf("Validate() for nil spec: %v", err)
+	}
+
+	spec = &SyncRule_SpecOfMove{DisableMove: true, Retire: RetirePolicyArchive}
+	if spec.AllowedMove() {
+		t.Error("AllowedMove() must be false")
+	}
+	if err := spec.Validate(); err != nil {
+		t.Errorf("Validate(): %v", err)
+	}
+
+	spec = &SyncRule_SpecOfMove{Retire: "delete"}
+	if err := spec.Validate(); err == nil {
+		t.Error("Validate() for unknown retire policy must return error")
+	}
+}
+
+// the rules of the moves: clients (10 => 20), partners (11 => 21), shared (12 => 20), the list 13 is tracked by clients
+func testMoveRules(specMove string) string {
+	return `
+mirror_task_rules:
+  - name: clients
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/10, https://app.clickup.com/100/v/li/13]
+    spec_add:
+      add_to_list: https://app.clickup.com/100/v/li/20
+    spec_move: ` + specMove + `
+  - name: partners
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/11]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/11]
+    spec_add:
+      add_to_list: https://app.clickup.com/100/v/li/21
+  - name: shared
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/12]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/12]
+    spec_add:
+      add_to_list: https://app.clickup.com/100/v/li/20
+`
+}
+
+// creates the original task in the list 10 and the mirror task by the rule clients, returns IDs of the tasks
+func setupMovedOrigTask(t *testing.T, env *syncEnv, opts *SyncPreferences) (string, string) {
+	t.Helper()
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+	env.change(opts, orig.ID)
+	loadList(env, "20")
+	return orig.ID, mirrorTaskIDOf(t, env, orig.ID)
+}
+
+// moves the original task to the list and syncs
+func moveOrigTask(env *syncEnv, opts *SyncPreferences, taskID, listID string) {
+	env.fake.task(taskID).ListID = listID
+	env.change(opts, taskID)
+}
+
+// returns the comment of the task with the substring (or "")
+func commentWith(env *syncEnv, taskID, substr string) string {
+	for _, comment := range env.fake.taskComments(taskID) {
+		if strings.Contains(comment, substr) {
+			return comment
+		}
+	}
+	return ""
+}
+
+func TestMirrorTaskSyncer_syncMovedOrigTask_target(t *testing.T) {
+	t.Run("moved to the target of another rule", func(t *testing.T) {
+		env := newSyncEnv(t)
+		opts := mustParsePreferences(t, testMoveRules("{}"))
+		origID, mirrorID := setupMovedOrigTask(t, env, opts)
+
+		moveOrigTask(env, opts, origID, "11")
+		if got := env.fake.task(mirrorID).ListID; got != "21" {
+			t.Errorf("the mirror task is in the list %q, want 21", got)
+		}
+		pairs := env.pairs(origID)
+		if len(pairs) != 1 || pairs[0].RuleName != "partners" || pairs[0].Destroyed {
+			t.Fatalf("the pairs of the moved task = %+v, want the only pair by the rule partners", pairs)
+		}
+		if commentWith(env, mirrorID, `moved to the list "List 11" - the mirror task has been moved by the rule "partners"`) == "" {
+			t.Errorf("the mirror task has not the comment about the move: %v", env.fake.taskComments(mirrorID))
+		}
+		moved := false
+		for _, entry := range env.store.ListMirrorTaskHistory(env.ctx, pairs[0]) {
+			moved = moved || (entry.Action == MirrorTaskActionMoved && entry.Reason == "rule partners")
+		}
+		if !moved {
+			t.Error("the move is not recorded in the history of the pair")
+		}
+	})
+
+	t.Run("moved to the rule with the same target", func(t *testing.T) {
+		env := newSyncEnv(t)
+		opts := mustParsePreferences(t, testMoveRules("{}"))
+		origID, mirrorID := setupMovedOrigTask(t, env, opts)
+
+		moveOrigTask(env, opts, origID, "12")
+		if got := env.fake.countRequests("PUT /workspaces/"); got != 0 {
+			t.Errorf("the mirror task is moved %d times, want 0", got)
+		}
+		if got := env.fake.task(mirrorID).ListID; got != "20" {
+			t.Errorf("the mirror task is in the list %q, want 20", got)
+		}
+		if pairs := env.pairs(origID); len(pairs) != 1 || pairs[0].RuleName != "shared" {
+			t.Errorf("the pairs of the moved task = %+v, want the only pair by the rule shared", pairs)
+		}
+	})
+
+	t.Run("moved within the rule", func(t *testing.T) {
+		env := newSyncEnv(t)
+		opts := mustParsePreferences(t, testMoveRules("{}"))
+		origID, mirrorID := setupMovedOrigTask(t, env, opts)
+
+		moveOrigTask(env, opts, origID, "13")
+		if pairs := env.pairs(origID); len(pairs) != 1 || pairs[0].RuleName != "clients" || pairs[0].Destroyed {
+			t.Errorf("the pairs of the moved task = %+v, want the only pair by the rule clients", pairs)
+		}
+		if env.fake.countRequests("PUT /workspaces/") != 0 || commentWith(env, mirrorID, "the mirror task has been moved") != "" {
+			t.Error("the mirror task is moved within the rule")
+		}
+		// only the notice about the changes of the original task
+		if commentWith(env, mirrorID, `- moved to the list "List 13"`) == "" {
+			t.Errorf("the mirror task has not the notice about the move: %v", env.fake.taskComments(mirrorID))
+		}
+	})
+
+	t.Run("disabled move", func(t *testing.T) {
+		env := newSyncEnv(t)
+		opts := mustParsePreferences(t, testMoveRules("{disable_move: true}"))
+		origID, mirrorID := setupMovedOrigTask(t, env, opts)
+
+		moveOrigTask(env, opts, origID, "11")
+		if got := env.fake.task(mirrorID).ListID; got != "20" {
+			t.Errorf("the mirror task is in the list %q, want 20", got)
+		}
+		// the original task is mirrored by the new rule as the new task
+		lists := map[string]string{}
+		for _, mirror := range env.pairs(origID) {
+			lists[mirror.RuleName] = env.fake.task(mirror.Mirror.ID).ListID
+		}
+		if want := map[string]string{"clients": "20", "partners": "21"}; !reflect.DeepEqual(lists, want) {
+			t.Errorf("the lists of the mirror tasks by rule = %v, want %v", lists, want)
+		}
+	})
+}
+
+func TestMirrorTaskSyncer_syncMovedOrigTask_retire(t *testing.T) {
+	tests := []struct {
+		specMove      string
+		wantDestroyed bool
+		wantStatus    string
+		wantArchived  bool
+		wantComment   string
+	}{
+		{"{}", false, "open", false, "is retired (comment)"},
+		{"{retire: comment}", false, "open", false, "is retired (comment)"},
+		{"{retire: close}", true, "closed", false, "is retired (close)"},
+		{"{retire: close, retire_status_name: done}", true, "done", false, "is retired (close)"},
+		{"{retire: archive}", true, "open", true, "is retired (archive)"},
+		{"{retire: unlink}", true, "open", false, "is retired (unlink)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.specMove, func(t *testing.T) {
+			env := newSyncEnv(t)
+			opts := mustParsePreferences(t, testMoveRules(tt.specMove))
+			origID, mirrorID := setupMovedOrigTask(t, env, opts)
+
+			// the list without rules
+			moveOrigTask(env, opts, origID, "99")
+
+			mirrorTask := env.fake.task(mirrorID)
+			if mirrorTask.ListID != "20" {
+				t.Errorf("the retired mirror task is moved to the list %q", mirrorTask.ListID)
+			}
+			if mirrorTask.Status != tt.wantStatus || mirrorTask.Archived != tt.wantArchived {
+				t.Errorf("the retired mirror task: status %q, archived %v, want %q, %v", mirrorTask.Status, mirrorTask.Archived, tt.wantStatus, tt.wantArchived)
+			}
+			if commentWith(env, mirrorID, tt.wantComment) == "" {
+				t.Errorf("the mirror task has not the comment %q: %v", tt.wantComment, env.fake.taskComments(mirrorID))
+			}
+			pairs := env.pairs(origID)
+			if len(pairs) != 1 || pairs[0].Destroyed != tt.wantDestroyed {
+				t.Fatalf("the pairs of the moved task = %+v, want the only pair destroyed=%v", pairs, tt.wantDestroyed)
+			}
+			if tt.wantDestroyed && !strings.Contains(pairs[0].DestroyedReason, "retire") {
+				t.Errorf("the reason of the destroyed pair = %q", pairs[0].DestroyedReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package clickup
+
+import (
+	"context"
+
+	"github.com/gebv/asap-tools/tracker"
+	"go.uber.org/zap"
+)
+
+// ruleForMirrorTask returns the rule by which the mirror task was created or nil.
+// For mirror tasks without the rule name (created before the rule name was stored) the rule is looked up by the list of the mirror task.
+func (s *mirrorTaskSyncer) ruleForMirrorTask(ctx context.Context, opts *SyncPreferences, mirror *MirrorTask) *MirrorTaskSpecification {
+	if rule := opts.RuleByName(mirror.RuleName); rule != nil {
+		return rule
+	}
+	listID := mirror.GetMirrorTask(ctx).ListRef.ID
+	for idx := range opts.MirrorTaskRules {
+		rule := &opts.MirrorTaskRules[idx]
+		if rule.SpecAdd.TargetByListID(listID) != nil {
+			return rule
+		}
+	}
+	return nil
+}
+
+// parentMirrorsWithSubtasks returns the mirror tasks of the parent (original) task whose rules mirror subtasks.
+func (s *mirrorTaskSyncer) parentMirrorsWithSubtasks(ctx context.Context, opts *SyncPreferences, parentTaskID string) []*MirrorTask {
+	mirrorList, _ := s.store.AllMatchesForMirrorTasks(ctx, parentTaskID)
+	res := []*MirrorTask{}
+	for _, mirror := range mirrorList {
+		// the subtasks are not mirrored to other providers
+		if mirror.Destroyed || mirror.Orig.ID != parentTaskID || mirror.external() {
+			continue
+		}
+		rule := s.ruleForMirrorTask(ctx, opts, mirror)
+		if rule == nil || !rule.SpecSync.MirrorSubtasks() {
+			continue
+		}
+		res = append(res, mirror)
+	}
+	return res
+}
+
+// addMirrorSubtasks adds the mirror of the subtask under each mirror of the parent task (if the rule mirrors subtasks).
+// mirroredLists - the lists in which the task is already mirrored.
+func (s *mirrorTaskSyncer) addMirrorSubtasks(ctx context.Context, opts *SyncPreferences, task *Task, mirroredLists map[string]bool) {
+	for _, parentMirror := range s.parentMirrorsWithSubtasks(ctx, opts, task.ParentTaskRef.ID) {
+		listID := parentMirror.GetMirrorTask(ctx).ListRef.ID
+		if mirroredLists[listID] {
+			continue
+		}
+
+		rule := s.ruleForMirrorTask(ctx, opts, parentMirror)
+		if s.addMirrorSubtask(ctx, rule, s.targetOf(ctx, rule, parentMirror), parentMirror.Mirror.ID, listID, task) != nil {
+			mirroredLists[listID] = true
+		}
+	}
+}
+
+// addExistingMirrorSubtasks adds the mirrors of the existing subtasks of the task (recursively) under the new mirror of the task.
+// The subtasks created before the mirror of the parent task are not changed and without it they would never be mirrored.
+func (s *mirrorTaskSyncer) addExistingMirrorSubtasks(ctx context.Context, rule *MirrorTaskSpecification, target *SyncRule_SpecOfAddTarget,
+	task *Task, parentMirrorTaskID, listID string) {
+	for _, subtask := range task.GetSubTasks() {
+		ModelTaskSetupLazyload(ctx, s.store, subtask)
+		if subtask.IsDeletedOrHidden() || s.mirroredInList(ctx, subtask.ID, listID) {
+			continue
+		}
+		mirror := s.addMirrorSubtask(ctx, rule, target, parentMirrorTaskID, listID, subtask)
+		if mirror == nil {
+			continue
+		}
+		s.addExistingMirrorSubtasks(ctx, rule, target, subtask, mirror.Mirror.ID, listID)
+	}
+}
+
+// returns true if the task already has the mirror task in the list
+func (s *mirrorTaskSyncer) mirroredInList(ctx context.Context, taskID, listID string) bool {
+	mirrorList, _ := s.store.AllMatchesForMirrorTasks(ctx, taskID)
+	for _, mirror := range mirrorList {
+		if mirror.Destroyed || mirror.Orig.ID != taskID {
+			continue
+		}
+		if mirrorTask := mirror.GetMirrorTask(ctx); mirrorTask.Exists() && mirrorTask.ListRef.ID == listID {
+			return true
+		}
+	}
+	return false
+}
+
+// addMirrorSubtask adds the mirror of the subtask under the mirror of the parent task in the list.
+// Returns the new pair or nil if failed.
+func (s *mirrorTaskSyncer) addMirrorSubtask(ctx context.Context, rule *MirrorTaskSpecification, target *SyncRule_SpecOfAddTarget,
+	parentMirrorTaskID, listID string, task *Task) *MirrorTask {
+	l := s.log.Named("add_mirror_subtask").With(zap.String("task_id", task.ID))
+
+	parent := TaskRef(parentMirrorTaskID)
+	mirrorTask := &tracker.Task{
+		Name:           s.mirrorTaskName(ctx, rule, target, true, task),
+		Parent:         &parent,
+		Links:          []tracker.TaskRef{TaskRef(task.ID)},
+		Tags:           rule.SpecSync.GetTags().NewMirrorTaskTags(),
+		Description:    s.mirrorTaskDescription(ctx, rule, target, task),
+		PriorityID:     task.PriorityID,
+		TimeEstimateMs: task.TimeEstimateMs,
+	}
+
+	created, err := s.providers.CreateTask(ctx, tracker.Location{Provider: ProviderName, ID: listID}, mirrorTask)
+	if err != nil {
+		l.Error("aborted creation of a mirror subtask - failed to create the task", zap.Error(err))
+		return nil
+	}
+
+	mirror := s.store.ModelMirrorTaskOf(task.ID, created.Ref)
+	mirror.RuleName = rule.Name
+	mirror.Subtask = true
+	mirror.CreatedAt = TimestampNow()
+	mirror.SyncedToMirror = NewMirrorTaskSnapshot(task)
+	if err := s.store.UpsertMirrorTask(ctx, mirror); err != nil {
+		l.Error("failed add mirror subtask to database", zap.Error(err), zap.String("mirror_task_id", created.Ref.ID))
+		return nil
+	}
+	s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+		Action:    MirrorTaskActionCreated,
+		Direction: SyncDirectionToMirror,
+		TaskID:    created.Ref.ID,
+	})
+	return mirror
+}
+
+// syncMirrorSubtaskHierarchy applies to the mirror subtask the removal or the move of the original subtask.
+// Returns true if the pair has been destroyed and no further processing is needed.
+func (s *mirrorTaskSyncer) syncMirrorSubtaskHierarchy(ctx context.Context, opts *SyncPreferences, mirror *MirrorTask, oldTask, task *Task) bool {
+	// removed the original subtask
+	if task.Deleted {
+		res := s.api.DeleteTask(ctx, mirror.Mirror.ID)
+		warnIfFailedRequest(s.log, res)
+		if res.Deleted() {
+			s.destroyMirrorTask(ctx, mirror, "original subtask has been DELETED")
+		}
+		return true
+	}
+
+	oldParentID, parentID := "", ""
+	if oldTask.ParentTaskRef != nil {
+		oldParentID = oldTask.ParentTaskRef.ID
+	}
+	if task.ParentTaskRef != nil {
+		parentID = task.ParentTaskRef.ID
+	}
+	if !oldTask.Exists() || oldParentID == parentID {
+		return false
+	}
+
+	// moved the original subtask to another parent
+	mirrorListID := mirror.GetMirrorTask(ctx).ListRef.ID
+	if parentID != "" {
+		for _, parentMirror := range s.parentMirrorsWithSubtasks(ctx, opts, parentID) {
+			if parentMirror.GetMirrorTask(ctx).ListRef.ID != mirrorListID {
+				continue
+			}
+
+			updTask := &tracker.Task{Parent: &parentMirror.Mirror}
+			if s.updateTask(ctx, mirror, mirror.Mirror, updTask, []string{tracker.FieldParent}) != nil {
+				s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+					Action:    MirrorTaskActionUpdated,
+					Direction: SyncDirectionToMirror,
+					TaskID:    mirror.Mirror.ID,
+					Fields:    []string{tracker.FieldParent},
+				})
+			}
+			return false
+		}
+	}
+
+	// NOTE: ClickUp API does not allow to convert the subtask to the task
+	rule := s.ruleForMirrorTask(ctx, opts, mirror)
+	s.sendMirrorComment(ctx, mirror, SyncDirectionToMirror, mirror.Mirror,
+		s.message(rule, MsgSubtaskMovedOut, newMirrorTaskTemplateData(ctx, rule, task, mirror.GetMirrorTask(ctx))), "")
+	return false
+}
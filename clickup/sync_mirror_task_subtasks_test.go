@@ -0,0 +1,134 @@
+package clickup
+
+import "testing"
+
+const testSubtasksRule = `
+mirror_task_rules:
+  - name: client
+    cond_add:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    cond_track_changes:
+      if_in_lists: [https://app.clickup.com/100/v/li/10]
+    spec_add:
+      add_to_list: https://app.clickup.com/100/v/li/20
+    spec_sync:
+      subtasks: true
+`
+
+// returns ID of the only active mirror task of the original task (or fails the test)
+func mirrorTaskIDOf(t *testing.T, env *syncEnv, taskID string) string {
+	t.Helper()
+	active := []*MirrorTask{}
+	for _, mirror := range env.pairs(taskID) {
+		if !mirror.Destroyed {
+			active = append(active, mirror)
+		}
+	}
+	if len(active) != 1 {
+		t.Fatalf("the task %q has %d active mirror tasks, want 1", taskID, len(active))
+	}
+	return active[0].Mirror.ID
+}
+
+// pulls all tasks of the list (eg the new mirror tasks) to the storage
+func loadList(env *syncEnv, listID string) {
+	for _, task := range env.fake.tasksInList(listID) {
+		env.load(task.ID)
+	}
+}
+
+func TestMirrorTaskSyncer_ExistingSubtasks(t *testing.T) {
+	env := newSyncEnv(t)
+	opts := mustParsePreferences(t, testSubtasksRule)
+
+	parent := env.fake.addTask(&fakeTask{Name: "Parent", ListID: "10"})
+	subtask := env.fake.addTask(&fakeTask{Name: "Subtask", ListID: "10", Parent: parent.ID})
+	nested := env.fake.addTask(&fakeTask{Name: "Nested", ListID: "10", Parent: subtask.ID})
+	closed := env.fake.addTask(&fakeTask{Name: "Closed", ListID: "10", Parent: parent.ID, Status: "done", StatusType: "closed"})
+	env.load(subtask.ID)
+	env.load(nested.ID)
+	env.load(closed.ID)
+
+	// the subtasks are mirrored only under the mirror of the parent task
+	env.change(opts, subtask.ID)
+	if got := env.fake.tasksInList("20"); len(got) != 0 {
+		t.Fatalf("the subtask without the mirror of the parent task must not be mirrored, got %d mirror tasks", len(got))
+	}
+
+	env.change(opts, parent.ID)
+
+	parentMirrorID := mirrorTaskIDOf(t, env, parent.ID)
+	subtaskMirrorID := mirrorTaskIDOf(t, env, subtask.ID)
+	nestedMirrorID := mirrorTaskIDOf(t, env, nested.ID)
+	if got := env.pairs(closed.ID); len(got) != 0 {
+		t.Errorf("the closed subtask must not be mirrored, got %d pairs", len(got))
+	}
+
+	if got := env.fake.task(parentMirrorID).Parent; got != "" {
+		t.Errorf("the mirror of the parent task has the parent %q", got)
+	}
+	if got := env.fake.task(subtaskMirrorID).Parent; got != parentMirrorID {
+		t.Errorf("the mirror of the subtask has the parent %q, want %q", got, parentMirrorID)
+	}
+	if got := env.fake.task(nestedMirrorID).Parent; got != subtaskMirrorID {
+		t.Errorf("the mirror of the nested subtask has the parent %q, want %q", got, subtaskMirrorID)
+	}
+	for _, taskID := range []string{subtask.ID, nested.ID} {
+		mirror := env.pairs(taskID)[0]
+		if !mirror.Subtask || mirror.RuleName != "client" {
+			t.Errorf("the pair of the subtask %q: Subtask=%v RuleName=%q", taskID, mirror.Subtask, mirror.RuleName)
+		}
+		if env.fake.task(mirror.Mirror.ID).ListID != "20" {
+			t.Errorf("the mirror of the subtask %q is not in the target list", taskID)
+		}
+	}
+
+	// the next changes of the subtasks do not create the mirror tasks again
+	loadList(env, "20")
+	env.change(opts, subtask.ID)
+	env.change(opts, nested.ID)
+	if got := env.fake.tasksInList("20"); len(got) != 3 {
+		t.Errorf("the target list has %d tasks, want 3", len(got))
+	}
+}
+
+func TestMirrorTaskSyncer_SubtaskHierarchy(t *testing.T) {
+	env := newSyncEnv(t)
+	opts := mustParsePreferences(t, testSubtasksRule)
+
+	parent := env.fake.addTask(&fakeTask{Name: "Parent", ListID: "10"})
+	other := env.fake.addTask(&fakeTask{Name: "Other parent", ListID: "10"})
+	env.change(opts, parent.ID)
+	env.change(opts, other.ID)
+	loadList(env, "20")
+	parentMirrorID := mirrorTaskIDOf(t, env, parent.ID)
+	otherMirrorID := mirrorTaskIDOf(t, env, other.ID)
+
+	// the new subtask of the mirrored parent task
+	subtask := env.fake.addTask(&fakeTask{Name: "Subtask", ListID: "10", Parent: parent.ID})
+	env.change(opts, subtask.ID)
+	subtaskMirrorID := mirrorTaskIDOf(t, env, subtask.ID)
+	if got := env.fake.task(subtaskMirrorID).Parent; got != parentMirrorID {
+		t.Fatalf("the mirror of the new subtask has the parent %q, want %q", got, parentMirrorID)
+	}
+	loadList(env, "20")
+
+	// moved to another mirrored parent task
+	env.fake.task(subtask.ID).Parent = other.ID
+	env.change(opts, subtask.ID)
+	if got := env.fake.task(subtaskMirrorID).Parent; got != otherMirrorID {
+		t.Errorf("the mirror of the moved subtask has the parent %q, want %q", got, otherMirrorID)
+	}
+	if got := env.fake.subtasks(parentMirrorID); len(got) != 0 {
+		t.Errorf("the mirror of the old parent task has the subtasks %v", got)
+	}
+
+	// deleted the original subtask
+	env.remove(opts, subtask.ID)
+	if env.fake.task(subtaskMirrorID) != nil {
+		t.Error("the mirror of the deleted subtask must be deleted")
+	}
+	if mirror := env.pairs(subtask.ID)[0]; !mirror.Destroyed {
+		t.Error("the pair of the deleted subtask must be destroyed")
+	}
+}
@@ -0,0 +1,129 @@
+package clickup
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+// spec of the sync of the tags between the original and the mirror tasks
+type SyncRule_SpecOfTags struct {
+	// sync direction of the tags (available orig_to_mirror, mirror_to_orig, both)
+	Direction string `yaml:"direction"`
+	// only these tags of the original task are synced (all by default)
+	Allow []string `yaml:"allow,omitempty"`
+	// these tags of the original task are never synced
+	Deny []string `yaml:"deny,omitempty"`
+	// renames the tags of the original task in the mirror task (<orig tag>: <mirror tag>)
+	Rename map[string]string `yaml:"rename,omitempty"`
+	// the prefix added to the tags of the original task in the mirror task (e.g. "client:").
+	// In the direction to the original task only tags with the prefix are synced.
+	Prefix string `yaml:"prefix,omitempty"`
+	// the tags are set on the new mirror task ("mirror" by default)
+	SetTags []string `yaml:"set_tags,omitempty"`
+}
+
+func (s *SyncRule_SpecOfTags) AllowedSync(direction string) bool {
+	if s == nil {
+		return false
+	}
+	return allowedSyncDirection(s.Direction, direction)
+}
+
+// NewMirrorTaskTags returns the tags for the new mirror task.
+func (s *SyncRule_SpecOfTags) NewMirrorTaskTags() []string {
+	if s == nil || len(s.SetTags) == 0 {
+		return []string{"mirror"}
+	}
+	res := []string{}
+	for _, tag := range s.SetTags {
+		res = append(res, strings.ToLower(tag))
+	}
+	return res
+}
+
+// MirrorTags returns the tags of the mirror task for the tags of the original task (after filtering and transformations).
+func (s *SyncRule_SpecOfTags) MirrorTags(origTags []string) []string {
+	res := []string{}
+	for _, tag := range origTags {
+		tag = strings.ToLower(tag)
+		if !s.allowedOrigTag(tag) {
+			continue
+		}
+		for from, to := range s.Rename {
+			if strings.EqualFold(from, tag) {
+				tag = strings.ToLower(to)
+				break
+			}
+		}
+		tag = strings.ToLower(s.Prefix) + tag
+		if !containsString(res, tag) {
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+// OrigTags returns the tags of the original task for the tags of the mirror task (the reverse transformations).
+// The tags that are set on the new mirror task are not synced to the original task.
+func (s *SyncRule_SpecOfTags) OrigTags(mirrorTags []string) []string {
+	res := []string{}
+	prefix := strings.ToLower(s.Prefix)
+	setTags := s.NewMirrorTaskTags()
+	for _, tag := range mirrorTags {
+		tag = strings.ToLower(tag)
+		if containsString(setTags, tag) {
+			continue
+		}
+		if prefix != "" {
+			if !strings.HasPrefix(tag, prefix) {
+				// the own tags of the mirror task
+				continue
+			}
+			tag = tag[len(prefix):]
+		}
+		for from, to := range s.Rename {
+			if strings.EqualFold(to, tag) {
+				tag = strings.ToLower(from)
+				break
+			}
+		}
+		if !s.allowedOrigTag(tag) {
+			continue
+		}
+		if !containsString(res, tag) {
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+func (s *SyncRule_SpecOfTags) allowedOrigTag(tag string) bool {
+	for _, denied := range s.Deny {
+		if strings.EqualFold(denied, tag) {
+			return false
+		}
+	}
+	if len(s.Allow) == 0 {
+		return true
+	}
+	for _, allowed := range s.Allow {
+		if strings.EqualFold(allowed, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncTags adds and removes the tags of the target task of the pair. Returns true if at least one tag has been changed.
+// The tags of source and lastSynced must be already transformed for the target task.
+func (s *mirrorTaskSyncer) syncTags(ctx context.Context, mirror *MirrorTask, ref tracker.TaskRef, source, lastSynced, target []string) bool {
+	adds, removes := diffSyncedValues(source, lastSynced, target)
+	if len(adds) == 0 && len(removes) == 0 {
+		return false
+	}
+
+	updTask := &tracker.Task{Tags: applySyncedValues(target, adds, removes)}
+	return s.updateTask(ctx, mirror, ref, updTask, []string{tracker.FieldTags}) != nil
+}
@@ -0,0 +1,40 @@
+package clickup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSyncRule_SpecOfTags(t *testing.T) {
+	spec := &SyncRule_SpecOfTags{
+		Direction: SyncDirectionBoth,
+		Deny:      []string{"internal"},
+		Rename:    map[string]string{"bug": "defect"},
+		Prefix:    "client:",
+	}
+
+	mirrorTags := spec.MirrorTags([]string{"Bug", "internal", "ui"})
+	if want := []string{"client:defect", "client:ui"}; !reflect.DeepEqual(mirrorTags, want) {
+		t.Errorf("MirrorTags() = %v, want %v", mirrorTags, want)
+	}
+
+	origTags := spec.OrigTags([]string{"mirror", "client:defect", "client:ui", "backend", "client:internal"})
+	if want := []string{"bug", "ui"}; !reflect.DeepEqual(origTags, want) {
+		t.Errorf("OrigTags() = %v, want %v", origTags, want)
+	}
+
+	if got := spec.NewMirrorTaskTags(); !reflect.DeepEqual(got, []string{"mirror"}) {
+		t.Errorf("NewMirrorTaskTags() = %v", got)
+	}
+	if (*SyncRule_SpecOfTags)(nil).AllowedSync(SyncDirectionToMirror) {
+		t.Error("AllowedSync() for nil spec must be false")
+	}
+}
+
+func TestSyncRule_SpecOfTags_Allow(t *testing.T) {
+	spec := &SyncRule_SpecOfTags{Allow: []string{"ui"}}
+
+	if got := spec.MirrorTags([]string{"bug", "UI"}); !reflect.DeepEqual(got, []string{"ui"}) {
+		t.Errorf("MirrorTags() = %v", got)
+	}
+}
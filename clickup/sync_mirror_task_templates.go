@@ -0,0 +1,380 @@
+package clickup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+// the keys of the messages (text/template) of the mirror tasks
+const (
+	// the name of the mirror task
+	MsgMirrorTaskName = "name"
+	// the name of the mirror subtask
+	MsgMirrorSubtaskName = "subtask_name"
+	// the description of the mirror task (markdown)
+	MsgMirrorTaskDescription = "description"
+	// the first comment in the new mirror task (.AssignTo - email of the member who was not found)
+	MsgIntroComment = "intro_comment"
+
+	// the header of the comment with the changes of the original task
+	MsgOrigChangesHeader          = "orig_changes_header"
+	MsgOrigChangedStatus          = "orig_changed_status"
+	MsgOrigClosed                 = "orig_closed"
+	MsgOrigArchived               = "orig_archived"
+	MsgOrigDeleted                = "orig_deleted"
+	MsgOrigMovedToFolder          = "orig_moved_to_folder"
+	MsgOrigMovedToList            = "orig_moved_to_list"
+	MsgDiffEstimateRemoved        = "diff_estimate_removed"
+	MsgDiffEstimateChanged        = "diff_estimate_changed"
+	MsgDiffEstimateAdded          = "diff_estimate_added"
+	MsgDiffDueDateRemoved         = "diff_due_date_removed"
+	MsgDiffDueDateChanged         = "diff_due_date_changed"
+	MsgDiffDueDateAdded           = "diff_due_date_added"
+	MsgDiffStartDateRemoved       = "diff_start_date_removed"
+	MsgDiffStartDateChanged       = "diff_start_date_changed"
+	MsgDiffStartDateAdded         = "diff_start_date_added"
+	MsgMirrorHiddenButOrigChanged = "mirror_hidden_but_orig_changed"
+
+	// the header of the comment with the changes of the mirror task
+	MsgMirrorChangesHeader  = "mirror_changes_header"
+	MsgMirrorRemoved        = "mirror_removed"
+	MsgOrigEstimateRemoved  = "orig_estimate_removed"
+	MsgOrigEstimateChanged  = "orig_estimate_changed"
+	MsgOrigDueDateRemoved   = "orig_due_date_removed"
+	MsgOrigDueDateChanged   = "orig_due_date_changed"
+	MsgOrigStartDateRemoved = "orig_start_date_removed"
+	MsgOrigStartDateChanged = "orig_start_date_changed"
+
+	// the unlink notices
+	MsgUnlinkMirrorHidden        = "unlink_mirror_hidden"
+	MsgUnlinkOrigHidden          = "unlink_orig_hidden"
+	MsgUnlinkChangedHiddenMirror = "unlink_changed_hidden_mirror"
+	MsgSubtaskMovedOut           = "subtask_moved_out"
+
+	// the mirror task has been moved to the target list of another rule (.Rule - the new rule)
+	MsgMirrorMoved = "mirror_moved"
+	// the original task has left all rules (.To - the retire policy)
+	MsgMirrorRetired = "mirror_retired"
+
+	// the issue in the external tracker has been created (.Issue - the new issue)
+	MsgExternalIssueCreated = "external_issue_created"
+	// the comment of the original task mirrored to the issue (.Comment - the comment)
+	MsgExternalCommentToIssue = "external_comment_to_issue"
+	// the comment of the issue mirrored to the original task (.Comment - the comment)
+	MsgExternalCommentToTask = "external_comment_to_task"
+	// the issue has been deleted or transferred (the pair is unlinked)
+	MsgExternalIssueRemoved = "external_issue_removed"
+)
+
+const DefaultLocale = "en"
+
+// the built-in messages by locale
+var mirrorTaskMessages = map[string]map[string]string{
+	"en": {
+		MsgMirrorTaskName:    `{{.List.Name}}: {{.Task.Name}}`,
+		MsgMirrorSubtaskName: `{{.Task.Name}}`,
+		MsgMirrorTaskDescription: `Mirror task from {{.Task.URL}} {{.TaskID}}
+NOTE: DO NOT EDIT - description auto-update from original task
+* * *
+{{.Description}}`,
+		MsgIntroComment: `The mirror task from {{.Task.URL}}
+{{if .AssignTo}}Must be assigned to {{.AssignTo}}
+{{end}}A ready go.
+
+NOTES: 
+- sets estimate in subtasks do not initiate a push into the original task (2022/01/18)
+- not always the due date is pushed into the orig task`,
+
+		MsgOrigChangesHeader:          `The original task has changed or differences with the mirror task:`,
+		MsgOrigChangedStatus:          `- changed task status name from {{printf "%q" .From}} to {{printf "%q" .To}}`,
+		MsgOrigClosed:                 `- closed`,
+		MsgOrigArchived:               `- archived`,
+		MsgOrigDeleted:                `- deleted`,
+		MsgOrigMovedToFolder:          `- moved to the folder {{printf "%q" .Folder.Name}}`,
+		MsgOrigMovedToList:            `- moved to the list {{printf "%q" .List.Name}}`,
+		MsgDiffEstimateRemoved:        `- different time estimate - should be equal to {{printf "%q" .To}} but nil`,
+		MsgDiffEstimateChanged:        `- different time estimate - equals {{.From}} but should be equal to {{.To}}`,
+		MsgDiffEstimateAdded:          `- different time estimate - should be nil but equals to {{printf "%q" .From}}`,
+		MsgDiffDueDateRemoved:         `- different due date - should be nil`,
+		MsgDiffDueDateChanged:         `- different due date - equals {{.From}} but should be equal to {{.To}}`,
+		MsgDiffDueDateAdded:           `- different due date - should be equal to {{.To}}`,
+		MsgDiffStartDateRemoved:       `- different start date - should be nil`,
+		MsgDiffStartDateChanged:       `- different start date - equals {{.From}} but should be equal to {{.To}}`,
+		MsgDiffStartDateAdded:         `- different start date - should be equal to {{.To}}`,
+		MsgMirrorHiddenButOrigChanged: `- mirror task is archived or closed but something has changed in original task {{.Task.URL}}`,
+
+		MsgMirrorChangesHeader:  `The mirror task has changed or differences with the original task (by main fields - estimate, due date, start date):`,
+		MsgMirrorRemoved:        `- mirror task has removed`,
+		MsgOrigEstimateRemoved:  `- orig task will be updated - time estimate will be removed`,
+		MsgOrigEstimateChanged:  `- orig task will be updated - time estimate will be sets to {{.To}}`,
+		MsgOrigDueDateRemoved:   `- orig task will be updated - due date will be removed`,
+		MsgOrigDueDateChanged:   `- orig task will be updated - due date will be sets to {{.To}}`,
+		MsgOrigStartDateRemoved: `- orig task will be updated - start date will be removed`,
+		MsgOrigStartDateChanged: `- orig task will be updated - start date will be sets to {{.To}}`,
+
+		MsgUnlinkMirrorHidden:        `UNLINK MIRROR TASK: the mirror task has been DELETED or HIDDEN`,
+		MsgUnlinkOrigHidden:          `UNLINK MIRROR TASK: the original task has been DELETED or HIDDEN`,
+		MsgUnlinkChangedHiddenMirror: `FYI changes have been made to a mirror task that is DELETED or HIDDEN - nothing will be updated in original tasks and UNLINK MIRROR TASK`,
+		MsgSubtaskMovedOut:           `The original subtask has been moved to the task without the mirror task (or is no longer a subtask) {{.Task.URL}}`,
+
+		MsgMirrorMoved:   `The original task has been moved to the list {{printf "%q" .List.Name}} - the mirror task has been moved by the rule {{printf "%q" .Rule.Name}}`,
+		MsgMirrorRetired: `The original task has been moved to the list {{printf "%q" .List.Name}} that is not synced - the mirror task is retired ({{.To}})`,
+
+		MsgExternalIssueCreated: `The issue has been created {{.Issue.URL}}`,
+		MsgExternalCommentToIssue: `**{{or .Comment.Author.Name .Comment.Author.ID}}** commented in {{.Task.URL}}
+
+{{.Comment.Body}}`,
+		MsgExternalCommentToTask: `{{or .Comment.Author.Name .Comment.Author.ID}} commented in {{.Issue.URL}}
+{{.Comment.Body}}`,
+		MsgExternalIssueRemoved: `UNLINK ISSUE: the issue {{.Issue.URL}} has been DELETED or TRANSFERRED`,
+	},
+	"ru": {
+		MsgMirrorTaskName:    `{{.List.Name}}: {{.Task.Name}}`,
+		MsgMirrorSubtaskName: `{{.Task.Name}}`,
+		MsgMirrorTaskDescription: `Зеркало задачи {{.Task.URL}} {{.TaskID}}
+ВНИМАНИЕ: НЕ РЕДАКТИРОВАТЬ - описание обновляется автоматически из оригинальной задачи
+* * *
+{{.Description}}`,
+		MsgIntroComment: `Зеркало задачи {{.Task.URL}}
+{{if .AssignTo}}Необходимо назначить на {{.AssignTo}}
+{{end}}Можно начинать.
+
+ЗАМЕТКИ: 
+- оценка времени в подзадачах не передается в оригинальную задачу (2022/01/18)
+- срок не всегда передается в оригинальную задачу`,
+
+		MsgOrigChangesHeader:          `Оригинальная задача изменилась или отличается от зеркала:`,
+		MsgOrigChangedStatus:          `- статус изменен с {{printf "%q" .From}} на {{printf "%q" .To}}`,
+		MsgOrigClosed:                 `- закрыта`,
+		MsgOrigArchived:               `- в архиве`,
+		MsgOrigDeleted:                `- удалена`,
+		MsgOrigMovedToFolder:          `- перемещена в папку {{printf "%q" .Folder.Name}}`,
+		MsgOrigMovedToList:            `- перемещена в список {{printf "%q" .List.Name}}`,
+		MsgDiffEstimateRemoved:        `- отличается оценка времени - должна быть {{printf "%q" .To}}, но не задана`,
+		MsgDiffEstimateChanged:        `- отличается оценка времени - равна {{.From}}, но должна быть {{.To}}`,
+		MsgDiffEstimateAdded:          `- отличается оценка времени - не должна быть задана, но равна {{printf "%q" .From}}`,
+		MsgDiffDueDateRemoved:         `- отличается срок - не должен быть задан`,
+		MsgDiffDueDateChanged:         `- отличается срок - равен {{.From}}, но должен быть {{.To}}`,
+		MsgDiffDueDateAdded:           `- отличается срок - должен быть {{.To}}`,
+		MsgDiffStartDateRemoved:       `- отличается дата начала - не должна быть задана`,
+		MsgDiffStartDateChanged:       `- отличается дата начала - равна {{.From}}, но должна быть {{.To}}`,
+		MsgDiffStartDateAdded:         `- отличается дата начала - должна быть {{.To}}`,
+		MsgMirrorHiddenButOrigChanged: `- зеркало в архиве или закрыто, но оригинальная задача изменилась {{.Task.URL}}`,
+
+		MsgMirrorChangesHeader:  `Зеркало изменилось или отличается от оригинальной задачи (по основным полям - оценка времени, срок, дата начала):`,
+		MsgMirrorRemoved:        `- зеркало удалено`,
+		MsgOrigEstimateRemoved:  `- в оригинальной задаче будет удалена оценка времени`,
+		MsgOrigEstimateChanged:  `- в оригинальной задаче будет установлена оценка времени {{.To}}`,
+		MsgOrigDueDateRemoved:   `- в оригинальной задаче будет удален срок`,
+		MsgOrigDueDateChanged:   `- в оригинальной задаче будет установлен срок {{.To}}`,
+		MsgOrigStartDateRemoved: `- в оригинальной задаче будет удалена дата начала`,
+		MsgOrigStartDateChanged: `- в оригинальной задаче будет установлена дата начала {{.To}}`,
+
+		MsgUnlinkMirrorHidden:        `ОТВЯЗКА ЗЕРКАЛА: зеркало УДАЛЕНО или СКРЫТО`,
+		MsgUnlinkOrigHidden:          `ОТВЯЗКА ЗЕРКАЛА: оригинальная задача УДАЛЕНА или СКРЫТА`,
+		MsgUnlinkChangedHiddenMirror: `К СВЕДЕНИЮ: изменено зеркало, которое УДАЛЕНО или СКРЫТО - оригинальная задача не будет обновлена, ОТВЯЗКА ЗЕРКАЛА`,
+		MsgSubtaskMovedOut:           `Оригинальная подзадача перемещена в задачу без зеркала (или больше не является подзадачей) {{.Task.URL}}`,
+
+		MsgMirrorMoved:   `Оригинальная задача перемещена в список {{printf "%q" .List.Name}} - зеркало перемещено по правилу {{printf "%q" .Rule.Name}}`,
+		MsgMirrorRetired: `Оригинальная задача перемещена в список {{printf "%q" .List.Name}}, который не синхронизируется - зеркало выведено из синхронизации ({{.To}})`,
+
+		MsgExternalIssueCreated: `Создана задача {{.Issue.URL}}`,
+		MsgExternalCommentToIssue: `**{{or .Comment.Author.Name .Comment.Author.ID}}** прокомментировал(а) в {{.Task.URL}}
+
+{{.Comment.Body}}`,
+		MsgExternalCommentToTask: `{{or .Comment.Author.Name .Comment.Author.ID}} прокомментировал(а) в {{.Issue.URL}}
+{{.Comment.Body}}`,
+		MsgExternalIssueRemoved: `ОТВЯЗКА ЗАДАЧИ: задача {{.Issue.URL}} УДАЛЕНА или ПЕРЕНЕСЕНА`,
+	},
+}
+
+// spec of the templates (text/template) of the names, descriptions and comments of the mirror tasks
+type SyncRule_SpecOfTemplates struct {
+	// locale of the built-in messages (available en, ru) (en by default)
+	Locale string `yaml:"locale,omitempty"`
+	// overrides the messages by key (see Msg* constants)
+	Messages map[string]string `yaml:"messages,omitempty"`
+}
+
+func (s *SyncRule_SpecOfTemplates) GetLocale() string {
+	if s == nil || s.Locale == "" {
+		return DefaultLocale
+	}
+	return s.Locale
+}
+
+// returns the template of the message by key
+// (overridden in spec or built-in for the locale or built-in for the default locale)
+func (s *SyncRule_SpecOfTemplates) message(key string) string {
+	if s != nil {
+		if msg, exists := s.Messages[key]; exists {
+			return msg
+		}
+	}
+	if msg, exists := mirrorTaskMessages[s.GetLocale()][key]; exists {
+		return msg
+	}
+	return mirrorTaskMessages[DefaultLocale][key]
+}
+
+// Validate returns error if the locale is not supported or any template is invalid.
+func (s *SyncRule_SpecOfTemplates) Validate() error {
+	if _, exists := mirrorTaskMessages[s.GetLocale()]; !exists {
+		return fmt.Errorf("not supported locale %q", s.GetLocale())
+	}
+	if s == nil {
+		return nil
+	}
+	for key, msg := range s.Messages {
+		if _, exists := mirrorTaskMessages[DefaultLocale][key]; !exists {
+			return fmt.Errorf("unknown message %q", key)
+		}
+		if _, err := template.New(key).Parse(msg); err != nil {
+			return fmt.Errorf("invalid template of message %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// the data of the templates of the messages
+type mirrorTaskTemplateData struct {
+	// the original task
+	Task *Task
+	// the mirror task (nil if not created yet)
+	MirrorTask *Task
+	Rule       *MirrorTaskSpecification
+	// the target list of the mirror task (nil if unknown)
+	Target *SyncRule_SpecOfAddTarget
+
+	// ID of the original task in the markdown format (CU-<ID>)
+	TaskID string
+	// description of the original task in the markdown format
+	Description string
+
+	// the values of the changed field (for the diff messages)
+	From string
+	To   string
+	// email of the member who must be assigned to the mirror task (for the intro comment)
+	AssignTo string
+
+	// the issue in the external tracker (for the external messages)
+	Issue *tracker.Task
+	// the mirrored comment (for the external messages)
+	Comment *tracker.Comment
+
+	ctx context.Context
+}
+
+// the location of the original task (loaded on demand)
+
+func (d *mirrorTaskTemplateData) List() *List {
+	return d.Task.GetList(d.ctx)
+}
+
+func (d *mirrorTaskTemplateData) Folder() *Folder {
+	return d.Task.GetFolder(d.ctx)
+}
+
+func (d *mirrorTaskTemplateData) Team() *Team {
+	return d.Task.GetTeam(d.ctx)
+}
+
+func newMirrorTaskTemplateData(ctx context.Context, rule *MirrorTaskSpecification, task, mirrorTask *Task) *mirrorTaskTemplateData {
+	var target *SyncRule_SpecOfAddTarget
+	if rule != nil && mirrorTask != nil && mirrorTask.ListRef != nil {
+		target = rule.SpecAdd.TargetByListID(mirrorTask.ListRef.ID)
+	}
+	return &mirrorTaskTemplateData{
+		Target:      target,
+		ctx:         ctx,
+		Task:        task,
+		MirrorTask:  mirrorTask,
+		Rule:        rule,
+		TaskID:      task.MarkdownTaskID(),
+		Description: task.DescriptionMarkdown(),
+	}
+}
+
+// with returns the copy of the data with the values of the changed field
+func (d mirrorTaskTemplateData) with(from, to string) *mirrorTaskTemplateData {
+	d.From = from
+	d.To = to
+	return &d
+}
+
+func (r *MirrorTaskSpecification) GetTemplates() *SyncRule_SpecOfTemplates {
+	if r == nil {
+		return nil
+	}
+	return r.Templates
+}
+
+// overriddenBy returns the templates overridden by the templates of the target
+func (s *SyncRule_SpecOfTemplates) overriddenBy(in *SyncRule_SpecOfTemplates) *SyncRule_SpecOfTemplates {
+	if in == nil {
+		return s
+	}
+	res := &SyncRule_SpecOfTemplates{
+		Locale:   s.GetLocale(),
+		Messages: map[string]string{},
+	}
+	if in.Locale != "" {
+		res.Locale = in.Locale
+	}
+	if s != nil {
+		for key, msg := range s.Messages {
+			res.Messages[key] = msg
+		}
+	}
+	for key, msg := range in.Messages {
+		res.Messages[key] = msg
+	}
+	return res
+}
+
+// renders the message by key
+func (s *SyncRule_SpecOfTemplates) render(key string, data *mirrorTaskTemplateData) (string, error) {
+	tpl, err := template.New(key).Parse(s.message(key))
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := tpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// returns the message by key for the rule (or the built-in message for the default locale if failed)
+func (s *mirrorTaskSyncer) message(rule *MirrorTaskSpecification, key string, data *mirrorTaskTemplateData) string {
+	msg, err := rule.GetTemplates().overriddenBy(data.Target.GetTemplates()).render(key, data)
+	if err == nil {
+		return msg
+	}
+	warnErrorIf(s.log, err, "failed to render the message by template", "key", key)
+	msg, err = (*SyncRule_SpecOfTemplates)(nil).render(key, data)
+	warnErrorIf(s.log, err, "failed to render the built-in message by template", "key", key)
+	return msg
+}
+
+// returns the name of the mirror task (subtask) in the target list for the original task
+func (s *mirrorTaskSyncer) mirrorTaskName(ctx context.Context, rule *MirrorTaskSpecification, target *SyncRule_SpecOfAddTarget, subtask bool, task *Task) string {
+	key := MsgMirrorTaskName
+	if subtask {
+		key = MsgMirrorSubtaskName
+	}
+	data := newMirrorTaskTemplateData(ctx, rule, task, nil)
+	data.Target = target
+	return s.message(rule, key, data)
+}
+
+// returns the description of the mirror task in the target list for the original task
+func (s *mirrorTaskSyncer) mirrorTaskDescription(ctx context.Context, rule *MirrorTaskSpecification, target *SyncRule_SpecOfAddTarget, task *Task) string {
+	data := newMirrorTaskTemplateData(ctx, rule, task, nil)
+	data.Target = target
+	return s.message(rule, MsgMirrorTaskDescription, data)
+}
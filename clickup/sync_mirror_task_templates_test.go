@@ -0,0 +1,111 @@
+package clickup
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSyncRule_SpecOfTemplates_render(t *testing.T) {
+	task := &Task{
+		Name:                "task",
+		URL:                 "https://app.clickup.com/t/abc",
+		Description:         "plain",
+		MarkdownDescription: "**bold**",
+		List:                &List{Name: "list"},
+	}
+	task.SetModelID("abc")
+	data := newMirrorTaskTemplateData(context.Background(), nil, task, nil)
+
+	tests := []struct {
+		name string
+		spec *SyncRule_SpecOfTemplates
+		key  string
+		data *mirrorTaskTemplateData
+		want string
+	}{
+		{"default name", nil, MsgMirrorTaskName, data, "list: task"},
+		{"default description", nil, MsgMirrorTaskDescription, data,
+			"Mirror task from https://app.clickup.com/t/abc CU-abc\nNOTE: DO NOT EDIT - description auto-update from original task\n* * *\n**bold**"},
+		{"diff", nil, MsgOrigChangedStatus, data.with("open", "done"), `- changed task status name from "open" to "done"`},
+		{"locale", &SyncRule_SpecOfTemplates{Locale: "ru"}, MsgOrigClosed, data, "- закрыта"},
+		{"override", &SyncRule_SpecOfTemplates{Locale: "ru", Messages: map[string]string{
+			MsgMirrorTaskName: "[{{.TaskID}}] {{.Task.Name}}",
+		}}, MsgMirrorTaskName, data, "[CU-abc] task"},
+		{"unknown locale", &SyncRule_SpecOfTemplates{Locale: "de"}, MsgOrigClosed, data, "- closed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.spec.render(tt.key, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncRule_SpecOfTemplates_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *SyncRule_SpecOfTemplates
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"locale", &SyncRule_SpecOfTemplates{Locale: "ru"}, false},
+		{"unknown locale", &SyncRule_SpecOfTemplates{Locale: "de"}, true},
+		{"unknown message", &SyncRule_SpecOfTemplates{Messages: map[string]string{"foo": "bar"}}, true},
+		{"invalid template", &SyncRule_SpecOfTemplates{Messages: map[string]string{MsgMirrorTaskName: "{{.Task"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.spec.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_mirrorTaskMessages(t *testing.T) {
+	for locale, messages := range mirrorTaskMessages {
+		for key := range mirrorTaskMessages[DefaultLocale] {
+			if _, exists := messages[key]; !exists {
+				t.Errorf("locale %q: missing message %q", locale, key)
+			}
+		}
+		for key := range messages {
+			spec := &SyncRule_SpecOfTemplates{Locale: locale, Messages: map[string]string{key: messages[key]}}
+			if err := spec.Validate(); err != nil {
+				t.Errorf("locale %q: %v", locale, err)
+			}
+		}
+	}
+}
+
+func TestSyncRule_SpecOfTemplates_overriddenBy(t *testing.T) {
+	rule := &SyncRule_SpecOfTemplates{Locale: "ru", Messages: map[string]string{
+		MsgMirrorTaskName:    "rule",
+		MsgMirrorSubtaskName: "rule subtask",
+	}}
+	target := &SyncRule_SpecOfTemplates{Messages: map[string]string{
+		MsgMirrorTaskName: "target",
+	}}
+
+	got := rule.overriddenBy(target)
+	if got.GetLocale() != "ru" {
+		t.Errorf("locale = %q, want ru", got.GetLocale())
+	}
+	if msg := got.message(MsgMirrorTaskName); msg != "target" {
+		t.Errorf("name = %q, want target", msg)
+	}
+	if msg := got.message(MsgMirrorSubtaskName); msg != "rule subtask" {
+		t.Errorf("subtask name = %q, want rule subtask", msg)
+	}
+	if rule.Messages[MsgMirrorTaskName] != "rule" {
+		t.Error("overriddenBy() must not modify the templates of the rule")
+	}
+	if got := (*SyncRule_SpecOfTemplates)(nil).overriddenBy(nil); got != nil {
+		t.Errorf("overriddenBy(nil) for nil templates = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,246 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gebv/asap-tools/clickup/api"
+	"go.uber.org/zap"
+)
+
+const (
+	TimeTrackingModeEntries     = "entries"
+	TimeTrackingModeCustomField = "custom_field"
+)
+
+// spec of the aggregation of the tracked time of the mirror task into the original task.
+// Synced only if allowed by the status of the mirror task (see sync_time_tracked in the mirror task statuses).
+type SyncRule_SpecOfTimeTracking struct {
+	// entries - copies each time entry of the mirror task to the original task (the changes and the removals of the time entries are applied to the copies)
+	// custom_field - sets the total tracked time of the mirror task to the custom field of the original task
+	Mode string `yaml:"mode"`
+	// ID of the custom field (number) of the original task (for mode custom_field)
+	CustomFieldID string `yaml:"custom_field_id,omitempty"`
+	// unit of the value of the custom field (available ms, minutes, hours) (hours by default)
+	CustomFieldUnit string `yaml:"custom_field_unit,omitempty"`
+	// includes the tracked time of the subtasks of the mirror task
+	IncludeSubtasks bool `yaml:"include_subtasks,omitempty"`
+}
+
+// returns the value of the custom field for the tracked time
+func (s *SyncRule_SpecOfTimeTracking) customFieldValue(trackedMs int64) float64 {
+	switch s.CustomFieldUnit {
+	case "ms":
+		return float64(trackedMs)
+	case "minutes":
+		return float64(trackedMs) / float64(time.Minute/time.Millisecond)
+	default:
+		return float64(trackedMs) / float64(time.Hour/time.Millisecond)
+	}
+}
+
+// syncTimeTracked aggregates the time entries of the mirror task (and its subtasks) into the original task.
+func (s *mirrorTaskSyncer) syncTimeTracked(ctx context.Context, opts *SyncPreferences, spec *SyncRule_SpecOfTimeTracking, mirror *MirrorTask, origTask, mirrorTask *Task) {
+	l := s.log.Named("sync_time_tracked").With(zap.String("model_id", mirror.ModelID()))
+
+	entries, ok := s.mirrorTimeEntries(ctx, spec, mirrorTask)
+	if !ok {
+		l.Warn("failed to get the time entries of the mirror task")
+		return
+	}
+
+	fields, changed := []string{}, false
+	switch spec.Mode {
+	case TimeTrackingModeEntries:
+		fields, changed = s.syncTimeEntries(ctx, opts, mirror, origTask, mirrorTask, entries)
+	case TimeTrackingModeCustomField:
+		total := int64(0)
+		for _, entry := range entries {
+			total += entry.DurationMs
+		}
+		if total == mirror.TimeTrackedMs || spec.CustomFieldID == "" {
+			break
+		}
+		res := s.api.SetCustomFieldValue(ctx, origTask.ID, spec.CustomFieldID, spec.customFieldValue(total))
+		warnIfFailedRequest(s.log, res)
+		if res.StatusOK() {
+			mirror.TimeTrackedMs = total
+			fields, changed = append(fields, "time_tracked"), true
+		}
+	default:
+		l.Warn("unknown mode of the sync of the tracked time", zap.String("mode", spec.Mode))
+		return
+	}
+
+	if !changed {
+		return
+	}
+	err := s.store.UpsertMirrorTask(ctx, mirror)
+	warnErrorIf(s.log, err, "failed to save the synced tracked time of the mirror task", "model_id", mirror.ModelID())
+	if len(fields) == 0 {
+		return
+	}
+	s.recordHistory(ctx, mirror, &MirrorTaskHistory{
+		Action:    MirrorTaskActionUpdated,
+		Direction: SyncDirectionToOrig,
+		TaskID:    origTask.ID,
+		Fields:    fields,
+	})
+}
+
+// syncTimeEntries copies the new time entries of the mirror task to the original task and
+// applies to the copies the changes (the description, the start and the duration) and the removals of the copied time entries.
+// Returns the synced fields and true if the pair has been changed.
+func (s *mirrorTaskSyncer) syncTimeEntries(ctx context.Context, opts *SyncPreferences, mirror *MirrorTask, origTask, mirrorTask *Task,
+	entries []api.TimeEntry) (_ []string, changed bool) {
+	if mirror.TimeEntryIDs == nil {
+		mirror.TimeEntryIDs = map[string]string{}
+	}
+	if mirror.TimeEntriesSynced == nil {
+		mirror.TimeEntriesSynced = map[string]MirrorTimeEntrySnapshot{}
+	}
+
+	fields := []string{}
+	exists := map[string]bool{}
+	for _, entry := range entries {
+		exists[entry.ID] = true
+		snapshot := MirrorTimeEntrySnapshot{
+			Description: entry.Description,
+			StartTs:     entry.StartTs,
+			DurationMs:  entry.DurationMs,
+			Billable:    entry.Billable,
+		}
+
+		if origEntryID, copied := mirror.TimeEntryIDs[entry.ID]; copied {
+			synced, known := mirror.TimeEntriesSynced[entry.ID]
+			if !known {
+				// copied before the values of the time entries were stored
+				mirror.TimeEntriesSynced[entry.ID] = snapshot
+				changed = true
+				continue
+			}
+			if synced == snapshot {
+				continue
+			}
+			res := s.api.UpdateTimeEntry(ctx, &api.UpdateTimeEntryRequest{
+				TeamID:      origTask.TeamID(),
+				TimeEntryID: origEntryID,
+				TaskID:      origTask.ID,
+				Description: origTimeEntryDescription(entry, mirrorTask),
+				StartTs:     entry.StartTs,
+				DurationMs:  entry.DurationMs,
+				Billable:    entry.Billable,
+			})
+			warnIfFailedRequest(s.log, res)
+			if res.StatusOK() {
+				mirror.TimeEntriesSynced[entry.ID] = snapshot
+				fields = append(fields, "time_entry_updated")
+			}
+			continue
+		}
+
+		req := &api.CreateTimeEntryRequest{
+			TeamID:      origTask.TeamID(),
+			TaskID:      origTask.ID,
+			Description: origTimeEntryDescription(entry, mirrorTask),
+			StartTs:     entry.StartTs,
+			DurationMs:  entry.DurationMs,
+			Billable:    entry.Billable,
+		}
+		if entry.User.Email != "" {
+			emails := opts.OrigMemberEmails([]string{entry.User.Email})
+			if memberID, ok := s.memberIDByEmail(ctx, emails[0]); ok {
+				req.AssigneeID = memberID
+			}
+		}
+
+		res := s.api.CreateTimeEntry(ctx, req)
+		warnIfFailedRequest(s.log, res)
+		if !res.StatusOK() {
+			continue
+		}
+		mirror.TimeEntryIDs[entry.ID] = res.TimeEntry.ID
+		mirror.TimeEntriesSynced[entry.ID] = snapshot
+		fields = append(fields, "time_entry")
+	}
+
+	// removed from the mirror task
+	for mirrorEntryID, origEntryID := range mirror.TimeEntryIDs {
+		if exists[mirrorEntryID] {
+			continue
+		}
+		res := s.api.DeleteTimeEntry(ctx, origTask.TeamID(), origEntryID)
+		warnIfFailedRequest(s.log, res)
+		// ClickUp API returns 404 if the time entry has been already removed
+		if !res.StatusOK() && !res.NotFound() {
+			continue
+		}
+		delete(mirror.TimeEntryIDs, mirrorEntryID)
+		delete(mirror.TimeEntriesSynced, mirrorEntryID)
+		fields = append(fields, "time_entry_removed")
+	}
+	return fields, changed || len(fields) > 0
+}
+
+// returns the description of the copy of the time entry of the mirror task
+func origTimeEntryDescription(entry api.TimeEntry, mirrorTask *Task) string {
+	return fmt.Sprintf("%s (from the mirror task %s by %s)", entry.Description, mirrorTask.URL, entry.User.Username)
+}
+
+// returns the stopped time entries of the mirror task (and subtasks if enabled in spec)
+func (s *mirrorTaskSyncer) mirrorTimeEntries(ctx context.Context, spec *SyncRule_SpecOfTimeTracking, mirrorTask *Task) ([]api.TimeEntry, bool) {
+	taskIDs := []string{mirrorTask.ID}
+	if spec.IncludeSubtasks {
+		for _, subtask := range mirrorTask.GetSubTasks() {
+			taskIDs = append(taskIDs, subtask.ID)
+		}
+	}
+
+	// the time entries of all members of the list of the mirror task
+	assigneeIDs := []string{}
+	members := s.api.ListMembersOfList(ctx, mirrorTask.ListRef.ID)
+	warnIfFailedRequest(s.log, members)
+	for _, member := range members.Members {
+		assigneeIDs = append(assigneeIDs, member.IDString())
+	}
+
+	res := []api.TimeEntry{}
+	for _, taskID := range taskIDs {
+		req := &api.SearchTimeEntriesRequest{
+			TeamID:      mirrorTask.TeamID(),
+			TaskID:      taskID,
+			AssigneeIDs: assigneeIDs,
+			// NOTE: by default ClickUp API returns the time entries for the last 30 days
+			StartTs: 1,
+			EndTs:   time.Now().Unix() * 1000,
+		}
+		entries := s.api.SearchTimeEntries(ctx, req)
+		warnIfFailedRequest(s.log, entries)
+		if !entries.StatusOK() {
+			return nil, false
+		}
+		for _, entry := range entries.TimeEntries {
+			if entry.Running() {
+				continue
+			}
+			res = append(res, entry)
+		}
+	}
+	return res, true
+}
+
+func (s *mirrorTaskSyncer) memberIDByEmail(ctx context.Context, email string) (int64, bool) {
+	member := s.store.MemberByEmail(ctx, email)
+	if !member.Exists() {
+		s.log.Warn("sync time tracking - not found member by email", zap.String("email", email))
+		return 0, false
+	}
+	memberID, err := strconv.ParseInt(member.ID, 10, 64)
+	if err != nil {
+		s.log.Warn("sync time tracking - invalid member ID", zap.String("member_id", member.ID), zap.Error(err))
+		return 0, false
+	}
+	return memberID, true
+}
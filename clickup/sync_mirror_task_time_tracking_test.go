@@ -0,0 +1,121 @@
+package clickup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSyncRule_SpecOfTimeTracking_customFieldValue(t *testing.T) {
+	tests := []struct {
+		unit string
+		ms   int64
+		want float64
+	}{
+		{"", 5400000, 1.5},
+		{"hours", 3600000, 1},
+		{"minutes", 90000, 1.5},
+		{"ms", 1500, 1500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			spec := &SyncRule_SpecOfTimeTracking{CustomFieldUnit: tt.unit}
+			if got := spec.customFieldValue(tt.ms); got != tt.want {
+				t.Errorf("customFieldValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirrorTaskSyncer_SyncTimeEntries(t *testing.T) {
+	env := newSyncEnv(t)
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+	mirrorTask := env.fake.addTask(&fakeTask{Name: "Task", ListID: "20"})
+	design := env.fake.addTimeEntry(&fakeTimeEntry{TaskID: mirrorTask.ID, Description: "design", StartTs: 1000, DurationMs: 60000, Username: "dev"})
+	review := env.fake.addTimeEntry(&fakeTimeEntry{TaskID: mirrorTask.ID, Description: "review", StartTs: 2000, DurationMs: 120000, Username: "dev"})
+
+	opts := &SyncPreferences{}
+	spec := &SyncRule_SpecOfTimeTracking{Mode: TimeTrackingModeEntries}
+	mirror := env.store.ModelMirrorTaskFor(orig.ID, mirrorTask.ID)
+	syncer := MirrorTaskSyncer(env.api, env.store, nil)
+	sync := func() {
+		syncer.syncTimeTracked(env.ctx, opts, spec, mirror, env.load(orig.ID), env.load(mirrorTask.ID))
+	}
+	from := " (from the mirror task https://app.clickup.com/t/" + mirrorTask.ID + " by dev)"
+
+	sync()
+	if got, want := env.fake.timeEntriesOf(orig.ID), []string{"60000:design" + from, "120000:review" + from}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("the time entries of the original task = %v, want %v", got, want)
+	}
+
+	// edited, removed and running time entries
+	env.fake.timeEntry(design.ID).DurationMs = 90000
+	env.fake.timeEntry(design.ID).Description = "design v2"
+	env.fake.removeTimeEntry(review.ID)
+	env.fake.addTimeEntry(&fakeTimeEntry{TaskID: mirrorTask.ID, Description: "running", StartTs: 3000, DurationMs: -1, Username: "dev"})
+	sync()
+	if got, want := env.fake.timeEntriesOf(orig.ID), []string{"90000:design v2" + from}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("the time entries of the original task = %v, want %v", got, want)
+	}
+
+	// nothing is changed
+	requests := env.fake.countRequests("POST /team/") + env.fake.countRequests("PUT /team/") + env.fake.countRequests("DELETE /team/")
+	sync()
+	if got := env.fake.countRequests("POST /team/") + env.fake.countRequests("PUT /team/") + env.fake.countRequests("DELETE /team/"); got != requests {
+		t.Errorf("the not changed time entries are synced again (%d requests)", got-requests)
+	}
+
+	// the copy has been removed in the original task before the time entry of the mirror task
+	for _, entry := range env.fake.sortedTimeEntries(orig.ID) {
+		env.fake.removeTimeEntry(entry.ID)
+	}
+	env.fake.removeTimeEntry(design.ID)
+	sync()
+	stored := env.store.GetMirrorTask(env.ctx, mirror.ModelID())
+	if len(stored.TimeEntryIDs) != 0 || len(stored.TimeEntriesSynced) != 0 {
+		t.Errorf("the stored time entries of the pair = %v %v, want empty", stored.TimeEntryIDs, stored.TimeEntriesSynced)
+	}
+
+	fields := [][]string{}
+	for _, entry := range env.store.ListMirrorTaskHistory(env.ctx, mirror) {
+		fields = append(fields, entry.Fields)
+	}
+	want := [][]string{
+		{"time_entry", "time_entry"},
+		{"time_entry_updated", "time_entry_removed"},
+		{"time_entry_removed"},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("the history of the pair = %v, want %v", fields, want)
+	}
+}
+
+func TestMirrorTaskSyncer_SyncTimeEntries_copiedBefore(t *testing.T) {
+	env := newSyncEnv(t)
+	orig := env.fake.addTask(&fakeTask{Name: "Task", ListID: "10"})
+	mirrorTask := env.fake.addTask(&fakeTask{Name: "Task", ListID: "20"})
+	entry := env.fake.addTimeEntry(&fakeTimeEntry{TaskID: mirrorTask.ID, Description: "design", StartTs: 1000, DurationMs: 60000})
+	copied := env.fake.addTimeEntry(&fakeTimeEntry{TaskID: orig.ID, Description: "design (copy)", StartTs: 1000, DurationMs: 60000})
+
+	// the pair without the values of the copied time entries
+	mirror := env.store.ModelMirrorTaskFor(orig.ID, mirrorTask.ID)
+	mirror.TimeEntryIDs = map[string]string{entry.ID: copied.ID}
+	spec := &SyncRule_SpecOfTimeTracking{Mode: TimeTrackingModeEntries}
+	syncer := MirrorTaskSyncer(env.api, env.store, nil)
+	syncer.syncTimeTracked(env.ctx, &SyncPreferences{}, spec, mirror, env.load(orig.ID), env.load(mirrorTask.ID))
+
+	if got := env.fake.countRequests("PUT /team/") + env.fake.countRequests("POST /team/"); got != 0 {
+		t.Errorf("the time entry copied before is synced again (%d requests)", got)
+	}
+	stored := env.store.GetMirrorTask(env.ctx, mirror.ModelID())
+	want := MirrorTimeEntrySnapshot{Description: "design", StartTs: 1000, DurationMs: 60000}
+	if got := stored.TimeEntriesSynced[entry.ID]; got != want {
+		t.Errorf("the stored values of the time entry = %+v, want %+v", got, want)
+	}
+
+	// the next changes are applied to the copy
+	env.fake.timeEntry(entry.ID).DurationMs = 30000
+	syncer.syncTimeTracked(env.ctx, &SyncPreferences{}, spec, mirror, env.load(orig.ID), env.load(mirrorTask.ID))
+	if got := env.fake.timeEntry(copied.ID).DurationMs; got != 30000 {
+		t.Errorf("the duration of the copy = %d, want 30000", got)
+	}
+}
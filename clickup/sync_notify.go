@@ -0,0 +1,230 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+	"github.com/gebv/asap-tools/webhook"
+	"go.uber.org/zap"
+)
+
+// the messenger of the notifications by default
+const DefaultMessenger = "telegram"
+
+// spec for the notifications about the changes of the tasks of the rule to the chats of the messenger
+type SyncRule_SpecOfNotify struct {
+	// name of the messenger (telegram by default)
+	Messenger string `yaml:"messenger,omitempty"`
+	// the chats of the messenger (eg the chat IDs of Telegram)
+	Chats []string `yaml:"chats"`
+	// the kinds of the events (status, assignees, due_date, estimate, mirror_added, unlinked, approval) (all by default)
+	Events []string `yaml:"events,omitempty"`
+	// the changes are sent as one message per chat at the end of the processing (the digest)
+	Digest bool `yaml:"digest,omitempty"`
+}
+
+// Validate returns error if the chats are not specified or the event is unknown.
+func (s *SyncRule_SpecOfNotify) Validate() error {
+	if len(s.Chats) == 0 {
+		return fmt.Errorf("chats are required")
+	}
+	for _, kind := range s.Events {
+		if !containsString(notify.EventKinds, kind) {
+			return fmt.Errorf("unknown event %q", kind)
+		}
+	}
+	return nil
+}
+
+func (s *SyncRule_SpecOfNotify) GetMessenger() string {
+	if s.Messenger == "" {
+		return DefaultMessenger
+	}
+	return strings.ToLower(s.Messenger)
+}
+
+// returns true if the event is sent to the chats
+func (s *SyncRule_SpecOfNotify) allowed(kind string) bool {
+	return len(s.Events) == 0 || containsString(s.Events, kind)
+}
+
+// returns the notifier of the changes of the tasks (nil if no notifiers and no webhooks)
+func newTaskNotifier(opts *SyncPreferences, store *Storage, notifiers map[string]*notify.Notifier, webhooks *webhook.Dispatcher) *taskNotifier {
+	if len(notifiers) == 0 && webhooks == nil {
+		return nil
+	}
+	return &taskNotifier{
+		opts:      opts,
+		store:     store,
+		notifiers: notifiers,
+		webhooks:  webhooks,
+		log:       zap.L().Named("sync_notify"),
+	}
+}
+
+type taskNotifier struct {
+	opts  *SyncPreferences
+	store *Storage
+	// the notifiers by the messengers
+	notifiers map[string]*notify.Notifier
+	// the dispatcher of the webhooks of the rules
+	webhooks *webhook.Dispatcher
+	log      *zap.Logger
+}
+
+// Sync sends the changes of the status, the assignees and the due date of the original tasks matched by the rules.
+func (s *taskNotifier) Sync(ctx context.Context, opts *SyncPreferences, oldTask, task *Task, changed bool) {
+	if s == nil || !changed || !oldTask.Exists() {
+		return
+	}
+
+	events := taskChangeEvents(oldTask, task)
+	if len(events) == 0 {
+		return
+	}
+
+	rules := matchedRules(opts.MirrorTaskRules, task)
+	notified := map[string]bool{}
+	for _, list := range [][]MirrorTaskSpecification{rules.addRules, rules.changedRules} {
+		for idx := range list {
+			rule := &list[idx]
+			if notified[rule.Name] {
+				continue
+			}
+			notified[rule.Name] = true
+			if len(rule.Notify) == 0 && len(rule.Webhooks) == 0 {
+				continue
+			}
+			mirror := s.rulePair(ctx, rule.Name, task)
+			for _, event := range events {
+				if mirror != nil {
+					event.MirrorURL = mirror.GetMirrorTask(ctx).URL
+					event.Actions = pairActions(mirror)
+				}
+				s.notify(ctx, rule, task, event)
+			}
+		}
+	}
+}
+
+// notifyByRuleName sends the event about the task to the chats of the rule by name
+func (s *taskNotifier) notifyByRuleName(ctx context.Context, ruleName string, task *Task, event *notify.Event) {
+	if s == nil {
+		return
+	}
+	if rule := s.opts.RuleByName(ruleName); rule != nil {
+		s.notify(ctx, rule, task, event)
+	}
+}
+
+// sends the event about the task to the chats and the webhooks of the rule (if the event is allowed)
+func (s *taskNotifier) notify(ctx context.Context, rule *MirrorTaskSpecification, task *Task, event *notify.Event) {
+	if s == nil {
+		return
+	}
+	routed := *event
+	routed.Rule = rule.Name
+	if task != nil {
+		routed.TaskID = task.ID
+		routed.Title = task.Name
+		routed.URL = task.URL
+	}
+	for idx := range rule.Notify {
+		spec := &rule.Notify[idx]
+		if !spec.allowed(event.Kind) {
+			continue
+		}
+		notifier := s.notifiers[spec.GetMessenger()]
+		if notifier == nil {
+			s.log.Warn("not registered messenger", zap.String("messenger", spec.GetMessenger()), zap.String("rule_name", rule.Name))
+			continue
+		}
+		for _, chatID := range spec.Chats {
+			err := notifier.Notify(ctx, chatID, &routed, spec.Digest)
+			warnErrorIf(s.log, err, "failed to send the notification", "chat_id", chatID, "rule_name", rule.Name, "event", event.Kind)
+		}
+	}
+	s.dispatchWebhooks(ctx, rule, &routed)
+}
+
+// returns the active pair of the rule in which the task is the original task (nil if not found)
+func (s *taskNotifier) rulePair(ctx context.Context, ruleName string, task *Task) *MirrorTask {
+	if s.store == nil {
+		return nil
+	}
+	mirrorList, _ := s.store.AllMatchesForMirrorTasks(ctx, task.ID)
+	for _, mirror := range mirrorList {
+		if !mirror.Destroyed && !mirror.Subtask && mirror.Orig.ID == task.ID && mirror.RuleName == ruleName {
+			return mirror
+		}
+	}
+	return nil
+}
+
+// returns the actions on the pair (unlink and resync)
+func pairActions(mirror *MirrorTask) []notify.Action {
+	return []notify.Action{
+		{Kind: notify.ActionUnlink, Value: mirror.ModelID()},
+		{Kind: notify.ActionResync, Value: mirror.ModelID()},
+	}
+}
+
+// returns the events by the changes of the task
+func taskChangeEvents(oldTask, task *Task) []*notify.Event {
+	events := []*notify.Event{}
+	if !strings.EqualFold(oldTask.StatusName, task.StatusName) {
+		events = append(events, &notify.Event{Kind: notify.EventStatus, From: oldTask.StatusName, To: task.StatusName})
+	}
+
+	oldAssignees, assignees := oldTask.AssigneeEmails(), task.AssigneeEmails()
+	sort.Strings(oldAssignees)
+	sort.Strings(assignees)
+	if strings.Join(oldAssignees, ",") != strings.Join(assignees, ",") {
+		events = append(events, &notify.Event{
+			Kind: notify.EventAssignees,
+			From: strings.Join(oldAssignees, ", "),
+			To:   strings.Join(assignees, ", "),
+		})
+	}
+
+	if oldDueDate, dueDate := timeFromTimestamp(oldTask.DueDateAt), timeFromTimestamp(task.DueDateAt); !equalTime(oldDueDate, dueDate) {
+		events = append(events, &notify.Event{Kind: notify.EventDueDate, From: formatDate(oldDueDate), To: formatDate(dueDate)})
+	}
+
+	if !equalInt64(oldTask.TimeEstimateMs, task.TimeEstimateMs) {
+		events = append(events, &notify.Event{
+			Kind: notify.EventEstimate,
+			From: formatEstimate(oldTask.TimeEstimateMs),
+			To:   formatEstimate(task.TimeEstimateMs),
+		})
+	}
+	return events
+}
+
+// returns the time estimate as the hours and the minutes (eg 2h30m) or "" if nil
+func formatEstimate(in *int64) string {
+	if in == nil {
+		return ""
+	}
+	d := (time.Duration(*in) * time.Millisecond).Round(time.Minute)
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// returns the date in the RFC3339 format or "" if nil
+func formatDate(in *time.Time) string {
+	if in == nil {
+		return ""
+	}
+	return in.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,127 @@
+package clickup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+	"github.com/gebv/asap-tools/webhook"
+)
+
+func newTestNotifyTask(status string, dueDate *time.Time, emails ...string) *Task {
+	task := &Task{StatusName: status}
+	if dueDate != nil {
+		task.DueDateAt = TimestampFromTime(*dueDate)
+	}
+	for _, email := range emails {
+		task.Assignees = append(task.Assignees, &Member{Email: email})
+	}
+	task.lazyLoadAssignees = func() {}
+	return task
+}
+
+func withEstimate(task *Task, estimate time.Duration) *Task {
+	ms := estimate.Milliseconds()
+	task.TimeEstimateMs = &ms
+	return task
+}
+
+func TestTaskChangeEvents(t *testing.T) {
+	dueDate := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		oldTask, task *Task
+		want          []notify.Event
+	}{
+		{
+			"not changed",
+			newTestNotifyTask("open", &dueDate, "b@x.com", "a@x.com"),
+			newTestNotifyTask("Open", &dueDate, "A@x.com", "b@x.com"),
+			[]notify.Event{},
+		},
+		{
+			"changed",
+			newTestNotifyTask("open", nil, "a@x.com"),
+			newTestNotifyTask("in progress", &dueDate, "b@x.com", "a@x.com"),
+			[]notify.Event{
+				{Kind: notify.EventStatus, From: "open", To: "in progress"},
+				{Kind: notify.EventAssignees, From: "a@x.com", To: "a@x.com, b@x.com"},
+				{Kind: notify.EventDueDate, From: "", To: "2022-02-01T10:00:00Z"},
+			},
+		},
+		{
+			"estimate",
+			withEstimate(newTestNotifyTask("open", nil), 90*time.Minute),
+			withEstimate(newTestNotifyTask("open", nil), 2*time.Hour),
+			[]notify.Event{
+				{Kind: notify.EventEstimate, From: "1h30m", To: "2h"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []notify.Event{}
+			for _, event := range taskChangeEvents(tt.oldTask, tt.task) {
+				got = append(got, *event)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("taskChangeEvents() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncRule_SpecOfNotify_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    SyncRule_SpecOfNotify
+		wantErr bool
+	}{
+		{"all events", SyncRule_SpecOfNotify{Chats: []string{"-100"}}, false},
+		{"events", SyncRule_SpecOfNotify{Chats: []string{"-100"}, Events: []string{notify.EventStatus, notify.EventUnlinked}}, false},
+		{"without chats", SyncRule_SpecOfNotify{}, true},
+		{"unknown event", SyncRule_SpecOfNotify{Chats: []string{"-100"}, Events: []string{"priority"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.spec.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSyncRule_SpecOfWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    SyncRule_SpecOfWebhook
+		wantErr bool
+	}{
+		{"all events", SyncRule_SpecOfWebhook{URL: "https://example.com/hook"}, false},
+		{"events", SyncRule_SpecOfWebhook{URL: "http://localhost:8080/hook", Events: []string{webhook.EventMirrorCreated, webhook.EventTaskEstimateChanged}}, false},
+		{"without url", SyncRule_SpecOfWebhook{}, true},
+		{"not http", SyncRule_SpecOfWebhook{URL: "ftp://example.com"}, true},
+		{"kind of notify", SyncRule_SpecOfWebhook{URL: "https://example.com/hook", Events: []string{notify.EventStatus}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.spec.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMirrorTaskApproval_ParseID(t *testing.T) {
+	approval := (&Storage{}).ModelMirrorTaskApprovalFor("abc", "174318787")
+	taskID, listID, err := MirrorTaskApprovalModel.ParseID(approval.ModelID())
+	if err != nil || taskID != "abc" || listID != "174318787" {
+		t.Errorf("ParseID(%q) = %q, %q, %v", approval.ModelID(), taskID, listID, err)
+	}
+	for _, in := range []string{"", "src:abc:dst:1", "src::list:1", "src:abc:list:"} {
+		if _, _, err := MirrorTaskApprovalModel.ParseID(in); err == nil {
+			t.Errorf("ParseID(%q) without error", in)
+		}
+	}
+}
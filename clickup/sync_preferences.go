@@ -1,6 +1,7 @@
 package clickup
 
 import (
+	"fmt"
 	"io"
 	"net/url"
 	"strconv"
@@ -16,13 +17,98 @@ func ParseSyncPreferences(in io.Reader) (*SyncPreferences, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
 	return res, nil
 }
 
+// Validate returns error if the spec of sync is invalid.
+func (s *SyncPreferences) Validate() error {
+	for _, rule := range s.MirrorTaskRules {
+		if err := rule.GetTemplates().Validate(); err != nil {
+			return fmt.Errorf("rule %q: templates: %w", rule.Name, err)
+		}
+		for _, target := range rule.GetSpecAdd().AllTargets() {
+			if err := target.GetTemplates().Validate(); err != nil {
+				return fmt.Errorf("rule %q: spec_add.targets %q: templates: %w", rule.Name, target.AddToList, err)
+			}
+		}
+		if err := rule.SpecMove.Validate(); err != nil {
+			return fmt.Errorf("rule %q: spec_move: %w", rule.Name, err)
+		}
+		if err := rule.SpecLifecycle.Validate(); err != nil {
+			return fmt.Errorf("rule %q: spec_lifecycle: %w", rule.Name, err)
+		}
+		for _, target := range rule.GetSpecAdd().GetExternal() {
+			if err := target.Validate(); err != nil {
+				return fmt.Errorf("rule %q: spec_add.external: %w", rule.Name, err)
+			}
+		}
+		if len(rule.GetSpecAdd().GetExternal()) > 0 {
+			// the external trackers are destination-only (the issues are created from the original tasks in ClickUp)
+			if err := rule.CondAdd.validateClickUpLocations(); err != nil {
+				return fmt.Errorf("rule %q: cond_add: %w (the original tasks of the external trackers must be in ClickUp)", rule.Name, err)
+			}
+		}
+		for _, spec := range rule.Notify {
+			if err := spec.Validate(); err != nil {
+				return fmt.Errorf("rule %q: notify: %w", rule.Name, err)
+			}
+		}
+		for _, spec := range rule.Webhooks {
+			if err := spec.Validate(); err != nil {
+				return fmt.Errorf("rule %q: webhooks: %w", rule.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 type SyncPreferences struct {
 	MirrorTaskRules []MirrorTaskSpecification `yaml:"mirror_task_rules"`
 	// ASSERTS: all mirror tasks have the same status life cycle
 	GlobalMirrorTaskStatuses MirrorTaskStatuses `yaml:"global_mirror_task_statuses"`
+	// the identity map of the members between the team with the original tasks and the team with the mirror tasks.
+	// If the member is not in the map then the member is matched by the same email.
+	MemberMap []MemberMapping `yaml:"member_map,omitempty"`
+}
+
+type MemberMapping struct {
+	OrigEmail   string `yaml:"orig_email"`
+	MirrorEmail string `yaml:"mirror_email"`
+}
+
+// MirrorMemberEmails returns the emails of the members in the team with the mirror tasks for the emails of the members in the team with the original tasks.
+func (s *SyncPreferences) MirrorMemberEmails(origEmails []string) []string {
+	res := []string{}
+	for _, email := range origEmails {
+		mapped := email
+		for _, item := range s.MemberMap {
+			if strings.EqualFold(item.OrigEmail, email) {
+				mapped = item.MirrorEmail
+				break
+			}
+		}
+		res = append(res, strings.ToLower(mapped))
+	}
+	return res
+}
+
+// OrigMemberEmails returns the emails of the members in the team with the original tasks for the emails of the members in the team with the mirror tasks.
+func (s *SyncPreferences) OrigMemberEmails(mirrorEmails []string) []string {
+	res := []string{}
+	for _, email := range mirrorEmails {
+		mapped := email
+		for _, item := range s.MemberMap {
+			if strings.EqualFold(item.MirrorEmail, email) {
+				mapped = item.OrigEmail
+				break
+			}
+		}
+		res = append(res, strings.ToLower(mapped))
+	}
+	return res
 }
 
 type MirrorTaskStatuses map[string]MirrorTaskStatus
@@ -36,6 +122,15 @@ func (s MirrorTaskStatuses) AllowedSyncEstimate(mirrorTaskStatus string) bool {
 	return rule.SyncEstimateAndDueDateToOrigTask
 }
 
+func (s MirrorTaskStatuses) AllowedSyncTimeTracked(mirrorTaskStatus string) bool {
+	mirrorTaskStatus = strings.ToLower(mirrorTaskStatus)
+	rule, exists := s[mirrorTaskStatus]
+	if !exists {
+		return false
+	}
+	return rule.SyncTimeTrackedToOrigTask
+}
+
 // returns "" if nothing needs to be done
 func (s MirrorTaskStatuses) SetStatusToOrigTaskIfExists(mirrorTaskStatus string) string {
 	mirrorTaskStatus = strings.ToLower(mirrorTaskStatus)
@@ -49,6 +144,23 @@ func (s MirrorTaskStatuses) SetStatusToOrigTaskIfExists(mirrorTaskStatus string)
 type MirrorTaskStatus struct {
 	SyncEstimateAndDueDateToOrigTask bool   `yaml:"sync_estimate"`
 	SetStatusToOriginalTask          string `yaml:"orig_task_status"`
+	SyncTimeTrackedToOrigTask        bool   `yaml:"sync_time_tracked,omitempty"`
+	// the rank of the status in the life cycle (the more advanced status has the higher rank),
+	// used for the aggregation of the statuses of the multiple mirror tasks (see status_aggregation)
+	Rank int `yaml:"rank,omitempty"`
+}
+
+// RuleByName returns the mirror task rule by name or nil if not found.
+func (s *SyncPreferences) RuleByName(name string) *MirrorTaskSpecification {
+	if name == "" {
+		return nil
+	}
+	for idx := range s.MirrorTaskRules {
+		if s.MirrorTaskRules[idx].Name == name {
+			return &s.MirrorTaskRules[idx]
+		}
+	}
+	return nil
 }
 
 func (s *SyncPreferences) AllUsedTeamIDs() []string {
@@ -74,7 +186,7 @@ func folderIDFromURL(in string) string {
 	}
 
 	args := strings.Split(parse.Path, "/")
-	if len(args) < 4 {
+	if len(args) < 5 {
 		zap.L().Warn("Failed to get folder ID (invalid format url?)", zap.String("url", in))
 		return ""
 	}
@@ -111,7 +223,7 @@ func listIDFromURL(in string) string {
 	}
 
 	args := strings.Split(parse.Path, "/")
-	if len(args) < 4 {
+	if len(args) < 5 {
 		zap.L().Warn("Failed to get list ID (invalid format url?)", zap.String("url", in))
 		return ""
 	}
@@ -0,0 +1,75 @@
+package clickup
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/gebv/asap-tools/notify"
+	"github.com/gebv/asap-tools/webhook"
+)
+
+// spec for the outbound webhooks of the events of the tasks of the rule
+type SyncRule_SpecOfWebhook struct {
+	// the endpoint of the POST requests with the JSON events
+	URL string `yaml:"url"`
+	// the secret of the signature of the body in X-Signature header (HMAC-SHA256 in hex, as ClickUp signs the webhooks),
+	// the environment variables are expanded (eg ${WEBHOOK_SECRET})
+	Secret string `yaml:"secret,omitempty"`
+	// the types of the events (mirror.created, mirror.unlinked, mirror.approval_required,
+	// task.status_changed, task.assignees_changed, task.due_date_changed, task.estimate_changed) (all by default)
+	Events []string `yaml:"events,omitempty"`
+}
+
+// Validate returns error if the URL is invalid or the event is unknown.
+func (s *SyncRule_SpecOfWebhook) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q", s.URL)
+	}
+	for _, eventType := range s.Events {
+		if !containsString(webhook.EventTypes, eventType) {
+			return fmt.Errorf("unknown event %q", eventType)
+		}
+	}
+	return nil
+}
+
+// returns true if the event is sent to the endpoint
+func (s *SyncRule_SpecOfWebhook) allowed(eventType string) bool {
+	return len(s.Events) == 0 || containsString(s.Events, eventType)
+}
+
+func (s *SyncRule_SpecOfWebhook) endpoint() webhook.Endpoint {
+	return webhook.Endpoint{URL: s.URL, Secret: webhook.ExpandSecret(s.Secret)}
+}
+
+// WebhookEndpoint returns the endpoint of the webhook of the rule by URL (eg for the redelivery).
+func (s *SyncPreferences) WebhookEndpoint(ruleName, endpointURL string) (webhook.Endpoint, bool) {
+	rule := s.RuleByName(ruleName)
+	if rule == nil {
+		return webhook.Endpoint{}, false
+	}
+	for idx := range rule.Webhooks {
+		if rule.Webhooks[idx].URL == endpointURL {
+			return rule.Webhooks[idx].endpoint(), true
+		}
+	}
+	return webhook.Endpoint{}, false
+}
+
+// queues the event about the task to the webhooks of the rule (if the event is allowed), the deliveries are sent by FlushNotifications
+func (s *taskNotifier) dispatchWebhooks(ctx context.Context, rule *MirrorTaskSpecification, event *notify.Event) {
+	if s.webhooks == nil || len(rule.Webhooks) == 0 {
+		return
+	}
+	for idx := range rule.Webhooks {
+		spec := &rule.Webhooks[idx]
+		payload := webhook.NewPayload(event)
+		if payload == nil || !spec.allowed(payload.Type) {
+			continue
+		}
+		err := s.webhooks.Enqueue(ctx, spec.endpoint(), payload)
+		warnErrorIf(s.log, err, "failed to queue the webhook", "url", spec.URL, "rule_name", rule.Name, "event", payload.Type)
+	}
+}
@@ -6,14 +6,27 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gebv/asap-tools/clickup"
 	clickupAPI "github.com/gebv/asap-tools/clickup/api"
+	"github.com/gebv/asap-tools/github"
+	"github.com/gebv/asap-tools/gitlab"
+	"github.com/gebv/asap-tools/jira"
+	"github.com/gebv/asap-tools/lastfm"
+	"github.com/gebv/asap-tools/linear"
 	"github.com/gebv/asap-tools/logger"
+	"github.com/gebv/asap-tools/music"
+	"github.com/gebv/asap-tools/notion"
+	"github.com/gebv/asap-tools/slack"
+	"github.com/gebv/asap-tools/spotify"
 	"github.com/gebv/asap-tools/storage"
+	"github.com/gebv/asap-tools/telegram"
 	"github.com/gebv/asap-tools/version"
+	"github.com/gebv/asap-tools/webhook"
 
 	"cloud.google.com/go/firestore"
 	"github.com/kelseyhightower/envconfig"
@@ -30,6 +43,36 @@ var (
 	clickupDebugExampleSpecF   = clickupCommands.Bool("debug-example-spec", false, "Shows an example of a spec of sync in yaml format.")
 	clickupRecentActivitySyncF = clickupCommands.Bool("recent-activity-sync", false, "Regular procedure for loading changed tasks from ClickUp API and processing.")
 	clickupDBSyncF             = clickupCommands.Bool("db-sync", false, "Foce loads all tasks from the database, loads actual data from the ClickUp API and processing. To use if the spec of sync file has been changed.")
+	clickupListDestroyedF      = clickupCommands.Bool("list-destroyed", false, "Shows the destroyed (unlinked) pairs of the mirror tasks with the reasons.")
+	clickupRestoreF            = clickupCommands.String("restore", "", "Restores the destroyed pair of the mirror tasks by ID (src:<TaskID>:dst:<MirrorTaskID>) and syncs the pair.")
+	clickupLinkF               = clickupCommands.String("link", "", "Links the existing task as the mirror task of the original task by ID (src:<TaskID>:dst:<MirrorTaskID>) and syncs the pair.")
+	clickupLinkRuleF           = clickupCommands.String("link-rule", "", "Name of the rule for the linked pair (see -link).")
+	clickupExternalSyncF       = clickupCommands.Bool("external-sync", false, "Regular procedure for loading changes of the issues in the external trackers (GitHub Issues, GitLab Issues, Notion, Jira, Linear) and applying them to the original tasks.")
+	clickupListenF             = clickupCommands.String("listen", "", "Address of HTTP server for the webhooks of the external trackers and the interactions of the notifications (eg :8080, GitLab events are received on /webhooks/gitlab, the buttons of Slack on /slack/interactions).")
+	clickupWebhooksFailedF     = clickupCommands.Bool("webhooks-failed", false, "Shows the failed deliveries of the webhooks of the rules (see webhooks of the rules).")
+	clickupWebhooksRedeliverF  = clickupCommands.String("webhooks-redeliver", "", "Redelivers the failed delivery of the webhook by ID or all failed deliveries by \"all\".")
+
+	slackCommands       = flag.NewFlagSet("slack", flag.ExitOnError)
+	slackArchiveF       = slackCommands.Bool("archive", false, "Regular procedure for saving the new messages, threads and reactions of the conversations (channels, private channels, direct messages) from Slack API.")
+	slackTypesF         = slackCommands.String("types", strings.Join(slack.ConversationTypes, ","), "Types of the archived conversations (comma separated).")
+	slackConversationsF = slackCommands.String("conversations", "", "Only the conversations by ID or #name (comma separated), all conversations by default.")
+	slackThreadsWindowF = slackCommands.Duration("threads-window", slack.DefaultThreadsWindow, "The threads are checked for the new replies while the parent message is younger.")
+	slackListF          = slackCommands.Bool("list", false, "Shows the archived conversations.")
+	slackExportF        = slackCommands.String("export", "", "Exports the archived conversation by ID (see -format and -out).")
+	slackExportFormatF  = slackCommands.String("format", slack.ExportMarkdown, "Format of the export (json, markdown, html).")
+	slackExportOutF     = slackCommands.String("out", "", "File of the export (stdout by default).")
+
+	telegramCommands      = flag.NewFlagSet("telegram", flag.ExitOnError)
+	telegramBackupF       = telegramCommands.Bool("backup", false, "Regular procedure for saving the new messages of the chats with the bot from Telegram Bot API (the updates are kept by Telegram for 24 hours).")
+	telegramImportF       = telegramCommands.String("import", "", "Path of result.json of the export of Telegram Desktop (machine-readable JSON) for saving the messages of the exported chats.")
+	telegramListF         = telegramCommands.Bool("list", false, "Shows the chats of the backup.")
+	telegramExportF       = telegramCommands.String("export", "", "Exports the chat of the backup by ID (see -format, -search and -out).")
+	telegramExportFormatF = telegramCommands.String("format", telegram.ExportHTML, "Format of the export (markdown, html).")
+	telegramExportSearchF = telegramCommands.String("search", "", "Exports only the messages containing the text.")
+	telegramExportOutF    = telegramCommands.String("out", "", "File of the export (stdout by default).")
+
+	musicCommands = flag.NewFlagSet("music", flag.ExitOnError)
+	musicSyncF    = musicCommands.Bool("sync", false, "Regular procedure for the cross likes: loves on Last.fm the new liked tracks of Spotify and saves on Spotify the new loved tracks of Last.fm.")
 )
 
 func printAllFlagUsage() {
@@ -37,7 +80,7 @@ func printAllFlagUsage() {
 	for _, item := range []interface {
 		Name() string
 		PrintDefaults()
-	}{clickupCommands} {
+	}{clickupCommands, slackCommands, telegramCommands, musicCommands} {
 		fmt.Printf("- command %q with flags:\n", item.Name())
 		item.PrintDefaults()
 	}
@@ -98,6 +141,12 @@ func main() {
 	switch os.Args[1] {
 	case clickupCommands.Name():
 		clickupCommands.Parse(os.Args[2:])
+	case slackCommands.Name():
+		slackCommands.Parse(os.Args[2:])
+	case telegramCommands.Name():
+		telegramCommands.Parse(os.Args[2:])
+	case musicCommands.Name():
+		musicCommands.Parse(os.Args[2:])
 	default:
 		unknownCommandAndExist()
 	}
@@ -108,6 +157,15 @@ func main() {
 	if clickupCommands.Parsed() {
 		handleClickupCommands()
 	}
+	if slackCommands.Parsed() {
+		handleSlackCommands()
+	}
+	if telegramCommands.Parsed() {
+		handleTelegramCommands()
+	}
+	if musicCommands.Parsed() {
+		handleMusicCommands()
+	}
 }
 
 func clickupShowDemoSpec() {
@@ -159,12 +217,7 @@ func handleClickupCommands() {
 		return
 	}
 
-	firestoreOpts := []option.ClientOption{}
-	if Cfg.Firestore.CredsInlineJSON != "" {
-		firestoreOpts = append(firestoreOpts, option.WithCredentialsJSON([]byte(Cfg.Firestore.CredsInlineJSON)))
-	}
-
-	client, err := firestore.NewClient(Ctx, Cfg.Firestore.ProjectID, firestoreOpts...)
+	client, err := newFirestoreClient()
 	if err != nil {
 		zap.L().Error("Failed setup firestore client", zap.Error(err))
 		return
@@ -181,12 +234,106 @@ func handleClickupCommands() {
 			zap.L().Fatal("Failed decode spec of sync from yaml", zap.String("file_path", Cfg.Clickup.FileSpecSync),
 				zap.Error(err), zap.String("file_raw", string(specBytes)))
 		}
+		if err := spec.Validate(); err != nil {
+			zap.L().Fatal("Invalid spec of sync", zap.String("file_path", Cfg.Clickup.FileSpecSync), zap.Error(err))
+		}
 	}
 
 	storage := storage.NewStorage(client)
 	clickupStorage := clickup.NewStorage(storage)
 	api := clickupAPI.NewAPI(Cfg.Clickup.ApiToken)
 	manage := clickup.NewChangeManager(api, clickupStorage)
+	if Cfg.Github.ApiToken != "" {
+		manage.RegisterProvider(github.NewProvider(github.NewAPI(Cfg.Github.ApiURL, Cfg.Github.ApiToken)))
+	}
+	if Cfg.Gitlab.ApiToken != "" {
+		manage.RegisterProvider(gitlab.NewProvider(gitlab.NewAPI(Cfg.Gitlab.ApiURL, Cfg.Gitlab.ApiToken)))
+	}
+	if Cfg.Jira.ApiToken != "" {
+		manage.RegisterProvider(jira.NewProvider(jira.NewAPI(Cfg.Jira.BaseURL, Cfg.Jira.Email, Cfg.Jira.ApiToken)))
+	}
+	if Cfg.Linear.ApiKey != "" {
+		manage.RegisterProvider(linear.NewProvider(linear.NewAPI("", Cfg.Linear.ApiKey)))
+	}
+	if Cfg.Notion.ApiToken != "" {
+		manage.RegisterProvider(notion.NewProvider(notion.NewAPI("", Cfg.Notion.ApiToken)))
+	}
+	if Cfg.Telegram.BotToken != "" {
+		manage.RegisterNotifier(telegram.MessengerName, telegram.NewNotifier(telegram.NewAPI(Cfg.Telegram.ApiURL, Cfg.Telegram.BotToken)))
+	}
+	// the incoming webhooks (the chats as the URLs of the webhooks) are sent without the bot token
+	manage.RegisterNotifier(slack.MessengerName, slack.NewNotifier(slack.NewAPI(Cfg.Slack.ApiURL, Cfg.Slack.BotToken)))
+
+	webhookStorage := webhook.NewStorage(storage)
+	webhooks := webhook.NewDispatcher(webhookStorage, webhook.Options{})
+	manage.RegisterWebhooks(webhooks)
+
+	if *clickupWebhooksFailedF {
+		for _, delivery := range webhookStorage.FailedDeliveries(Ctx) {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", delivery.ID, delivery.EventType, delivery.Rule, delivery.URL, delivery.LastError)
+		}
+		return
+	}
+
+	if *clickupWebhooksRedeliverF != "" {
+		deliveries := webhookStorage.FailedDeliveries(Ctx)
+		if *clickupWebhooksRedeliverF != "all" {
+			delivery := webhookStorage.GetDelivery(Ctx, *clickupWebhooksRedeliverF)
+			if !delivery.Exists() {
+				zap.L().Fatal("Not found delivery of the webhook", zap.String("delivery_id", *clickupWebhooksRedeliverF))
+			}
+			deliveries = []*webhook.Delivery{delivery}
+		}
+		failed := 0
+		for _, delivery := range deliveries {
+			endpoint, exists := spec.WebhookEndpoint(delivery.Rule, delivery.URL)
+			if !exists {
+				zap.L().Warn("skip the delivery of the removed webhook", zap.String("delivery_id", delivery.ID), zap.String("rule_name", delivery.Rule), zap.String("url", delivery.URL))
+				failed++
+				continue
+			}
+			if err := webhooks.Redeliver(Ctx, endpoint, delivery); err != nil {
+				zap.L().Warn("failed redelivery of the webhook", zap.Error(err))
+				failed++
+			}
+		}
+		zap.L().Info("redelivered the webhooks", zap.Int("num_deliveries", len(deliveries)), zap.Int("num_failed", failed))
+		return
+	}
+
+	if *clickupListDestroyedF {
+		for _, mirror := range clickupStorage.ListDestroyedMirrorTasks(Ctx) {
+			destroyedAt := ""
+			if mirror.DestroyedAt != nil {
+				destroyedAt = mirror.DestroyedAt.AsTime().Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", mirror.ModelID(), mirror.RuleName, destroyedAt, mirror.DestroyedReason)
+		}
+		return
+	}
+
+	if *clickupRestoreF != "" {
+		if err := manage.RestoreMirrorTask(Ctx, spec, *clickupRestoreF); err != nil {
+			zap.L().Fatal("Failed restore the pair of the mirror tasks", zap.Error(err), zap.String("model_id", *clickupRestoreF))
+		}
+		zap.L().Info("restored the pair of the mirror tasks", zap.String("model_id", *clickupRestoreF))
+		return
+	}
+
+	if *clickupLinkF != "" {
+		taskID, mirror, err := clickup.MirrorTaskModel.ParseID(*clickupLinkF)
+		if err != nil {
+			zap.L().Fatal("Invalid ID of the pair of the mirror tasks", zap.Error(err))
+		}
+		if mirror.Provider != clickup.ProviderName {
+			zap.L().Fatal("Only the tasks of ClickUp can be linked", zap.String("model_id", *clickupLinkF))
+		}
+		if err := manage.LinkMirrorTask(Ctx, spec, taskID, mirror.ID, *clickupLinkRuleF); err != nil {
+			zap.L().Fatal("Failed link the pair of the mirror tasks", zap.Error(err), zap.String("model_id", *clickupLinkF))
+		}
+		zap.L().Info("linked the pair of the mirror tasks", zap.String("model_id", *clickupLinkF))
+		return
+	}
 
 	if *clickupDBSyncF {
 		teamIDs := spec.AllUsedTeamIDs()
@@ -205,6 +352,222 @@ func handleClickupCommands() {
 		}
 	}
 
+	if *clickupDBSyncF || *clickupRecentActivitySyncF {
+		// unlinks the pairs whose task is still closed after the grace period (see spec_lifecycle.grace_period)
+		manage.DestroyExpiredMirrorTasks(Ctx, spec)
+	}
+
+	if *clickupExternalSyncF {
+		zap.L().Info("processing of the changed issues in the external trackers")
+		manage.ApplyExternalChanges(Ctx, spec)
+	}
+
+	// sends the digests of the notifications and the webhooks
+	manage.FlushNotifications(Ctx)
+
+	if *clickupListenF != "" {
+		mux := http.NewServeMux()
+		if Cfg.Gitlab.ApiToken != "" && Cfg.Gitlab.WebhookSecret == "" {
+			zap.L().Warn("the webhooks of GitLab are not handled without the secret token (see ASAPTOOLS_GITLAB_WEBHOOK_SECRET)")
+		}
+		if Cfg.Gitlab.ApiToken != "" && Cfg.Gitlab.WebhookSecret != "" {
+			mux.Handle("/webhooks/gitlab", gitlab.NewWebhookHandler(Cfg.Gitlab.WebhookSecret, func(ctx context.Context, issueID string) error {
+				if !manage.ApplyExternalIssueChanges(ctx, spec, gitlab.ProviderName, issueID) {
+					zap.L().Debug("skip the event of the not synced issue", zap.String("tracker", gitlab.ProviderName), zap.String("issue_id", issueID))
+				}
+				manage.FlushNotifications(ctx)
+				return nil
+			}))
+		}
+		if Cfg.Slack.SigningSecret != "" {
+			slackAPI := slack.NewAPI(Cfg.Slack.ApiURL, Cfg.Slack.BotToken)
+			mux.Handle("/slack/interactions", slack.NewInteractionHandler(slackAPI, Cfg.Slack.SigningSecret, func(ctx context.Context, action, value, user string) error {
+				defer manage.FlushNotifications(ctx)
+				return manage.HandleNotificationAction(ctx, spec, action, value, user)
+			}))
+		}
+		zap.L().Info("listening of the webhooks", zap.String("addr", *clickupListenF))
+		if err := http.ListenAndServe(*clickupListenF, mux); err != nil {
+			zap.L().Fatal("Failed listen of the webhooks", zap.Error(err), zap.String("addr", *clickupListenF))
+		}
+	}
+
+}
+
+func handleSlackCommands() {
+	client, err := newFirestoreClient()
+	if err != nil {
+		zap.L().Error("Failed setup firestore client", zap.Error(err))
+		return
+	}
+	store := slack.NewStorage(storage.NewStorage(client))
+
+	if *slackListF {
+		for _, conv := range store.AllConversations(Ctx) {
+			fmt.Printf("%s\t%s\t%s\n", conv.ID, conv.Type, conv.Name)
+		}
+		return
+	}
+
+	if *slackExportF != "" {
+		export, err := store.ExportConversation(Ctx, *slackExportF)
+		if err != nil {
+			zap.L().Fatal("Failed export of the conversation", zap.Error(err), zap.String("conversation_id", *slackExportF))
+		}
+		out := os.Stdout
+		if *slackExportOutF != "" {
+			out, err = os.Create(*slackExportOutF)
+			if err != nil {
+				zap.L().Fatal("Failed create file of the export", zap.Error(err), zap.String("file_path", *slackExportOutF))
+			}
+			defer out.Close()
+		}
+		if err := export.Render(out, *slackExportFormatF); err != nil {
+			zap.L().Fatal("Failed export of the conversation", zap.Error(err), zap.String("conversation_id", *slackExportF))
+		}
+		return
+	}
+
+	if *slackArchiveF {
+		token := Cfg.Slack.UserToken
+		if token == "" {
+			token = Cfg.Slack.BotToken
+		}
+		if token == "" {
+			zap.L().Fatal("Slack is not configured (see ASAPTOOLS_SLACK_USER_TOKEN)")
+		}
+		opts := slack.ArchiveOptions{
+			Types:         splitList(*slackTypesF),
+			Conversations: splitList(*slackConversationsF),
+			ThreadsWindow: *slackThreadsWindowF,
+		}
+		zap.L().Info("saving of the new messages of the conversations from Slack", zap.Strings("types", opts.Types))
+		if err := slack.NewArchiver(slack.NewAPI(Cfg.Slack.ApiURL, token), store).Archive(Ctx, opts); err != nil {
+			zap.L().Fatal("Failed archive of the conversations", zap.Error(err))
+		}
+	}
+}
+
+func handleTelegramCommands() {
+	client, err := newFirestoreClient()
+	if err != nil {
+		zap.L().Error("Failed setup firestore client", zap.Error(err))
+		return
+	}
+	store := telegram.NewStorage(storage.NewStorage(client))
+
+	if *telegramListF {
+		for _, chat := range store.AllChats(Ctx) {
+			fmt.Printf("%s\t%s\t%s\n", chat.ID, chat.Type, chat.GetName())
+		}
+		return
+	}
+
+	if *telegramExportF != "" {
+		export, err := store.ExportChat(Ctx, *telegramExportF)
+		if err != nil {
+			zap.L().Fatal("Failed export of the chat", zap.Error(err), zap.String("chat_id", *telegramExportF))
+		}
+		if *telegramExportSearchF != "" {
+			export = export.Search(*telegramExportSearchF)
+		}
+		out := os.Stdout
+		if *telegramExportOutF != "" {
+			out, err = os.Create(*telegramExportOutF)
+			if err != nil {
+				zap.L().Fatal("Failed create file of the export", zap.Error(err), zap.String("file_path", *telegramExportOutF))
+			}
+			defer out.Close()
+		}
+		if err := export.Render(out, *telegramExportFormatF); err != nil {
+			zap.L().Fatal("Failed export of the chat", zap.Error(err), zap.String("chat_id", *telegramExportF))
+		}
+		return
+	}
+
+	if *telegramImportF != "" {
+		f, err := os.Open(*telegramImportF)
+		if err != nil {
+			zap.L().Fatal("Failed open file of the export of Telegram Desktop", zap.Error(err), zap.String("file_path", *telegramImportF))
+		}
+		defer f.Close()
+		chats, err := telegram.ParseDesktopExport(f)
+		if err != nil {
+			zap.L().Fatal("Failed parse file of the export of Telegram Desktop", zap.Error(err), zap.String("file_path", *telegramImportF))
+		}
+		num, err := telegram.NewBackup(nil, store).ImportDesktop(Ctx, chats)
+		if err != nil {
+			zap.L().Fatal("Failed import of the chats", zap.Error(err), zap.Int("num_saved_messages", num))
+		}
+		zap.L().Info("imported the chats", zap.Int("num_chats", len(chats)), zap.Int("num_messages", num))
+	}
+
+	if *telegramBackupF {
+		if Cfg.Telegram.BotToken == "" {
+			zap.L().Fatal("Telegram is not configured (see ASAPTOOLS_TELEGRAM_BOT_TOKEN)")
+		}
+		num, err := telegram.NewBackup(telegram.NewAPI(Cfg.Telegram.ApiURL, Cfg.Telegram.BotToken), store).BackupUpdates(Ctx)
+		if err != nil {
+			zap.L().Fatal("Failed backup of the chats", zap.Error(err), zap.Int("num_saved_messages", num))
+		}
+		zap.L().Info("saved the new messages of the chats", zap.Int("num_messages", num))
+	}
+}
+
+func handleMusicCommands() {
+	if !*musicSyncF {
+		return
+	}
+	if Cfg.Spotify.ClientID == "" || Cfg.Spotify.ClientSecret == "" || Cfg.Spotify.RefreshToken == "" {
+		zap.L().Fatal("Spotify is not configured (see ASAPTOOLS_SPOTIFY_CLIENT_ID, ASAPTOOLS_SPOTIFY_CLIENT_SECRET and ASAPTOOLS_SPOTIFY_REFRESH_TOKEN)")
+	}
+	if Cfg.Lastfm.ApiKey == "" || Cfg.Lastfm.ApiSecret == "" || Cfg.Lastfm.SessionKey == "" || Cfg.Lastfm.User == "" {
+		zap.L().Fatal("Last.fm is not configured (see ASAPTOOLS_LASTFM_API_KEY, ASAPTOOLS_LASTFM_API_SECRET, ASAPTOOLS_LASTFM_SESSION_KEY and ASAPTOOLS_LASTFM_USER)")
+	}
+
+	client, err := newFirestoreClient()
+	if err != nil {
+		zap.L().Error("Failed setup firestore client", zap.Error(err))
+		return
+	}
+
+	spotifyAPI := spotify.NewAPI(Cfg.Spotify.ApiURL, Cfg.Spotify.AccountsURL, spotify.Credentials{
+		ClientID:     Cfg.Spotify.ClientID,
+		ClientSecret: Cfg.Spotify.ClientSecret,
+		RefreshToken: Cfg.Spotify.RefreshToken,
+	})
+	lastfmAPI := lastfm.NewAPI(Cfg.Lastfm.ApiURL, lastfm.Credentials{
+		APIKey:     Cfg.Lastfm.ApiKey,
+		APISecret:  Cfg.Lastfm.ApiSecret,
+		SessionKey: Cfg.Lastfm.SessionKey,
+		User:       Cfg.Lastfm.User,
+	})
+	res, err := music.NewSyncer(spotifyAPI, lastfmAPI, music.NewStorage(storage.NewStorage(client))).Sync(Ctx)
+	if err != nil {
+		zap.L().Fatal("Failed sync of the likes", zap.Error(err))
+	}
+	zap.L().Info("synced the likes of Spotify and Last.fm", zap.Int("num_new_spotify", res.NewSpotify), zap.Int("num_new_lastfm", res.NewLastfm),
+		zap.Int("num_loved", res.Loved), zap.Int("num_saved", res.Saved), zap.Int("num_not_found", res.NotFound))
+}
+
+// returns the client of Firestore by the settings
+func newFirestoreClient() (*firestore.Client, error) {
+	firestoreOpts := []option.ClientOption{}
+	if Cfg.Firestore.CredsInlineJSON != "" {
+		firestoreOpts = append(firestoreOpts, option.WithCredentialsJSON([]byte(Cfg.Firestore.CredsInlineJSON)))
+	}
+	return firestore.NewClient(Ctx, Cfg.Firestore.ProjectID, firestoreOpts...)
+}
+
+// returns the not empty items of the comma separated list
+func splitList(in string) []string {
+	res := []string{}
+	for _, item := range strings.Split(in, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
 }
 
 type Config struct {
@@ -213,6 +576,15 @@ type Config struct {
 
 	Firestore *FirestoreSettings `envconfig:"FIRESTORE"`
 	Clickup   *ClickupConfig     `envconfig:"CLICKUP"`
+	Github    *GithubConfig      `envconfig:"GITHUB"`
+	Gitlab    *GitlabConfig      `envconfig:"GITLAB"`
+	Notion    *NotionConfig      `envconfig:"NOTION"`
+	Jira      *JiraConfig        `envconfig:"JIRA"`
+	Linear    *LinearConfig      `envconfig:"LINEAR"`
+	Telegram  *TelegramConfig    `envconfig:"TELEGRAM"`
+	Slack     *SlackConfig       `envconfig:"SLACK"`
+	Spotify   *SpotifyConfig     `envconfig:"SPOTIFY"`
+	Lastfm    *LastfmConfig      `envconfig:"LASTFM"`
 }
 
 type ClickupConfig struct {
@@ -221,6 +593,59 @@ type ClickupConfig struct {
 	FileSpecSync  string `envconfig:"FILE_SPEC_SYNC"`
 }
 
+type GithubConfig struct {
+	ApiToken string `envconfig:"API_TOKEN" desc:"Token from GitHub API with access to the issues (for the sync with GitHub Issues)"`
+	ApiURL   string `envconfig:"API_URL" default:"https://api.github.com" desc:"GitHub API URL (for GitHub Enterprise)"`
+}
+
+type GitlabConfig struct {
+	ApiToken      string `envconfig:"API_TOKEN" desc:"Personal or project access token of GitLab with the api scope (for the sync with GitLab Issues)"`
+	ApiURL        string `envconfig:"API_URL" default:"https://gitlab.com" desc:"GitLab URL (for the self-managed instance)"`
+	WebhookSecret string `envconfig:"WEBHOOK_SECRET" desc:"Secret token of the webhook of the project (required for the webhooks, see -listen)"`
+}
+
+type NotionConfig struct {
+	ApiToken string `envconfig:"API_TOKEN" desc:"Token of Notion integration with access to the databases (for the sync with Notion)"`
+}
+
+type JiraConfig struct {
+	BaseURL  string `envconfig:"BASE_URL" desc:"Jira Cloud site (eg https://<site>.atlassian.net)"`
+	Email    string `envconfig:"EMAIL" desc:"Email of Jira user of the API token"`
+	ApiToken string `envconfig:"API_TOKEN" desc:"API token of Jira user (for the sync with Jira, follow link https://id.atlassian.com/manage-profile/security/api-tokens)"`
+}
+
+type LinearConfig struct {
+	ApiKey string `envconfig:"API_KEY" desc:"Personal API key of Linear (for the sync with Linear, follow link https://linear.app/settings/api)"`
+}
+
+type TelegramConfig struct {
+	BotToken string `envconfig:"BOT_TOKEN" desc:"Token of Telegram bot (for the notifications about the changes of the tasks and the backup of the chats with the bot, follow link https://t.me/BotFather)"`
+	ApiURL   string `envconfig:"API_URL" default:"https://api.telegram.org" desc:"Telegram Bot API URL (for the local Bot API server)"`
+}
+
+type SlackConfig struct {
+	BotToken      string `envconfig:"BOT_TOKEN" desc:"Bot token of Slack app with chat:write (for the notifications to the channels by ID, the webhook URLs do not need the token)"`
+	UserToken     string `envconfig:"USER_TOKEN" desc:"User token of Slack app with the history scopes (for the archive of the conversations including the direct messages, the bot token is used if empty)"`
+	ApiURL        string `envconfig:"API_URL" default:"https://slack.com/api" desc:"Slack Web API URL"`
+	SigningSecret string `envconfig:"SIGNING_SECRET" desc:"Signing secret of Slack app for the buttons of the notifications (see -listen, the request URL is /slack/interactions)"`
+}
+
+type SpotifyConfig struct {
+	ClientID     string `envconfig:"CLIENT_ID" desc:"Client ID of Spotify app (for the cross likes, follow link https://developer.spotify.com/dashboard)"`
+	ClientSecret string `envconfig:"CLIENT_SECRET" desc:"Client secret of Spotify app"`
+	RefreshToken string `envconfig:"REFRESH_TOKEN" desc:"Refresh token of the user with the scopes user-library-read and user-library-modify"`
+	ApiURL       string `envconfig:"API_URL" default:"https://api.spotify.com/v1" desc:"Spotify Web API URL"`
+	AccountsURL  string `envconfig:"ACCOUNTS_URL" default:"https://accounts.spotify.com" desc:"Spotify Accounts service URL"`
+}
+
+type LastfmConfig struct {
+	ApiKey     string `envconfig:"API_KEY" desc:"API key of Last.fm API account (for the cross likes, follow link https://www.last.fm/api/account/create)"`
+	ApiSecret  string `envconfig:"API_SECRET" desc:"Shared secret of Last.fm API account"`
+	SessionKey string `envconfig:"SESSION_KEY" desc:"Session key of the user (see the authentication of Last.fm API)"`
+	User       string `envconfig:"USER" desc:"Name of the user of Last.fm"`
+	ApiURL     string `envconfig:"API_URL" default:"https://ws.audioscrobbler.com/2.0/" desc:"Last.fm API URL"`
+}
+
 type FirestoreSettings struct {
 	CredsInlineJSON string `envconfig:"PRIVATE_KEY_INLINE_JSON" desc:"Inline json file with Google Cloud service account private key."`
 	ProjectID       string `envconfig:"PROJECT_ID" desc:"Google Cloud project ID"`
@@ -0,0 +1,200 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+const DefaultBaseURL = "https://api.github.com"
+
+// the max size of the page of the list requests
+const perPage = 100
+
+// ErrNotFound returns API if the resource is not found (or gone, eg the deleted or transferred issue).
+var ErrNotFound = errors.New("github: not found")
+
+type httpClientLogger struct {
+	*zap.Logger
+}
+
+func (l *httpClientLogger) Printf(msg string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(msg, args...))
+}
+
+// NewAPI returns the client of GitHub REST API (baseURL for GitHub Enterprise or tests, DefaultBaseURL by default).
+func NewAPI(baseURL, accessToken string) *API {
+	l := zap.L().Named("github_api")
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = &httpClientLogger{l.Named("http")}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &API{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   accessToken,
+		client:  httpClient.StandardClient(),
+		log:     l,
+	}
+}
+
+type API struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	log     *zap.Logger
+}
+
+type Issue struct {
+	ID        int64     `json:"id"`
+	Number    int       `json:"number"`
+	HTMLURL   string    `json:"html_url"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	Labels    []Label   `json:"labels"`
+	Assignees []User    `json:"assignees"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Label struct {
+	Name string `json:"name"`
+}
+
+type User struct {
+	Login string `json:"login"`
+}
+
+type IssueComment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	User      User      `json:"user"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IssueRequest the fields of the new or updated issue (only the not nil fields are updated).
+type IssueRequest struct {
+	Title     *string   `json:"title,omitempty"`
+	Body      *string   `json:"body,omitempty"`
+	State     *string   `json:"state,omitempty"`
+	Labels    *[]string `json:"labels,omitempty"`
+	Assignees *[]string `json:"assignees,omitempty"`
+}
+
+// CreateIssue creates the issue in the repo (owner/repo).
+func (a *API) CreateIssue(ctx context.Context, repo string, req *IssueRequest) (*Issue, error) {
+	res := &Issue{}
+	err := a.doRequest(ctx, http.MethodPost, "/repos/"+repo+"/issues", nil, req, res)
+	return res, err
+}
+
+func (a *API) UpdateIssue(ctx context.Context, repo string, number int, req *IssueRequest) (*Issue, error) {
+	res := &Issue{}
+	err := a.doRequest(ctx, http.MethodPatch, "/repos/"+repo+"/issues/"+strconv.Itoa(number), nil, req, res)
+	return res, err
+}
+
+func (a *API) GetIssue(ctx context.Context, repo string, number int) (*Issue, error) {
+	res := &Issue{}
+	err := a.doRequest(ctx, http.MethodGet, "/repos/"+repo+"/issues/"+strconv.Itoa(number), nil, nil, res)
+	return res, err
+}
+
+// ListIssueComments returns all comments of the issue (the oldest first).
+func (a *API) ListIssueComments(ctx context.Context, repo string, number int) ([]IssueComment, error) {
+	list := []IssueComment{}
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("per_page", strconv.Itoa(perPage))
+		query.Set("page", strconv.Itoa(page))
+
+		res := []IssueComment{}
+		err := a.doRequest(ctx, http.MethodGet, "/repos/"+repo+"/issues/"+strconv.Itoa(number)+"/comments", query, nil, &res)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, res...)
+		if len(res) < perPage {
+			return list, nil
+		}
+	}
+}
+
+func (a *API) CreateIssueComment(ctx context.Context, repo string, number int, body string) (*IssueComment, error) {
+	res := &IssueComment{}
+	req := map[string]string{"body": body}
+	err := a.doRequest(ctx, http.MethodPost, "/repos/"+repo+"/issues/"+strconv.Itoa(number)+"/comments", nil, req, res)
+	return res, err
+}
+
+func (a *API) doRequest(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	reqURL := a.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	var body *bytes.Reader
+	if in != nil {
+		dat, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(dat)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	dat, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	a.log.Debug("API request", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("method", method))
+
+	switch {
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone:
+		return ErrNotFound
+	case res.StatusCode >= 300:
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("body_raw", string(dat)))
+		return fmt.Errorf("github: %s %s: got status %d", method, path, res.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(dat, out); err != nil {
+		return fmt.Errorf("github: failed decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
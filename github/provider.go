@@ -0,0 +1,193 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+// ProviderName name of GitHub in the spec of sync (spec_add.external[].tracker) and in the task references.
+const ProviderName = "github"
+
+// NewProvider returns GitHub Issues as the provider of the tasks (the issues).
+// The location of the issues is the repo (owner/repo), the key is the number of the issue, the members are the logins.
+func NewProvider(api *API) *Provider {
+	return &Provider{api: api}
+}
+
+type Provider struct {
+	api *API
+}
+
+var _ tracker.Provider = (*Provider)(nil)
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+func (p *Provider) Capabilities() tracker.Capability {
+	return tracker.CapComments | tracker.CapTags | tracker.CapAssignees | tracker.CapMarkdown
+}
+
+func (p *Provider) Fields(location tracker.Location) []string {
+	return []string{
+		tracker.FieldName,
+		tracker.FieldDescription,
+		tracker.FieldTags,
+		tracker.FieldAssignees,
+		tracker.FieldState,
+	}
+}
+
+// CreateTask creates the open issue in the repo.
+func (p *Provider) CreateTask(ctx context.Context, location tracker.Location, task *tracker.Task) (*tracker.Task, error) {
+	req := issueRequest(task, []string{
+		tracker.FieldName,
+		tracker.FieldDescription,
+		tracker.FieldTags,
+		tracker.FieldAssignees,
+	})
+	res, err := p.api.CreateIssue(ctx, location.ID, req)
+	if err != nil {
+		return nil, err
+	}
+	return providerTask(location, res), nil
+}
+
+func (p *Provider) UpdateTask(ctx context.Context, location tracker.Location, task *tracker.Task, fields []string) (*tracker.Task, error) {
+	number, err := issueNumber(task.Key)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.api.UpdateIssue(ctx, location.ID, number, issueRequest(task, fields))
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return providerTask(location, res), nil
+}
+
+// GetTask returns the issue by the number or tracker.ErrNotFound (eg the deleted or transferred issue).
+func (p *Provider) GetTask(ctx context.Context, location tracker.Location, key string) (*tracker.Task, error) {
+	number, err := issueNumber(key)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.api.GetIssue(ctx, location.ID, number)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return providerTask(location, res), nil
+}
+
+func (p *Provider) ListComments(ctx context.Context, location tracker.Location, key string) ([]*tracker.Comment, error) {
+	number, err := issueNumber(key)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.api.ListIssueComments(ctx, location.ID, number)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	list := []*tracker.Comment{}
+	for idx := range res {
+		list = append(list, providerComment(&res[idx]))
+	}
+	return list, nil
+}
+
+func (p *Provider) AddComment(ctx context.Context, location tracker.Location, key string, comment *tracker.Comment) (*tracker.Comment, error) {
+	number, err := issueNumber(key)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.api.CreateIssueComment(ctx, location.ID, number, comment.Body)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return providerComment(res), nil
+}
+
+// ListMembers is not supported (the assignees are the logins from the spec of sync).
+func (p *Provider) ListMembers(ctx context.Context, location tracker.Location) ([]*tracker.Member, error) {
+	return nil, fmt.Errorf("the members of the repo %q are not supported", location.ID)
+}
+
+func issueNumber(key string) (int, error) {
+	number, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number of the issue %q: %w", key, err)
+	}
+	return number, nil
+}
+
+func notFoundErr(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return tracker.ErrNotFound
+	}
+	return err
+}
+
+// returns the request with the specified fields of the task
+func issueRequest(task *tracker.Task, fields []string) *IssueRequest {
+	req := &IssueRequest{}
+	for _, field := range fields {
+		switch field {
+		case tracker.FieldName:
+			req.Title = &task.Name
+		case tracker.FieldDescription:
+			req.Body = &task.Description
+		case tracker.FieldTags:
+			labels := append([]string{}, task.Tags...)
+			req.Labels = &labels
+		case tracker.FieldAssignees:
+			assignees := []string{}
+			for _, member := range task.Assignees {
+				assignees = append(assignees, member.ID)
+			}
+			req.Assignees = &assignees
+		case tracker.FieldState:
+			state := "open"
+			if task.Closed {
+				state = "closed"
+			}
+			req.State = &state
+		}
+	}
+	return req
+}
+
+func providerTask(location tracker.Location, in *Issue) *tracker.Task {
+	id := strconv.FormatInt(in.ID, 10)
+	res := &tracker.Task{
+		Ref:         tracker.TaskRef{Provider: ProviderName, ID: id},
+		Key:         strconv.Itoa(in.Number),
+		Location:    location,
+		Name:        in.Title,
+		Description: in.Body,
+		Tags:        []string{},
+		Assignees:   []tracker.Member{},
+		Closed:      in.State == "closed",
+		URL:         in.HTMLURL,
+		UpdatedAt:   in.UpdatedAt,
+	}
+	for _, label := range in.Labels {
+		res.Tags = append(res.Tags, label.Name)
+	}
+	for _, user := range in.Assignees {
+		res.Assignees = append(res.Assignees, tracker.Member{ID: user.Login, Name: user.Login})
+	}
+	return res
+}
+
+func providerComment(in *IssueComment) *tracker.Comment {
+	return &tracker.Comment{
+		ID:        strconv.FormatInt(in.ID, 10),
+		Author:    tracker.Member{ID: in.User.Login, Name: in.User.Login},
+		Body:      in.Body,
+		URL:       in.HTMLURL,
+		CreatedAt: in.CreatedAt,
+	}
+}
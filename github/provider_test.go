@@ -0,0 +1,173 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gebv/asap-tools/tracker"
+	"github.com/gebv/asap-tools/tracker/trackertest"
+)
+
+const testToken = "test-token"
+
+var testLocation = tracker.Location{Provider: ProviderName, ID: "gebv/asap-tools"}
+
+// fakeAPI the in-memory GitHub API (only the issues and the comments of the issues)
+type fakeAPI struct {
+	nextID   int64
+	issues   map[string]map[int]*Issue
+	comments map[string][]IssueComment
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /repos/{owner}/{repo}/issues[/{number}[/comments]]
+	args := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(args) < 4 || args[0] != "repos" || args[3] != "issues" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	repo := args[1] + "/" + args[2]
+
+	if len(args) == 4 && r.Method == http.MethodPost {
+		req := &IssueRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		if f.issues[repo] == nil {
+			f.issues[repo] = map[int]*Issue{}
+		}
+		f.nextID++
+		number := len(f.issues[repo]) + 1
+		issue := &Issue{
+			ID:      f.nextID,
+			Number:  number,
+			HTMLURL: fmt.Sprintf("https://github.com/%s/issues/%d", repo, number),
+			State:   "open",
+		}
+		applyIssueRequest(issue, req)
+		f.issues[repo][number] = issue
+		trackertest.WriteJSON(w, http.StatusCreated, issue)
+		return
+	}
+
+	if len(args) < 5 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	number, _ := strconv.Atoi(args[4])
+	issue, exists := f.issues[repo][number]
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := fmt.Sprintf("%s#%d", repo, number)
+
+	switch {
+	case len(args) == 5 && r.Method == http.MethodGet:
+		trackertest.WriteJSON(w, http.StatusOK, issue)
+	case len(args) == 5 && r.Method == http.MethodPatch:
+		req := &IssueRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		applyIssueRequest(issue, req)
+		trackertest.WriteJSON(w, http.StatusOK, issue)
+	case len(args) == 6 && args[5] == "comments" && r.Method == http.MethodPost:
+		req := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.nextID++
+		comment := IssueComment{ID: f.nextID, Body: req["body"], User: User{Login: "bot"}}
+		f.comments[key] = append(f.comments[key], comment)
+		trackertest.WriteJSON(w, http.StatusCreated, comment)
+	case len(args) == 6 && args[5] == "comments" && r.Method == http.MethodGet:
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		list := f.comments[key]
+		from, to := trackertest.Page(len(list), (page-1)*size, size)
+		trackertest.WriteJSON(w, http.StatusOK, list[from:to])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func applyIssueRequest(issue *Issue, req *IssueRequest) {
+	if req.Title != nil {
+		issue.Title = *req.Title
+	}
+	if req.Body != nil {
+		issue.Body = *req.Body
+	}
+	if req.State != nil {
+		issue.State = *req.State
+	}
+	if req.Labels != nil {
+		issue.Labels = []Label{}
+		for _, name := range *req.Labels {
+			issue.Labels = append(issue.Labels, Label{Name: name})
+		}
+	}
+	if req.Assignees != nil {
+		issue.Assignees = []User{}
+		for _, login := range *req.Assignees {
+			issue.Assignees = append(issue.Assignees, User{Login: login})
+		}
+	}
+}
+
+func newTestProvider(t *testing.T) (*Provider, *fakeAPI) {
+	fake := &fakeAPI{
+		nextID:   1000,
+		issues:   map[string]map[int]*Issue{},
+		comments: map[string][]IssueComment{},
+	}
+	url := trackertest.NewServer(t, trackertest.HeaderAuth("Authorization", "Bearer "+testToken), fake.ServeHTTP)
+	return NewProvider(NewAPI(url, testToken)), fake
+}
+
+func TestProvider(t *testing.T) {
+	provider, fake := newTestProvider(t)
+	suite := &trackertest.Suite{
+		Provider: provider,
+		Location: testLocation,
+		Task: &tracker.Task{
+			Name:        "Task",
+			Description: "**description**",
+			Tags:        []string{"bug"},
+			Assignees:   []tracker.Member{{ID: "gebv"}},
+		},
+		Update: &tracker.Task{
+			Description: "changed",
+			Tags:        []string{"bug", "ui"},
+			Assignees:   []tracker.Member{},
+			Closed:      true,
+		},
+		Remove: func(task *tracker.Task) {
+			number, _ := strconv.Atoi(task.Key)
+			delete(fake.issues[testLocation.ID], number)
+		},
+		PageSize:      perPage,
+		CommentAuthor: "bot",
+	}
+	suite.Run(t)
+}
+
+func TestProvider_CreateTask(t *testing.T) {
+	ctx := context.Background()
+	provider, _ := newTestProvider(t)
+
+	created, err := provider.CreateTask(ctx, testLocation, &tracker.Task{Name: "Task", Closed: true})
+	if err != nil {
+		t.Fatalf("CreateTask(): %v", err)
+	}
+	if created.Key != "1" || created.URL != "https://github.com/gebv/asap-tools/issues/1" {
+		t.Errorf("CreateTask() = %+v, want the issue number 1", created)
+	}
+	if created.Closed {
+		t.Error("CreateTask() the new issue must be open")
+	}
+
+	if _, err := provider.GetTask(ctx, testLocation, "CU-1"); err == nil {
+		t.Error("GetTask() for the invalid key must return error")
+	}
+}
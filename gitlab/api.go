@@ -0,0 +1,214 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// DefaultBaseURL the URL of GitLab.com (the self-managed instances have own URL)
+const DefaultBaseURL = "https://gitlab.com"
+
+// the max size of the page of the list requests
+const perPage = 100
+
+// ErrNotFound returns API if the issue is not found (or the user has no access to the project).
+var ErrNotFound = errors.New("gitlab: not found")
+
+type httpClientLogger struct {
+	*zap.Logger
+}
+
+func (l *httpClientLogger) Printf(msg string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(msg, args...))
+}
+
+// NewAPI returns the client of GitLab REST API v4 (baseURL of the instance, DefaultBaseURL by default).
+func NewAPI(baseURL, token string) *API {
+	l := zap.L().Named("gitlab_api")
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = &httpClientLogger{l.Named("http")}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &API{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  httpClient.StandardClient(),
+		log:     l,
+	}
+}
+
+type API struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	log     *zap.Logger
+}
+
+type Issue struct {
+	// the unique ID of the issue in the instance
+	ID int64 `json:"id"`
+	// the number of the issue in the project
+	IID         int    `json:"iid"`
+	ProjectID   int64  `json:"project_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// opened or closed
+	State     string     `json:"state"`
+	Labels    []string   `json:"labels"`
+	Assignees []User     `json:"assignees"`
+	Milestone *Milestone `json:"milestone"`
+	Weight    *int       `json:"weight"`
+	// the date in the format 2006-01-02
+	DueDate   *string   `json:"due_date"`
+	WebURL    string    `json:"web_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type Milestone struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	State string `json:"state"`
+}
+
+// Note the comment of the issue (the system notes are the changes of the issue).
+type Note struct {
+	ID     int64  `json:"id"`
+	Body   string `json:"body"`
+	Author User   `json:"author"`
+	System bool   `json:"system"`
+}
+
+// projectPath returns the escaped path of the project (the ID or the path with the namespace, eg group/project).
+func projectPath(project string) string {
+	return "/api/v4/projects/" + url.PathEscape(project)
+}
+
+// CreateIssue creates the issue with the attributes (eg {"title": "...", "labels": "bug,ui"}).
+func (a *API) CreateIssue(ctx context.Context, project string, attrs map[string]interface{}) (*Issue, error) {
+	res := &Issue{}
+	err := a.doRequest(ctx, http.MethodPost, projectPath(project)+"/issues", nil, attrs, res)
+	return res, err
+}
+
+// UpdateIssue updates only the specified attributes of the issue (eg {"state_event": "close"}).
+func (a *API) UpdateIssue(ctx context.Context, project string, iid int, attrs map[string]interface{}) (*Issue, error) {
+	res := &Issue{}
+	err := a.doRequest(ctx, http.MethodPut, fmt.Sprintf("%s/issues/%d", projectPath(project), iid), nil, attrs, res)
+	return res, err
+}
+
+func (a *API) GetIssue(ctx context.Context, project string, iid int) (*Issue, error) {
+	res := &Issue{}
+	err := a.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d", projectPath(project), iid), nil, nil, res)
+	return res, err
+}
+
+// ListMilestones returns the active milestones of the project with the title.
+func (a *API) ListMilestones(ctx context.Context, project, title string) ([]Milestone, error) {
+	query := url.Values{}
+	query.Set("title", title)
+	query.Set("state", "active")
+	res := []Milestone{}
+	err := a.doRequest(ctx, http.MethodGet, projectPath(project)+"/milestones", query, nil, &res)
+	return res, err
+}
+
+// ListNotes returns all notes of the issue (the oldest first).
+func (a *API) ListNotes(ctx context.Context, project string, iid int) ([]Note, error) {
+	list := []Note{}
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("sort", "asc")
+		query.Set("order_by", "created_at")
+		query.Set("per_page", fmt.Sprint(perPage))
+		query.Set("page", fmt.Sprint(page))
+
+		res := []Note{}
+		if err := a.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d/notes", projectPath(project), iid), query, nil, &res); err != nil {
+			return nil, err
+		}
+		list = append(list, res...)
+		if len(res) < perPage {
+			return list, nil
+		}
+	}
+}
+
+func (a *API) CreateNote(ctx context.Context, project string, iid int, body string) (*Note, error) {
+	res := &Note{}
+	err := a.doRequest(ctx, http.MethodPost, fmt.Sprintf("%s/issues/%d/notes", projectPath(project), iid), nil, map[string]string{"body": body}, res)
+	return res, err
+}
+
+func (a *API) doRequest(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	reqURL := a.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	body := bytes.NewReader(nil)
+	if in != nil {
+		dat, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(dat)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", a.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	dat, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	a.log.Debug("API request", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("method", method))
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case res.StatusCode >= 300:
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("body_raw", string(dat)))
+		return fmt.Errorf("gitlab: %s %s: got status %d", method, path, res.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(dat, out); err != nil {
+		return fmt.Errorf("gitlab: failed decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
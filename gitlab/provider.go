@@ -0,0 +1,411 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+// ProviderName name of GitLab in the spec of sync (spec_add.external[].tracker) and in the task references.
+const ProviderName = "gitlab"
+
+// the attributes of the issue of GitLab for the mapping of the fields (spec_add.external[].properties)
+const (
+	// the weight by the estimate (the number of hours, rounded up) or by the priority (4 urgent ... 1 low)
+	AttrWeight = "weight"
+	// the scoped labels by the status (status::<status>) or by the priority (priority::<name>)
+	AttrLabels = "labels"
+	// the milestone by the name of the list of the task (eg the sprint)
+	AttrMilestone = "milestone"
+)
+
+// the prefixes of the scoped labels
+const (
+	statusLabelPrefix   = "status::"
+	priorityLabelPrefix = "priority::"
+)
+
+// the date format of GitLab API
+const dateLayout = "2006-01-02"
+
+// the names of the priorities of ClickUp (1 urgent, 2 high, 3 normal, 4 low)
+var priorityNames = map[int]string{
+	1: "urgent",
+	2: "high",
+	3: "normal",
+	4: "low",
+}
+
+var hourMs = int64(time.Hour / time.Millisecond)
+
+// NewProvider returns GitLab Issues as the provider of the tasks (the issues).
+// The location of the issues is the project (the path with the namespace or the ID), the key is the IID of the issue,
+// the members are the IDs of GitLab users.
+// The status, the priority, the estimate and the list of the task are synced by the mapping to the attributes
+// of the issue (see Attr* constants), eg properties: {status: labels, estimate: weight, milestone: milestone}.
+func NewProvider(api *API) *Provider {
+	return &Provider{api: api}
+}
+
+type Provider struct {
+	api *API
+}
+
+var (
+	_ tracker.Provider   = (*Provider)(nil)
+	_ tracker.Normalizer = (*Provider)(nil)
+)
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+func (p *Provider) Capabilities() tracker.Capability {
+	return tracker.CapComments | tracker.CapTags | tracker.CapAssignees | tracker.CapStatus | tracker.CapPriority |
+		tracker.CapDueDate | tracker.CapEstimate | tracker.CapMarkdown
+}
+
+// Fields returns the common fields of the issues and the mapped fields.
+func (p *Provider) Fields(location tracker.Location) []string {
+	fields := []string{
+		tracker.FieldName,
+		tracker.FieldDescription,
+		tracker.FieldTags,
+		tracker.FieldAssignees,
+		tracker.FieldState,
+		tracker.FieldDueDate,
+	}
+	if location.Property(tracker.FieldStatus) == AttrLabels {
+		fields = append(fields, tracker.FieldStatus)
+	}
+	if attr := location.Property(tracker.FieldPriority); attr == AttrLabels || attr == AttrWeight {
+		fields = append(fields, tracker.FieldPriority)
+	}
+	if location.Property(tracker.FieldEstimate) == AttrWeight && location.Property(tracker.FieldPriority) != AttrWeight {
+		fields = append(fields, tracker.FieldEstimate)
+	}
+	if location.Property(tracker.FieldMilestone) == AttrMilestone {
+		fields = append(fields, tracker.FieldMilestone)
+	}
+	return fields
+}
+
+// NormalizeTask truncates the due date to the day (in UTC) and rounds up the estimate to the hours of the weight.
+func (p *Provider) NormalizeTask(location tracker.Location, task *tracker.Task) {
+	if task.DueDate != nil {
+		dueDate := task.DueDate.UTC().Truncate(24 * time.Hour)
+		task.DueDate = &dueDate
+	}
+	if task.TimeEstimateMs != nil {
+		estimate := (*task.TimeEstimateMs + hourMs - 1) / hourMs * hourMs
+		task.TimeEstimateMs = &estimate
+	}
+}
+
+func (p *Provider) CreateTask(ctx context.Context, location tracker.Location, task *tracker.Task) (*tracker.Task, error) {
+	fields := p.Fields(location)
+	attrs, err := p.issueAttrs(ctx, location, task, nil, fields)
+	if err != nil {
+		return nil, err
+	}
+	// the new issue is always open
+	delete(attrs, "state_event")
+
+	labels := []string{}
+	if add, ok := attrs["add_labels"].(string); ok && add != "" {
+		labels = strings.Split(add, ",")
+	}
+	delete(attrs, "add_labels")
+	delete(attrs, "remove_labels")
+	attrs["labels"] = strings.Join(labels, ",")
+
+	created, err := p.api.CreateIssue(ctx, location.ID, attrs)
+	if err != nil {
+		return nil, err
+	}
+	return p.providerTask(location, created), nil
+}
+
+func (p *Provider) UpdateTask(ctx context.Context, location tracker.Location, task *tracker.Task, fields []string) (*tracker.Task, error) {
+	iid, err := parseIID(task.Key)
+	if err != nil {
+		return nil, err
+	}
+	current, err := p.api.GetIssue(ctx, location.ID, iid)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	attrs, err := p.issueAttrs(ctx, location, task, current, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := p.api.UpdateIssue(ctx, location.ID, iid, attrs)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return p.providerTask(location, updated), nil
+}
+
+func (p *Provider) GetTask(ctx context.Context, location tracker.Location, key string) (*tracker.Task, error) {
+	iid, err := parseIID(key)
+	if err != nil {
+		return nil, err
+	}
+	issue, err := p.api.GetIssue(ctx, location.ID, iid)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return p.providerTask(location, issue), nil
+}
+
+// ListComments returns the comments of the issue (without the system notes).
+func (p *Provider) ListComments(ctx context.Context, location tracker.Location, key string) ([]*tracker.Comment, error) {
+	iid, err := parseIID(key)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.api.ListNotes(ctx, location.ID, iid)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	list := []*tracker.Comment{}
+	for idx := range res {
+		if res[idx].System {
+			continue
+		}
+		list = append(list, providerComment(&res[idx]))
+	}
+	return list, nil
+}
+
+func (p *Provider) AddComment(ctx context.Context, location tracker.Location, key string, comment *tracker.Comment) (*tracker.Comment, error) {
+	iid, err := parseIID(key)
+	if err != nil {
+		return nil, err
+	}
+	res, err := p.api.CreateNote(ctx, location.ID, iid, comment.Body)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return providerComment(res), nil
+}
+
+// ListMembers is not supported (the assignees are the IDs of the users from the spec of sync).
+func (p *Provider) ListMembers(ctx context.Context, location tracker.Location) ([]*tracker.Member, error) {
+	return nil, fmt.Errorf("the members of the project %q are not supported", location.ID)
+}
+
+func notFoundErr(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return tracker.ErrNotFound
+	}
+	return err
+}
+
+func parseIID(key string) (int, error) {
+	iid, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("invalid key %q of the issue of GitLab: %w", key, err)
+	}
+	return iid, nil
+}
+
+// returns the attributes of the request by the fields of the task (the labels are changed by add_labels and remove_labels)
+func (p *Provider) issueAttrs(ctx context.Context, location tracker.Location, task *tracker.Task,
+	current *Issue, fields []string) (map[string]interface{}, error) {
+
+	currentLabels := []string{}
+	if current != nil {
+		currentLabels = current.Labels
+	}
+	addLabels, removeLabels := []string{}, []string{}
+	// replaces the labels with the prefix (or the labels without the managed prefixes if the prefix is empty)
+	replaceLabels := func(prefix string, labels []string) {
+		for _, label := range currentLabels {
+			if p.labelPrefix(location, label) == prefix && !containsFold(labels, label) {
+				removeLabels = append(removeLabels, label)
+			}
+		}
+		for _, label := range labels {
+			if !containsFold(currentLabels, label) {
+				addLabels = append(addLabels, label)
+			}
+		}
+	}
+
+	attrs := map[string]interface{}{}
+	for _, field := range fields {
+		switch field {
+		case tracker.FieldName:
+			attrs["title"] = task.Name
+		case tracker.FieldDescription:
+			attrs["description"] = task.Description
+		case tracker.FieldTags:
+			replaceLabels("", task.Tags)
+		case tracker.FieldAssignees:
+			ids := []int64{}
+			for _, member := range task.Assignees {
+				if id, err := strconv.ParseInt(member.ID, 10, 64); err == nil {
+					ids = append(ids, id)
+				}
+			}
+			attrs["assignee_ids"] = ids
+		case tracker.FieldState:
+			if current == nil || task.Closed != (current.State == "closed") {
+				attrs["state_event"] = "reopen"
+				if task.Closed {
+					attrs["state_event"] = "close"
+				}
+			}
+		case tracker.FieldDueDate:
+			attrs["due_date"] = nil
+			if task.DueDate != nil {
+				attrs["due_date"] = task.DueDate.UTC().Format(dateLayout)
+			}
+		case tracker.FieldStatus:
+			labels := []string{}
+			if task.Status != "" {
+				labels = append(labels, statusLabelPrefix+task.Status)
+			}
+			replaceLabels(statusLabelPrefix, labels)
+		case tracker.FieldPriority:
+			if location.Property(field) == AttrWeight {
+				attrs["weight"] = nil
+				if task.PriorityID != nil && *task.PriorityID > 0 {
+					attrs["weight"] = 5 - *task.PriorityID
+				}
+				continue
+			}
+			labels := []string{}
+			if task.PriorityID != nil && priorityNames[*task.PriorityID] != "" {
+				labels = append(labels, priorityLabelPrefix+priorityNames[*task.PriorityID])
+			}
+			replaceLabels(priorityLabelPrefix, labels)
+		case tracker.FieldEstimate:
+			attrs["weight"] = nil
+			if task.TimeEstimateMs != nil && *task.TimeEstimateMs > 0 {
+				attrs["weight"] = (*task.TimeEstimateMs + hourMs - 1) / hourMs
+			}
+		case tracker.FieldMilestone:
+			milestoneID, err := p.milestoneID(ctx, location, task.Milestone)
+			if err != nil {
+				return nil, err
+			}
+			attrs["milestone_id"] = milestoneID
+		}
+	}
+	if len(addLabels) > 0 {
+		attrs["add_labels"] = strings.Join(addLabels, ",")
+	}
+	if len(removeLabels) > 0 {
+		attrs["remove_labels"] = strings.Join(removeLabels, ",")
+	}
+	return attrs, nil
+}
+
+// returns ID of the active milestone by the title or 0 (removes the milestone of the issue) if not found
+func (p *Provider) milestoneID(ctx context.Context, location tracker.Location, title string) (int64, error) {
+	if title == "" {
+		return 0, nil
+	}
+	list, err := p.api.ListMilestones(ctx, location.ID, title)
+	if err != nil {
+		return 0, err
+	}
+	for _, milestone := range list {
+		if milestone.Title == title {
+			return milestone.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+// returns the managed prefix of the label (the status or the priority by the mapping) or ""
+func (p *Provider) labelPrefix(location tracker.Location, label string) string {
+	lower := strings.ToLower(label)
+	if location.Property(tracker.FieldStatus) == AttrLabels && strings.HasPrefix(lower, statusLabelPrefix) {
+		return statusLabelPrefix
+	}
+	if location.Property(tracker.FieldPriority) == AttrLabels && strings.HasPrefix(lower, priorityLabelPrefix) {
+		return priorityLabelPrefix
+	}
+	return ""
+}
+
+func (p *Provider) providerTask(location tracker.Location, in *Issue) *tracker.Task {
+	res := &tracker.Task{
+		Ref:         tracker.TaskRef{Provider: ProviderName, ID: fmt.Sprint(in.ID)},
+		Key:         fmt.Sprint(in.IID),
+		Location:    location,
+		Name:        in.Title,
+		Description: in.Description,
+		Tags:        []string{},
+		Assignees:   []tracker.Member{},
+		Closed:      in.State == "closed",
+		URL:         in.WebURL,
+		UpdatedAt:   in.UpdatedAt,
+	}
+	for _, label := range in.Labels {
+		switch p.labelPrefix(location, label) {
+		case statusLabelPrefix:
+			res.Status = label[len(statusLabelPrefix):]
+		case priorityLabelPrefix:
+			name := label[len(priorityLabelPrefix):]
+			for id, priorityName := range priorityNames {
+				if strings.EqualFold(name, priorityName) {
+					priorityID := id
+					res.PriorityID = &priorityID
+				}
+			}
+		default:
+			res.Tags = append(res.Tags, label)
+		}
+	}
+	for _, user := range in.Assignees {
+		res.Assignees = append(res.Assignees, tracker.Member{ID: fmt.Sprint(user.ID), Name: user.Username})
+	}
+	if in.DueDate != nil {
+		if dueDate, err := time.Parse(dateLayout, *in.DueDate); err == nil {
+			res.DueDate = &dueDate
+		}
+	}
+	if in.Milestone != nil {
+		res.Milestone = in.Milestone.Title
+	}
+	if in.Weight != nil && *in.Weight > 0 {
+		switch {
+		case location.Property(tracker.FieldPriority) == AttrWeight:
+			priorityID := 5 - *in.Weight
+			if priorityID < 1 {
+				priorityID = 1
+			}
+			res.PriorityID = &priorityID
+		case location.Property(tracker.FieldEstimate) == AttrWeight:
+			estimate := int64(*in.Weight) * hourMs
+			res.TimeEstimateMs = &estimate
+		}
+	}
+	return res
+}
+
+func providerComment(in *Note) *tracker.Comment {
+	return &tracker.Comment{
+		ID:     fmt.Sprint(in.ID),
+		Author: tracker.Member{ID: fmt.Sprint(in.Author.ID), Name: in.Author.Username},
+		Body:   in.Body,
+	}
+}
+
+func containsFold(list []string, in string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, in) {
+			return true
+		}
+	}
+	return false
+}
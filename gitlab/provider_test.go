@@ -0,0 +1,366 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+	"github.com/gebv/asap-tools/tracker/trackertest"
+)
+
+const (
+	testToken   = "test-token"
+	testProject = "group/project"
+)
+
+var testLocation = tracker.Location{
+	Provider: ProviderName,
+	ID:       testProject,
+	Properties: map[string]string{
+		tracker.FieldStatus:    AttrLabels,
+		tracker.FieldPriority:  AttrLabels,
+		tracker.FieldEstimate:  AttrWeight,
+		tracker.FieldMilestone: AttrMilestone,
+	},
+}
+
+// fakeAPI the in-memory GitLab REST API (only the issues, the notes and the milestones of the project group/project)
+type fakeAPI struct {
+	url        string
+	issues     map[int]*Issue
+	notes      map[int][]Note
+	milestones []Milestone
+	nextID     int64
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		issues:     map[int]*Issue{},
+		notes:      map[int][]Note{},
+		milestones: []Milestone{{ID: 7, Title: "Sprint 1", State: "active"}, {ID: 8, Title: "Sprint 2", State: "active"}},
+		nextID:     1000,
+	}
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /api/v4/projects/{escaped project}/issues[/{iid}[/notes]] or /milestones
+	args := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4/projects/"), "/")
+	if project, _ := url.PathUnescape(args[0]); project != testProject || len(args) < 2 {
+		trackertest.WriteJSON(w, http.StatusNotFound, map[string]string{"message": "404 Project Not Found"})
+		return
+	}
+
+	if args[1] == "milestones" && r.Method == http.MethodGet {
+		list := []Milestone{}
+		for _, milestone := range f.milestones {
+			if milestone.Title == r.URL.Query().Get("title") {
+				list = append(list, milestone)
+			}
+		}
+		trackertest.WriteJSON(w, http.StatusOK, list)
+		return
+	}
+	if args[1] != "issues" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(args) == 2 && r.Method == http.MethodPost {
+		attrs := map[string]json.RawMessage{}
+		json.NewDecoder(r.Body).Decode(&attrs)
+		f.nextID++
+		issue := &Issue{ID: f.nextID, IID: len(f.issues) + 1, State: "opened", Labels: []string{}, Assignees: []User{}}
+		issue.WebURL = fmt.Sprintf("https://gitlab.example.com/%s/-/issues/%d", testProject, issue.IID)
+		f.applyAttrs(issue, attrs)
+		f.issues[issue.IID] = issue
+		trackertest.WriteJSON(w, http.StatusCreated, issue)
+		return
+	}
+
+	if len(args) < 3 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	iid, _ := strconv.Atoi(args[2])
+	issue, exists := f.issues[iid]
+	if !exists {
+		trackertest.WriteJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not found"})
+		return
+	}
+
+	switch {
+	case len(args) == 3 && r.Method == http.MethodGet:
+		trackertest.WriteJSON(w, http.StatusOK, issue)
+	case len(args) == 3 && r.Method == http.MethodPut:
+		attrs := map[string]json.RawMessage{}
+		json.NewDecoder(r.Body).Decode(&attrs)
+		f.applyAttrs(issue, attrs)
+		trackertest.WriteJSON(w, http.StatusOK, issue)
+	case len(args) == 4 && args[3] == "notes" && r.Method == http.MethodPost:
+		req := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.nextID++
+		note := Note{ID: f.nextID, Body: req["body"], Author: User{ID: 1, Username: "bot"}}
+		f.notes[iid] = append(f.notes[iid], note)
+		trackertest.WriteJSON(w, http.StatusCreated, note)
+	case len(args) == 4 && args[3] == "notes" && r.Method == http.MethodGet:
+		list := f.notes[iid]
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		from, to := trackertest.Page(len(list), (page-1)*size, size)
+		trackertest.WriteJSON(w, http.StatusOK, list[from:to])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeAPI) applyAttrs(issue *Issue, attrs map[string]json.RawMessage) {
+	splitLabels := func(value json.RawMessage) []string {
+		labels := ""
+		json.Unmarshal(value, &labels)
+		if labels == "" {
+			return []string{}
+		}
+		return strings.Split(labels, ",")
+	}
+
+	for name, value := range attrs {
+		switch name {
+		case "title":
+			json.Unmarshal(value, &issue.Title)
+		case "description":
+			json.Unmarshal(value, &issue.Description)
+		case "labels":
+			issue.Labels = splitLabels(value)
+		case "add_labels":
+			issue.Labels = append(issue.Labels, splitLabels(value)...)
+		case "remove_labels":
+			labels := []string{}
+			for _, label := range issue.Labels {
+				if !containsFold(splitLabels(value), label) {
+					labels = append(labels, label)
+				}
+			}
+			issue.Labels = labels
+		case "assignee_ids":
+			ids := []int64{}
+			json.Unmarshal(value, &ids)
+			issue.Assignees = []User{}
+			for _, id := range ids {
+				issue.Assignees = append(issue.Assignees, User{ID: id, Username: fmt.Sprint("user", id)})
+			}
+		case "state_event":
+			event := ""
+			json.Unmarshal(value, &event)
+			issue.State = map[string]string{"close": "closed", "reopen": "opened"}[event]
+		case "due_date":
+			issue.DueDate = nil
+			json.Unmarshal(value, &issue.DueDate)
+		case "weight":
+			issue.Weight = nil
+			json.Unmarshal(value, &issue.Weight)
+		case "milestone_id":
+			id := int64(0)
+			json.Unmarshal(value, &id)
+			issue.Milestone = nil
+			for _, milestone := range f.milestones {
+				if milestone.ID == id {
+					milestone := milestone
+					issue.Milestone = &milestone
+				}
+			}
+		}
+	}
+	issue.UpdatedAt = time.Now().UTC()
+}
+
+func newTestProvider(t *testing.T) (*Provider, *fakeAPI) {
+	fake := newFakeAPI()
+	fake.url = trackertest.NewServer(t, trackertest.HeaderAuth("PRIVATE-TOKEN", testToken), fake.ServeHTTP)
+	return NewProvider(NewAPI(fake.url, testToken)), fake
+}
+
+func TestProvider_Fields(t *testing.T) {
+	common := []string{
+		tracker.FieldName,
+		tracker.FieldDescription,
+		tracker.FieldTags,
+		tracker.FieldAssignees,
+		tracker.FieldState,
+		tracker.FieldDueDate,
+	}
+	tests := []struct {
+		name       string
+		properties map[string]string
+		want       []string
+	}{
+		{"without mapping", nil, common},
+		{"all", testLocation.Properties, append(common[:len(common):len(common)],
+			tracker.FieldStatus, tracker.FieldPriority, tracker.FieldEstimate, tracker.FieldMilestone)},
+		{"weight by priority", map[string]string{
+			tracker.FieldPriority: AttrWeight,
+			tracker.FieldEstimate: AttrWeight,
+		}, append(common[:len(common):len(common)], tracker.FieldPriority)},
+		{"unknown attributes", map[string]string{
+			tracker.FieldStatus:   AttrWeight,
+			tracker.FieldEstimate: AttrLabels,
+		}, common},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := tracker.Location{Provider: ProviderName, ID: testProject, Properties: tt.properties}
+			if got := NewProvider(nil).Fields(location); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_NormalizeTask(t *testing.T) {
+	dueDate := time.Date(2022, 2, 1, 18, 30, 0, 0, time.UTC)
+	estimate := int64(90 * time.Minute / time.Millisecond)
+	task := &tracker.Task{DueDate: &dueDate, TimeEstimateMs: &estimate}
+	NewProvider(nil).NormalizeTask(testLocation, task)
+
+	wantDueDate := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	wantEstimate := int64(2 * time.Hour / time.Millisecond)
+	want := &tracker.Task{DueDate: &wantDueDate, TimeEstimateMs: &wantEstimate}
+	if !reflect.DeepEqual(task, want) {
+		t.Errorf("NormalizeTask() = %+v, want %+v", task, want)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	provider, fake := newTestProvider(t)
+	dueDate := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	estimate := int64(3 * time.Hour / time.Millisecond)
+	priority := 2
+	suite := &trackertest.Suite{
+		Provider: provider,
+		Location: testLocation,
+		Task: &tracker.Task{
+			Name:           "Task",
+			Description:    "description",
+			Tags:           []string{"bug"},
+			Assignees:      []tracker.Member{{ID: "11"}, {ID: "12"}},
+			Status:         "in progress",
+			DueDate:        &dueDate,
+			TimeEstimateMs: &estimate,
+			PriorityID:     &priority,
+			Milestone:      "Sprint 1",
+		},
+		// closes the issue and removes the fields
+		Update: &tracker.Task{
+			Description: "changed",
+			Tags:        []string{"feature"},
+			Assignees:   []tracker.Member{{ID: "13"}},
+			Closed:      true,
+			Status:      "done",
+			Milestone:   "Sprint 2",
+		},
+		Remove: func(task *tracker.Task) {
+			iid, _ := strconv.Atoi(task.Key)
+			delete(fake.issues, iid)
+		},
+		PageSize:      perPage,
+		CommentAuthor: "1",
+	}
+	suite.Run(t)
+}
+
+func TestProvider_Issues(t *testing.T) {
+	ctx := context.Background()
+	provider, fake := newTestProvider(t)
+
+	priority := 2
+	created, err := provider.CreateTask(ctx, testLocation, &tracker.Task{
+		Name:       "Task",
+		Tags:       []string{"bug"},
+		Status:     "in progress",
+		PriorityID: &priority,
+	})
+	if err != nil {
+		t.Fatalf("CreateTask(): %v", err)
+	}
+	if created.Ref.ID != "1001" || created.Key != "1" || created.URL != "https://gitlab.example.com/group/project/-/issues/1" {
+		t.Errorf("CreateTask() = %+v, want the issue 1", created)
+	}
+	// the status and the priority by the scoped labels
+	if got, want := fake.issues[1].Labels, []string{"bug", "status::in progress", "priority::high"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("the labels of the created issue = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(created.Tags, []string{"bug"}) {
+		t.Errorf("the labels of the created issue = %v, want without the scoped labels", created.Tags)
+	}
+
+	// the labels added in GitLab are kept
+	fake.issues[1].Labels = append(fake.issues[1].Labels, "external")
+	created.Status = "done"
+	created.PriorityID = nil
+	created.Milestone = "Sprint 3"
+	updated, err := provider.UpdateTask(ctx, testLocation, created, []string{
+		tracker.FieldStatus,
+		tracker.FieldPriority,
+		tracker.FieldMilestone,
+	})
+	if err != nil {
+		t.Fatalf("UpdateTask(): %v", err)
+	}
+	if got, want := fake.issues[1].Labels, []string{"bug", "external", "status::done"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("the labels of the updated issue = %v, want %v", got, want)
+	}
+	if updated.Milestone != "" {
+		t.Errorf("the milestone of the updated issue = %q, want the not found milestone removed", updated.Milestone)
+	}
+
+	// the priority by the weight
+	weightLocation := tracker.Location{Provider: ProviderName, ID: testProject,
+		Properties: map[string]string{tracker.FieldPriority: AttrWeight}}
+	priority = 1
+	updated, err = provider.UpdateTask(ctx, weightLocation, &tracker.Task{Key: "1", PriorityID: &priority},
+		[]string{tracker.FieldPriority})
+	if err != nil {
+		t.Fatalf("UpdateTask(): %v", err)
+	}
+	if *fake.issues[1].Weight != 4 || updated.PriorityID == nil || *updated.PriorityID != 1 {
+		t.Errorf("UpdateTask() with the priority by the weight: weight %v, priority %v", *fake.issues[1].Weight, updated.PriorityID)
+	}
+
+	if _, err := provider.GetTask(ctx, testLocation, "PRJ-1"); err == nil {
+		t.Error("GetTask() with the invalid key must return error")
+	}
+	if _, err := provider.CreateTask(ctx, tracker.Location{Provider: ProviderName, ID: "other"},
+		&tracker.Task{Name: "Task"}); err == nil {
+		t.Error("CreateTask() in the not found project must return error")
+	}
+}
+
+func TestProvider_ListComments(t *testing.T) {
+	ctx := context.Background()
+	provider, fake := newTestProvider(t)
+
+	task, err := provider.CreateTask(ctx, testLocation, &tracker.Task{Name: "Task"})
+	if err != nil {
+		t.Fatalf("CreateTask(): %v", err)
+	}
+	if _, err := provider.AddComment(ctx, testLocation, task.Key, &tracker.Comment{Body: "comment"}); err != nil {
+		t.Fatalf("AddComment(): %v", err)
+	}
+	// the system notes are skipped
+	fake.notes[1] = append(fake.notes[1], Note{ID: 1, Body: "changed the description", System: true})
+	list, err := provider.ListComments(ctx, testLocation, task.Key)
+	if err != nil {
+		t.Fatalf("ListComments(): %v", err)
+	}
+	if len(list) != 1 || list[0].Body != "comment" || list[0].Author.Name != "bot" {
+		t.Errorf("ListComments() = %+v, want only the comment of the bot", list)
+	}
+}
@@ -0,0 +1,81 @@
+package gitlab
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// the header with the secret token of the webhook
+const tokenHeader = "X-Gitlab-Token"
+
+// the header with the type of the event
+const eventHeader = "X-Gitlab-Event"
+
+// the payload of the issue events and the comment events (only the used fields)
+type webhookEvent struct {
+	ObjectKind       string `json:"object_kind"`
+	ObjectAttributes struct {
+		ID           int64  `json:"id"`
+		NoteableType string `json:"noteable_type"`
+	} `json:"object_attributes"`
+	// the issue of the comment
+	Issue *struct {
+		ID int64 `json:"id"`
+	} `json:"issue"`
+}
+
+// NewWebhookHandler returns the handler of the webhooks of GitLab (the URL of the handler is added to the settings of the project).
+// The request is verified by the secret token (all requests are rejected if the secret is empty).
+// The handle is called with the ID of the issue (tracker.TaskRef.ID) on the issue events and on the comments of the issues,
+// the other events are ignored.
+func NewWebhookHandler(secret string, handle func(ctx context.Context, issueID string) error) http.Handler {
+	l := zap.L().Named("gitlab_webhook")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if secret == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get(tokenHeader)), []byte(secret)) != 1 {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		dat, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed read body", http.StatusBadRequest)
+			return
+		}
+		event := &webhookEvent{}
+		if err := json.Unmarshal(dat, event); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		issueID := int64(0)
+		switch {
+		case event.ObjectKind == "issue":
+			issueID = event.ObjectAttributes.ID
+		case event.ObjectKind == "note" && event.ObjectAttributes.NoteableType == "Issue" && event.Issue != nil:
+			issueID = event.Issue.ID
+		}
+		if issueID == 0 {
+			l.Debug("Skip event", zap.String("event", r.Header.Get(eventHeader)), zap.String("object_kind", event.ObjectKind))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if err := handle(r.Context(), fmt.Sprint(issueID)); err != nil {
+			l.Warn("Failed handle event", zap.String("event", r.Header.Get(eventHeader)), zap.Int64("issue_id", issueID), zap.Error(err))
+			http.Error(w, "failed handle event", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -0,0 +1,68 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		event      string
+		body       string
+		handleErr  error
+		wantStatus int
+		wantIssue  string
+	}{
+		{"issue event", "secret", "Issue Hook", `{"object_kind":"issue","object_attributes":{"id":1001,"iid":1}}`, nil, http.StatusOK, "1001"},
+		{"issue comment", "secret", "Note Hook", `{"object_kind":"note","object_attributes":{"id":5,"noteable_type":"Issue"},"issue":{"id":1002}}`, nil, http.StatusOK, "1002"},
+		{"merge request comment", "secret", "Note Hook", `{"object_kind":"note","object_attributes":{"id":5,"noteable_type":"MergeRequest"}}`, nil, http.StatusOK, ""},
+		{"push event", "secret", "Push Hook", `{"object_kind":"push"}`, nil, http.StatusOK, ""},
+		{"invalid token", "other", "Issue Hook", `{"object_kind":"issue","object_attributes":{"id":1001}}`, nil, http.StatusUnauthorized, ""},
+		{"invalid payload", "secret", "Issue Hook", `{`, nil, http.StatusBadRequest, ""},
+		{"failed handle", "secret", "Issue Hook", `{"object_kind":"issue","object_attributes":{"id":1001}}`, errors.New("failed"), http.StatusInternalServerError, "1001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotIssue := ""
+			handler := NewWebhookHandler("secret", func(ctx context.Context, issueID string) error {
+				gotIssue = issueID
+				return tt.handleErr
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/gitlab", strings.NewReader(tt.body))
+			req.Header.Set(tokenHeader, tt.token)
+			req.Header.Set(eventHeader, tt.event)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotIssue != tt.wantIssue {
+				t.Errorf("handled issue = %q, want %q", gotIssue, tt.wantIssue)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_withoutSecret(t *testing.T) {
+	handler := NewWebhookHandler("", func(ctx context.Context, issueID string) error {
+		t.Errorf("the event of the issue %q is handled without the secret", issueID)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/gitlab", strings.NewReader(`{"object_kind":"issue","object_attributes":{"id":1001}}`))
+	req.Header.Set(eventHeader, "Issue Hook")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,233 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// the max size of the page of the list requests
+const maxResults = 100
+
+// ErrNotFound returns API if the issue is not found (or the user has no access to the issue).
+var ErrNotFound = errors.New("jira: not found")
+
+type httpClientLogger struct {
+	*zap.Logger
+}
+
+func (l *httpClientLogger) Printf(msg string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(msg, args...))
+}
+
+// NewAPI returns the client of Jira Cloud REST API v2 (baseURL is the site, eg https://<site>.atlassian.net).
+// The requests are authorized by the email of the user and API token.
+func NewAPI(baseURL, email, apiToken string) *API {
+	l := zap.L().Named("jira_api")
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = &httpClientLogger{l.Named("http")}
+
+	return &API{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		email:    email,
+		apiToken: apiToken,
+		client:   httpClient.StandardClient(),
+		log:      l,
+	}
+}
+
+type API struct {
+	baseURL  string
+	email    string
+	apiToken string
+	client   *http.Client
+	log      *zap.Logger
+}
+
+// BrowseURL returns the link to the issue on the site.
+func (a *API) BrowseURL(key string) string {
+	return a.baseURL + "/browse/" + key
+}
+
+type Issue struct {
+	ID     string      `json:"id"`
+	Key    string      `json:"key"`
+	Fields IssueFields `json:"fields"`
+}
+
+type IssueFields struct {
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	Labels      []string  `json:"labels"`
+	Assignee    *User     `json:"assignee"`
+	Status      *Status   `json:"status"`
+	Priority    *Priority `json:"priority"`
+	// the date in the format 2006-01-02
+	DueDate string `json:"duedate"`
+	// the original estimate in seconds
+	TimeOriginalEstimate *int64 `json:"timeoriginalestimate"`
+	Updated              string `json:"updated"`
+}
+
+type User struct {
+	AccountID   string `json:"accountId"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+type Status struct {
+	ID             string `json:"id,omitempty"`
+	Name           string `json:"name"`
+	StatusCategory struct {
+		// new, indeterminate, done
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+type Priority struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+type Transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// the status of the issue after the transition
+	To Status `json:"to"`
+}
+
+type Comment struct {
+	ID     string `json:"id"`
+	Body   string `json:"body"`
+	Author User   `json:"author"`
+}
+
+// the fields of the issue requested by GetIssue
+const getIssueFields = "summary,description,labels,assignee,status,priority,duedate,timeoriginalestimate,updated"
+
+// CreateIssue creates the issue with the fields (eg {"project": {"key": "PRJ"}, "summary": "..."}) and returns the ID and the key.
+func (a *API) CreateIssue(ctx context.Context, fields map[string]interface{}) (*Issue, error) {
+	res := &Issue{}
+	err := a.doRequest(ctx, http.MethodPost, "/rest/api/2/issue", nil, map[string]interface{}{"fields": fields}, res)
+	return res, err
+}
+
+// UpdateIssue updates only the specified fields of the issue.
+func (a *API) UpdateIssue(ctx context.Context, key string, fields map[string]interface{}) error {
+	return a.doRequest(ctx, http.MethodPut, "/rest/api/2/issue/"+key, nil, map[string]interface{}{"fields": fields}, nil)
+}
+
+func (a *API) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	query := url.Values{}
+	query.Set("fields", getIssueFields)
+	res := &Issue{}
+	err := a.doRequest(ctx, http.MethodGet, "/rest/api/2/issue/"+key, query, nil, res)
+	return res, err
+}
+
+// ListTransitions returns the transitions available for the issue in the current status.
+func (a *API) ListTransitions(ctx context.Context, key string) ([]Transition, error) {
+	res := &struct {
+		Transitions []Transition `json:"transitions"`
+	}{}
+	err := a.doRequest(ctx, http.MethodGet, "/rest/api/2/issue/"+key+"/transitions", nil, nil, res)
+	return res.Transitions, err
+}
+
+func (a *API) DoTransition(ctx context.Context, key, transitionID string) error {
+	req := map[string]interface{}{
+		"transition": map[string]string{"id": transitionID},
+	}
+	return a.doRequest(ctx, http.MethodPost, "/rest/api/2/issue/"+key+"/transitions", nil, req, nil)
+}
+
+// ListComments returns all comments of the issue (the oldest first).
+func (a *API) ListComments(ctx context.Context, key string) ([]Comment, error) {
+	list := []Comment{}
+	for {
+		query := url.Values{}
+		query.Set("startAt", fmt.Sprint(len(list)))
+		query.Set("maxResults", fmt.Sprint(maxResults))
+
+		res := &struct {
+			Comments []Comment `json:"comments"`
+			Total    int       `json:"total"`
+		}{}
+		if err := a.doRequest(ctx, http.MethodGet, "/rest/api/2/issue/"+key+"/comment", query, nil, res); err != nil {
+			return nil, err
+		}
+		list = append(list, res.Comments...)
+		if len(res.Comments) == 0 || len(list) >= res.Total {
+			return list, nil
+		}
+	}
+}
+
+func (a *API) AddComment(ctx context.Context, key, body string) (*Comment, error) {
+	res := &Comment{}
+	err := a.doRequest(ctx, http.MethodPost, "/rest/api/2/issue/"+key+"/comment", nil, map[string]string{"body": body}, res)
+	return res, err
+}
+
+func (a *API) doRequest(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	reqURL := a.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	body := bytes.NewReader(nil)
+	if in != nil {
+		dat, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(dat)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(a.email, a.apiToken)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	dat, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	a.log.Debug("API request", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("method", method))
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case res.StatusCode >= 300:
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("body_raw", string(dat)))
+		return fmt.Errorf("jira: %s %s: got status %d", method, path, res.StatusCode)
+	}
+
+	if out == nil || len(dat) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(dat, out); err != nil {
+		return fmt.Errorf("jira: failed decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
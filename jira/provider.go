@@ -0,0 +1,289 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+// ProviderName name of Jira in the spec of sync (spec_add.external[].tracker) and in the task references.
+const ProviderName = "jira"
+
+// the type of the new issues
+const issueType = "Task"
+
+// the format of the dates and the times in Jira API
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04:05.000-0700"
+)
+
+// the names of the priorities of Jira by the priorities of ClickUp (1 urgent, 2 high, 3 normal, 4 low)
+var priorityNames = map[int]string{
+	1: "Highest",
+	2: "High",
+	3: "Medium",
+	4: "Low",
+}
+
+// NewProvider returns Jira Cloud as the provider of the tasks (the issues).
+// The location of the issues is the key of the project, the key is the key of the issue, the members are the account IDs of Jira.
+// The status of the issue is changed by the workflow transition to the status mapped by status_map,
+// the issue is closed if the status is in the done category.
+// Jira is the destination of the mirror tasks only (the issues are created from the original tasks in ClickUp, see spec_add.external).
+func NewProvider(api *API) *Provider {
+	return &Provider{api: api}
+}
+
+type Provider struct {
+	api *API
+}
+
+var (
+	_ tracker.Provider   = (*Provider)(nil)
+	_ tracker.Normalizer = (*Provider)(nil)
+)
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+func (p *Provider) Capabilities() tracker.Capability {
+	return tracker.CapComments | tracker.CapTags | tracker.CapAssignees | tracker.CapStatus | tracker.CapPriority |
+		tracker.CapDueDate | tracker.CapEstimate
+}
+
+func (p *Provider) Fields(location tracker.Location) []string {
+	return []string{
+		tracker.FieldName,
+		tracker.FieldDescription,
+		tracker.FieldTags,
+		tracker.FieldAssignees,
+		tracker.FieldStatus,
+		tracker.FieldDueDate,
+		tracker.FieldEstimate,
+		tracker.FieldPriority,
+	}
+}
+
+// NormalizeTask truncates the due date to the day (in UTC) and the estimate to the minutes,
+// only the first assignee is kept (the issue of Jira has one assignee).
+func (p *Provider) NormalizeTask(location tracker.Location, task *tracker.Task) {
+	if task.DueDate != nil {
+		dueDate := task.DueDate.UTC().Truncate(24 * time.Hour)
+		task.DueDate = &dueDate
+	}
+	if task.TimeEstimateMs != nil {
+		estimate := *task.TimeEstimateMs / int64(time.Minute/time.Millisecond) * int64(time.Minute/time.Millisecond)
+		task.TimeEstimateMs = &estimate
+	}
+	if len(task.Assignees) > 1 {
+		task.Assignees = task.Assignees[:1]
+	}
+}
+
+func (p *Provider) CreateTask(ctx context.Context, location tracker.Location, task *tracker.Task) (*tracker.Task, error) {
+	fields := issueFields(task, p.Fields(location))
+	fields["project"] = map[string]string{"key": location.ID}
+	fields["issuetype"] = map[string]string{"name": issueType}
+
+	created, err := p.api.CreateIssue(ctx, fields)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != "" {
+		if err := p.transition(ctx, created.Key, task.Status); err != nil {
+			return nil, err
+		}
+	}
+	return p.GetTask(ctx, location, created.Key)
+}
+
+func (p *Provider) UpdateTask(ctx context.Context, location tracker.Location, task *tracker.Task, fields []string) (*tracker.Task, error) {
+	if values := issueFields(task, fields); len(values) > 0 {
+		if err := p.api.UpdateIssue(ctx, task.Key, values); err != nil {
+			return nil, notFoundErr(err)
+		}
+	}
+	for _, field := range fields {
+		if field == tracker.FieldStatus && task.Status != "" {
+			if err := p.transition(ctx, task.Key, task.Status); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p.GetTask(ctx, location, task.Key)
+}
+
+// GetTask returns the issue by the key or tracker.ErrNotFound.
+func (p *Provider) GetTask(ctx context.Context, location tracker.Location, key string) (*tracker.Task, error) {
+	issue, err := p.api.GetIssue(ctx, key)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return p.providerTask(location, issue), nil
+}
+
+func (p *Provider) ListComments(ctx context.Context, location tracker.Location, key string) ([]*tracker.Comment, error) {
+	res, err := p.api.ListComments(ctx, key)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	list := []*tracker.Comment{}
+	for idx := range res {
+		list = append(list, p.providerComment(key, &res[idx]))
+	}
+	return list, nil
+}
+
+func (p *Provider) AddComment(ctx context.Context, location tracker.Location, key string, comment *tracker.Comment) (*tracker.Comment, error) {
+	res, err := p.api.AddComment(ctx, key, comment.Body)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return p.providerComment(key, res), nil
+}
+
+// ListMembers is not supported (the assignees are the account IDs from the spec of sync).
+func (p *Provider) ListMembers(ctx context.Context, location tracker.Location) ([]*tracker.Member, error) {
+	return nil, fmt.Errorf("the members of the project %q are not supported", location.ID)
+}
+
+func notFoundErr(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return tracker.ErrNotFound
+	}
+	return err
+}
+
+// moves the issue to the status by the available transition (nothing if the issue is already in the status)
+func (p *Provider) transition(ctx context.Context, key, status string) error {
+	issue, err := p.api.GetIssue(ctx, key)
+	if err != nil {
+		return notFoundErr(err)
+	}
+	if issue.Fields.Status != nil && strings.EqualFold(issue.Fields.Status.Name, status) {
+		return nil
+	}
+
+	list, err := p.api.ListTransitions(ctx, key)
+	if err != nil {
+		return notFoundErr(err)
+	}
+	for _, transition := range list {
+		if strings.EqualFold(transition.To.Name, status) || strings.EqualFold(transition.Name, status) {
+			return p.api.DoTransition(ctx, key, transition.ID)
+		}
+	}
+	return fmt.Errorf("not found the transition of the issue %q to the status %q", key, status)
+}
+
+// returns the values of the fields of the request by the fields of the task (except the status)
+func issueFields(task *tracker.Task, fields []string) map[string]interface{} {
+	res := map[string]interface{}{}
+	for _, field := range fields {
+		switch field {
+		case tracker.FieldName:
+			res["summary"] = task.Name
+		case tracker.FieldDescription:
+			res["description"] = task.Description
+		case tracker.FieldTags:
+			labels := []string{}
+			for _, label := range task.Tags {
+				// the labels of Jira can not contain the spaces
+				labels = append(labels, strings.ReplaceAll(label, " ", "_"))
+			}
+			res["labels"] = labels
+		case tracker.FieldAssignees:
+			res["assignee"] = nil
+			if len(task.Assignees) > 0 {
+				res["assignee"] = &User{AccountID: task.Assignees[0].ID}
+			}
+		case tracker.FieldDueDate:
+			res["duedate"] = nil
+			if task.DueDate != nil {
+				res["duedate"] = task.DueDate.UTC().Format(dateLayout)
+			}
+		case tracker.FieldEstimate:
+			// the estimate can not be removed, the zero estimate is set instead
+			minutes := int64(0)
+			if task.TimeEstimateMs != nil {
+				minutes = *task.TimeEstimateMs / int64(time.Minute/time.Millisecond)
+			}
+			res["timetracking"] = map[string]string{"originalEstimate": fmt.Sprintf("%dm", minutes)}
+		case tracker.FieldPriority:
+			if task.PriorityID != nil {
+				if name, exists := priorityNames[*task.PriorityID]; exists {
+					res["priority"] = &Priority{Name: name}
+				}
+			}
+		}
+	}
+	return res
+}
+
+// returns the priority of ClickUp by the priority of Jira (the lowest priority is low)
+func priorityID(in *Priority) *int {
+	if in == nil {
+		return nil
+	}
+	if strings.EqualFold(in.Name, "Lowest") {
+		res := 4
+		return &res
+	}
+	for id, name := range priorityNames {
+		if strings.EqualFold(in.Name, name) {
+			res := id
+			return &res
+		}
+	}
+	return nil
+}
+
+func (p *Provider) providerTask(location tracker.Location, in *Issue) *tracker.Task {
+	res := &tracker.Task{
+		Ref:         tracker.TaskRef{Provider: ProviderName, ID: in.ID},
+		Key:         in.Key,
+		Location:    location,
+		Name:        in.Fields.Summary,
+		Description: in.Fields.Description,
+		Tags:        []string{},
+		Assignees:   []tracker.Member{},
+		PriorityID:  priorityID(in.Fields.Priority),
+		URL:         p.api.BrowseURL(in.Key),
+	}
+	if in.Fields.Labels != nil {
+		res.Tags = in.Fields.Labels
+	}
+	if in.Fields.Assignee != nil {
+		res.Assignees = append(res.Assignees, tracker.Member{ID: in.Fields.Assignee.AccountID, Name: in.Fields.Assignee.DisplayName})
+	}
+	if in.Fields.Status != nil {
+		res.Status = in.Fields.Status.Name
+		res.Closed = in.Fields.Status.StatusCategory.Key == "done"
+	}
+	if dueDate, err := time.Parse(dateLayout, in.Fields.DueDate); err == nil {
+		res.DueDate = &dueDate
+	}
+	if in.Fields.TimeOriginalEstimate != nil && *in.Fields.TimeOriginalEstimate > 0 {
+		estimate := *in.Fields.TimeOriginalEstimate * int64(time.Second/time.Millisecond)
+		res.TimeEstimateMs = &estimate
+	}
+	if updatedAt, err := time.Parse(dateTimeLayout, in.Fields.Updated); err == nil {
+		res.UpdatedAt = updatedAt
+	}
+	return res
+}
+
+func (p *Provider) providerComment(key string, in *Comment) *tracker.Comment {
+	return &tracker.Comment{
+		ID:     in.ID,
+		Author: tracker.Member{ID: in.Author.AccountID, Name: in.Author.DisplayName},
+		Body:   in.Body,
+		URL:    p.api.BrowseURL(key) + "?focusedCommentId=" + in.ID,
+	}
+}
@@ -0,0 +1,270 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+	"github.com/gebv/asap-tools/tracker/trackertest"
+)
+
+const (
+	testEmail = "bot@example.com"
+	testToken = "test-token"
+)
+
+var testLocation = tracker.Location{Provider: ProviderName, ID: "PRJ"}
+
+// the workflow of the fake project (any status is available from another status)
+var testStatuses = map[string]string{
+	"11": "To Do",
+	"21": "In Progress",
+	"31": "Done",
+}
+
+var testStatusCategories = map[string]string{
+	"To Do":       "new",
+	"In Progress": "indeterminate",
+	"Done":        "done",
+}
+
+// fakeAPI the in-memory Jira REST API (only the issues of the project PRJ and the comments of the issues)
+type fakeAPI struct {
+	url      string
+	issues   map[string]*Issue
+	comments map[string][]Comment
+	nextID   int
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		issues:   map[string]*Issue{},
+		comments: map[string][]Comment{},
+		nextID:   10000,
+	}
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /rest/api/2/issue[/{key}[/transitions|/comment]]
+	args := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/api/2/"), "/")
+	if args[0] != "issue" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(args) == 1 && r.Method == http.MethodPost {
+		req := &struct {
+			Fields map[string]json.RawMessage `json:"fields"`
+		}{}
+		json.NewDecoder(r.Body).Decode(req)
+		project := &struct{ Key string }{}
+		json.Unmarshal(req.Fields["project"], project)
+		if project.Key != "PRJ" {
+			trackertest.WriteJSON(w, http.StatusBadRequest, map[string]interface{}{"errors": map[string]string{"project": "not found"}})
+			return
+		}
+		f.nextID++
+		issue := &Issue{ID: strconv.Itoa(f.nextID), Key: fmt.Sprintf("PRJ-%d", len(f.issues)+1)}
+		issue.Fields.Labels = []string{}
+		setStatus(issue, "To Do")
+		applyFields(issue, req.Fields)
+		f.issues[issue.Key] = issue
+		trackertest.WriteJSON(w, http.StatusCreated, map[string]string{"id": issue.ID, "key": issue.Key})
+		return
+	}
+
+	if len(args) < 2 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	issue, exists := f.issues[args[1]]
+	if !exists {
+		trackertest.WriteJSON(w, http.StatusNotFound, map[string]interface{}{"errorMessages": []string{"Issue does not exist"}})
+		return
+	}
+
+	switch {
+	case len(args) == 2 && r.Method == http.MethodGet:
+		trackertest.WriteJSON(w, http.StatusOK, issue)
+	case len(args) == 2 && r.Method == http.MethodPut:
+		req := &struct {
+			Fields map[string]json.RawMessage `json:"fields"`
+		}{}
+		json.NewDecoder(r.Body).Decode(req)
+		applyFields(issue, req.Fields)
+		w.WriteHeader(http.StatusNoContent)
+	case len(args) == 3 && args[2] == "transitions" && r.Method == http.MethodGet:
+		list := []Transition{}
+		for _, id := range []string{"11", "21", "31"} {
+			if testStatuses[id] != issue.Fields.Status.Name {
+				list = append(list, Transition{ID: id, Name: "Move to " + testStatuses[id], To: Status{Name: testStatuses[id]}})
+			}
+		}
+		trackertest.WriteJSON(w, http.StatusOK, map[string]interface{}{"transitions": list})
+	case len(args) == 3 && args[2] == "transitions" && r.Method == http.MethodPost:
+		req := &struct {
+			Transition struct{ ID string } `json:"transition"`
+		}{}
+		json.NewDecoder(r.Body).Decode(req)
+		status, exists := testStatuses[req.Transition.ID]
+		if !exists || status == issue.Fields.Status.Name {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		setStatus(issue, status)
+		w.WriteHeader(http.StatusNoContent)
+	case len(args) == 3 && args[2] == "comment" && r.Method == http.MethodPost:
+		req := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.nextID++
+		comment := Comment{ID: strconv.Itoa(f.nextID), Body: req["body"], Author: User{AccountID: "bot"}}
+		f.comments[issue.Key] = append(f.comments[issue.Key], comment)
+		trackertest.WriteJSON(w, http.StatusCreated, comment)
+	case len(args) == 3 && args[2] == "comment" && r.Method == http.MethodGet:
+		list := f.comments[issue.Key]
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+		from, to := trackertest.Page(len(list), startAt, size)
+		trackertest.WriteJSON(w, http.StatusOK, map[string]interface{}{"comments": list[from:to], "startAt": from, "total": len(list)})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func setStatus(issue *Issue, name string) {
+	issue.Fields.Status = &Status{Name: name}
+	issue.Fields.Status.StatusCategory.Key = testStatusCategories[name]
+}
+
+func applyFields(issue *Issue, fields map[string]json.RawMessage) {
+	for name, value := range fields {
+		switch name {
+		case "summary":
+			json.Unmarshal(value, &issue.Fields.Summary)
+		case "description":
+			json.Unmarshal(value, &issue.Fields.Description)
+		case "labels":
+			json.Unmarshal(value, &issue.Fields.Labels)
+		case "assignee":
+			issue.Fields.Assignee = nil
+			json.Unmarshal(value, &issue.Fields.Assignee)
+		case "priority":
+			json.Unmarshal(value, &issue.Fields.Priority)
+		case "duedate":
+			issue.Fields.DueDate = ""
+			json.Unmarshal(value, &issue.Fields.DueDate)
+		case "timetracking":
+			req := map[string]string{}
+			json.Unmarshal(value, &req)
+			minutes, _ := strconv.ParseInt(strings.TrimSuffix(req["originalEstimate"], "m"), 10, 64)
+			seconds := minutes * 60
+			issue.Fields.TimeOriginalEstimate = &seconds
+		}
+	}
+	issue.Fields.Updated = time.Now().UTC().Format(dateTimeLayout)
+}
+
+func newTestProvider(t *testing.T) (*Provider, *fakeAPI) {
+	fake := newFakeAPI()
+	authorized := func(r *http.Request) bool {
+		email, token, ok := r.BasicAuth()
+		return ok && email == testEmail && token == testToken
+	}
+	fake.url = trackertest.NewServer(t, authorized, fake.ServeHTTP)
+	return NewProvider(NewAPI(fake.url, testEmail, testToken)), fake
+}
+
+func TestProvider_NormalizeTask(t *testing.T) {
+	dueDate := time.Date(2022, 2, 1, 18, 30, 0, 0, time.UTC)
+	estimate := int64(90*time.Minute/time.Millisecond) + 500
+	task := &tracker.Task{Assignees: []tracker.Member{{ID: "a1"}, {ID: "a2"}}, DueDate: &dueDate, TimeEstimateMs: &estimate}
+	NewProvider(nil).NormalizeTask(testLocation, task)
+
+	wantDueDate := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	wantEstimate := int64(90 * time.Minute / time.Millisecond)
+	want := &tracker.Task{Assignees: []tracker.Member{{ID: "a1"}}, DueDate: &wantDueDate, TimeEstimateMs: &wantEstimate}
+	if !reflect.DeepEqual(task, want) {
+		t.Errorf("NormalizeTask() = %+v, want %+v", task, want)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	provider, fake := newTestProvider(t)
+	dueDate := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	estimate, changedEstimate := int64(90*time.Minute/time.Millisecond), int64(2*time.Hour/time.Millisecond)
+	priority, changedPriority := 2, 3
+	suite := &trackertest.Suite{
+		Provider: provider,
+		Location: testLocation,
+		Task: &tracker.Task{
+			Name:           "Task",
+			Description:    "description",
+			Tags:           []string{"bug"},
+			Assignees:      []tracker.Member{{ID: "acc-1"}},
+			Status:         "In Progress",
+			DueDate:        &dueDate,
+			TimeEstimateMs: &estimate,
+			PriorityID:     &priority,
+		},
+		// closes the issue by the transition and removes the due date and the assignee
+		Update: &tracker.Task{
+			Description:    "changed",
+			Tags:           []string{"bug", "ui"},
+			Assignees:      []tracker.Member{},
+			Status:         "Done",
+			TimeEstimateMs: &changedEstimate,
+			PriorityID:     &changedPriority,
+		},
+		Remove: func(task *tracker.Task) {
+			delete(fake.issues, task.Key)
+		},
+		PageSize:      maxResults,
+		CommentAuthor: "bot",
+	}
+	suite.Run(t)
+}
+
+func TestProvider_Issues(t *testing.T) {
+	ctx := context.Background()
+	provider, fake := newTestProvider(t)
+
+	// the spaces are not allowed in the labels
+	created, err := provider.CreateTask(ctx, testLocation, &tracker.Task{Name: "Task", Tags: []string{"bug", "needs review"}})
+	if err != nil {
+		t.Fatalf("CreateTask(): %v", err)
+	}
+	if created.Ref.ID != "10001" || created.Key != "PRJ-1" || created.URL != fake.url+"/browse/PRJ-1" {
+		t.Errorf("CreateTask() = %+v, want the issue PRJ-1", created)
+	}
+	if want := []string{"bug", "needs_review"}; !reflect.DeepEqual(created.Tags, want) {
+		t.Errorf("the labels of the created issue = %v, want %v", created.Tags, want)
+	}
+	if created.Status != "To Do" || created.Closed {
+		t.Errorf("the status of the created issue = %q (closed %v), want the initial status", created.Status, created.Closed)
+	}
+
+	// the status category done closes the issue
+	created.Status = "done"
+	updated, err := provider.UpdateTask(ctx, testLocation, created, []string{tracker.FieldStatus})
+	if err != nil {
+		t.Fatalf("UpdateTask(): %v", err)
+	}
+	if updated.Status != "Done" || !updated.Closed {
+		t.Errorf("the status of the updated issue = %q (closed %v), want Done", updated.Status, updated.Closed)
+	}
+
+	created.Status = "Blocked"
+	if _, err := provider.UpdateTask(ctx, testLocation, created, []string{tracker.FieldStatus}); err == nil {
+		t.Error("UpdateTask() to the status without the transition must return error")
+	}
+	if _, err := provider.CreateTask(ctx, tracker.Location{Provider: ProviderName, ID: "OTHER"}, &tracker.Task{Name: "Task"}); err == nil {
+		t.Error("CreateTask() in the not found project must return error")
+	}
+}
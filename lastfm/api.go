@@ -0,0 +1,225 @@
+package lastfm
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// DefaultBaseURL the URL of Last.fm API
+const DefaultBaseURL = "https://ws.audioscrobbler.com/2.0/"
+
+// the max size of the page of the loved tracks
+const pageSize = 200
+
+type httpClientLogger struct {
+	*zap.Logger
+}
+
+func (l *httpClientLogger) Printf(msg string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(msg, args...))
+}
+
+// Credentials the API account of Last.fm and the session of the user.
+type Credentials struct {
+	APIKey    string
+	APISecret string
+	// the session key of the user (see auth.getMobileSession or the web authentication)
+	SessionKey string
+	// the name of the user
+	User string
+}
+
+// NewAPI returns the client of Last.fm API (baseURL is DefaultBaseURL by default).
+// The write requests are signed by the secret and authorized by the session key of the user.
+func NewAPI(baseURL string, creds Credentials) *API {
+	l := zap.L().Named("lastfm_api")
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = &httpClientLogger{l.Named("http")}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &API{
+		baseURL: baseURL,
+		creds:   creds,
+		client:  httpClient.StandardClient(),
+		log:     l,
+	}
+}
+
+type API struct {
+	baseURL string
+	creds   Credentials
+	client  *http.Client
+	log     *zap.Logger
+}
+
+type Artist struct {
+	Name string `json:"name"`
+	MBID string `json:"mbid"`
+}
+
+type LovedTrack struct {
+	Name   string `json:"name"`
+	MBID   string `json:"mbid"`
+	URL    string `json:"url"`
+	Artist Artist `json:"artist"`
+	Date   struct {
+		UTS string `json:"uts"`
+	} `json:"date"`
+}
+
+// LovedAt returns the time of the love of the track.
+func (t *LovedTrack) LovedAt() time.Time {
+	uts, _ := strconv.ParseInt(t.Date.UTS, 10, 64)
+	return time.Unix(uts, 0)
+}
+
+// Error the unsuccessful response of Last.fm API (see https://www.last.fm/api/errorcodes).
+type Error struct {
+	Code    int    `json:"error"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("lastfm: %d %s", e.Code, e.Message)
+}
+
+// LovedTracks returns the tracks loved by the user after the time (the oldest first, all tracks for the zero time).
+func (a *API) LovedTracks(ctx context.Context, after time.Time) ([]LovedTrack, error) {
+	res := []LovedTrack{}
+	for page := 1; ; page++ {
+		resp := &struct {
+			LovedTracks struct {
+				Track []LovedTrack `json:"track"`
+				Attr  struct {
+					TotalPages string `json:"totalPages"`
+				} `json:"@attr"`
+			} `json:"lovedtracks"`
+		}{}
+		params := url.Values{
+			"method": {"user.getLovedTracks"},
+			"user":   {a.creds.User},
+			"limit":  {strconv.Itoa(pageSize)},
+			"page":   {strconv.Itoa(page)},
+		}
+		if err := a.doRequest(ctx, http.MethodGet, params, resp); err != nil {
+			return nil, err
+		}
+
+		// the newest first
+		for _, track := range resp.LovedTracks.Track {
+			if !track.LovedAt().After(after) {
+				return reverse(res), nil
+			}
+			res = append(res, track)
+		}
+		if totalPages, _ := strconv.Atoi(resp.LovedTracks.Attr.TotalPages); page >= totalPages {
+			return reverse(res), nil
+		}
+	}
+}
+
+// LoveTrack loves the track by the artist and the title.
+func (a *API) LoveTrack(ctx context.Context, artist, title string) error {
+	params := url.Values{
+		"method": {"track.love"},
+		"artist": {artist},
+		"track":  {title},
+		"sk":     {a.creds.SessionKey},
+	}
+	return a.doRequest(ctx, http.MethodPost, params, nil)
+}
+
+// returns the signature of the params of the request (the params are sorted by the name, the format is not signed)
+func (a *API) signature(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if key != "format" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	buf := &strings.Builder{}
+	for _, key := range keys {
+		buf.WriteString(key + params.Get(key))
+	}
+	buf.WriteString(a.creds.APISecret)
+	sum := md5.Sum([]byte(buf.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (a *API) doRequest(ctx context.Context, method string, params url.Values, out interface{}) error {
+	params.Set("api_key", a.creds.APIKey)
+	if method == http.MethodPost {
+		params.Set("api_sig", a.signature(params))
+	}
+	params.Set("format", "json")
+
+	var req *http.Request
+	var err error
+	if method == http.MethodPost {
+		req, err = http.NewRequestWithContext(ctx, method, a.baseURL, strings.NewReader(params.Encode()))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, a.baseURL+"?"+params.Encode(), nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		// the error contains the URL with the API key
+		return fmt.Errorf("lastfm: failed request %s", params.Get("method"))
+	}
+	defer res.Body.Close()
+
+	dat, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	a.log.Debug("API request", zap.String("status", res.Status), zap.String("method", params.Get("method")))
+
+	apiErr := &Error{}
+	if err := json.Unmarshal(dat, apiErr); err == nil && apiErr.Code != 0 {
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("method", params.Get("method")), zap.String("body_raw", string(dat)))
+		return apiErr
+	}
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("lastfm: %s: got status %d", params.Get("method"), res.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(dat, out); err != nil {
+		return fmt.Errorf("lastfm: failed decode response of %s: %w", params.Get("method"), err)
+	}
+	return nil
+}
+
+func reverse(in []LovedTrack) []LovedTrack {
+	for i, j := 0, len(in)-1; i < j; i, j = i+1, j-1 {
+		in[i], in[j] = in[j], in[i]
+	}
+	return in
+}
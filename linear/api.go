@@ -0,0 +1,293 @@
+package linear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// DefaultBaseURL the URL of Linear API
+const DefaultBaseURL = "https://api.linear.app"
+
+// the max size of the page of the list requests
+const pageSize = 100
+
+// ErrNotFound returns API if the issue or the team is not found (or the user has no access).
+var ErrNotFound = errors.New("linear: not found")
+
+type httpClientLogger struct {
+	*zap.Logger
+}
+
+func (l *httpClientLogger) Printf(msg string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(msg, args...))
+}
+
+// NewAPI returns the client of Linear GraphQL API (baseURL is DefaultBaseURL by default).
+// The requests are authorized by the personal API key.
+func NewAPI(baseURL, apiKey string) *API {
+	l := zap.L().Named("linear_api")
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = &httpClientLogger{l.Named("http")}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &API{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  httpClient.StandardClient(),
+		log:     l,
+	}
+}
+
+type API struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	log     *zap.Logger
+}
+
+type Team struct {
+	ID   string `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// WorkflowState the status of the issue in the workflow of the team.
+type WorkflowState struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// triage, backlog, unstarted, started, completed, canceled
+	Type string `json:"type"`
+}
+
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Issue struct {
+	ID string `json:"id"`
+	// the key of the issue (eg ENG-123)
+	Identifier  string         `json:"identifier"`
+	URL         string         `json:"url"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	State       *WorkflowState `json:"state"`
+	Assignee    *User          `json:"assignee"`
+	// 0 no priority, 1 urgent, 2 high, 3 medium, 4 low
+	Priority int `json:"priority"`
+	// the points of the estimate
+	Estimate *int `json:"estimate"`
+	// the date in the format 2006-01-02
+	DueDate   *string   `json:"dueDate"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Comment struct {
+	ID   string `json:"id"`
+	Body string `json:"body"`
+	URL  string `json:"url"`
+	User *User  `json:"user"`
+}
+
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// the fields of the issue requested by the queries
+const issueFields = `id identifier url title description state { id name type } assignee { id name } priority estimate dueDate updatedAt`
+
+// GetTeam returns the team (by the ID or the key) with the workflow states.
+func (a *API) GetTeam(ctx context.Context, idOrKey string) (*Team, []WorkflowState, error) {
+	res := &struct {
+		Team *struct {
+			Team
+			States struct {
+				Nodes []WorkflowState `json:"nodes"`
+			} `json:"states"`
+		} `json:"team"`
+	}{}
+	query := `query($id: String!) { team(id: $id) { id key name states { nodes { id name type } } } }`
+	if err := a.doRequest(ctx, query, map[string]interface{}{"id": idOrKey}, res); err != nil {
+		return nil, nil, err
+	}
+	if res.Team == nil {
+		return nil, nil, ErrNotFound
+	}
+	return &res.Team.Team, res.Team.States.Nodes, nil
+}
+
+// CreateIssue creates the issue by the input (eg {"teamId": "...", "title": "..."}).
+func (a *API) CreateIssue(ctx context.Context, input map[string]interface{}) (*Issue, error) {
+	res := &struct {
+		IssueCreate struct {
+			Issue *Issue `json:"issue"`
+		} `json:"issueCreate"`
+	}{}
+	query := `mutation($input: IssueCreateInput!) { issueCreate(input: $input) { success issue { ` + issueFields + ` } } }`
+	if err := a.doRequest(ctx, query, map[string]interface{}{"input": input}, res); err != nil {
+		return nil, err
+	}
+	if res.IssueCreate.Issue == nil {
+		return nil, fmt.Errorf("linear: the issue is not created")
+	}
+	return res.IssueCreate.Issue, nil
+}
+
+// UpdateIssue updates only the specified fields of the issue (by the ID or the identifier).
+func (a *API) UpdateIssue(ctx context.Context, id string, input map[string]interface{}) (*Issue, error) {
+	res := &struct {
+		IssueUpdate struct {
+			Issue *Issue `json:"issue"`
+		} `json:"issueUpdate"`
+	}{}
+	query := `mutation($id: String!, $input: IssueUpdateInput!) { issueUpdate(id: $id, input: $input) { success issue { ` + issueFields + ` } } }`
+	if err := a.doRequest(ctx, query, map[string]interface{}{"id": id, "input": input}, res); err != nil {
+		return nil, err
+	}
+	if res.IssueUpdate.Issue == nil {
+		return nil, ErrNotFound
+	}
+	return res.IssueUpdate.Issue, nil
+}
+
+// GetIssue returns the issue by the ID or the identifier (eg ENG-123).
+func (a *API) GetIssue(ctx context.Context, id string) (*Issue, error) {
+	res := &struct {
+		Issue *Issue `json:"issue"`
+	}{}
+	query := `query($id: String!) { issue(id: $id) { ` + issueFields + ` } }`
+	if err := a.doRequest(ctx, query, map[string]interface{}{"id": id}, res); err != nil {
+		return nil, err
+	}
+	if res.Issue == nil {
+		return nil, ErrNotFound
+	}
+	return res.Issue, nil
+}
+
+// ListComments returns all comments of the issue (the oldest first).
+func (a *API) ListComments(ctx context.Context, issueID string) ([]Comment, error) {
+	list := []Comment{}
+	query := `query($id: String!, $first: Int!, $after: String) { issue(id: $id) {
+		comments(first: $first, after: $after, orderBy: createdAt) { nodes { id body url user { id name } } pageInfo { hasNextPage endCursor } } } }`
+	vars := map[string]interface{}{"id": issueID, "first": pageSize}
+	for {
+		res := &struct {
+			Issue *struct {
+				Comments struct {
+					Nodes    []Comment `json:"nodes"`
+					PageInfo pageInfo  `json:"pageInfo"`
+				} `json:"comments"`
+			} `json:"issue"`
+		}{}
+		if err := a.doRequest(ctx, query, vars, res); err != nil {
+			return nil, err
+		}
+		if res.Issue == nil {
+			return nil, ErrNotFound
+		}
+		list = append(list, res.Issue.Comments.Nodes...)
+		if !res.Issue.Comments.PageInfo.HasNextPage {
+			return list, nil
+		}
+		vars["after"] = res.Issue.Comments.PageInfo.EndCursor
+	}
+}
+
+func (a *API) CreateComment(ctx context.Context, issueID, body string) (*Comment, error) {
+	res := &struct {
+		CommentCreate struct {
+			Comment *Comment `json:"comment"`
+		} `json:"commentCreate"`
+	}{}
+	query := `mutation($input: CommentCreateInput!) { commentCreate(input: $input) { success comment { id body url user { id name } } } }`
+	input := map[string]interface{}{"issueId": issueID, "body": body}
+	if err := a.doRequest(ctx, query, map[string]interface{}{"input": input}, res); err != nil {
+		return nil, err
+	}
+	if res.CommentCreate.Comment == nil {
+		return nil, fmt.Errorf("linear: the comment is not created")
+	}
+	return res.CommentCreate.Comment, nil
+}
+
+type graphqlError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Type                   string `json:"type"`
+		UserPresentableMessage string `json:"userPresentableMessage"`
+	} `json:"extensions"`
+}
+
+func (e *graphqlError) notFound() bool {
+	return strings.Contains(e.Message, "Entity not found") || strings.Contains(e.Extensions.UserPresentableMessage, "Entity not found")
+}
+
+func (a *API) doRequest(ctx context.Context, query string, vars map[string]interface{}, out interface{}) error {
+	dat, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err != nil {
+		return err
+	}
+
+	reqURL := a.baseURL + "/graphql"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(dat))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", a.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	dat, err = ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	a.log.Debug("API request", zap.String("status", res.Status), zap.String("uri", reqURL))
+
+	resp := &struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphqlError  `json:"errors"`
+	}{}
+	if err := json.Unmarshal(dat, resp); err != nil {
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("body_raw", string(dat)))
+		return fmt.Errorf("linear: got status %d", res.StatusCode)
+	}
+	if len(resp.Errors) > 0 {
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("body_raw", string(dat)))
+		if resp.Errors[0].notFound() {
+			return ErrNotFound
+		}
+		return fmt.Errorf("linear: %s", resp.Errors[0].Message)
+	}
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("linear: got status %d", res.StatusCode)
+	}
+
+	if out == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return fmt.Errorf("linear: failed decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package linear
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+// ProviderName name of Linear in the spec of sync (spec_add.external[].tracker) and in the task references.
+const ProviderName = "linear"
+
+// the date format of Linear API
+const dateLayout = "2006-01-02"
+
+// the duration of one point of the estimate by default (see properties.estimate)
+const defaultPointDuration = time.Hour
+
+// NewProvider returns Linear as the provider of the tasks (the issues).
+// The location of the issues is the team (the ID or the key), the key is the identifier of the issue (eg ENG-1),
+// the members are the IDs of Linear users.
+// The status of the task is the workflow state of the team with the same name (see status_map),
+// the issue is closed if the state is completed or canceled.
+// The priority of ClickUp is the priority of Linear (1 urgent ... 4 low), the estimate is the points
+// (one point is the duration from properties.estimate, eg "4h", one hour by default).
+func NewProvider(api *API) *Provider {
+	return &Provider{api: api}
+}
+
+type Provider struct {
+	api *API
+}
+
+var (
+	_ tracker.Provider   = (*Provider)(nil)
+	_ tracker.Normalizer = (*Provider)(nil)
+)
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+func (p *Provider) Capabilities() tracker.Capability {
+	return tracker.CapComments | tracker.CapAssignees | tracker.CapStatus | tracker.CapPriority | tracker.CapDueDate |
+		tracker.CapEstimate | tracker.CapMarkdown
+}
+
+func (p *Provider) Fields(location tracker.Location) []string {
+	return []string{
+		tracker.FieldName,
+		tracker.FieldDescription,
+		tracker.FieldAssignees,
+		tracker.FieldStatus,
+		tracker.FieldDueDate,
+		tracker.FieldPriority,
+		tracker.FieldEstimate,
+	}
+}
+
+// NormalizeTask truncates the due date to the day (in UTC) and rounds up the estimate to the points,
+// only the first assignee is kept (the issue of Linear has one assignee).
+func (p *Provider) NormalizeTask(location tracker.Location, task *tracker.Task) {
+	if task.DueDate != nil {
+		dueDate := task.DueDate.UTC().Truncate(24 * time.Hour)
+		task.DueDate = &dueDate
+	}
+	if task.TimeEstimateMs != nil {
+		pointMs := pointMs(location)
+		estimate := (*task.TimeEstimateMs + pointMs - 1) / pointMs * pointMs
+		task.TimeEstimateMs = &estimate
+	}
+	if len(task.Assignees) > 1 {
+		task.Assignees = task.Assignees[:1]
+	}
+}
+
+func (p *Provider) CreateTask(ctx context.Context, location tracker.Location, task *tracker.Task) (*tracker.Task, error) {
+	team, states, err := p.api.GetTeam(ctx, location.ID)
+	if err != nil {
+		return nil, err
+	}
+	input, err := issueInput(location, states, task, p.Fields(location))
+	if err != nil {
+		return nil, err
+	}
+	input["teamId"] = team.ID
+
+	created, err := p.api.CreateIssue(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return providerTask(location, created), nil
+}
+
+func (p *Provider) UpdateTask(ctx context.Context, location tracker.Location, task *tracker.Task, fields []string) (*tracker.Task, error) {
+	states := []WorkflowState{}
+	for _, field := range fields {
+		if field == tracker.FieldStatus {
+			_, teamStates, err := p.api.GetTeam(ctx, location.ID)
+			if err != nil {
+				return nil, err
+			}
+			states = teamStates
+		}
+	}
+	input, err := issueInput(location, states, task, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := p.api.UpdateIssue(ctx, task.Key, input)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return providerTask(location, updated), nil
+}
+
+// GetTask returns the issue by the identifier or tracker.ErrNotFound.
+func (p *Provider) GetTask(ctx context.Context, location tracker.Location, key string) (*tracker.Task, error) {
+	issue, err := p.api.GetIssue(ctx, key)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return providerTask(location, issue), nil
+}
+
+func (p *Provider) ListComments(ctx context.Context, location tracker.Location, key string) ([]*tracker.Comment, error) {
+	res, err := p.api.ListComments(ctx, key)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	list := []*tracker.Comment{}
+	for idx := range res {
+		list = append(list, providerComment(&res[idx]))
+	}
+	return list, nil
+}
+
+func (p *Provider) AddComment(ctx context.Context, location tracker.Location, key string, comment *tracker.Comment) (*tracker.Comment, error) {
+	issue, err := p.api.GetIssue(ctx, key)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	res, err := p.api.CreateComment(ctx, issue.ID, comment.Body)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return providerComment(res), nil
+}
+
+// ListMembers is not supported (the assignees are the IDs of the users from the spec of sync).
+func (p *Provider) ListMembers(ctx context.Context, location tracker.Location) ([]*tracker.Member, error) {
+	return nil, fmt.Errorf("the members of the team %q are not supported", location.ID)
+}
+
+func notFoundErr(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return tracker.ErrNotFound
+	}
+	return err
+}
+
+// returns the duration of one point of the estimate in milliseconds (by default if not specified or invalid)
+func pointMs(location tracker.Location) int64 {
+	duration, err := time.ParseDuration(location.Property(tracker.FieldEstimate))
+	if err != nil || duration < time.Minute {
+		duration = defaultPointDuration
+	}
+	return int64(duration / time.Millisecond)
+}
+
+// returns the input of the mutation by the fields of the task
+func issueInput(location tracker.Location, states []WorkflowState, task *tracker.Task,
+	fields []string) (map[string]interface{}, error) {
+
+	input := map[string]interface{}{}
+	for _, field := range fields {
+		switch field {
+		case tracker.FieldName:
+			input["title"] = task.Name
+		case tracker.FieldDescription:
+			input["description"] = task.Description
+		case tracker.FieldAssignees:
+			input["assigneeId"] = nil
+			if len(task.Assignees) > 0 {
+				input["assigneeId"] = task.Assignees[0].ID
+			}
+		case tracker.FieldStatus:
+			if task.Status == "" {
+				continue
+			}
+			stateID := ""
+			for _, state := range states {
+				if strings.EqualFold(state.Name, task.Status) {
+					stateID = state.ID
+				}
+			}
+			if stateID == "" {
+				return nil, fmt.Errorf("not found the workflow state %q in the team %q", task.Status, location.ID)
+			}
+			input["stateId"] = stateID
+		case tracker.FieldDueDate:
+			input["dueDate"] = nil
+			if task.DueDate != nil {
+				input["dueDate"] = task.DueDate.UTC().Format(dateLayout)
+			}
+		case tracker.FieldPriority:
+			// the zero priority is no priority
+			input["priority"] = 0
+			if task.PriorityID != nil {
+				input["priority"] = *task.PriorityID
+			}
+		case tracker.FieldEstimate:
+			input["estimate"] = nil
+			if task.TimeEstimateMs != nil && *task.TimeEstimateMs > 0 {
+				pointMs := pointMs(location)
+				input["estimate"] = (*task.TimeEstimateMs + pointMs - 1) / pointMs
+			}
+		}
+	}
+	return input, nil
+}
+
+func providerTask(location tracker.Location, in *Issue) *tracker.Task {
+	res := &tracker.Task{
+		Ref:         tracker.TaskRef{Provider: ProviderName, ID: in.ID},
+		Key:         in.Identifier,
+		Location:    location,
+		Name:        in.Title,
+		Description: in.Description,
+		Tags:        []string{},
+		Assignees:   []tracker.Member{},
+		URL:         in.URL,
+		UpdatedAt:   in.UpdatedAt,
+	}
+	if in.Assignee != nil {
+		res.Assignees = append(res.Assignees, tracker.Member{ID: in.Assignee.ID, Name: in.Assignee.Name})
+	}
+	if in.State != nil {
+		res.Status = in.State.Name
+		res.Closed = in.State.Type == "completed" || in.State.Type == "canceled"
+	}
+	if in.Priority > 0 {
+		priority := in.Priority
+		res.PriorityID = &priority
+	}
+	if in.Estimate != nil && *in.Estimate > 0 {
+		estimate := int64(*in.Estimate) * pointMs(location)
+		res.TimeEstimateMs = &estimate
+	}
+	if in.DueDate != nil {
+		if dueDate, err := time.Parse(dateLayout, *in.DueDate); err == nil {
+			res.DueDate = &dueDate
+		}
+	}
+	return res
+}
+
+func providerComment(in *Comment) *tracker.Comment {
+	res := &tracker.Comment{
+		ID:   in.ID,
+		Body: in.Body,
+		URL:  in.URL,
+	}
+	if in.User != nil {
+		res.Author = tracker.Member{ID: in.User.ID, Name: in.User.Name}
+	}
+	return res
+}
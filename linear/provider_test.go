@@ -0,0 +1,263 @@
+package linear
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+	"github.com/gebv/asap-tools/tracker/trackertest"
+)
+
+const testAPIKey = "lin_api_test"
+
+var testLocation = tracker.Location{
+	Provider:   ProviderName,
+	ID:         "ENG",
+	Properties: map[string]string{tracker.FieldEstimate: "4h"},
+}
+
+var testTeam = Team{ID: "team-1", Key: "ENG", Name: "Engineering"}
+
+var testStates = []WorkflowState{
+	{ID: "state-1", Name: "Todo", Type: "unstarted"},
+	{ID: "state-2", Name: "In Progress", Type: "started"},
+	{ID: "state-3", Name: "Done", Type: "completed"},
+}
+
+// fakeAPI the in-memory Linear GraphQL API (only the team ENG, the issues and the comments of the issues)
+type fakeAPI struct {
+	issues   map[string]*Issue
+	comments map[string][]Comment
+	nextID   int
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		issues:   map[string]*Issue{},
+		comments: map[string][]Comment{},
+	}
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := &struct {
+		Query     string                     `json:"query"`
+		Variables map[string]json.RawMessage `json:"variables"`
+	}{}
+	json.NewDecoder(r.Body).Decode(req)
+	id := ""
+	json.Unmarshal(req.Variables["id"], &id)
+	input := map[string]json.RawMessage{}
+	json.Unmarshal(req.Variables["input"], &input)
+
+	notFound := func() {
+		trackertest.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"data":   nil,
+			"errors": []map[string]interface{}{{"message": "Entity not found", "extensions": map[string]string{"type": "invalid input"}}},
+		})
+	}
+
+	switch {
+	case strings.Contains(req.Query, "team(id:"):
+		if id != testTeam.ID && id != testTeam.Key {
+			notFound()
+			return
+		}
+		trackertest.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"team": map[string]interface{}{
+			"id": testTeam.ID, "key": testTeam.Key, "name": testTeam.Name, "states": map[string]interface{}{"nodes": testStates},
+		}}})
+	case strings.Contains(req.Query, "issueCreate("):
+		teamID := ""
+		json.Unmarshal(input["teamId"], &teamID)
+		if teamID != testTeam.ID {
+			notFound()
+			return
+		}
+		f.nextID++
+		issue := &Issue{ID: fmt.Sprint("issue-", f.nextID), Identifier: fmt.Sprint("ENG-", len(f.issues)+1), State: &testStates[0]}
+		issue.URL = "https://linear.app/test/issue/" + issue.Identifier
+		applyInput(issue, input)
+		f.issues[issue.ID] = issue
+		trackertest.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"issueCreate": map[string]interface{}{"success": true, "issue": issue}}})
+	case strings.Contains(req.Query, "issueUpdate("):
+		issue := f.issue(id)
+		if issue == nil {
+			notFound()
+			return
+		}
+		applyInput(issue, input)
+		trackertest.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"issueUpdate": map[string]interface{}{"success": true, "issue": issue}}})
+	case strings.Contains(req.Query, "commentCreate("):
+		issueID, body := "", ""
+		json.Unmarshal(input["issueId"], &issueID)
+		json.Unmarshal(input["body"], &body)
+		if f.issues[issueID] == nil {
+			notFound()
+			return
+		}
+		f.nextID++
+		comment := Comment{ID: fmt.Sprint("comment-", f.nextID), Body: body, User: &User{ID: "user-1", Name: "bot"}}
+		f.comments[issueID] = append(f.comments[issueID], comment)
+		trackertest.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"commentCreate": map[string]interface{}{"success": true, "comment": comment}}})
+	case strings.Contains(req.Query, "comments("):
+		issue := f.issue(id)
+		if issue == nil {
+			notFound()
+			return
+		}
+		list := f.comments[issue.ID]
+		first, after := 0, ""
+		json.Unmarshal(req.Variables["first"], &first)
+		json.Unmarshal(req.Variables["after"], &after)
+		cursor, _ := strconv.Atoi(after)
+		from, to := trackertest.Page(len(list), cursor, first)
+		trackertest.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"issue": map[string]interface{}{
+			"comments": map[string]interface{}{
+				"nodes":    list[from:to],
+				"pageInfo": pageInfo{HasNextPage: to < len(list), EndCursor: strconv.Itoa(to)},
+			},
+		}}})
+	case strings.Contains(req.Query, "issue(id:"):
+		issue := f.issue(id)
+		if issue == nil {
+			notFound()
+			return
+		}
+		trackertest.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"issue": issue}})
+	default:
+		trackertest.WriteJSON(w, http.StatusBadRequest, map[string]interface{}{"errors": []map[string]string{{"message": "unknown query"}}})
+	}
+}
+
+// returns the issue by the ID or the identifier
+func (f *fakeAPI) issue(id string) *Issue {
+	for _, issue := range f.issues {
+		if issue.ID == id || issue.Identifier == id {
+			return issue
+		}
+	}
+	return nil
+}
+
+func applyInput(issue *Issue, input map[string]json.RawMessage) {
+	for name, value := range input {
+		switch name {
+		case "title":
+			json.Unmarshal(value, &issue.Title)
+		case "description":
+			json.Unmarshal(value, &issue.Description)
+		case "assigneeId":
+			issue.Assignee = nil
+			userID := ""
+			if json.Unmarshal(value, &userID); userID != "" {
+				issue.Assignee = &User{ID: userID, Name: "user " + userID}
+			}
+		case "stateId":
+			stateID := ""
+			json.Unmarshal(value, &stateID)
+			for idx := range testStates {
+				if testStates[idx].ID == stateID {
+					issue.State = &testStates[idx]
+				}
+			}
+		case "priority":
+			json.Unmarshal(value, &issue.Priority)
+		case "estimate":
+			issue.Estimate = nil
+			json.Unmarshal(value, &issue.Estimate)
+		case "dueDate":
+			issue.DueDate = nil
+			json.Unmarshal(value, &issue.DueDate)
+		}
+	}
+	issue.UpdatedAt = time.Now().UTC()
+}
+
+func newTestProvider(t *testing.T) (*Provider, *fakeAPI) {
+	fake := newFakeAPI()
+	url := trackertest.NewServer(t, trackertest.HeaderAuth("Authorization", testAPIKey), fake.ServeHTTP)
+	return NewProvider(NewAPI(url, testAPIKey)), fake
+}
+
+func TestProvider_NormalizeTask(t *testing.T) {
+	dueDate := time.Date(2022, 2, 1, 18, 30, 0, 0, time.UTC)
+	estimate := int64(5 * time.Hour / time.Millisecond)
+	task := &tracker.Task{Assignees: []tracker.Member{{ID: "u1"}, {ID: "u2"}}, DueDate: &dueDate, TimeEstimateMs: &estimate}
+	NewProvider(nil).NormalizeTask(testLocation, task)
+
+	wantDueDate := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	// two points of 4h
+	wantEstimate := int64(8 * time.Hour / time.Millisecond)
+	want := &tracker.Task{Assignees: []tracker.Member{{ID: "u1"}}, DueDate: &wantDueDate, TimeEstimateMs: &wantEstimate}
+	if !reflect.DeepEqual(task, want) {
+		t.Errorf("NormalizeTask() = %+v, want %+v", task, want)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	provider, fake := newTestProvider(t)
+	dueDate := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	estimate := int64(8 * time.Hour / time.Millisecond)
+	priority := 2
+	suite := &trackertest.Suite{
+		Provider: provider,
+		Location: testLocation,
+		Task: &tracker.Task{
+			Name:           "Task",
+			Description:    "description",
+			Assignees:      []tracker.Member{{ID: "user-2"}},
+			Status:         "In Progress",
+			DueDate:        &dueDate,
+			TimeEstimateMs: &estimate,
+			PriorityID:     &priority,
+		},
+		// completes the issue and removes the fields
+		Update: &tracker.Task{
+			Description: "changed",
+			Assignees:   []tracker.Member{},
+			Status:      "Done",
+		},
+		Remove: func(task *tracker.Task) {
+			delete(fake.issues, task.Ref.ID)
+		},
+		PageSize:      pageSize,
+		CommentAuthor: "user-1",
+	}
+	suite.Run(t)
+}
+
+func TestProvider_Issues(t *testing.T) {
+	ctx := context.Background()
+	provider, fake := newTestProvider(t)
+
+	// the estimate in the points of 4h
+	estimate := int64(8 * time.Hour / time.Millisecond)
+	created, err := provider.CreateTask(ctx, testLocation, &tracker.Task{Name: "Task", Status: "in progress", TimeEstimateMs: &estimate})
+	if err != nil {
+		t.Fatalf("CreateTask(): %v", err)
+	}
+	if created.Ref.ID != "issue-1" || created.Key != "ENG-1" || created.URL != "https://linear.app/test/issue/ENG-1" {
+		t.Errorf("CreateTask() = %+v, want the issue ENG-1", created)
+	}
+	if points := *fake.issues["issue-1"].Estimate; points != 2 {
+		t.Errorf("the estimate of the created issue = %d points, want 2", points)
+	}
+	// the workflow state by the name in any case
+	if created.Status != "In Progress" {
+		t.Errorf("the status of the created issue = %q, want In Progress", created.Status)
+	}
+
+	created.Status = "Blocked"
+	if _, err := provider.UpdateTask(ctx, testLocation, created, []string{tracker.FieldStatus}); err == nil {
+		t.Error("UpdateTask() to the unknown workflow state must return error")
+	}
+	if _, err := provider.CreateTask(ctx, tracker.Location{Provider: ProviderName, ID: "OTHER"}, &tracker.Task{Name: "Task"}); err == nil {
+		t.Error("CreateTask() in the not found team must return error")
+	}
+}
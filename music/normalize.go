@@ -0,0 +1,44 @@
+package music
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// the versions of the same recording (eg "Song (Remastered 2011)", "Song - Radio Edit", "Song (feat. Artist)")
+	versionSuffix = regexp.MustCompile(`(?i)\s+-\s+.*\b(remaster(ed)?|radio edit|single version|album version|mono|stereo|bonus track)\b.*$`)
+	versionParens = regexp.MustCompile(`(?i)\s*[(\[][^)\]]*\b(remaster(ed)?|radio edit|single version|album version|mono|stereo|bonus track|feat\.?|ft\.?|featuring|with)\b[^)\]]*[)\]]`)
+	featArtists   = regexp.MustCompile(`(?i)\s+(feat\.?|ft\.?|featuring)\s+.*$`)
+)
+
+// TrackKey returns the normalized artist and title of the track (the same key for the versions of the same recording on Spotify and Last.fm).
+func TrackKey(artist, title string) string {
+	return normalizeArtist(artist) + " - " + normalizeTitle(title)
+}
+
+func normalizeArtist(in string) string {
+	in = featArtists.ReplaceAllString(in, "")
+	in = normalize(in)
+	return strings.TrimPrefix(in, "the ")
+}
+
+func normalizeTitle(in string) string {
+	in = versionParens.ReplaceAllString(in, "")
+	in = versionSuffix.ReplaceAllString(in, "")
+	in = featArtists.ReplaceAllString(in, "")
+	return normalize(in)
+}
+
+// returns the lower case letters and digits separated by the single spaces ("&" is "and")
+func normalize(in string) string {
+	in = strings.ReplaceAll(strings.ToLower(in), "&", " and ")
+	words := strings.FieldsFunc(in, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	for idx := range words {
+		words[idx] = strings.ReplaceAll(words[idx], "'", "")
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,21 @@
+package music
+
+import "testing"
+
+func TestTrackKey(t *testing.T) {
+	for _, tt := range []struct {
+		artist, title string
+		want          string
+	}{
+		{"The Beatles", "Hey Jude - Remastered 2015", "beatles - hey jude"},
+		{"Beatles", "Hey Jude", "beatles - hey jude"},
+		{"Simon & Garfunkel", "The Boxer (Live)", "simon and garfunkel - the boxer live"},
+		{"Daft Punk feat. Pharrell Williams", "Get Lucky (feat. Pharrell Williams) [Radio Edit]", "daft punk - get lucky"},
+		{"AC/DC", "Don't Stop Me Now", "ac dc - dont stop me now"},
+		{"Кино", "Группа крови", "кино - группа крови"},
+	} {
+		if got := TrackKey(tt.artist, tt.title); got != tt.want {
+			t.Errorf("TrackKey(%q, %q) = %q, want %q", tt.artist, tt.title, got, tt.want)
+		}
+	}
+}
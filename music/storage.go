@@ -0,0 +1,139 @@
+package music
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gebv/asap-tools/storage"
+	"go.uber.org/zap"
+)
+
+type StoreModel = storage.Model
+
+func NewStorage(s *storage.Storage) *Storage {
+	return &Storage{
+		Storage: s,
+		log:     zap.L().Named("music_storage"),
+	}
+}
+
+// the state of the cross likes
+//
+// - method Get<ModelName> - returns model by ID
+// - method GetStateOf<ModelName> - returns status of loading of changes for the model
+// - method Upsert<ModelName> - create or overwrite model by ID
+type Storage struct {
+	*storage.Storage
+	log *zap.Logger
+}
+
+var (
+	TrackMatchModel                   = (*TrackMatch)(nil)
+	_                      StoreModel = (*TrackMatch)(nil)
+	LoadStatusOfLikesModel            = (*LoadStatusOfLikes)(nil)
+	_                      StoreModel = (*LoadStatusOfLikes)(nil)
+)
+
+// a new model instance and call GetModel
+func (s *Storage) GetTrackMatch(ctx context.Context, key string) *TrackMatch {
+	model := &TrackMatch{Key: key}
+	s.GetModel(ctx, model)
+	return model
+}
+
+// FindTrackMatchByISRC returns the track with the ISRC (nil if not found).
+func (s *Storage) FindTrackMatchByISRC(ctx context.Context, isrc string) *TrackMatch {
+	iter := s.FirestoreClient().Collection(TrackMatchModel.CollectionName()).
+		Where("ISRC", "==", isrc).Limit(1).Documents(ctx)
+	res := s.Iterate(iter, TrackMatchModel)
+	if len(res) == 0 {
+		return nil
+	}
+	return res[0].(*TrackMatch)
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertTrackMatch(ctx context.Context, model *TrackMatch) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertLoadStatusOfLikes(ctx context.Context, model *LoadStatusOfLikes) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// a new model instance and call GetModel
+func (s *Storage) GetStateOfLoadLikes(ctx context.Context, service string) *LoadStatusOfLikes {
+	model := &LoadStatusOfLikes{Service: service}
+	s.GetModel(ctx, model)
+	return model
+}
+
+// TrackMatch the liked track on Spotify and Last.fm (ID is the normalized key of the track, see TrackKey).
+type TrackMatch struct {
+	storage.ModelCustomID
+	Key string `firestore:"-"`
+
+	Artist string
+	Title  string
+	ISRC   string
+
+	SpotifyID      string
+	SpotifyLikedAt *storage.Timestamp
+	LastfmLovedAt  *storage.Timestamp
+	// the track is not found on Spotify (the like of Last.fm is not synced)
+	SpotifyNotFound bool
+}
+
+func (*TrackMatch) NewModel() StoreModel {
+	return &TrackMatch{}
+}
+
+func (*TrackMatch) CollectionName() string {
+	return "music_track_matches"
+}
+
+// the key is escaped (the slashes are not allowed in ID of the document)
+func (m *TrackMatch) SetModelID(in string) {
+	key, err := url.PathUnescape(in)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid format ID %q for %T", in, m))
+	}
+	m.Key = key
+}
+
+func (m *TrackMatch) ModelID() string {
+	return url.PathEscape(m.Key)
+}
+
+// LoadStatusOfLikes the cursor of the likes of the service.
+type LoadStatusOfLikes struct {
+	storage.ModelCustomID
+	// see ServiceSpotify, ServiceLastfm
+	Service string `firestore:"-"`
+	// the time of the last loaded like
+	LastLikedAt *storage.Timestamp
+}
+
+func (m *LoadStatusOfLikes) NewModel() StoreModel {
+	return &LoadStatusOfLikes{}
+}
+
+func (m *LoadStatusOfLikes) SetModelID(in string) {
+	const prefix = "service:"
+	if strings.HasPrefix(in, prefix) {
+		m.Service = in[len(prefix):]
+	} else {
+		panic(fmt.Sprintf("Invalid format ID %q for %T", in, m))
+	}
+}
+
+func (m *LoadStatusOfLikes) ModelID() string {
+	return fmt.Sprintf("service:%s", m.Service)
+}
+
+func (LoadStatusOfLikes) CollectionName() string {
+	return "music_load_status_of_likes"
+}
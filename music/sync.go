@@ -0,0 +1,223 @@
+package music
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/lastfm"
+	"github.com/gebv/asap-tools/spotify"
+	"go.uber.org/zap"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// the services of the cross likes
+const (
+	ServiceSpotify = "spotify"
+	ServiceLastfm  = "lastfm"
+)
+
+// the state of the cross likes (see Storage)
+type stateStore interface {
+	GetTrackMatch(ctx context.Context, key string) *TrackMatch
+	FindTrackMatchByISRC(ctx context.Context, isrc string) *TrackMatch
+	UpsertTrackMatch(ctx context.Context, model *TrackMatch) error
+	GetStateOfLoadLikes(ctx context.Context, service string) *LoadStatusOfLikes
+	UpsertLoadStatusOfLikes(ctx context.Context, model *LoadStatusOfLikes) error
+}
+
+var _ stateStore = (*Storage)(nil)
+
+// NewSyncer returns the sync of the liked tracks of Spotify (Liked Songs) and the loved tracks of Last.fm.
+func NewSyncer(spotifyAPI *spotify.API, lastfmAPI *lastfm.API, store *Storage) *Syncer {
+	return newSyncer(spotifyAPI, lastfmAPI, store)
+}
+
+func newSyncer(spotifyAPI *spotify.API, lastfmAPI *lastfm.API, store stateStore) *Syncer {
+	return &Syncer{
+		spotify: spotifyAPI,
+		lastfm:  lastfmAPI,
+		store:   store,
+		log:     zap.L().Named("music_sync"),
+		now:     time.Now,
+	}
+}
+
+type Syncer struct {
+	spotify *spotify.API
+	lastfm  *lastfm.API
+	store   stateStore
+	log     *zap.Logger
+	now     func() time.Time
+}
+
+// SyncResult the numbers of the changes of the sync.
+type SyncResult struct {
+	// the new liked tracks of Spotify and the new loved tracks of Last.fm
+	NewSpotify, NewLastfm int
+	// the tracks loved on Last.fm and saved on Spotify by the sync
+	Loved, Saved int
+	// the loved tracks of Last.fm not found on Spotify
+	NotFound int
+}
+
+// Sync loves on Last.fm the new liked tracks of Spotify and saves on Spotify the new loved tracks of Last.fm
+// (the next call continues from the last loaded likes, the removed likes are not synced).
+func (s *Syncer) Sync(ctx context.Context) (*SyncResult, error) {
+	res := &SyncResult{}
+	spotifyStatus := s.store.GetStateOfLoadLikes(ctx, ServiceSpotify)
+	lastfmStatus := s.store.GetStateOfLoadLikes(ctx, ServiceLastfm)
+
+	saved, err := s.spotify.SavedTracks(ctx, asTime(spotifyStatus.LastLikedAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load liked tracks of Spotify: %w", err)
+	}
+	loved, err := s.lastfm.LovedTracks(ctx, asTime(lastfmStatus.LastLikedAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load loved tracks of Last.fm: %w", err)
+	}
+	res.NewSpotify, res.NewLastfm = len(saved), len(loved)
+
+	// the matches of the new likes in the order of the likes
+	matches := map[string]*TrackMatch{}
+	keys := []string{}
+	add := func(match *TrackMatch) *TrackMatch {
+		if _, exists := matches[match.Key]; !exists {
+			keys = append(keys, match.Key)
+			matches[match.Key] = match
+		}
+		return matches[match.Key]
+	}
+
+	for idx := range saved {
+		track := &saved[idx].Track
+		match := matches[TrackKey(track.GetArtist(), track.Name)]
+		if match == nil {
+			match = s.findMatch(ctx, track)
+		}
+		match = add(match)
+		if match.Artist == "" {
+			match.Artist, match.Title = track.GetArtist(), cleanTitle(track.Name)
+		}
+		match.SpotifyID, match.ISRC = track.ID, track.ExternalIDs.ISRC
+		match.SpotifyLikedAt = timestamppb.New(saved[idx].AddedAt)
+		match.SpotifyNotFound = false
+	}
+	for idx := range loved {
+		track := &loved[idx]
+		key := TrackKey(track.Artist.Name, track.Name)
+		match := matches[key]
+		if match == nil {
+			match = add(s.store.GetTrackMatch(ctx, key))
+		}
+		if match.Artist == "" {
+			match.Artist, match.Title = track.Artist.Name, track.Name
+		}
+		match.LastfmLovedAt = timestamppb.New(track.LovedAt())
+	}
+
+	for _, key := range keys {
+		match := matches[key]
+		switch {
+		case match.SpotifyLikedAt != nil && match.LastfmLovedAt == nil:
+			if err := s.lastfm.LoveTrack(ctx, match.Artist, match.Title); err != nil {
+				// the cursors are not moved - the likes are loaded again by the next call
+				return res, fmt.Errorf("failed to love track %q on Last.fm: %w", key, err)
+			}
+			match.LastfmLovedAt = timestamppb.New(s.now())
+			res.Loved++
+			s.log.Info("loved the track on Last.fm", zap.String("artist", match.Artist), zap.String("title", match.Title))
+		case match.LastfmLovedAt != nil && match.SpotifyLikedAt == nil && !match.SpotifyNotFound:
+			track, err := s.searchSpotify(ctx, match)
+			if err != nil {
+				return res, fmt.Errorf("failed to search track %q on Spotify: %w", key, err)
+			}
+			if track == nil {
+				match.SpotifyNotFound = true
+				res.NotFound++
+				s.log.Info("not found the track on Spotify", zap.String("artist", match.Artist), zap.String("title", match.Title))
+				break
+			}
+			if err := s.spotify.SaveTracks(ctx, []string{track.ID}); err != nil {
+				return res, fmt.Errorf("failed to save track %q on Spotify: %w", key, err)
+			}
+			match.SpotifyID, match.ISRC = track.ID, track.ExternalIDs.ISRC
+			match.SpotifyLikedAt = timestamppb.New(s.now())
+			res.Saved++
+			s.log.Info("saved the track on Spotify", zap.String("artist", match.Artist), zap.String("title", match.Title), zap.String("spotify_id", track.ID))
+		}
+		if err := s.store.UpsertTrackMatch(ctx, match); err != nil {
+			return res, fmt.Errorf("failed to upsert match of track %q: %w", key, err)
+		}
+	}
+
+	if len(saved) > 0 {
+		spotifyStatus.LastLikedAt = timestamppb.New(saved[len(saved)-1].AddedAt)
+		err := s.store.UpsertLoadStatusOfLikes(ctx, spotifyStatus)
+		warnErrorIf(s.log, err, "failed to upsert the status of the likes", "service", ServiceSpotify)
+	}
+	if len(loved) > 0 {
+		lastfmStatus.LastLikedAt = timestamppb.New(loved[len(loved)-1].LovedAt())
+		err := s.store.UpsertLoadStatusOfLikes(ctx, lastfmStatus)
+		warnErrorIf(s.log, err, "failed to upsert the status of the likes", "service", ServiceLastfm)
+	}
+	return res, nil
+}
+
+// returns the stored match of the track of Spotify by the key or by ISRC (the other version of the same recording)
+func (s *Syncer) findMatch(ctx context.Context, track *spotify.Track) *TrackMatch {
+	key := TrackKey(track.GetArtist(), track.Name)
+	match := s.store.GetTrackMatch(ctx, key)
+	if match.Exists() || track.ExternalIDs.ISRC == "" {
+		return match
+	}
+	if found := s.store.FindTrackMatchByISRC(ctx, track.ExternalIDs.ISRC); found != nil {
+		return found
+	}
+	return match
+}
+
+// returns the track of Spotify with the same key (by ISRC if known or by the artist and the title, nil if not found)
+func (s *Syncer) searchSpotify(ctx context.Context, match *TrackMatch) (*spotify.Track, error) {
+	if match.ISRC != "" {
+		tracks, err := s.spotify.SearchByISRC(ctx, match.ISRC)
+		if err != nil {
+			return nil, err
+		}
+		if len(tracks) > 0 {
+			return &tracks[0], nil
+		}
+	}
+
+	tracks, err := s.spotify.SearchByName(ctx, match.Artist, match.Title)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range tracks {
+		if TrackKey(tracks[idx].GetArtist(), tracks[idx].Name) == match.Key {
+			return &tracks[idx], nil
+		}
+	}
+	return nil, nil
+}
+
+// returns the title without the version of the recording (eg "Song - Remastered 2011" is "Song")
+func cleanTitle(in string) string {
+	in = versionParens.ReplaceAllString(in, "")
+	in = versionSuffix.ReplaceAllString(in, "")
+	return strings.TrimSpace(in)
+}
+
+func asTime(in *timestamppb.Timestamp) time.Time {
+	if in == nil {
+		return time.Time{}
+	}
+	return in.AsTime()
+}
+
+func warnErrorIf(l *zap.Logger, err error, msg string, pairs ...interface{}) {
+	if err != nil {
+		l.With(zap.Error(err)).Sugar().Warnw(msg, pairs...)
+	}
+}
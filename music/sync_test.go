@@ -0,0 +1,305 @@
+package music
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/lastfm"
+	"github.com/gebv/asap-tools/spotify"
+)
+
+// fakeSpotify the in-memory Spotify Web API (Liked Songs and the search by the catalog)
+type fakeSpotify struct {
+	mu sync.Mutex
+	// the liked tracks (the newest first)
+	saved   []spotify.SavedTrack
+	catalog []spotify.Track
+	now     time.Time
+}
+
+func (f *fakeSpotify) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/api/token" {
+		if user, pass, _ := r.BasicAuth(); user != "client" || pass != "secret" || r.FormValue("refresh_token") != "refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, map[string]interface{}{"access_token": "token", "expires_in": 3600})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		writeJSON(w, map[string]interface{}{"error": map[string]interface{}{"status": 401, "message": "Invalid access token"}})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/me/tracks":
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end, next := offset+limit, ""
+		if end < len(f.saved) {
+			next = "next"
+		} else {
+			end = len(f.saved)
+		}
+		writeJSON(w, map[string]interface{}{"items": f.saved[offset:end], "next": next})
+	case r.Method == http.MethodPut && r.URL.Path == "/v1/me/tracks":
+		req := &struct {
+			IDs []string `json:"ids"`
+		}{}
+		json.NewDecoder(r.Body).Decode(req)
+		for _, id := range req.IDs {
+			for _, track := range f.catalog {
+				if track.ID == id {
+					f.saved = append([]spotify.SavedTrack{{AddedAt: f.now, Track: track}}, f.saved...)
+				}
+			}
+		}
+	case r.URL.Path == "/v1/search":
+		// by ISRC or by the artist and the beginning of the title
+		q := strings.ToLower(r.URL.Query().Get("q"))
+		title := ""
+		if parts := strings.SplitN(q, `track:"`, 2); len(parts) == 2 {
+			title = strings.SplitN(parts[1], `"`, 2)[0]
+		}
+		items := []spotify.Track{}
+		for _, track := range f.catalog {
+			byISRC := q == "isrc:"+strings.ToLower(track.ExternalIDs.ISRC)
+			byName := title != "" && strings.HasPrefix(strings.ToLower(track.Name), title) &&
+				strings.Contains(q, strings.ToLower(`artist:"`+track.GetArtist()+`"`))
+			if byISRC || byName {
+				items = append(items, track)
+			}
+		}
+		writeJSON(w, map[string]interface{}{"tracks": map[string]interface{}{"items": items}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// fakeLastfm the in-memory Last.fm API (the loved tracks of the user)
+type fakeLastfm struct {
+	mu sync.Mutex
+	// the loved tracks (the newest first)
+	loved []lastfm.LovedTrack
+	now   time.Time
+}
+
+func (f *fakeLastfm) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r.ParseForm()
+	if r.Form.Get("api_key") != "key" || r.Form.Get("format") != "json" {
+		writeJSON(w, map[string]interface{}{"error": 10, "message": "Invalid API key"})
+		return
+	}
+
+	switch r.Form.Get("method") {
+	case "user.getLovedTracks":
+		page, _ := strconv.Atoi(r.Form.Get("page"))
+		limit, _ := strconv.Atoi(r.Form.Get("limit"))
+		start, end := (page-1)*limit, page*limit
+		if end > len(f.loved) {
+			end = len(f.loved)
+		}
+		writeJSON(w, map[string]interface{}{"lovedtracks": map[string]interface{}{
+			"track": f.loved[start:end],
+			"@attr": map[string]string{"page": strconv.Itoa(page), "totalPages": strconv.Itoa((len(f.loved) + limit - 1) / limit)},
+		}})
+	case "track.love":
+		sig := r.Form.Get("api_sig")
+		r.Form.Del("api_sig")
+		if r.Method != http.MethodPost || r.Form.Get("sk") != "session" || sig != signature(r.Form, "secret") {
+			writeJSON(w, map[string]interface{}{"error": 13, "message": "Invalid method signature supplied"})
+			return
+		}
+		track := lastfm.LovedTrack{Name: r.Form.Get("track"), Artist: lastfm.Artist{Name: r.Form.Get("artist")}}
+		track.Date.UTS = strconv.FormatInt(f.now.Unix(), 10)
+		f.loved = append([]lastfm.LovedTrack{track}, f.loved...)
+		writeJSON(w, map[string]interface{}{})
+	default:
+		writeJSON(w, map[string]interface{}{"error": 3, "message": "Invalid Method"})
+	}
+}
+
+// returns the signature of the request of Last.fm API
+func signature(params url.Values, secret string) string {
+	keys := []string{}
+	for key := range params {
+		if key != "format" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	buf := ""
+	for _, key := range keys {
+		buf += key + params.Get(key)
+	}
+	sum := md5.Sum([]byte(buf + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeJSON(w http.ResponseWriter, in interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(in)
+}
+
+// memStore the in-memory state of the cross likes
+type memStore struct {
+	matches  map[string]TrackMatch
+	statuses map[string]LoadStatusOfLikes
+}
+
+func (s *memStore) GetTrackMatch(ctx context.Context, key string) *TrackMatch {
+	match := s.matches[key]
+	match.Key = key
+	return &match
+}
+
+func (s *memStore) FindTrackMatchByISRC(ctx context.Context, isrc string) *TrackMatch {
+	for _, match := range s.matches {
+		if match.ISRC == isrc {
+			return &match
+		}
+	}
+	return nil
+}
+
+func (s *memStore) UpsertTrackMatch(ctx context.Context, model *TrackMatch) error {
+	s.matches[model.Key] = *model
+	return nil
+}
+
+func (s *memStore) GetStateOfLoadLikes(ctx context.Context, service string) *LoadStatusOfLikes {
+	status := s.statuses[service]
+	status.Service = service
+	return &status
+}
+
+func (s *memStore) UpsertLoadStatusOfLikes(ctx context.Context, model *LoadStatusOfLikes) error {
+	s.statuses[model.Service] = *model
+	return nil
+}
+
+func newTestTrack(id, artist, title, isrc string) spotify.Track {
+	track := spotify.Track{ID: id, Name: title, Artists: []spotify.Artist{{Name: artist}}}
+	track.ExternalIDs.ISRC = isrc
+	return track
+}
+
+func newTestLoved(artist, title string, at time.Time) lastfm.LovedTrack {
+	track := lastfm.LovedTrack{Name: title, Artist: lastfm.Artist{Name: artist}}
+	track.Date.UTS = strconv.FormatInt(at.Unix(), 10)
+	return track
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	now := day.Add(10 * 24 * time.Hour)
+
+	heyJude := newTestTrack("s1", "The Beatles", "Hey Jude - Remastered 2015", "GBAYE0601498")
+	boxer := newTestTrack("s2", "Simon & Garfunkel", "The Boxer", "USSM19922509")
+	lucky := newTestTrack("s3", "Daft Punk", "Get Lucky (feat. Pharrell Williams)", "USQX91300108")
+	fakeSp := &fakeSpotify{now: now, catalog: []spotify.Track{heyJude, boxer, lucky}, saved: []spotify.SavedTrack{
+		{AddedAt: day.Add(2 * time.Hour), Track: boxer},
+		{AddedAt: day, Track: heyJude},
+	}}
+	fakeFm := &fakeLastfm{now: now, loved: []lastfm.LovedTrack{
+		newTestLoved("Unknown Artist", "Demo", day.Add(3*time.Hour)),
+		newTestLoved("Daft Punk", "Get Lucky", day.Add(2*time.Hour)),
+		newTestLoved("Beatles", "Hey Jude", day.Add(time.Hour)),
+	}}
+	spSrv := httptest.NewServer(fakeSp)
+	defer spSrv.Close()
+	fmSrv := httptest.NewServer(fakeFm)
+	defer fmSrv.Close()
+
+	store := &memStore{matches: map[string]TrackMatch{}, statuses: map[string]LoadStatusOfLikes{}}
+	s := newSyncer(
+		spotify.NewAPI(spSrv.URL+"/v1", spSrv.URL, spotify.Credentials{ClientID: "client", ClientSecret: "secret", RefreshToken: "refresh"}),
+		lastfm.NewAPI(fmSrv.URL+"/2.0/", lastfm.Credentials{APIKey: "key", APISecret: "secret", SessionKey: "session", User: "john"}),
+		store,
+	)
+	s.now = func() time.Time { return now }
+
+	res, err := s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync(): %v", err)
+	}
+	if want := (&SyncResult{NewSpotify: 2, NewLastfm: 3, Loved: 1, Saved: 1, NotFound: 1}); !reflect.DeepEqual(res, want) {
+		t.Errorf("result %+v, want %+v", res, want)
+	}
+	if got := fakeFm.loved[0]; got.Artist.Name != "Simon & Garfunkel" || got.Name != "The Boxer" {
+		t.Errorf("loved on Last.fm %+v", got)
+	}
+	if got := fakeSp.saved[0].Track.ID; got != "s3" {
+		t.Errorf("saved on Spotify %q", got)
+	}
+	if match := store.matches["beatles - hey jude"]; match.SpotifyID != "s1" || match.ISRC != "GBAYE0601498" || match.LastfmLovedAt == nil {
+		t.Errorf("match %+v", match)
+	}
+	if match := store.matches["unknown artist - demo"]; !match.SpotifyNotFound || match.SpotifyLikedAt != nil {
+		t.Errorf("match %+v", match)
+	}
+	if got := store.statuses[ServiceSpotify].LastLikedAt.AsTime(); !got.Equal(day.Add(2 * time.Hour)) {
+		t.Errorf("cursor of Spotify %v", got)
+	}
+
+	// the next sync: only the likes of the previous sync are loaded, nothing to change
+	res, err = s.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync(): %v", err)
+	}
+	if want := (&SyncResult{NewSpotify: 1, NewLastfm: 1}); !reflect.DeepEqual(res, want) {
+		t.Errorf("result %+v, want %+v", res, want)
+	}
+	if len(fakeFm.loved) != 4 || len(fakeSp.saved) != 3 {
+		t.Errorf("loved %d, saved %d", len(fakeFm.loved), len(fakeSp.saved))
+	}
+
+	// the other version of the same recording is matched by ISRC
+	remaster := newTestTrack("s4", "Simon and Garfunkel", "Boxer (2014 Mix)", "USSM19922509")
+	fakeSp.saved = append([]spotify.SavedTrack{{AddedAt: now.Add(time.Hour), Track: remaster}}, fakeSp.saved...)
+	res, err = s.Sync(ctx)
+	if err != nil || res.Loved != 0 {
+		t.Errorf("Sync(): %v, %+v", err, res)
+	}
+}
+
+func TestLovedTracks_Pages(t *testing.T) {
+	day := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	fake := &fakeLastfm{}
+	for idx := 0; idx < 450; idx++ {
+		fake.loved = append(fake.loved, newTestLoved("Artist", "Title "+strconv.Itoa(idx), day.Add(-time.Duration(idx)*time.Minute)))
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	api := lastfm.NewAPI(srv.URL+"/2.0/", lastfm.Credentials{APIKey: "key", User: "john"})
+
+	all, err := api.LovedTracks(context.Background(), time.Time{})
+	if err != nil || len(all) != 450 || all[0].Name != "Title 449" {
+		t.Fatalf("LovedTracks(): %v, %d", err, len(all))
+	}
+	recent, err := api.LovedTracks(context.Background(), day.Add(-10*time.Minute))
+	if err != nil || len(recent) != 10 || recent[9].Name != "Title 0" {
+		t.Errorf("LovedTracks() after: %v, %d", err, len(recent))
+	}
+}
@@ -0,0 +1,256 @@
+// Package notify sends the notifications about the changes of the tasks to the chats of the messengers
+// with the batching of the changes into the digests and the rate limiting of the messages.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// the kinds of the events
+const (
+	// the status of the task has been changed
+	EventStatus = "status"
+	// the assignees of the task have been changed
+	EventAssignees = "assignees"
+	// the due date of the task has been changed
+	EventDueDate = "due_date"
+	// the time estimate of the task has been changed
+	EventEstimate = "estimate"
+	// the mirror task has been created
+	EventMirrorAdded = "mirror_added"
+	// the pair of the mirror tasks has been unlinked
+	EventUnlinked = "unlinked"
+	// the creation of the mirror task awaits the approval
+	EventApproval = "approval"
+)
+
+// EventKinds all kinds of the events.
+var EventKinds = []string{EventStatus, EventAssignees, EventDueDate, EventEstimate, EventMirrorAdded, EventUnlinked, EventApproval}
+
+// the kinds of the actions of the event (eg the buttons of the interactive messages)
+const (
+	// approves the creation of the mirror task
+	ActionApprove = "approve"
+	// unlinks the pair of the mirror tasks
+	ActionUnlink = "unlink"
+	// syncs the pair of the mirror tasks again
+	ActionResync = "resync"
+)
+
+// Action the action on the task available from the message.
+type Action struct {
+	Kind string
+	// the ID of the object of the action (eg the ID of the pair of the mirror tasks)
+	Value string
+}
+
+// Event the change of the task.
+type Event struct {
+	Kind string
+	// the name of the rule of the sync
+	Rule string
+	// the ID, the name and the link of the task
+	TaskID string
+	Title  string
+	URL    string
+	// the link to the mirror task (empty if the event is not about the pair)
+	MirrorURL string
+	// the values of the changed field (eg the statuses)
+	From string
+	To   string
+	// the details of the event (eg the link to the mirror task or the reason of the unlink)
+	Details string
+	// the actions on the task (only for the messengers with the interactive messages)
+	Actions []Action
+	At      time.Time
+}
+
+// Sender sends the messages to the chats of the messenger.
+type Sender interface {
+	// Send sends the events to the chat as one message (the digest if more than one event).
+	Send(ctx context.Context, chatID string, events []*Event) error
+}
+
+// RetryAfterError returns Sender if the messenger limits the messages to the chat.
+type RetryAfterError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.After)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// Options of the rate limiting and the batching.
+type Options struct {
+	// the min interval between the messages to the same chat
+	ChatInterval time.Duration
+	// the min interval between any messages
+	Interval time.Duration
+	// the max number of the events in the digest (the digest is sent right away if it is full), 50 by default
+	MaxDigestEvents int
+	// the max wait for the retry of the limited message, 1 minute by default
+	MaxRetryAfter time.Duration
+}
+
+const (
+	defaultMaxDigestEvents = 50
+	defaultMaxRetryAfter   = time.Minute
+)
+
+// NewNotifier returns the notifier which sends the messages by the sender.
+func NewNotifier(sender Sender, opts Options) *Notifier {
+	if opts.MaxDigestEvents <= 0 {
+		opts.MaxDigestEvents = defaultMaxDigestEvents
+	}
+	if opts.MaxRetryAfter <= 0 {
+		opts.MaxRetryAfter = defaultMaxRetryAfter
+	}
+	return &Notifier{
+		sender:   sender,
+		opts:     opts,
+		pending:  map[string][]*Event{},
+		lastSent: map[string]time.Time{},
+		now:      time.Now,
+		sleep:    sleep,
+		log:      zap.L().Named("notify"),
+	}
+}
+
+type Notifier struct {
+	sender Sender
+	opts   Options
+
+	mu sync.Mutex
+	// the events of the digests by the chats (the order of the chats is kept for the flush)
+	pending map[string][]*Event
+	chats   []string
+	// the time of the last message by the chats ("" - any chat)
+	lastSent map[string]time.Time
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+	log   *zap.Logger
+}
+
+// Notify sends the event to the chat or adds the event to the digest of the chat (sent by Flush or if the digest is full).
+func (n *Notifier) Notify(ctx context.Context, chatID string, event *Event, digest bool) error {
+	if event.At.IsZero() {
+		event.At = n.now()
+	}
+	if !digest {
+		return n.send(ctx, chatID, []*Event{event})
+	}
+
+	n.mu.Lock()
+	if _, exists := n.pending[chatID]; !exists {
+		n.chats = append(n.chats, chatID)
+	}
+	n.pending[chatID] = append(n.pending[chatID], event)
+	full := len(n.pending[chatID]) >= n.opts.MaxDigestEvents
+	n.mu.Unlock()
+
+	if full {
+		return n.flushChat(ctx, chatID)
+	}
+	return nil
+}
+
+// Flush sends the digests of all chats. Returns the first error (the digests of the other chats are sent).
+func (n *Notifier) Flush(ctx context.Context) error {
+	n.mu.Lock()
+	chats := n.chats
+	n.mu.Unlock()
+
+	var firstErr error
+	for _, chatID := range chats {
+		if err := n.flushChat(ctx, chatID); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (n *Notifier) flushChat(ctx context.Context, chatID string) error {
+	n.mu.Lock()
+	events := n.pending[chatID]
+	delete(n.pending, chatID)
+	for idx, id := range n.chats {
+		if id == chatID {
+			n.chats = append(n.chats[:idx:idx], n.chats[idx+1:]...)
+			break
+		}
+	}
+	n.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+	return n.send(ctx, chatID, events)
+}
+
+// sends the message with the rate limits (the message limited by the messenger is retried once)
+func (n *Notifier) send(ctx context.Context, chatID string, events []*Event) error {
+	if err := n.wait(ctx, chatID); err != nil {
+		return err
+	}
+	err := n.sender.Send(ctx, chatID, events)
+
+	retryErr := &RetryAfterError{}
+	if errors.As(err, &retryErr) && retryErr.After <= n.opts.MaxRetryAfter {
+		n.log.Debug("Retry of the limited message", zap.String("chat_id", chatID), zap.Duration("retry_after", retryErr.After))
+		if err := n.sleep(ctx, retryErr.After); err != nil {
+			return err
+		}
+		err = n.sender.Send(ctx, chatID, events)
+	}
+	n.mu.Lock()
+	n.lastSent[chatID] = n.now()
+	n.lastSent[""] = n.now()
+	n.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("failed send %d events to the chat %q: %w", len(events), chatID, err)
+	}
+	return nil
+}
+
+// waits for the intervals after the last messages to the chat and to any chat
+func (n *Notifier) wait(ctx context.Context, chatID string) error {
+	n.mu.Lock()
+	now := n.now()
+	delay := time.Duration(0)
+	if last, exists := n.lastSent[chatID]; exists && last.Add(n.opts.ChatInterval).After(now) {
+		delay = last.Add(n.opts.ChatInterval).Sub(now)
+	}
+	if last, exists := n.lastSent[""]; exists && last.Add(n.opts.Interval).Sub(now) > delay {
+		delay = last.Add(n.opts.Interval).Sub(now)
+	}
+	n.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	return n.sleep(ctx, delay)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
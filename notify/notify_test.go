@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeSender records the sent messages (the kinds of the events by the chats)
+type fakeSender struct {
+	sent []string
+	// the errors of the next sends
+	errs []error
+}
+
+func (s *fakeSender) Send(ctx context.Context, chatID string, events []*Event) error {
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	msg := chatID + ":"
+	for _, event := range events {
+		msg += " " + event.Kind
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+// fakeClock the time is moved only by the sleeps
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func newTestNotifier(sender Sender, opts Options) (*Notifier, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)}
+	n := NewNotifier(sender, opts)
+	n.now = clock.Now
+	n.sleep = clock.Sleep
+	return n, clock
+}
+
+func TestNotifier_Digest(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSender{}
+	n, _ := newTestNotifier(sender, Options{MaxDigestEvents: 3})
+
+	for _, event := range []struct {
+		chatID string
+		kind   string
+		digest bool
+	}{
+		{"chat1", EventStatus, true},
+		{"chat2", EventDueDate, true},
+		{"chat1", EventMirrorAdded, false},
+		{"chat1", EventAssignees, true},
+		{"chat1", EventUnlinked, true},
+		{"chat1", EventStatus, true},
+	} {
+		if err := n.Notify(ctx, event.chatID, &Event{Kind: event.kind}, event.digest); err != nil {
+			t.Fatalf("Notify(): %v", err)
+		}
+	}
+	if err := n.Flush(ctx); err != nil {
+		t.Fatalf("Flush(): %v", err)
+	}
+
+	want := []string{
+		"chat1: mirror_added",
+		// the full digest is sent right away
+		"chat1: status assignees unlinked",
+		"chat2: due_date",
+		"chat1: status",
+	}
+	if !reflect.DeepEqual(sender.sent, want) {
+		t.Errorf("sent %q, want %q", sender.sent, want)
+	}
+
+	sender.sent = nil
+	if err := n.Flush(ctx); err != nil || len(sender.sent) != 0 {
+		t.Errorf("Flush() without the events: sent %q, err %v", sender.sent, err)
+	}
+}
+
+func TestNotifier_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSender{}
+	n, clock := newTestNotifier(sender, Options{ChatInterval: time.Second, Interval: 100 * time.Millisecond})
+
+	for _, chatID := range []string{"chat1", "chat2", "chat1"} {
+		if err := n.Notify(ctx, chatID, &Event{Kind: EventStatus}, false); err != nil {
+			t.Fatalf("Notify(): %v", err)
+		}
+	}
+	// the interval between any messages and the rest of the interval of the chat
+	want := []time.Duration{100 * time.Millisecond, 900 * time.Millisecond}
+	if !reflect.DeepEqual(clock.sleeps, want) {
+		t.Errorf("sleeps %v, want %v", clock.sleeps, want)
+	}
+}
+
+func TestNotifier_RetryAfter(t *testing.T) {
+	ctx := context.Background()
+	limited := &RetryAfterError{After: 5 * time.Second, Err: errors.New("too many requests")}
+	sender := &fakeSender{errs: []error{limited, nil}}
+	n, clock := newTestNotifier(sender, Options{})
+
+	if err := n.Notify(ctx, "chat1", &Event{Kind: EventStatus}, false); err != nil {
+		t.Fatalf("Notify(): %v", err)
+	}
+	if len(sender.sent) != 1 || !reflect.DeepEqual(clock.sleeps, []time.Duration{5 * time.Second}) {
+		t.Errorf("sent %q after sleeps %v", sender.sent, clock.sleeps)
+	}
+
+	// too long wait
+	sender.errs = []error{&RetryAfterError{After: time.Hour, Err: errors.New("too many requests")}}
+	if err := n.Notify(ctx, "chat1", &Event{Kind: EventStatus}, false); !errors.As(err, &limited) {
+		t.Errorf("Notify() = %v, want RetryAfterError", err)
+	}
+}
@@ -0,0 +1,272 @@
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultBaseURL = "https://api.notion.com"
+	// the version of Notion API
+	Version = "2022-06-28"
+)
+
+// the max size of the page of the list requests
+const pageSize = 100
+
+// ErrNotFound returns API if the object is not found (or not shared with the integration).
+var ErrNotFound = errors.New("notion: not found")
+
+type httpClientLogger struct {
+	*zap.Logger
+}
+
+func (l *httpClientLogger) Printf(msg string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(msg, args...))
+}
+
+// NewAPI returns the client of Notion API (baseURL for tests, DefaultBaseURL by default).
+func NewAPI(baseURL, accessToken string) *API {
+	l := zap.L().Named("notion_api")
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = &httpClientLogger{l.Named("http")}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &API{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   accessToken,
+		client:  httpClient.StandardClient(),
+		log:     l,
+	}
+}
+
+type API struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	log     *zap.Logger
+}
+
+type Database struct {
+	ID string `json:"id"`
+	// the schema of the properties by name
+	Properties map[string]PropertySchema `json:"properties"`
+}
+
+type PropertySchema struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type Page struct {
+	ID             string                   `json:"id"`
+	URL            string                   `json:"url"`
+	Archived       bool                     `json:"archived"`
+	LastEditedTime time.Time                `json:"last_edited_time"`
+	Properties     map[string]PropertyValue `json:"properties"`
+}
+
+// PropertyValue the value of the property of the page (only the field by the type is populated).
+type PropertyValue struct {
+	Type        string         `json:"type"`
+	Title       []RichText     `json:"title"`
+	RichText    []RichText     `json:"rich_text"`
+	Select      *SelectOption  `json:"select"`
+	Status      *SelectOption  `json:"status"`
+	MultiSelect []SelectOption `json:"multi_select"`
+	People      []User         `json:"people"`
+	Date        *Date          `json:"date"`
+	Number      *float64       `json:"number"`
+}
+
+type RichText struct {
+	Type      string `json:"type,omitempty"`
+	PlainText string `json:"plain_text,omitempty"`
+	Text      *Text  `json:"text,omitempty"`
+}
+
+type Text struct {
+	Content string `json:"content"`
+}
+
+type SelectOption struct {
+	Name string `json:"name"`
+}
+
+type User struct {
+	ID string `json:"id"`
+}
+
+type Date struct {
+	Start string  `json:"start"`
+	End   *string `json:"end,omitempty"`
+}
+
+type Comment struct {
+	ID          string     `json:"id"`
+	RichText    []RichText `json:"rich_text"`
+	CreatedBy   User       `json:"created_by"`
+	CreatedTime time.Time  `json:"created_time"`
+}
+
+// PlainText returns the text of the rich text.
+func PlainText(in []RichText) string {
+	res := ""
+	for _, item := range in {
+		if item.PlainText == "" && item.Text != nil {
+			res += item.Text.Content
+			continue
+		}
+		res += item.PlainText
+	}
+	return res
+}
+
+// TextValue returns the rich text with the content.
+func TextValue(content string) []RichText {
+	return []RichText{{Type: "text", Text: &Text{Content: content}}}
+}
+
+func (a *API) GetDatabase(ctx context.Context, databaseID string) (*Database, error) {
+	res := &Database{}
+	err := a.doRequest(ctx, http.MethodGet, "/v1/databases/"+databaseID, nil, nil, res)
+	return res, err
+}
+
+// CreatePage creates the page in the database with the properties (the values by the type, eg {"date": {"start": "2022-01-02"}})
+// and the content blocks.
+func (a *API) CreatePage(ctx context.Context, databaseID string, properties map[string]interface{}, children []interface{}) (*Page, error) {
+	req := map[string]interface{}{
+		"parent":     map[string]string{"database_id": databaseID},
+		"properties": properties,
+	}
+	if len(children) > 0 {
+		req["children"] = children
+	}
+	res := &Page{}
+	err := a.doRequest(ctx, http.MethodPost, "/v1/pages", nil, req, res)
+	return res, err
+}
+
+// UpdatePage updates only the specified properties of the page.
+func (a *API) UpdatePage(ctx context.Context, pageID string, properties map[string]interface{}) (*Page, error) {
+	req := map[string]interface{}{
+		"properties": properties,
+	}
+	res := &Page{}
+	err := a.doRequest(ctx, http.MethodPatch, "/v1/pages/"+pageID, nil, req, res)
+	return res, err
+}
+
+func (a *API) GetPage(ctx context.Context, pageID string) (*Page, error) {
+	res := &Page{}
+	err := a.doRequest(ctx, http.MethodGet, "/v1/pages/"+pageID, nil, nil, res)
+	return res, err
+}
+
+// ListComments returns all comments of the page (the oldest first).
+func (a *API) ListComments(ctx context.Context, pageID string) ([]Comment, error) {
+	list := []Comment{}
+	cursor := ""
+	for {
+		query := url.Values{}
+		query.Set("block_id", pageID)
+		query.Set("page_size", fmt.Sprint(pageSize))
+		if cursor != "" {
+			query.Set("start_cursor", cursor)
+		}
+
+		res := &struct {
+			Results    []Comment `json:"results"`
+			HasMore    bool      `json:"has_more"`
+			NextCursor string    `json:"next_cursor"`
+		}{}
+		if err := a.doRequest(ctx, http.MethodGet, "/v1/comments", query, nil, res); err != nil {
+			return nil, err
+		}
+		list = append(list, res.Results...)
+		if !res.HasMore || res.NextCursor == "" {
+			return list, nil
+		}
+		cursor = res.NextCursor
+	}
+}
+
+func (a *API) CreateComment(ctx context.Context, pageID, text string) (*Comment, error) {
+	req := map[string]interface{}{
+		"parent":    map[string]string{"page_id": pageID},
+		"rich_text": TextValue(text),
+	}
+	res := &Comment{}
+	err := a.doRequest(ctx, http.MethodPost, "/v1/comments", nil, req, res)
+	return res, err
+}
+
+func (a *API) doRequest(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	reqURL := a.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	body := bytes.NewReader(nil)
+	if in != nil {
+		dat, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(dat)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("Notion-Version", Version)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	dat, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	a.log.Debug("API request", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("method", method))
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case res.StatusCode >= 300:
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("uri", reqURL), zap.String("body_raw", string(dat)))
+		return fmt.Errorf("notion: %s %s: got status %d", method, path, res.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(dat, out); err != nil {
+		return fmt.Errorf("notion: failed decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
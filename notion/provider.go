@@ -0,0 +1,329 @@
+package notion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+// ProviderName name of Notion in the spec of sync (spec_add.external[].tracker) and in the task references.
+const ProviderName = "notion"
+
+// the max size of the text of the rich text object
+const maxTextLength = 2000
+
+// the max number of the blocks of the new page
+const maxChildren = 100
+
+// NewProvider returns Notion databases as the provider of the tasks (the pages).
+// The location of the pages is the database ID, the key is the page ID, the members are the IDs of Notion users.
+// The fields of the task are synced with the properties of the database by the mapping (spec_add.external[].properties),
+// the estimate is synced as the number of hours, the description is set as the content of the new page only.
+func NewProvider(api *API) *Provider {
+	return &Provider{
+		api:     api,
+		schemas: map[string]map[string]PropertySchema{},
+	}
+}
+
+type Provider struct {
+	api *API
+
+	mu sync.Mutex
+	// the properties of the databases by ID
+	schemas map[string]map[string]PropertySchema
+}
+
+var _ tracker.Provider = (*Provider)(nil)
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+func (p *Provider) Capabilities() tracker.Capability {
+	return tracker.CapComments | tracker.CapTags | tracker.CapAssignees | tracker.CapStatus | tracker.CapDueDate |
+		tracker.CapStartDate | tracker.CapEstimate
+}
+
+// Fields returns the name and the mapped fields.
+func (p *Provider) Fields(location tracker.Location) []string {
+	fields := []string{tracker.FieldName}
+	for _, field := range []string{
+		tracker.FieldStatus,
+		tracker.FieldDueDate,
+		tracker.FieldStartDate,
+		tracker.FieldEstimate,
+		tracker.FieldAssignees,
+		tracker.FieldTags,
+	} {
+		if location.Property(field) != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (p *Provider) CreateTask(ctx context.Context, location tracker.Location, task *tracker.Task) (*tracker.Task, error) {
+	properties, err := p.properties(ctx, location, task, p.Fields(location))
+	if err != nil {
+		return nil, err
+	}
+	page, err := p.api.CreatePage(ctx, location.ID, properties, paragraphs(task.Description))
+	if err != nil {
+		return nil, err
+	}
+	return p.providerTask(ctx, location, page)
+}
+
+func (p *Provider) UpdateTask(ctx context.Context, location tracker.Location, task *tracker.Task, fields []string) (*tracker.Task, error) {
+	properties, err := p.properties(ctx, location, task, fields)
+	if err != nil {
+		return nil, err
+	}
+	page, err := p.api.UpdatePage(ctx, task.Key, properties)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return p.providerTask(ctx, location, page)
+}
+
+// GetTask returns the page by the ID or tracker.ErrNotFound (eg the archived page).
+func (p *Provider) GetTask(ctx context.Context, location tracker.Location, key string) (*tracker.Task, error) {
+	page, err := p.api.GetPage(ctx, key)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	if page.Archived {
+		// the deleted page is archived (in the trash)
+		return nil, tracker.ErrNotFound
+	}
+	return p.providerTask(ctx, location, page)
+}
+
+func (p *Provider) ListComments(ctx context.Context, location tracker.Location, key string) ([]*tracker.Comment, error) {
+	res, err := p.api.ListComments(ctx, key)
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	list := []*tracker.Comment{}
+	for idx := range res {
+		list = append(list, providerComment(&res[idx]))
+	}
+	return list, nil
+}
+
+func (p *Provider) AddComment(ctx context.Context, location tracker.Location, key string, comment *tracker.Comment) (*tracker.Comment, error) {
+	res, err := p.api.CreateComment(ctx, key, truncate(comment.Body))
+	if err != nil {
+		return nil, notFoundErr(err)
+	}
+	return providerComment(res), nil
+}
+
+// ListMembers is not supported (the assignees are the IDs of the users from the spec of sync).
+func (p *Provider) ListMembers(ctx context.Context, location tracker.Location) ([]*tracker.Member, error) {
+	return nil, fmt.Errorf("the members of the database %q are not supported", location.ID)
+}
+
+func notFoundErr(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return tracker.ErrNotFound
+	}
+	return err
+}
+
+// returns the properties of the database (loaded once)
+func (p *Provider) schema(ctx context.Context, databaseID string) (map[string]PropertySchema, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if schema, exists := p.schemas[databaseID]; exists {
+		return schema, nil
+	}
+	database, err := p.api.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the database %q: %w", databaseID, err)
+	}
+	p.schemas[databaseID] = database.Properties
+	return database.Properties, nil
+}
+
+// returns the name of the property by the field (the title property of the database for the name by default)
+func propertyName(schema map[string]PropertySchema, location tracker.Location, field string) string {
+	if name := location.Property(field); name != "" {
+		return name
+	}
+	if field == tracker.FieldName {
+		for name, property := range schema {
+			if property.Type == "title" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// returns the values of the properties by the fields of the task
+func (p *Provider) properties(ctx context.Context, location tracker.Location, task *tracker.Task,
+	fields []string) (map[string]interface{}, error) {
+
+	schema, err := p.schema(ctx, location.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]interface{}{}
+	for _, field := range fields {
+		name := propertyName(schema, location, field)
+		property, exists := schema[name]
+		if !exists {
+			return nil, fmt.Errorf("not found the property %q (field %q) in the database %q", name, field, location.ID)
+		}
+
+		var value interface{}
+		switch {
+		case field == tracker.FieldName && property.Type == "title":
+			value = TextValue(truncate(task.Name))
+		case field == tracker.FieldStatus && (property.Type == "select" || property.Type == "status"):
+			if task.Status != "" {
+				value = SelectOption{Name: task.Status}
+			}
+		case field == tracker.FieldTags && property.Type == "multi_select":
+			options := []SelectOption{}
+			for _, tag := range task.Tags {
+				options = append(options, SelectOption{Name: tag})
+			}
+			value = options
+		case field == tracker.FieldAssignees && property.Type == "people":
+			people := []User{}
+			for _, member := range task.Assignees {
+				people = append(people, User{ID: member.ID})
+			}
+			value = people
+		case field == tracker.FieldDueDate && property.Type == "date":
+			value = dateValue(task.DueDate)
+		case field == tracker.FieldStartDate && property.Type == "date":
+			value = dateValue(task.StartDate)
+		case field == tracker.FieldEstimate && property.Type == "number":
+			if task.TimeEstimateMs != nil {
+				value = float64(*task.TimeEstimateMs) / float64(time.Hour/time.Millisecond)
+			}
+		default:
+			return nil, fmt.Errorf("not supported type %q of the property %q for field %q", property.Type, name, field)
+		}
+		res[name] = map[string]interface{}{property.Type: value}
+	}
+	return res, nil
+}
+
+func dateValue(in *time.Time) *Date {
+	if in == nil {
+		return nil
+	}
+	return &Date{Start: in.UTC().Format(time.RFC3339)}
+}
+
+func parseDate(in *Date) *time.Time {
+	if in == nil || in.Start == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if res, err := time.Parse(layout, in.Start); err == nil {
+			return &res
+		}
+	}
+	return nil
+}
+
+// returns the task by the page
+func (p *Provider) providerTask(ctx context.Context, location tracker.Location, page *Page) (*tracker.Task, error) {
+	schema, err := p.schema(ctx, location.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &tracker.Task{
+		Ref:       tracker.TaskRef{Provider: ProviderName, ID: page.ID},
+		Key:       page.ID,
+		Location:  location,
+		Tags:      []string{},
+		Assignees: []tracker.Member{},
+		URL:       page.URL,
+		UpdatedAt: page.LastEditedTime,
+	}
+	for _, field := range p.Fields(location) {
+		value := page.Properties[propertyName(schema, location, field)]
+		switch field {
+		case tracker.FieldName:
+			res.Name = PlainText(value.Title)
+		case tracker.FieldStatus:
+			if value.Select != nil {
+				res.Status = value.Select.Name
+			}
+			if value.Status != nil {
+				res.Status = value.Status.Name
+			}
+		case tracker.FieldTags:
+			for _, option := range value.MultiSelect {
+				res.Tags = append(res.Tags, option.Name)
+			}
+		case tracker.FieldAssignees:
+			for _, user := range value.People {
+				res.Assignees = append(res.Assignees, tracker.Member{ID: user.ID})
+			}
+		case tracker.FieldDueDate:
+			res.DueDate = parseDate(value.Date)
+		case tracker.FieldStartDate:
+			res.StartDate = parseDate(value.Date)
+		case tracker.FieldEstimate:
+			if value.Number != nil {
+				estimate := int64(math.Round(*value.Number * float64(time.Hour/time.Millisecond)))
+				res.TimeEstimateMs = &estimate
+			}
+		}
+	}
+	return res, nil
+}
+
+func providerComment(in *Comment) *tracker.Comment {
+	return &tracker.Comment{
+		ID:        in.ID,
+		Author:    tracker.Member{ID: in.CreatedBy.ID},
+		Body:      PlainText(in.RichText),
+		CreatedAt: in.CreatedTime,
+	}
+}
+
+// returns the paragraph blocks by the lines of the text
+func paragraphs(text string) []interface{} {
+	res := []interface{}{}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(res) == maxChildren {
+			break
+		}
+		res = append(res, map[string]interface{}{
+			"object":    "block",
+			"type":      "paragraph",
+			"paragraph": map[string]interface{}{"rich_text": TextValue(truncate(line))},
+		})
+	}
+	return res
+}
+
+// returns the text not longer than the limit of the rich text object
+func truncate(in string) string {
+	runes := []rune(in)
+	if len(runes) <= maxTextLength {
+		return in
+	}
+	return string(runes[:maxTextLength])
+}
@@ -0,0 +1,273 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/tracker"
+	"github.com/gebv/asap-tools/tracker/trackertest"
+)
+
+const (
+	testToken      = "test-token"
+	testDatabaseID = "d9824bdc-8445-4327-be8b-5b47500af6ce"
+)
+
+var testLocation = tracker.Location{
+	Provider: ProviderName,
+	ID:       testDatabaseID,
+	Properties: map[string]string{
+		tracker.FieldStatus:    "Status",
+		tracker.FieldDueDate:   "Due",
+		tracker.FieldEstimate:  "Estimate",
+		tracker.FieldAssignees: "Assignees",
+		tracker.FieldTags:      "Tags",
+	},
+}
+
+// fakeAPI the in-memory Notion API (only one database, the pages of the database and the comments of the pages)
+type fakeAPI struct {
+	nextID   int
+	schema   map[string]PropertySchema
+	pages    map[string]*fakePage
+	comments map[string][]Comment
+	// the number of the requests of the database schema
+	schemaRequests int
+}
+
+type fakePage struct {
+	archived bool
+	// the values of the properties by name (without the type)
+	properties map[string]json.RawMessage
+	children   []json.RawMessage
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		schema: map[string]PropertySchema{
+			"Name":      {ID: "title", Type: "title"},
+			"Status":    {ID: "s1", Type: "select"},
+			"Due":       {ID: "d1", Type: "date"},
+			"Estimate":  {ID: "e1", Type: "number"},
+			"Assignees": {ID: "p1", Type: "people"},
+			"Tags":      {ID: "t1", Type: "multi_select"},
+		},
+		pages:    map[string]*fakePage{},
+		comments: map[string][]Comment{},
+	}
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	args := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/"), "/")
+	switch {
+	case args[0] == "databases" && len(args) == 2 && r.Method == http.MethodGet:
+		if args[1] != testDatabaseID {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.schemaRequests++
+		trackertest.WriteJSON(w, http.StatusOK, &Database{ID: testDatabaseID, Properties: f.schema})
+
+	case args[0] == "pages" && len(args) == 1 && r.Method == http.MethodPost:
+		req := &struct {
+			Parent struct {
+				DatabaseID string `json:"database_id"`
+			} `json:"parent"`
+			Properties map[string]map[string]json.RawMessage `json:"properties"`
+			Children   []json.RawMessage                     `json:"children"`
+		}{}
+		json.NewDecoder(r.Body).Decode(req)
+		if req.Parent.DatabaseID != testDatabaseID {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.nextID++
+		id := fmt.Sprintf("00000000-0000-0000-0000-%012d", f.nextID)
+		f.pages[id] = &fakePage{properties: map[string]json.RawMessage{}, children: req.Children}
+		f.setProperties(w, f.pages[id], req.Properties)
+		trackertest.WriteJSON(w, http.StatusOK, f.page(id))
+
+	case args[0] == "pages" && len(args) == 2:
+		page, exists := f.pages[args[1]]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPatch {
+			req := &struct {
+				Properties map[string]map[string]json.RawMessage `json:"properties"`
+			}{}
+			json.NewDecoder(r.Body).Decode(req)
+			if !f.setProperties(w, page, req.Properties) {
+				return
+			}
+		}
+		trackertest.WriteJSON(w, http.StatusOK, f.page(args[1]))
+
+	case args[0] == "comments" && r.Method == http.MethodPost:
+		req := &struct {
+			Parent struct {
+				PageID string `json:"page_id"`
+			} `json:"parent"`
+			RichText []RichText `json:"rich_text"`
+		}{}
+		json.NewDecoder(r.Body).Decode(req)
+		if page, exists := f.pages[req.Parent.PageID]; !exists || page.archived {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.nextID++
+		comment := Comment{ID: strconv.Itoa(f.nextID), RichText: req.RichText, CreatedBy: User{ID: "bot"}}
+		f.comments[req.Parent.PageID] = append(f.comments[req.Parent.PageID], comment)
+		trackertest.WriteJSON(w, http.StatusOK, comment)
+
+	case args[0] == "comments" && r.Method == http.MethodGet:
+		list := f.comments[r.URL.Query().Get("block_id")]
+		size, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+		cursor, _ := strconv.Atoi(r.URL.Query().Get("start_cursor"))
+		from, to := trackertest.Page(len(list), cursor, size)
+		res := map[string]interface{}{"results": list[from:to], "has_more": false, "next_cursor": nil}
+		if to < len(list) {
+			res["has_more"] = true
+			res["next_cursor"] = strconv.Itoa(to)
+		}
+		trackertest.WriteJSON(w, http.StatusOK, res)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// sets the values of the properties if the types are valid
+func (f *fakeAPI) setProperties(w http.ResponseWriter, page *fakePage, properties map[string]map[string]json.RawMessage) bool {
+	for name, value := range properties {
+		property, exists := f.schema[name]
+		if !exists || len(value) != 1 || value[property.Type] == nil {
+			trackertest.WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid property " + name})
+			return false
+		}
+		page.properties[name] = value[property.Type]
+	}
+	return true
+}
+
+func (f *fakeAPI) page(id string) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for name, value := range f.pages[id].properties {
+		property := f.schema[name]
+		if property.Type == "title" {
+			// the plain text is populated by the API
+			list := []RichText{}
+			json.Unmarshal(value, &list)
+			for idx := range list {
+				list[idx].PlainText = list[idx].Text.Content
+			}
+			value, _ = json.Marshal(list)
+		}
+		properties[name] = map[string]interface{}{"id": property.ID, "type": property.Type, property.Type: value}
+	}
+	return map[string]interface{}{
+		"object":     "page",
+		"id":         id,
+		"url":        "https://www.notion.so/" + strings.ReplaceAll(id, "-", ""),
+		"archived":   f.pages[id].archived,
+		"properties": properties,
+	}
+}
+
+func newTestProvider(t *testing.T) (*Provider, *fakeAPI) {
+	fake := newFakeAPI()
+	authorized := func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer "+testToken && r.Header.Get("Notion-Version") == Version
+	}
+	url := trackertest.NewServer(t, authorized, fake.ServeHTTP)
+	return NewProvider(NewAPI(url, testToken)), fake
+}
+
+func TestProvider_Fields(t *testing.T) {
+	provider := NewProvider(nil)
+	want := []string{
+		tracker.FieldName,
+		tracker.FieldStatus,
+		tracker.FieldDueDate,
+		tracker.FieldEstimate,
+		tracker.FieldAssignees,
+		tracker.FieldTags,
+	}
+	if got := provider.Fields(testLocation); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+	if got := provider.Fields(tracker.Location{}); !reflect.DeepEqual(got, []string{tracker.FieldName}) {
+		t.Errorf("Fields() without properties = %v", got)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	provider, fake := newTestProvider(t)
+	dueDate := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	estimate := int64(90 * time.Minute / time.Millisecond)
+	suite := &trackertest.Suite{
+		Provider: provider,
+		Location: testLocation,
+		Task: &tracker.Task{
+			Name:           "Task",
+			Tags:           []string{"bug"},
+			Assignees:      []tracker.Member{{ID: "user-1"}},
+			Status:         "In progress",
+			DueDate:        &dueDate,
+			TimeEstimateMs: &estimate,
+		},
+		Update: &tracker.Task{
+			Tags:      []string{"bug", "ui"},
+			Assignees: []tracker.Member{{ID: "user-2"}},
+			Status:    "Done",
+		},
+		Remove: func(task *tracker.Task) {
+			fake.pages[task.Key].archived = true
+		},
+		PageSize:      pageSize,
+		CommentAuthor: "bot",
+	}
+	suite.Run(t)
+}
+
+func TestProvider_Pages(t *testing.T) {
+	ctx := context.Background()
+	provider, fake := newTestProvider(t)
+
+	// the description is the content of the page
+	created, err := provider.CreateTask(ctx, testLocation, &tracker.Task{Name: "Task", Description: "first line\n\nsecond line"})
+	if err != nil {
+		t.Fatalf("CreateTask(): %v", err)
+	}
+	if created.Key != created.Ref.ID || created.URL != "https://www.notion.so/"+strings.ReplaceAll(created.Key, "-", "") {
+		t.Errorf("CreateTask() = %+v, want the key and the URL by the ID of the page", created)
+	}
+	if got := len(fake.pages[created.Key].children); got != 2 {
+		t.Errorf("CreateTask() the page has %d blocks, want 2", got)
+	}
+	if _, err := provider.GetTask(ctx, testLocation, created.Key); err != nil {
+		t.Fatalf("GetTask(): %v", err)
+	}
+	if fake.schemaRequests != 1 {
+		t.Errorf("the schema of the database is requested %d times, want 1", fake.schemaRequests)
+	}
+	if _, err := provider.GetTask(ctx, testLocation, "not-found"); !errors.Is(err, tracker.ErrNotFound) {
+		t.Errorf("GetTask() for the not found page: %v, want %v", err, tracker.ErrNotFound)
+	}
+
+	// the property of the wrong type
+	wrongLocation := testLocation
+	wrongLocation.Properties = map[string]string{tracker.FieldEstimate: "Due"}
+	if _, err := provider.CreateTask(ctx, wrongLocation, &tracker.Task{Name: "Task"}); err == nil {
+		t.Error("CreateTask() with the property of the wrong type must return error")
+	}
+}
@@ -0,0 +1,179 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// DefaultBaseURL the URL of Slack Web API
+const DefaultBaseURL = "https://slack.com/api"
+
+type httpClientLogger struct {
+	*zap.Logger
+}
+
+func (l *httpClientLogger) Printf(msg string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(msg, args...))
+}
+
+// NewAPI returns the client of Slack Web API and the incoming webhooks (baseURL is DefaultBaseURL by default).
+// The token (of the bot or the user) is required only for the methods of Web API (eg chat.postMessage).
+func NewAPI(baseURL, botToken string) *API {
+	l := zap.L().Named("slack_api")
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = &httpClientLogger{l.Named("http")}
+	// the limited requests (429) are retried by the notifier after Retry-After
+	httpClient.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			return false, nil
+		}
+		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &API{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		botToken: botToken,
+		client:   httpClient.StandardClient(),
+		log:      l,
+	}
+}
+
+type API struct {
+	baseURL  string
+	botToken string
+	client   *http.Client
+	log      *zap.Logger
+}
+
+// Message the message with the blocks of Block Kit (the text is the fallback for the notifications).
+type Message struct {
+	// the channel (only for chat.postMessage)
+	Channel string  `json:"channel,omitempty"`
+	Text    string  `json:"text"`
+	Blocks  []Block `json:"blocks,omitempty"`
+	// ephemeral or in_channel (only for the response URL of the interactions)
+	ResponseType    string `json:"response_type,omitempty"`
+	ReplaceOriginal bool   `json:"replace_original,omitempty"`
+	UnfurlLinks     bool   `json:"unfurl_links"`
+}
+
+// Error the unsuccessful response of Slack (the error code of Web API or the text of the webhook).
+type Error struct {
+	Status int
+	Code   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("slack: %d %s", e.Status, e.Code)
+}
+
+// PostMessage sends the message to the channel (ID or name) by chat.postMessage, returns the timestamp of the message.
+func (a *API) PostMessage(ctx context.Context, channel string, msg *Message) (string, error) {
+	req := *msg
+	req.Channel = channel
+	res := &struct {
+		TS string `json:"ts"`
+	}{}
+	err := a.doRequest(ctx, a.baseURL+"/chat.postMessage", true, &req, res)
+	return res.TS, err
+}
+
+// PostWebhook sends the message to the incoming webhook or to the response URL of the interaction.
+func (a *API) PostWebhook(ctx context.Context, webhookURL string, msg *Message) error {
+	return a.doRequest(ctx, webhookURL, false, msg, nil)
+}
+
+// the webhooks respond with the plain text "ok", Web API responds with the JSON {"ok": ...}
+func (a *API) doRequest(ctx context.Context, reqURL string, webAPI bool, in, out interface{}) error {
+	dat, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(dat))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return a.do(req, webAPI, out)
+}
+
+// calls the read method of Web API with the query (eg conversations.history)
+func (a *API) doGetRequest(ctx context.Context, method string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+"/"+method+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	return a.do(req, true, out)
+}
+
+func (a *API) do(req *http.Request, webAPI bool, out interface{}) error {
+	if webAPI {
+		req.Header.Set("Authorization", "Bearer "+a.botToken)
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		// the error contains the URL of the webhook (the URL is the secret)
+		return fmt.Errorf("slack: failed request")
+	}
+	defer res.Body.Close()
+
+	dat, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	a.log.Debug("API request", zap.String("status", res.Status), zap.Bool("web_api", webAPI))
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		after, _ := strconv.Atoi(res.Header.Get("Retry-After"))
+		return &notify.RetryAfterError{
+			After: time.Duration(after) * time.Second,
+			Err:   &Error{Status: res.StatusCode, Code: "rate_limited"},
+		}
+	}
+	if res.StatusCode >= 300 {
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("body_raw", string(dat)))
+		return &Error{Status: res.StatusCode, Code: strings.TrimSpace(string(dat))}
+	}
+	if !webAPI {
+		return nil
+	}
+
+	resp := &struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}{}
+	if err := json.Unmarshal(dat, resp); err != nil {
+		return fmt.Errorf("slack: got status %d", res.StatusCode)
+	}
+	if !resp.OK {
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("body_raw", string(dat)))
+		return &Error{Status: res.StatusCode, Code: resp.Error}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(dat, out); err != nil {
+		return fmt.Errorf("slack: failed decode response: %w", err)
+	}
+	return nil
+}
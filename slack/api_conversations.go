@@ -0,0 +1,212 @@
+package slack
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// the max size of the page of the list requests
+const pageLimit = "200"
+
+// the types of the conversations (see conversations.list)
+const (
+	ConversationPublic  = "public_channel"
+	ConversationPrivate = "private_channel"
+	ConversationIM      = "im"
+	ConversationMPIM    = "mpim"
+)
+
+// ConversationTypes all types of the conversations.
+var ConversationTypes = []string{ConversationPublic, ConversationPrivate, ConversationIM, ConversationMPIM}
+
+type Conversation struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IsChannel  bool   `json:"is_channel"`
+	IsPrivate  bool   `json:"is_private"`
+	IsIM       bool   `json:"is_im"`
+	IsMPIM     bool   `json:"is_mpim"`
+	IsArchived bool   `json:"is_archived"`
+	// the other user of the direct messages
+	User    string `json:"user"`
+	Created int64  `json:"created"`
+	Topic   struct {
+		Value string `json:"value"`
+	} `json:"topic"`
+	Purpose struct {
+		Value string `json:"value"`
+	} `json:"purpose"`
+}
+
+// Type returns the type of the conversation (see ConversationTypes).
+func (c *Conversation) Type() string {
+	switch {
+	case c.IsIM:
+		return ConversationIM
+	case c.IsMPIM:
+		return ConversationMPIM
+	case c.IsPrivate:
+		return ConversationPrivate
+	}
+	return ConversationPublic
+}
+
+// ConversationMessage the message of the history of the conversation or the reply of the thread.
+type ConversationMessage struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	// the ID of the message in the conversation (eg 1643709600.000100)
+	TS string `json:"ts"`
+	// the ID of the parent message of the thread (equals TS for the parent message)
+	ThreadTS   string `json:"thread_ts"`
+	ReplyCount int    `json:"reply_count"`
+	User       string `json:"user"`
+	BotID      string `json:"bot_id"`
+	Username   string `json:"username"`
+	Text       string `json:"text"`
+	Edited     *struct {
+		User string `json:"user"`
+		TS   string `json:"ts"`
+	} `json:"edited"`
+	Reactions []Reaction `json:"reactions"`
+	Files     []File     `json:"files"`
+}
+
+type Reaction struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Users []string `json:"users"`
+}
+
+// File the metadata of the file (the content is not downloaded).
+type File struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Title      string `json:"title"`
+	Mimetype   string `json:"mimetype"`
+	Filetype   string `json:"filetype"`
+	Size       int64  `json:"size"`
+	URLPrivate string `json:"url_private"`
+	Permalink  string `json:"permalink"`
+}
+
+type User struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Deleted  bool   `json:"deleted"`
+	IsBot    bool   `json:"is_bot"`
+	Profile  struct {
+		DisplayName string `json:"display_name"`
+	} `json:"profile"`
+}
+
+type pageMetadata struct {
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+// ListConversations returns the conversations of the types available to the token (all pages).
+func (a *API) ListConversations(ctx context.Context, types []string) ([]Conversation, error) {
+	query := url.Values{
+		"types":            {strings.Join(types, ",")},
+		"exclude_archived": {"false"},
+		"limit":            {pageLimit},
+	}
+	list := []Conversation{}
+	for {
+		res := &struct {
+			pageMetadata
+			Channels []Conversation `json:"channels"`
+		}{}
+		if err := a.doGetRequest(ctx, "conversations.list", query, res); err != nil {
+			return nil, err
+		}
+		list = append(list, res.Channels...)
+		if res.ResponseMetadata.NextCursor == "" {
+			return list, nil
+		}
+		query.Set("cursor", res.ResponseMetadata.NextCursor)
+	}
+}
+
+// ConversationHistory returns the messages of the conversation after oldest (all messages if empty), the oldest first.
+// The replies of the threads are not included (see ConversationReplies).
+func (a *API) ConversationHistory(ctx context.Context, channel, oldest string) ([]ConversationMessage, error) {
+	query := url.Values{"channel": {channel}, "limit": {pageLimit}}
+	if oldest != "" {
+		query.Set("oldest", oldest)
+	}
+	list := []ConversationMessage{}
+	for {
+		res := &struct {
+			pageMetadata
+			Messages []ConversationMessage `json:"messages"`
+		}{}
+		if err := a.doGetRequest(ctx, "conversations.history", query, res); err != nil {
+			return nil, err
+		}
+		list = append(list, res.Messages...)
+		if res.ResponseMetadata.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", res.ResponseMetadata.NextCursor)
+	}
+
+	// the history is returned the newest first
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	return list, nil
+}
+
+// ConversationReplies returns the replies of the thread after oldest (all replies if empty), the oldest first.
+// The parent message is not included.
+func (a *API) ConversationReplies(ctx context.Context, channel, threadTS, oldest string) ([]ConversationMessage, error) {
+	query := url.Values{"channel": {channel}, "ts": {threadTS}, "limit": {pageLimit}}
+	if oldest != "" {
+		query.Set("oldest", oldest)
+	}
+	list := []ConversationMessage{}
+	for {
+		res := &struct {
+			pageMetadata
+			Messages []ConversationMessage `json:"messages"`
+		}{}
+		if err := a.doGetRequest(ctx, "conversations.replies", query, res); err != nil {
+			return nil, err
+		}
+		for _, msg := range res.Messages {
+			// the parent message is always returned
+			if msg.TS != threadTS {
+				list = append(list, msg)
+			}
+		}
+		if res.ResponseMetadata.NextCursor == "" {
+			return list, nil
+		}
+		query.Set("cursor", res.ResponseMetadata.NextCursor)
+	}
+}
+
+// ListUsers returns the users of the workspace (all pages).
+func (a *API) ListUsers(ctx context.Context) ([]User, error) {
+	query := url.Values{"limit": {pageLimit}}
+	list := []User{}
+	for {
+		res := &struct {
+			pageMetadata
+			Members []User `json:"members"`
+		}{}
+		if err := a.doGetRequest(ctx, "users.list", query, res); err != nil {
+			return nil, err
+		}
+		list = append(list, res.Members...)
+		if res.ResponseMetadata.NextCursor == "" {
+			return list, nil
+		}
+		query.Set("cursor", res.ResponseMetadata.NextCursor)
+	}
+}
@@ -0,0 +1,311 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+	"go.uber.org/zap"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// the threads are checked for the new replies while the parent message is younger than the window by default
+const DefaultThreadsWindow = 30 * 24 * time.Hour
+
+// the max number of the retries of the limited request
+const maxRateLimitRetries = 3
+
+// ArchiveOptions the conversations of the archive.
+type ArchiveOptions struct {
+	// the types of the conversations (all by default, see ConversationTypes)
+	Types []string
+	// only the conversations by ID or by name (all by default)
+	Conversations []string
+	// the threads are checked for the new replies while the parent message is younger (DefaultThreadsWindow by default)
+	ThreadsWindow time.Duration
+}
+
+func (o *ArchiveOptions) allowed(conv *Conversation) bool {
+	if len(o.Conversations) == 0 {
+		return true
+	}
+	for _, name := range o.Conversations {
+		if name == conv.ID || strings.TrimPrefix(name, "#") == conv.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewArchiver returns the archiver of the conversations of Slack into the storage.
+func NewArchiver(api *API, store *Storage) *Archiver {
+	return &Archiver{
+		api:   api,
+		store: store,
+		log:   zap.L().Named("slack_archiver"),
+		now:   time.Now,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			select {
+			case <-time.After(d):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+type Archiver struct {
+	api   *API
+	store *Storage
+	log   *zap.Logger
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Archive saves the users and the new messages of the conversations (the next call continues from the last saved message).
+func (a *Archiver) Archive(ctx context.Context, opts ArchiveOptions) error {
+	types := opts.Types
+	if len(types) == 0 {
+		types = ConversationTypes
+	}
+
+	users := []User{}
+	err := a.withRetry(ctx, func() (err error) {
+		users, err = a.api.ListUsers(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
+	}
+	for _, user := range users {
+		err := a.store.UpsertUser(ctx, ArchivedUserFromAPI(&user))
+		warnErrorIf(a.log, err, "failed to upsert the user", "user_id", user.ID)
+	}
+
+	list := []Conversation{}
+	err = a.withRetry(ctx, func() (err error) {
+		list, err = a.api.ListConversations(ctx, types)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list conversations: %w", err)
+	}
+
+	for idx := range list {
+		conv := &list[idx]
+		if !opts.allowed(conv) {
+			continue
+		}
+		if err := a.ArchiveConversation(ctx, conv, opts.ThreadsWindow); err != nil {
+			// the next conversations are archived anyway, the failed conversation is continued by the next call
+			a.log.Warn("failed to archive the conversation", zap.String("conversation_id", conv.ID), zap.String("name", conv.Name), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// ArchiveConversation saves the new messages of the history and the new replies of the threads of the conversation.
+func (a *Archiver) ArchiveConversation(ctx context.Context, conv *Conversation, threadsWindow time.Duration) error {
+	err := a.store.UpsertConversation(ctx, ArchivedConversationFromAPI(conv))
+	warnErrorIf(a.log, err, "failed to upsert the conversation", "conversation_id", conv.ID)
+
+	status := a.store.GetStateOfLoadConversation(ctx, conv.ID)
+	changes, err := a.fetchChanges(ctx, conv.ID, status, threadsWindow)
+	if err != nil {
+		return err
+	}
+
+	for idx := range changes.Messages {
+		msg := ArchivedMessageFromAPI(conv.ID, &changes.Messages[idx])
+		if err := a.store.UpsertMessage(ctx, msg); err != nil {
+			// the cursor is not moved - the messages are saved again by the next call
+			return fmt.Errorf("failed to upsert message %q: %w", msg.TS, err)
+		}
+	}
+
+	err = a.store.UpsertLoadStatusOfConversation(ctx, changes.Status)
+	warnErrorIf(a.log, err, "failed to upsert the status of the archive", "conversation_id", conv.ID)
+
+	a.log.Info("archived the conversation", zap.String("conversation_id", conv.ID), zap.String("name", conv.Name),
+		zap.Int("num_messages", len(changes.Messages)), zap.Int("num_threads", len(changes.Status.Threads)))
+	return nil
+}
+
+// the new messages of the conversation and the next status of the archive
+type conversationChanges struct {
+	Messages []ConversationMessage
+	Status   *LoadStatusOfConversation
+}
+
+// returns the messages after the last archived message and the replies after the last archived reply of each thread
+// (the archived threads with the parent message older than the window are not checked anymore)
+func (a *Archiver) fetchChanges(ctx context.Context, conversationID string, status *LoadStatusOfConversation, threadsWindow time.Duration) (*conversationChanges, error) {
+	if threadsWindow <= 0 {
+		threadsWindow = DefaultThreadsWindow
+	}
+	res := &conversationChanges{Status: &LoadStatusOfConversation{
+		ConversationID: conversationID,
+		LastMessageTS:  status.LastMessageTS,
+	}}
+
+	history := []ConversationMessage{}
+	err := a.withRetry(ctx, func() (err error) {
+		history, err = a.api.ConversationHistory(ctx, conversationID, status.LastMessageTS)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	threads := map[string]string{}
+	tracked := map[string]bool{}
+	for _, thread := range status.Threads {
+		threads[thread.TS] = thread.LastReplyTS
+		tracked[thread.TS] = true
+	}
+	added := map[string]bool{}
+	for _, msg := range history {
+		added[msg.TS] = true
+		res.Messages = append(res.Messages, msg)
+		if tsAfter(msg.TS, res.Status.LastMessageTS) {
+			res.Status.LastMessageTS = msg.TS
+		}
+		if _, exists := threads[msg.TS]; !exists && msg.ReplyCount > 0 && msg.ThreadTS == msg.TS {
+			threads[msg.TS] = ""
+		}
+	}
+
+	threadsFrom := a.now().Add(-threadsWindow)
+	for _, threadTS := range sortedKeys(threads) {
+		// the replies of the new threads are loaded anyway (eg the first archive of the conversation)
+		if tracked[threadTS] && tsTime(threadTS).Before(threadsFrom) {
+			continue
+		}
+		lastReplyTS := threads[threadTS]
+		replies := []ConversationMessage{}
+		err := a.withRetry(ctx, func() (err error) {
+			replies, err = a.api.ConversationReplies(ctx, conversationID, threadTS, lastReplyTS)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load replies of thread %q: %w", threadTS, err)
+		}
+		for _, msg := range replies {
+			if !tsAfter(msg.TS, lastReplyTS) {
+				continue
+			}
+			if tsAfter(msg.TS, threads[threadTS]) {
+				threads[threadTS] = msg.TS
+			}
+			// the replies sent also to the conversation are in the history
+			if !added[msg.TS] {
+				added[msg.TS] = true
+				res.Messages = append(res.Messages, msg)
+			}
+		}
+		res.Status.Threads = append(res.Status.Threads, ThreadCursor{TS: threadTS, LastReplyTS: threads[threadTS]})
+	}
+	return res, nil
+}
+
+// calls the request again after the wait of Slack if the request is limited
+func (a *Archiver) withRetry(ctx context.Context, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		limited := &notify.RetryAfterError{}
+		if !errors.As(err, &limited) || attempt >= maxRateLimitRetries {
+			return err
+		}
+		a.log.Debug("the request is limited", zap.Duration("retry_after", limited.After))
+		if err := a.sleep(ctx, limited.After); err != nil {
+			return err
+		}
+	}
+}
+
+// ArchivedConversationFromAPI returns the model of the conversation.
+func ArchivedConversationFromAPI(in *Conversation) *ArchivedConversation {
+	conv := &ArchivedConversation{
+		Name:      in.Name,
+		Type:      in.Type(),
+		UserID:    in.User,
+		Topic:     in.Topic.Value,
+		Purpose:   in.Purpose.Value,
+		Archived:  in.IsArchived,
+		CreatedAt: timestamppb.New(time.Unix(in.Created, 0)),
+	}
+	conv.SetModelID(in.ID)
+	return conv
+}
+
+// ArchivedMessageFromAPI returns the model of the message of the conversation.
+func ArchivedMessageFromAPI(conversationID string, in *ConversationMessage) *ArchivedMessage {
+	return &ArchivedMessage{
+		ConversationID: conversationID,
+		TS:             in.TS,
+		ThreadTS:       in.ThreadTS,
+		ReplyCount:     in.ReplyCount,
+		Subtype:        in.Subtype,
+		UserID:         in.User,
+		BotID:          in.BotID,
+		Username:       in.Username,
+		Text:           in.Text,
+		Reactions:      in.Reactions,
+		Files:          in.Files,
+		Edited:         in.Edited != nil,
+		PostedAt:       timestamppb.New(tsTime(in.TS)),
+	}
+}
+
+// ArchivedUserFromAPI returns the model of the user.
+func ArchivedUserFromAPI(in *User) *ArchivedUser {
+	user := &ArchivedUser{
+		Name:        in.Name,
+		RealName:    in.RealName,
+		DisplayName: in.Profile.DisplayName,
+		IsBot:       in.IsBot,
+		Deleted:     in.Deleted,
+	}
+	user.SetModelID(in.ID)
+	return user
+}
+
+// returns the time of the ID of the message (eg 1643709600.000100 - the seconds and the microseconds)
+func tsTime(ts string) time.Time {
+	parts := strings.SplitN(ts, ".", 2)
+	sec, _ := strconv.ParseInt(parts[0], 10, 64)
+	usec := int64(0)
+	if len(parts) == 2 {
+		usec, _ = strconv.ParseInt((parts[1] + "000000")[:6], 10, 64)
+	}
+	return time.Unix(sec, usec*int64(time.Microsecond)).UTC()
+}
+
+// returns true if the message a is after the message b (or b is empty)
+func tsAfter(a, b string) bool {
+	if b == "" {
+		return a != ""
+	}
+	return tsTime(a).After(tsTime(b))
+}
+
+func sortedKeys(in map[string]string) []string {
+	res := make([]string, 0, len(in))
+	for key := range in {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func warnErrorIf(l *zap.Logger, err error, msg string, pairs ...interface{}) {
+	if err != nil {
+		l.With(zap.Error(err)).Sugar().Warnw(msg, pairs...)
+	}
+}
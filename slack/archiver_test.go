@@ -0,0 +1,171 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConversations the in-memory Slack Web API of the conversations (the pages of two messages)
+type fakeConversations struct {
+	mu sync.Mutex
+	// the messages and the replies by the channels (the oldest first)
+	messages map[string][]ConversationMessage
+	// the number of the next requests limited by 429
+	limited int
+	calls   []string
+}
+
+func (f *fakeConversations) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+testBotToken {
+		writeJSON(w, map[string]interface{}{"ok": false, "error": "not_authed"})
+		return
+	}
+	if f.limited > 0 {
+		f.limited--
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	query := r.URL.Query()
+	f.calls = append(f.calls, r.URL.Path+" "+query.Get("ts")+" "+query.Get("oldest"))
+	list := []ConversationMessage{}
+	switch r.URL.Path {
+	case "/conversations.history":
+		for _, msg := range f.messages[query.Get("channel")] {
+			if (msg.ThreadTS == "" || msg.ThreadTS == msg.TS || msg.Subtype == "thread_broadcast") && tsAfter(msg.TS, query.Get("oldest")) {
+				list = append(list, msg)
+			}
+		}
+		sort.Slice(list, func(i, j int) bool { return tsAfter(list[i].TS, list[j].TS) })
+	case "/conversations.replies":
+		for _, msg := range f.messages[query.Get("channel")] {
+			// the parent message is returned anyway
+			if msg.TS == query.Get("ts") || (msg.ThreadTS == query.Get("ts") && tsAfter(msg.TS, query.Get("oldest"))) {
+				list = append(list, msg)
+			}
+		}
+	default:
+		writeJSON(w, map[string]interface{}{"ok": false, "error": "unknown_method"})
+		return
+	}
+
+	offset, _ := strconv.Atoi(query.Get("cursor"))
+	end, next := offset+2, ""
+	if end < len(list) {
+		next = strconv.Itoa(end)
+	} else {
+		end = len(list)
+	}
+	writeJSON(w, map[string]interface{}{
+		"ok":                true,
+		"messages":          list[offset:end],
+		"response_metadata": map[string]string{"next_cursor": next},
+	})
+}
+
+func newTestArchiver(t *testing.T, fake *fakeConversations, now time.Time) (*Archiver, *[]time.Duration) {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	a := NewArchiver(NewAPI(srv.URL, testBotToken), nil)
+	a.now = func() time.Time { return now }
+	sleeps := &[]time.Duration{}
+	a.sleep = func(ctx context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return nil
+	}
+	return a, sleeps
+}
+
+func messageTS(list []ConversationMessage) []string {
+	res := []string{}
+	for _, msg := range list {
+		res = append(res, msg.TS)
+	}
+	return res
+}
+
+func TestArchiver_FetchChanges(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeConversations{messages: map[string][]ConversationMessage{"C1": {
+		{TS: "1000000000.000100", ThreadTS: "1000000000.000100", ReplyCount: 1, Text: "old thread"},
+		{TS: "1000000001.000100", ThreadTS: "1000000000.000100", Text: "old reply"},
+		{TS: "1643709600.000100", Text: "hello"},
+		{TS: "1643709601.000100", ThreadTS: "1643709601.000100", ReplyCount: 2, Text: "thread"},
+		{TS: "1643709602.000100", ThreadTS: "1643709601.000100", Text: "reply 1"},
+		{TS: "1643709603.000100", ThreadTS: "1643709601.000100", Subtype: "thread_broadcast", Text: "reply 2"},
+	}}}
+	now := time.Unix(1643709700, 0)
+	a, sleeps := newTestArchiver(t, fake, now)
+
+	// the first archive
+	fake.limited = 1
+	changes, err := a.fetchChanges(ctx, "C1", &LoadStatusOfConversation{ConversationID: "C1"}, 0)
+	if err != nil {
+		t.Fatalf("fetchChanges(): %v", err)
+	}
+	wantTS := []string{
+		"1000000000.000100", "1643709600.000100", "1643709601.000100", "1643709603.000100",
+		"1000000001.000100", "1643709602.000100",
+	}
+	if got := messageTS(changes.Messages); !reflect.DeepEqual(got, wantTS) {
+		t.Errorf("messages %v, want %v", got, wantTS)
+	}
+	wantStatus := &LoadStatusOfConversation{ConversationID: "C1", LastMessageTS: "1643709603.000100", Threads: []ThreadCursor{
+		{TS: "1000000000.000100", LastReplyTS: "1000000001.000100"},
+		{TS: "1643709601.000100", LastReplyTS: "1643709603.000100"},
+	}}
+	if !reflect.DeepEqual(changes.Status, wantStatus) {
+		t.Errorf("status %+v, want %+v", changes.Status, wantStatus)
+	}
+	if !reflect.DeepEqual(*sleeps, []time.Duration{3 * time.Second}) {
+		t.Errorf("sleeps %v", *sleeps)
+	}
+
+	// the next archive: the new message and the new reply of the thread (the old thread is out of the window)
+	fake.messages["C1"] = append(fake.messages["C1"],
+		ConversationMessage{TS: "1643709604.000100", Text: "new"},
+		ConversationMessage{TS: "1643709605.000100", ThreadTS: "1643709601.000100", Text: "reply 3"},
+		ConversationMessage{TS: "1643709606.000100", ThreadTS: "1000000000.000100", Text: "late reply"},
+	)
+	fake.calls = nil
+	changes, err = a.fetchChanges(ctx, "C1", changes.Status, time.Hour)
+	if err != nil {
+		t.Fatalf("fetchChanges(): %v", err)
+	}
+	if got, want := messageTS(changes.Messages), []string{"1643709604.000100", "1643709605.000100"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("messages %v, want %v", got, want)
+	}
+	wantStatus = &LoadStatusOfConversation{ConversationID: "C1", LastMessageTS: "1643709604.000100", Threads: []ThreadCursor{
+		{TS: "1643709601.000100", LastReplyTS: "1643709605.000100"},
+	}}
+	if !reflect.DeepEqual(changes.Status, wantStatus) {
+		t.Errorf("status %+v, want %+v", changes.Status, wantStatus)
+	}
+	wantCalls := []string{
+		"/conversations.history  1643709603.000100",
+		"/conversations.replies 1643709601.000100 1643709603.000100",
+	}
+	if !reflect.DeepEqual(fake.calls, wantCalls) {
+		t.Errorf("calls %q, want %q", fake.calls, wantCalls)
+	}
+}
+
+func TestTSTime(t *testing.T) {
+	if got, want := tsTime("1643709600.000100"), time.Unix(1643709600, 100000).UTC(); !got.Equal(want) {
+		t.Errorf("tsTime() = %v, want %v", got, want)
+	}
+	if !tsAfter("1643709600.000200", "1643709600.000100") || tsAfter("999999999.000100", "1000000000.000100") || !tsAfter("1", "") {
+		t.Error("tsAfter() is invalid")
+	}
+}
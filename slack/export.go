@@ -0,0 +1,228 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// the formats of the export of the conversation
+const (
+	ExportJSON     = "json"
+	ExportMarkdown = "markdown"
+	ExportHTML     = "html"
+)
+
+// ExportFormats all formats of the export.
+var ExportFormats = []string{ExportJSON, ExportMarkdown, ExportHTML}
+
+// ExportedConversation the conversation with the messages grouped by the threads.
+type ExportedConversation struct {
+	ID       string             `json:"id"`
+	Name     string             `json:"name"`
+	Type     string             `json:"type"`
+	Topic    string             `json:"topic,omitempty"`
+	Purpose  string             `json:"purpose,omitempty"`
+	Messages []*ExportedMessage `json:"messages"`
+}
+
+type ExportedMessage struct {
+	TS        string             `json:"ts"`
+	PostedAt  time.Time          `json:"posted_at"`
+	UserID    string             `json:"user_id,omitempty"`
+	User      string             `json:"user"`
+	Subtype   string             `json:"subtype,omitempty"`
+	Text      string             `json:"text"`
+	Edited    bool               `json:"edited,omitempty"`
+	Reactions []Reaction         `json:"reactions,omitempty"`
+	Files     []File             `json:"files,omitempty"`
+	Replies   []*ExportedMessage `json:"replies,omitempty"`
+}
+
+// ExportConversation returns the archived conversation (by ID) for the export.
+func (s *Storage) ExportConversation(ctx context.Context, conversationID string) (*ExportedConversation, error) {
+	conv := s.GetConversation(ctx, conversationID)
+	if !conv.Exists() {
+		return nil, fmt.Errorf("not found archived conversation %q", conversationID)
+	}
+	return NewExportedConversation(conv, s.ListMessages(ctx, conversationID), s.AllUsers(ctx)), nil
+}
+
+// NewExportedConversation returns the conversation for the export
+// (the replies are nested in the parent messages, the replies without the archived parent are top-level).
+func NewExportedConversation(conv *ArchivedConversation, messages []*ArchivedMessage, users map[string]*ArchivedUser) *ExportedConversation {
+	res := &ExportedConversation{
+		ID:       conv.ID,
+		Name:     conversationName(conv, users),
+		Type:     conv.Type,
+		Topic:    conv.Topic,
+		Purpose:  conv.Purpose,
+		Messages: []*ExportedMessage{},
+	}
+
+	parents := map[string]*ExportedMessage{}
+	for _, msg := range messages {
+		if !msg.IsReply() {
+			parents[msg.TS] = exportedMessage(msg, users)
+		}
+	}
+	for _, msg := range messages {
+		if msg.IsReply() {
+			if parent := parents[msg.ThreadTS]; parent != nil {
+				parent.Replies = append(parent.Replies, exportedMessage(msg, users))
+				continue
+			}
+			res.Messages = append(res.Messages, exportedMessage(msg, users))
+			continue
+		}
+		res.Messages = append(res.Messages, parents[msg.TS])
+	}
+	return res
+}
+
+func exportedMessage(msg *ArchivedMessage, users map[string]*ArchivedUser) *ExportedMessage {
+	res := &ExportedMessage{
+		TS:        msg.TS,
+		UserID:    msg.UserID,
+		User:      users[msg.UserID].GetName(),
+		Subtype:   msg.Subtype,
+		Text:      msg.Text,
+		Edited:    msg.Edited,
+		Reactions: msg.Reactions,
+		Files:     msg.Files,
+	}
+	if msg.PostedAt != nil {
+		res.PostedAt = msg.PostedAt.AsTime().UTC()
+	}
+	switch {
+	case res.User != "":
+	case msg.Username != "":
+		res.User = msg.Username
+	case msg.UserID != "":
+		res.User = msg.UserID
+	default:
+		res.User = msg.BotID
+	}
+	return res
+}
+
+// returns #name of the channel or @name of the user of the direct messages
+func conversationName(conv *ArchivedConversation, users map[string]*ArchivedUser) string {
+	switch {
+	case conv.Type == ConversationIM && users[conv.UserID] != nil:
+		return "@" + users[conv.UserID].GetName()
+	case conv.Type == ConversationIM:
+		return "@" + conv.UserID
+	case conv.Name != "":
+		return "#" + conv.Name
+	}
+	return conv.ID
+}
+
+// Render writes the conversation in the format (see ExportFormats).
+func (c *ExportedConversation) Render(w io.Writer, format string) error {
+	switch format {
+	case ExportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	case ExportMarkdown:
+		return c.renderMarkdown(w)
+	case ExportHTML:
+		return htmlTemplate.Execute(w, c)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func (c *ExportedConversation) renderMarkdown(w io.Writer) error {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "# %s\n\n", c.Name)
+	if c.Topic != "" {
+		fmt.Fprintf(buf, "_%s_\n\n", c.Topic)
+	}
+	for _, msg := range c.Messages {
+		writeMarkdownMessage(buf, msg, "")
+		for _, reply := range msg.Replies {
+			writeMarkdownMessage(buf, reply, "> ")
+		}
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+func writeMarkdownMessage(buf *strings.Builder, msg *ExportedMessage, prefix string) {
+	lines := []string{fmt.Sprintf("**%s** · %s", msg.User, formatPostedAt(msg.PostedAt))}
+	if msg.Text != "" {
+		lines = append(lines, strings.Split(msg.Text, "\n")...)
+	}
+	for _, file := range msg.Files {
+		lines = append(lines, fmt.Sprintf("📎 [%s](%s)", fileName(file), file.Permalink))
+	}
+	if len(msg.Reactions) > 0 {
+		lines = append(lines, formatReactions(msg.Reactions))
+	}
+	for idx, line := range lines {
+		if idx == 1 {
+			buf.WriteString(strings.TrimSuffix(prefix, " ") + "\n")
+		}
+		buf.WriteString(prefix + line + "\n")
+	}
+	buf.WriteString("\n")
+}
+
+func formatPostedAt(in time.Time) string {
+	return in.Format("2006-01-02 15:04 MST")
+}
+
+func formatReactions(reactions []Reaction) string {
+	list := []string{}
+	for _, reaction := range reactions {
+		list = append(list, fmt.Sprintf(":%s: %d", reaction.Name, reaction.Count))
+	}
+	return strings.Join(list, " ")
+}
+
+func fileName(file File) string {
+	if file.Title != "" {
+		return file.Title
+	}
+	return file.Name
+}
+
+var htmlTemplate = template.Must(template.New("conversation").Funcs(template.FuncMap{
+	"postedAt":  formatPostedAt,
+	"reactions": formatReactions,
+	"fileName":  fileName,
+	"lines": func(in string) []string {
+		return strings.Split(in, "\n")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; }
+.message { margin: 1em 0; }
+.replies { margin-left: 2em; border-left: 3px solid #ddd; padding-left: 1em; }
+.meta { color: #666; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{with .Topic}}<p><i>{{.}}</i></p>{{end}}
+{{define "message"}}<div class="message" id="{{.TS}}">
+<div><b>{{.User}}</b> <span class="meta">{{postedAt .PostedAt}}{{if .Edited}} (edited){{end}}</span></div>
+<div>{{range $idx, $line := lines .Text}}{{if $idx}}<br>{{end}}{{$line}}{{end}}</div>
+{{range .Files}}<div>📎 <a href="{{.Permalink}}">{{fileName .}}</a></div>
+{{end}}{{with .Reactions}}<div class="meta">{{reactions .}}</div>
+{{end}}</div>
+{{end}}{{range .Messages}}{{template "message" .}}{{with .Replies}}<div class="replies">
+{{range .}}{{template "message" .}}{{end}}</div>
+{{end}}{{end}}</body>
+</html>
+`))
@@ -0,0 +1,93 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newTestExport() *ExportedConversation {
+	conv := &ArchivedConversation{Name: "general", Type: ConversationPublic, Topic: "news"}
+	conv.SetModelID("C1")
+	users := map[string]*ArchivedUser{"U1": {Name: "john", RealName: "John Doe"}}
+
+	messages := []*ArchivedMessage{}
+	for _, msg := range []ConversationMessage{
+		{TS: "1643709600.000100", ThreadTS: "1643709600.000100", ReplyCount: 1, User: "U1", Text: "hello <world>\nsecond line",
+			Reactions: []Reaction{{Name: "+1", Count: 2}}},
+		{TS: "1643709660.000100", ThreadTS: "1643709600.000100", User: "U2", Text: "reply",
+			Files: []File{{Name: "a.png", Title: "screen", Permalink: "https://files.slack.com/a.png"}}},
+		{TS: "1643709720.000100", ThreadTS: "1643709000.000100", BotID: "B1", Text: "reply without parent"},
+	} {
+		msg := ArchivedMessageFromAPI("C1", &msg)
+		msg.PostedAt = timestamppb.New(tsTime(msg.TS))
+		messages = append(messages, msg)
+	}
+	return NewExportedConversation(conv, messages, users)
+}
+
+func TestExportedConversation_Render(t *testing.T) {
+	export := newTestExport()
+
+	buf := &bytes.Buffer{}
+	if err := export.Render(buf, ExportMarkdown); err != nil {
+		t.Fatalf("Render(): %v", err)
+	}
+	want := `# #general
+
+_news_
+
+**John Doe** · 2022-02-01 10:00 UTC
+
+hello <world>
+second line
+:+1: 2
+
+> **U2** · 2022-02-01 10:01 UTC
+>
+> reply
+> 📎 [screen](https://files.slack.com/a.png)
+
+**B1** · 2022-02-01 10:02 UTC
+
+reply without parent
+
+`
+	if buf.String() != want {
+		t.Errorf("markdown:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := export.Render(buf, ExportJSON); err != nil {
+		t.Fatalf("Render(): %v", err)
+	}
+	decoded := &ExportedConversation{}
+	if err := json.Unmarshal(buf.Bytes(), decoded); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(decoded.Messages) != 2 || len(decoded.Messages[0].Replies) != 1 || decoded.Messages[0].Replies[0].Text != "reply" {
+		t.Errorf("json: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := export.Render(buf, ExportHTML); err != nil {
+		t.Fatalf("Render(): %v", err)
+	}
+	for _, part := range []string{
+		"<h1>#general</h1>",
+		"hello &lt;world&gt;<br>second line",
+		`<div class="replies">`,
+		`<a href="https://files.slack.com/a.png">screen</a>`,
+	} {
+		if !strings.Contains(buf.String(), part) {
+			t.Errorf("html does not contain %q:\n%s", part, buf.String())
+		}
+	}
+
+	if err := export.Render(buf, "pdf"); err == nil {
+		t.Error("Render() with unknown format without error")
+	}
+}
@@ -0,0 +1,124 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// the headers of the signature of the request
+const (
+	timestampHeader = "X-Slack-Request-Timestamp"
+	signatureHeader = "X-Slack-Signature"
+)
+
+// the max age of the request (protection against the replay)
+const maxRequestAge = 5 * time.Minute
+
+// the max time of the handling of the action (after the response to Slack)
+const actionTimeout = 5 * time.Minute
+
+// the payload of the block_actions interactions (only the used fields)
+type interactionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	ResponseURL string `json:"response_url"`
+}
+
+// NewInteractionHandler returns the handler of the interactivity of Slack app (the request URL in the settings of the app).
+// The request is verified by the signing secret of the app.
+// The handle is called with the kind of the action (notify.Action) and the value of the clicked button on behalf of the user,
+// Slack gets the response right away and the result of the action is sent to the user as the ephemeral message.
+func NewInteractionHandler(api *API, signingSecret string, handle func(ctx context.Context, action, value, user string) error) http.Handler {
+	l := zap.L().Named("slack_interactions")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		dat, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed read body", http.StatusBadRequest)
+			return
+		}
+		if !VerifySignature(signingSecret, r.Header, dat, time.Now()) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		form, err := url.ParseQuery(string(dat))
+		if err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		payload := &interactionPayload{}
+		if err := json.Unmarshal([]byte(form.Get("payload")), payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+		if payload.Type != "block_actions" {
+			l.Debug("Skip interaction", zap.String("type", payload.Type))
+			return
+		}
+		user := payload.User.Username
+		if user == "" {
+			user = payload.User.ID
+		}
+		for _, action := range payload.Actions {
+			go func(action, value string) {
+				ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+				defer cancel()
+
+				text := "Done: " + action
+				if err := handle(ctx, action, value, user); err != nil {
+					l.Warn("Failed handle action", zap.String("action", action), zap.String("value", value), zap.String("user", user), zap.Error(err))
+					text = "Failed " + action + ": " + err.Error()
+				}
+				if payload.ResponseURL == "" {
+					return
+				}
+				err := api.PostWebhook(ctx, payload.ResponseURL, &Message{Text: text, ResponseType: "ephemeral"})
+				if err != nil {
+					l.Warn("Failed respond to action", zap.String("action", action), zap.Error(err))
+				}
+			}(action.ActionID, action.Value)
+		}
+	})
+}
+
+// VerifySignature returns true if the request is signed by the signing secret of the app (the signature v0)
+// and the request is not older than 5 minutes.
+func VerifySignature(signingSecret string, header http.Header, body []byte, now time.Time) bool {
+	ts, err := strconv.ParseInt(header.Get(timestampHeader), 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte("v0:" + header.Get(timestampHeader) + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(header.Get(signatureHeader)))
+}
@@ -0,0 +1,87 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+func signedRequest(t *testing.T, body string, ts time.Time, secret string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(timestampHeader, fmt.Sprint(ts.Unix()))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("v0:%d:%s", ts.Unix(), body)))
+	req.Header.Set(signatureHeader, "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestInteractionHandler(t *testing.T) {
+	api, fake, baseURL := newTestAPI(t)
+
+	type call struct{ action, value, user string }
+	calls := make(chan call, 1)
+	handler := NewInteractionHandler(api, testSigningSecret, func(ctx context.Context, action, value, user string) error {
+		calls <- call{action, value, user}
+		return errors.New("already unlinked")
+	})
+
+	payload := fmt.Sprintf(`{"type":"block_actions","user":{"id":"U1","username":"john"},"response_url":"%s/hooks/ok",`+
+		`"actions":[{"action_id":"unlink","value":"src:a:dst:b"}]}`, baseURL)
+	body := url.Values{"payload": {payload}}.Encode()
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{"invalid signature", signedRequest(t, body, time.Now(), "other"), http.StatusUnauthorized},
+		{"old request", signedRequest(t, body, time.Now().Add(-10*time.Minute), testSigningSecret), http.StatusUnauthorized},
+		{"invalid payload", signedRequest(t, "payload=%7B", time.Now(), testSigningSecret), http.StatusBadRequest},
+		{"action", signedRequest(t, body, time.Now(), testSigningSecret), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+
+	select {
+	case got := <-calls:
+		if got != (call{"unlink", "src:a:dst:b", "john"}) {
+			t.Errorf("handled %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("action is not handled")
+	}
+
+	// the result is sent to the response URL
+	for wait := 0; wait < 100; wait++ {
+		fake.mu.Lock()
+		sent := fake.messages["/hooks/ok"]
+		fake.mu.Unlock()
+		if len(sent) > 0 {
+			if sent[0].Text != "Failed unlink: already unlinked" || sent[0].ResponseType != "ephemeral" {
+				t.Errorf("response %+v", sent[0])
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("response is not sent")
+}
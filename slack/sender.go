@@ -0,0 +1,217 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+)
+
+// MessengerName name of the messenger in the spec of sync (notify[].messenger)
+const MessengerName = "slack"
+
+// the limit of chat.postMessage (about one message per second to the channel)
+const chatInterval = time.Second
+
+// the max number of the blocks of the message
+const maxBlocks = 50
+
+// NewNotifier returns the notifier to the channels of Slack with the limits of Web API.
+func NewNotifier(api *API) *notify.Notifier {
+	return notify.NewNotifier(NewSender(api), notify.Options{ChatInterval: chatInterval})
+}
+
+// NewSender returns the sender of the notifications to the channels of Slack
+// (the chat is the ID of the channel or the URL of the incoming webhook).
+func NewSender(api *API) *Sender {
+	return &Sender{api: api}
+}
+
+type Sender struct {
+	api *API
+}
+
+var _ notify.Sender = (*Sender)(nil)
+
+func (s *Sender) Send(ctx context.Context, chatID string, events []*notify.Event) error {
+	msg := FormatEvents(events)
+	if isWebhookURL(chatID) {
+		return s.api.PostWebhook(ctx, chatID, msg)
+	}
+	_, err := s.api.PostMessage(ctx, chatID, msg)
+	return err
+}
+
+func isWebhookURL(chatID string) bool {
+	return strings.HasPrefix(chatID, "https://") || strings.HasPrefix(chatID, "http://")
+}
+
+// Block the block of Block Kit (only section, context and actions are used).
+type Block struct {
+	Type     string        `json:"type"`
+	Text     *Text         `json:"text,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+// Text the text object of Block Kit (mrkdwn or plain_text).
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Button the button element of the actions block (the action ID is the kind of notify.Action).
+type Button struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text"`
+	ActionID string `json:"action_id"`
+	Value    string `json:"value"`
+	Style    string `json:"style,omitempty"`
+}
+
+// the labels and the styles of the buttons by the kinds of the actions
+var actionButtons = map[string][2]string{
+	notify.ActionApprove: {"Approve", "primary"},
+	notify.ActionUnlink:  {"Unlink", "danger"},
+	notify.ActionResync:  {"Resync", ""},
+}
+
+// FormatEvents returns the message with the blocks grouped by the tasks (the digest if more than one event).
+// The actions of the events are added as the buttons after the events of the task.
+func FormatEvents(events []*notify.Event) *Message {
+	msg := &Message{}
+	if len(events) > 1 {
+		msg.Blocks = append(msg.Blocks, Block{Type: "section", Text: mrkdwn(fmt.Sprintf("*Digest: %d changes*", len(events)))})
+	}
+
+	lines := []string{}
+	for start := 0; start < len(events); {
+		end := start + 1
+		for end < len(events) && sameTask(events[start], events[end]) {
+			end++
+		}
+		group := events[start:end]
+
+		blocks := []Block{{Type: "section", Text: mrkdwn(formatTask(group[0]) + "\n" + formatEventLines(group))}}
+		if buttons := actionElements(group); len(buttons) > 0 {
+			blocks = append(blocks, Block{Type: "actions", Elements: buttons})
+		}
+		// keeps the place for the note about the rest of the events
+		if len(msg.Blocks)+len(blocks) > maxBlocks-1 {
+			msg.Blocks = append(msg.Blocks, Block{Type: "context", Elements: []interface{}{mrkdwn(fmt.Sprintf("... and %d more changes", len(events)-start))}})
+			break
+		}
+		msg.Blocks = append(msg.Blocks, blocks...)
+		for _, event := range group {
+			lines = append(lines, group[0].Title+": "+formatEvent(event, false))
+		}
+		start = end
+	}
+	msg.Text = strings.Join(lines, "\n")
+	return msg
+}
+
+// returns true if the events are about the same task
+func sameTask(a, b *notify.Event) bool {
+	return a.Rule == b.Rule && a.URL == b.URL && a.Title == b.Title
+}
+
+// returns the name of the task with the links to the task and the mirror task
+func formatTask(event *notify.Event) string {
+	title := "*" + escape(event.Title) + "*"
+	if event.URL != "" {
+		title = "*<" + event.URL + "|" + escape(event.Title) + ">*"
+	}
+	if event.MirrorURL != "" {
+		title += " · <" + event.MirrorURL + "|mirror task>"
+	}
+	if event.Rule != "" {
+		title += " _(" + escape(event.Rule) + ")_"
+	}
+	return title
+}
+
+func formatEventLines(events []*notify.Event) string {
+	if len(events) == 1 {
+		return formatEvent(events[0], true)
+	}
+	lines := []string{}
+	for _, event := range events {
+		lines = append(lines, "• "+formatEvent(event, true))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatEvent(event *notify.Event, markup bool) string {
+	from, to := valueOrNone(event.From), valueOrNone(event.To)
+	if markup {
+		from, to = escape(from), "*"+escape(to)+"*"
+	}
+	text := ""
+	switch event.Kind {
+	case notify.EventStatus:
+		text = fmt.Sprintf("status: %s → %s", from, to)
+	case notify.EventAssignees:
+		text = fmt.Sprintf("assignees: %s → %s", from, to)
+	case notify.EventDueDate:
+		text = fmt.Sprintf("due date: %s → %s", from, to)
+	case notify.EventEstimate:
+		text = fmt.Sprintf("estimate: %s → %s", from, to)
+	case notify.EventMirrorAdded:
+		text = "mirror task created"
+	case notify.EventUnlinked:
+		text = "mirror tasks unlinked"
+	case notify.EventApproval:
+		text = "mirror task awaits approval"
+	default:
+		text = event.Kind
+	}
+	if event.Details != "" {
+		details := event.Details
+		if markup {
+			details = escape(details)
+		}
+		text += ": " + details
+	}
+	return text
+}
+
+// returns the buttons of the actions of the events (the first action of each kind)
+func actionElements(events []*notify.Event) []interface{} {
+	res := []interface{}{}
+	added := map[string]bool{}
+	for _, event := range events {
+		for _, action := range event.Actions {
+			button, exists := actionButtons[action.Kind]
+			if !exists || added[action.Kind] {
+				continue
+			}
+			added[action.Kind] = true
+			res = append(res, &Button{
+				Type:     "button",
+				Text:     &Text{Type: "plain_text", Text: button[0]},
+				ActionID: action.Kind,
+				Value:    action.Value,
+				Style:    button[1],
+			})
+		}
+	}
+	return res
+}
+
+func mrkdwn(text string) *Text {
+	return &Text{Type: "mrkdwn", Text: text}
+}
+
+// escapes the control characters of mrkdwn
+func escape(in string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(in)
+}
+
+func valueOrNone(in string) string {
+	if in == "" {
+		return "none"
+	}
+	return in
+}
@@ -0,0 +1,145 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+)
+
+const testBotToken = "xoxb-test"
+
+// fakeSlack the in-memory Slack Web API (chat.postMessage) and the incoming webhook (/hooks/ok)
+type fakeSlack struct {
+	mu       sync.Mutex
+	messages map[string][]*Message
+	// the number of the next requests limited by 429
+	limited int
+}
+
+func (f *fakeSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.limited > 0 {
+		f.limited--
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	msg := &Message{}
+	json.NewDecoder(r.Body).Decode(msg)
+	switch r.URL.Path {
+	case "/hooks/ok":
+		f.messages[r.URL.Path] = append(f.messages[r.URL.Path], msg)
+		w.Write([]byte("ok"))
+	case "/api/chat.postMessage":
+		if r.Header.Get("Authorization") != "Bearer "+testBotToken {
+			writeJSON(w, map[string]interface{}{"ok": false, "error": "invalid_auth"})
+			return
+		}
+		if _, exists := f.messages[msg.Channel]; !exists {
+			writeJSON(w, map[string]interface{}{"ok": false, "error": "channel_not_found"})
+			return
+		}
+		f.messages[msg.Channel] = append(f.messages[msg.Channel], msg)
+		writeJSON(w, map[string]interface{}{"ok": true, "channel": msg.Channel, "ts": "1643709600.000100"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no_service"))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, in interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(in)
+}
+
+func newTestAPI(t *testing.T) (*API, *fakeSlack, string) {
+	fake := &fakeSlack{messages: map[string][]*Message{"C100": nil}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return NewAPI(srv.URL+"/api", testBotToken), fake, srv.URL
+}
+
+func TestFormatEvents(t *testing.T) {
+	events := []*notify.Event{
+		{Kind: notify.EventStatus, Rule: "qa", Title: "Fix <login>", URL: "https://app.clickup.com/t/a", MirrorURL: "https://app.clickup.com/t/b", From: "open", To: "done",
+			Actions: []notify.Action{{Kind: notify.ActionUnlink, Value: "src:a:dst:b"}, {Kind: notify.ActionResync, Value: "src:a:dst:b"}}},
+		{Kind: notify.EventDueDate, Rule: "qa", Title: "Fix <login>", URL: "https://app.clickup.com/t/a", MirrorURL: "https://app.clickup.com/t/b",
+			Actions: []notify.Action{{Kind: notify.ActionUnlink, Value: "src:a:dst:b"}}},
+		{Kind: notify.EventApproval, Rule: "qa", Title: "New", Actions: []notify.Action{{Kind: notify.ActionApprove, Value: "src:c:list:1"}}},
+	}
+	msg := FormatEvents(events)
+
+	wantText := "Fix <login>: status: open → done\nFix <login>: due date: none → none\nNew: mirror task awaits approval"
+	if msg.Text != wantText {
+		t.Errorf("text = %q, want %q", msg.Text, wantText)
+	}
+	wantBlocks := []Block{
+		{Type: "section", Text: mrkdwn("*Digest: 3 changes*")},
+		{Type: "section", Text: mrkdwn("*<https://app.clickup.com/t/a|Fix &lt;login&gt;>* · <https://app.clickup.com/t/b|mirror task> _(qa)_\n" +
+			"• status: open → *done*\n• due date: none → *none*")},
+		{Type: "actions", Elements: []interface{}{
+			&Button{Type: "button", Text: &Text{Type: "plain_text", Text: "Unlink"}, ActionID: notify.ActionUnlink, Value: "src:a:dst:b", Style: "danger"},
+			&Button{Type: "button", Text: &Text{Type: "plain_text", Text: "Resync"}, ActionID: notify.ActionResync, Value: "src:a:dst:b"},
+		}},
+		{Type: "section", Text: mrkdwn("*New* _(qa)_\nmirror task awaits approval")},
+		{Type: "actions", Elements: []interface{}{
+			&Button{Type: "button", Text: &Text{Type: "plain_text", Text: "Approve"}, ActionID: notify.ActionApprove, Value: "src:c:list:1", Style: "primary"},
+		}},
+	}
+	if !reflect.DeepEqual(msg.Blocks, wantBlocks) {
+		got, _ := json.Marshal(msg.Blocks)
+		t.Errorf("blocks = %s", got)
+	}
+
+	// the limit of the blocks
+	events = nil
+	for idx := 0; idx < 60; idx++ {
+		events = append(events, &notify.Event{Kind: notify.EventStatus, Title: "task", URL: "https://app.clickup.com/t/" + string(rune('a'+idx%26)) + string(rune('a'+idx/26))})
+	}
+	msg = FormatEvents(events)
+	if len(msg.Blocks) != maxBlocks || msg.Blocks[maxBlocks-1].Type != "context" {
+		t.Errorf("FormatEvents() returns %d blocks", len(msg.Blocks))
+	}
+}
+
+func TestSender(t *testing.T) {
+	ctx := context.Background()
+	api, fake, baseURL := newTestAPI(t)
+	sender := NewSender(api)
+	event := &notify.Event{Kind: notify.EventMirrorAdded, Rule: "qa", Title: "task", MirrorURL: "https://app.clickup.com/t/b"}
+
+	if err := sender.Send(ctx, "C100", []*notify.Event{event}); err != nil {
+		t.Fatalf("Send() to the channel: %v", err)
+	}
+	if err := sender.Send(ctx, baseURL+"/hooks/ok", []*notify.Event{event}); err != nil {
+		t.Fatalf("Send() to the webhook: %v", err)
+	}
+	if len(fake.messages["C100"]) != 1 || len(fake.messages["/hooks/ok"]) != 1 {
+		t.Errorf("sent messages %v", fake.messages)
+	}
+
+	apiErr := &Error{}
+	if err := sender.Send(ctx, "C200", []*notify.Event{event}); !errors.As(err, &apiErr) || apiErr.Code != "channel_not_found" {
+		t.Errorf("Send() to the unknown channel: %v", err)
+	}
+	if err := sender.Send(ctx, baseURL+"/hooks/unknown", []*notify.Event{event}); !errors.As(err, &apiErr) || apiErr.Code != "no_service" {
+		t.Errorf("Send() to the unknown webhook: %v", err)
+	}
+
+	fake.limited = 1
+	limited := &notify.RetryAfterError{}
+	if err := sender.Send(ctx, "C100", []*notify.Event{event}); !errors.As(err, &limited) || limited.After != time.Second {
+		t.Errorf("Send() limited: %v", err)
+	}
+}
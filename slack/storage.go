@@ -0,0 +1,247 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gebv/asap-tools/storage"
+	"go.uber.org/zap"
+)
+
+type StoreModel = storage.Model
+
+func NewStorage(s *storage.Storage) *Storage {
+	return &Storage{
+		Storage: s,
+		log:     zap.L().Named("slack_storage"),
+	}
+}
+
+// the archive of the conversations
+//
+// - method Get<ModelName> - returns model by ID
+// - method GetStateOf<ModelName> - returns status of loading of changes for the model
+// - method Upsert<ModelName> - create or overwrite model by ID
+type Storage struct {
+	*storage.Storage
+	log *zap.Logger
+}
+
+var (
+	ArchivedConversationModel                = (*ArchivedConversation)(nil)
+	_                             StoreModel = (*ArchivedConversation)(nil)
+	ArchivedMessageModel                     = (*ArchivedMessage)(nil)
+	_                             StoreModel = (*ArchivedMessage)(nil)
+	ArchivedUserModel                        = (*ArchivedUser)(nil)
+	_                             StoreModel = (*ArchivedUser)(nil)
+	LoadStatusOfConversationModel            = (*LoadStatusOfConversation)(nil)
+	_                             StoreModel = (*LoadStatusOfConversation)(nil)
+)
+
+// a new model instance and call GetModel
+func (s *Storage) GetConversation(ctx context.Context, modelID string) *ArchivedConversation {
+	model := storage.NewWithID(ArchivedConversationModel, modelID).(*ArchivedConversation)
+	s.GetModel(ctx, model)
+	return model
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertConversation(ctx context.Context, model *ArchivedConversation) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// AllConversations returns the archived conversations.
+func (s *Storage) AllConversations(ctx context.Context) []*ArchivedConversation {
+	iter := s.FirestoreClient().Collection(ArchivedConversationModel.CollectionName()).Documents(ctx)
+	res := s.Iterate(iter, ArchivedConversationModel)
+	list := []*ArchivedConversation{}
+	for idx := range res {
+		list = append(list, res[idx].(*ArchivedConversation))
+	}
+	return list
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertMessage(ctx context.Context, model *ArchivedMessage) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// ListMessages returns the messages and the replies of the conversation (the oldest first).
+func (s *Storage) ListMessages(ctx context.Context, conversationID string) []*ArchivedMessage {
+	factory := &ArchivedMessage{ConversationID: conversationID}
+
+	iter := s.FirestoreClient().Collection(factory.CollectionName()).
+		OrderBy("PostedAt", firestore.Asc).Documents(ctx)
+	res := s.Iterate(iter, factory)
+
+	list := []*ArchivedMessage{}
+	for idx := range res {
+		msg := res[idx].(*ArchivedMessage)
+		msg.ConversationID = conversationID
+		list = append(list, msg)
+	}
+	return list
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertUser(ctx context.Context, model *ArchivedUser) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// AllUsers returns the archived users by ID.
+func (s *Storage) AllUsers(ctx context.Context) map[string]*ArchivedUser {
+	iter := s.FirestoreClient().Collection(ArchivedUserModel.CollectionName()).Documents(ctx)
+	res := s.Iterate(iter, ArchivedUserModel)
+	users := map[string]*ArchivedUser{}
+	for idx := range res {
+		user := res[idx].(*ArchivedUser)
+		users[user.ID] = user
+	}
+	return users
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertLoadStatusOfConversation(ctx context.Context, model *LoadStatusOfConversation) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// a new model instance and call GetModel
+func (s *Storage) GetStateOfLoadConversation(ctx context.Context, conversationID string) *LoadStatusOfConversation {
+	model := &LoadStatusOfConversation{ConversationID: conversationID}
+	s.GetModel(ctx, model)
+	return model
+}
+
+// ArchivedConversation the channel, the private channel or the direct messages.
+type ArchivedConversation struct {
+	storage.StdModel
+	Name string
+	// see ConversationTypes
+	Type string
+	// the other user of the direct messages
+	UserID    string
+	Topic     string
+	Purpose   string
+	Archived  bool
+	CreatedAt *storage.Timestamp
+}
+
+func (*ArchivedConversation) NewModel() StoreModel {
+	return &ArchivedConversation{}
+}
+
+func (*ArchivedConversation) CollectionName() string {
+	return "slack_conversations"
+}
+
+// ArchivedMessage the message or the reply of the thread.
+// Stored in the subcollection of the conversation.
+type ArchivedMessage struct {
+	storage.ModelCustomID
+	ConversationID string `firestore:"-"`
+	TS             string `firestore:"-"`
+
+	// the parent message of the thread (empty for the messages without the replies)
+	ThreadTS   string
+	ReplyCount int
+	Subtype    string
+	UserID     string
+	BotID      string
+	Username   string
+	Text       string
+	Reactions  []Reaction
+	Files      []File
+	Edited     bool
+	PostedAt   *storage.Timestamp
+}
+
+func (*ArchivedMessage) NewModel() StoreModel {
+	return &ArchivedMessage{}
+}
+
+func (m *ArchivedMessage) CollectionName() string {
+	return ArchivedConversationModel.CollectionName() + "/" + m.ConversationID + "/messages"
+}
+
+func (m *ArchivedMessage) SetModelID(in string) {
+	m.TS = in
+}
+
+func (m *ArchivedMessage) ModelID() string {
+	return m.TS
+}
+
+// IsReply returns true if the message is the reply of the thread.
+func (m *ArchivedMessage) IsReply() bool {
+	return m.ThreadTS != "" && m.ThreadTS != m.TS
+}
+
+// ArchivedUser the user of the workspace (for the names in the exports).
+type ArchivedUser struct {
+	storage.StdModel
+	Name        string
+	RealName    string
+	DisplayName string
+	IsBot       bool
+	Deleted     bool
+}
+
+func (*ArchivedUser) NewModel() StoreModel {
+	return &ArchivedUser{}
+}
+
+func (*ArchivedUser) CollectionName() string {
+	return "slack_users"
+}
+
+// GetName returns the display name or the real name or the name of the user.
+func (u *ArchivedUser) GetName() string {
+	switch {
+	case u == nil:
+		return ""
+	case u.DisplayName != "":
+		return u.DisplayName
+	case u.RealName != "":
+		return u.RealName
+	}
+	return u.Name
+}
+
+// LoadStatusOfConversation the cursor of the archive of the conversation.
+type LoadStatusOfConversation struct {
+	storage.ModelCustomID
+	ConversationID string `firestore:"-"`
+	// the last archived message of the history
+	LastMessageTS string
+	// the threads checked for the new replies
+	Threads []ThreadCursor
+}
+
+// ThreadCursor the last archived reply of the thread.
+type ThreadCursor struct {
+	TS          string
+	LastReplyTS string
+}
+
+func (m *LoadStatusOfConversation) NewModel() StoreModel {
+	return &LoadStatusOfConversation{}
+}
+
+func (m *LoadStatusOfConversation) SetModelID(in string) {
+	const prefix = "conversation:"
+	if strings.HasPrefix(in, prefix) {
+		m.ConversationID = in[len(prefix):]
+	} else {
+		panic(fmt.Sprintf("Invalid format ID %q for %T", in, m))
+	}
+}
+
+func (m *LoadStatusOfConversation) ModelID() string {
+	return fmt.Sprintf("conversation:%s", m.ConversationID)
+}
+
+func (LoadStatusOfConversation) CollectionName() string {
+	return "slack_load_status_of_conversations"
+}
@@ -0,0 +1,296 @@
+package spotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// the URLs of Spotify Web API and Spotify Accounts service
+const (
+	DefaultBaseURL     = "https://api.spotify.com/v1"
+	DefaultAccountsURL = "https://accounts.spotify.com"
+)
+
+// the max size of the page of the saved tracks (and the max number of the saved tracks by one request)
+const pageSize = 50
+
+type httpClientLogger struct {
+	*zap.Logger
+}
+
+func (l *httpClientLogger) Printf(msg string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(msg, args...))
+}
+
+// Credentials the app of Spotify and the refresh token of the user
+// (the scopes user-library-read and user-library-modify).
+type Credentials struct {
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+}
+
+// NewAPI returns the client of Spotify Web API (the URLs are DefaultBaseURL and DefaultAccountsURL by default).
+// The access token is refreshed by the refresh token of the user.
+// The limited requests (429) are retried after Retry-After.
+func NewAPI(baseURL, accountsURL string, creds Credentials) *API {
+	l := zap.L().Named("spotify_api")
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = &httpClientLogger{l.Named("http")}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if accountsURL == "" {
+		accountsURL = DefaultAccountsURL
+	}
+
+	return &API{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		accountsURL: strings.TrimSuffix(accountsURL, "/"),
+		creds:       creds,
+		client:      httpClient.StandardClient(),
+		log:         l,
+	}
+}
+
+type API struct {
+	baseURL     string
+	accountsURL string
+	creds       Credentials
+	client      *http.Client
+	log         *zap.Logger
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+type Artist struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Album struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Track struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	URI     string   `json:"uri"`
+	Artists []Artist `json:"artists"`
+	Album   Album    `json:"album"`
+	// the ISRC code of the recording (the same recording on the different albums)
+	ExternalIDs struct {
+		ISRC string `json:"isrc"`
+	} `json:"external_ids"`
+}
+
+// GetArtist returns the name of the first (main) artist of the track.
+func (t *Track) GetArtist() string {
+	if len(t.Artists) == 0 {
+		return ""
+	}
+	return t.Artists[0].Name
+}
+
+// SavedTrack the track of Liked Songs of the user.
+type SavedTrack struct {
+	AddedAt time.Time `json:"added_at"`
+	Track   Track     `json:"track"`
+}
+
+// Error the unsuccessful response of Spotify Web API.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("spotify: %d %s", e.Status, e.Message)
+}
+
+// SavedTracks returns the tracks saved to Liked Songs after the time (the oldest first, all tracks for the zero time).
+func (a *API) SavedTracks(ctx context.Context, after time.Time) ([]SavedTrack, error) {
+	res := []SavedTrack{}
+	for offset := 0; ; offset += pageSize {
+		page := &struct {
+			Items []SavedTrack `json:"items"`
+			Next  string       `json:"next"`
+		}{}
+		query := url.Values{"limit": {strconv.Itoa(pageSize)}, "offset": {strconv.Itoa(offset)}}
+		if err := a.doRequest(ctx, http.MethodGet, "/me/tracks", query, nil, page); err != nil {
+			return nil, err
+		}
+
+		// the newest first
+		for _, item := range page.Items {
+			if !item.AddedAt.After(after) {
+				return reverse(res), nil
+			}
+			res = append(res, item)
+		}
+		if page.Next == "" {
+			return reverse(res), nil
+		}
+	}
+}
+
+// SaveTracks saves the tracks (by ID) to Liked Songs of the user.
+func (a *API) SaveTracks(ctx context.Context, ids []string) error {
+	for len(ids) > 0 {
+		chunk := ids
+		if len(chunk) > pageSize {
+			chunk = chunk[:pageSize]
+		}
+		ids = ids[len(chunk):]
+		if err := a.doRequest(ctx, http.MethodPut, "/me/tracks", nil, map[string]interface{}{"ids": chunk}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchByISRC returns the tracks of the recording by ISRC.
+func (a *API) SearchByISRC(ctx context.Context, isrc string) ([]Track, error) {
+	return a.searchTracks(ctx, "isrc:"+isrc)
+}
+
+// SearchByName returns the tracks found by the artist and the title.
+func (a *API) SearchByName(ctx context.Context, artist, title string) ([]Track, error) {
+	return a.searchTracks(ctx, fmt.Sprintf("track:%q artist:%q", title, artist))
+}
+
+func (a *API) searchTracks(ctx context.Context, q string) ([]Track, error) {
+	res := &struct {
+		Tracks struct {
+			Items []Track `json:"items"`
+		} `json:"tracks"`
+	}{}
+	query := url.Values{"q": {q}, "type": {"track"}, "limit": {"10"}}
+	err := a.doRequest(ctx, http.MethodGet, "/search", query, nil, res)
+	return res.Tracks.Items, err
+}
+
+// returns the access token (refreshed if expired)
+func (a *API) token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.accessToken != "" && time.Now().Before(a.expiresAt) {
+		return a.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {a.creds.RefreshToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.accountsURL+"/api/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(a.creds.ClientID, a.creds.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	resp := &struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(resp); err != nil || resp.AccessToken == "" {
+		a.log.Debug("Unsuccessful refresh of the access token", zap.String("status", res.Status), zap.String("error", resp.Error))
+		return "", &Error{Status: res.StatusCode, Message: "failed refresh of the access token: " + resp.ErrorDescription}
+	}
+
+	a.accessToken = resp.AccessToken
+	// the token is refreshed a minute before the expiration
+	a.expiresAt = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - time.Minute)
+	return a.accessToken, nil
+}
+
+func (a *API) doRequest(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	token, err := a.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		dat, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(dat)
+	}
+
+	reqURL := a.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	dat, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	a.log.Debug("API request", zap.String("status", res.Status), zap.String("method", method), zap.String("path", path))
+
+	if res.StatusCode >= 300 {
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("path", path), zap.String("body_raw", string(dat)))
+		resp := &struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}{}
+		json.Unmarshal(dat, resp)
+		return &Error{Status: res.StatusCode, Message: resp.Error.Message}
+	}
+
+	if out == nil || len(dat) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(dat, out); err != nil {
+		return fmt.Errorf("spotify: failed decode response of %s: %w", path, err)
+	}
+	return nil
+}
+
+func reverse(in []SavedTrack) []SavedTrack {
+	for i, j := 0, len(in)-1; i < j; i, j = i+1, j-1 {
+		in[i], in[j] = in[j], in[i]
+	}
+	return in
+}
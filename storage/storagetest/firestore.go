@@ -0,0 +1,433 @@
+// Package storagetest provides the in-memory Firestore for the tests of the storage models.
+//
+// Only the requests used by the storage are supported: get, set, create and delete of the documents,
+// the queries by the filters of the fields with the sort and the limit (without the cursors and the transactions).
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/api/option"
+	pb "google.golang.org/genproto/googleapis/firestore/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const projectID = "test-project"
+
+// NewClient returns the client of the new empty in-memory Firestore (stopped after the test).
+func NewClient(t testing.TB) *firestore.Client {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	pb.RegisterFirestoreServer(srv, &server{docs: map[string]*pb.Document{}})
+	go srv.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial the fake firestore: %v", err)
+	}
+	client, err := firestore.NewClient(context.Background(), projectID, option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("failed to create the client of the fake firestore: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		srv.Stop()
+	})
+	return client
+}
+
+type server struct {
+	pb.UnimplementedFirestoreServer
+
+	mu   sync.Mutex
+	docs map[string]*pb.Document
+}
+
+func (s *server) BatchGetDocuments(req *pb.BatchGetDocumentsRequest, stream pb.Firestore_BatchGetDocumentsServer) error {
+	s.mu.Lock()
+	res := []*pb.BatchGetDocumentsResponse{}
+	for _, name := range req.Documents {
+		item := &pb.BatchGetDocumentsResponse{ReadTime: timestamppb.Now()}
+		if doc, exists := s.docs[name]; exists {
+			item.Result = &pb.BatchGetDocumentsResponse_Found{Found: proto.Clone(doc).(*pb.Document)}
+		} else {
+			item.Result = &pb.BatchGetDocumentsResponse_Missing{Missing: name}
+		}
+		res = append(res, item)
+	}
+	s.mu.Unlock()
+
+	for _, item := range res {
+		if err := stream.Send(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) Commit(ctx context.Context, req *pb.CommitRequest) (*pb.CommitResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the preconditions are checked before any changes (the writes are atomic)
+	for _, write := range req.Writes {
+		name := write.GetDelete()
+		if update := write.GetUpdate(); update != nil {
+			name = update.Name
+		}
+		_, exists := s.docs[name]
+		if cond, ok := write.GetCurrentDocument().GetConditionType().(*pb.Precondition_Exists); ok {
+			if cond.Exists && !exists {
+				return nil, status.Errorf(codes.NotFound, "document %q not found", name)
+			}
+			if !cond.Exists && exists {
+				return nil, status.Errorf(codes.AlreadyExists, "document %q already exists", name)
+			}
+		}
+	}
+
+	now := timestamppb.Now()
+	res := &pb.CommitResponse{CommitTime: now}
+	for _, write := range req.Writes {
+		switch op := write.Operation.(type) {
+		case *pb.Write_Update:
+			doc := proto.Clone(op.Update).(*pb.Document)
+			doc.CreateTime, doc.UpdateTime = now, now
+			if current, exists := s.docs[doc.Name]; exists {
+				doc.CreateTime = current.CreateTime
+				if mask := write.GetUpdateMask(); mask != nil {
+					doc.Fields = mergeFields(current.Fields, doc.Fields, mask.FieldPaths)
+				}
+			}
+			s.docs[doc.Name] = doc
+		case *pb.Write_Delete:
+			delete(s.docs, op.Delete)
+		default:
+			return nil, status.Errorf(codes.Unimplemented, "write %T not supported", op)
+		}
+		res.WriteResults = append(res.WriteResults, &pb.WriteResult{UpdateTime: now})
+	}
+	return res, nil
+}
+
+func (s *server) RunQuery(req *pb.RunQueryRequest, stream pb.Firestore_RunQueryServer) error {
+	query := req.GetStructuredQuery()
+	if query == nil || len(query.From) != 1 || query.From[0].AllDescendants {
+		return status.Errorf(codes.Unimplemented, "only the queries of the collection are supported")
+	}
+	if query.StartAt != nil || query.EndAt != nil {
+		return status.Errorf(codes.Unimplemented, "the cursors are not supported")
+	}
+	prefix := req.Parent + "/" + query.From[0].CollectionId + "/"
+
+	s.mu.Lock()
+	list := []*pb.Document{}
+	for name, doc := range s.docs {
+		if !strings.HasPrefix(name, prefix) || strings.Contains(strings.TrimPrefix(name, prefix), "/") {
+			continue
+		}
+		matched, err := matchFilter(doc, query.Where)
+		if err != nil {
+			s.mu.Unlock()
+			return err
+		}
+		if matched {
+			list = append(list, proto.Clone(doc).(*pb.Document))
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		for _, order := range query.OrderBy {
+			a, _ := fieldValue(list[i], order.Field.FieldPath)
+			b, _ := fieldValue(list[j], order.Field.FieldPath)
+			if cmp := compareValues(a, b); cmp != 0 {
+				if order.Direction == pb.StructuredQuery_DESCENDING {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+		}
+		return list[i].Name < list[j].Name
+	})
+	if offset := int(query.Offset); offset > 0 {
+		if offset > len(list) {
+			offset = len(list)
+		}
+		list = list[offset:]
+	}
+	if query.Limit != nil && int(query.Limit.Value) < len(list) {
+		list = list[:query.Limit.Value]
+	}
+
+	if len(list) == 0 {
+		return stream.Send(&pb.RunQueryResponse{ReadTime: timestamppb.Now()})
+	}
+	for _, doc := range list {
+		if err := stream.Send(&pb.RunQueryResponse{Document: doc, ReadTime: timestamppb.Now()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchFilter(doc *pb.Document, filter *pb.StructuredQuery_Filter) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	switch f := filter.FilterType.(type) {
+	case *pb.StructuredQuery_Filter_CompositeFilter:
+		for _, item := range f.CompositeFilter.Filters {
+			matched, err := matchFilter(doc, item)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	case *pb.StructuredQuery_Filter_FieldFilter:
+		value, exists := fieldValue(doc, f.FieldFilter.Field.FieldPath)
+		if !exists {
+			return false, nil
+		}
+		return matchOperator(f.FieldFilter.Op, value, f.FieldFilter.Value)
+	case *pb.StructuredQuery_Filter_UnaryFilter:
+		value, exists := fieldValue(doc, f.UnaryFilter.GetField().FieldPath)
+		isNull := exists && value.GetValueType() != nil && isNullValue(value)
+		switch f.UnaryFilter.Op {
+		case pb.StructuredQuery_UnaryFilter_IS_NULL:
+			return isNull, nil
+		case pb.StructuredQuery_UnaryFilter_IS_NOT_NULL:
+			return exists && !isNull, nil
+		}
+		return false, status.Errorf(codes.Unimplemented, "unary filter %s not supported", f.UnaryFilter.Op)
+	}
+	return false, status.Errorf(codes.Unimplemented, "filter %T not supported", filter.FilterType)
+}
+
+func matchOperator(op pb.StructuredQuery_FieldFilter_Operator, value, arg *pb.Value) (bool, error) {
+	switch op {
+	case pb.StructuredQuery_FieldFilter_EQUAL:
+		return compareValues(value, arg) == 0, nil
+	case pb.StructuredQuery_FieldFilter_NOT_EQUAL:
+		return compareValues(value, arg) != 0, nil
+	case pb.StructuredQuery_FieldFilter_LESS_THAN:
+		return sameType(value, arg) && compareValues(value, arg) < 0, nil
+	case pb.StructuredQuery_FieldFilter_LESS_THAN_OR_EQUAL:
+		return sameType(value, arg) && compareValues(value, arg) <= 0, nil
+	case pb.StructuredQuery_FieldFilter_GREATER_THAN:
+		return sameType(value, arg) && compareValues(value, arg) > 0, nil
+	case pb.StructuredQuery_FieldFilter_GREATER_THAN_OR_EQUAL:
+		return sameType(value, arg) && compareValues(value, arg) >= 0, nil
+	case pb.StructuredQuery_FieldFilter_ARRAY_CONTAINS:
+		for _, item := range value.GetArrayValue().GetValues() {
+			if compareValues(item, arg) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case pb.StructuredQuery_FieldFilter_IN:
+		for _, item := range arg.GetArrayValue().GetValues() {
+			if compareValues(value, item) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, status.Errorf(codes.Unimplemented, "operator %s not supported", op)
+}
+
+// returns the value of the field by the path (eg Ref.ID or `the-field`.ID)
+func fieldValue(doc *pb.Document, path string) (*pb.Value, bool) {
+	fields := doc.Fields
+	names := splitFieldPath(path)
+	for idx, name := range names {
+		value, exists := fields[name]
+		if !exists {
+			return nil, false
+		}
+		if idx == len(names)-1 {
+			return value, true
+		}
+		fields = value.GetMapValue().GetFields()
+	}
+	return nil, false
+}
+
+func splitFieldPath(path string) []string {
+	res := []string{}
+	name := &strings.Builder{}
+	quoted := false
+	for idx := 0; idx < len(path); idx++ {
+		switch c := path[idx]; {
+		case c == '`':
+			quoted = !quoted
+		case c == '\\' && quoted && idx+1 < len(path):
+			idx++
+			name.WriteByte(path[idx])
+		case c == '.' && !quoted:
+			res = append(res, name.String())
+			name.Reset()
+		default:
+			name.WriteByte(c)
+		}
+	}
+	return append(res, name.String())
+}
+
+// returns the fields with the fields of the update by the paths of the mask (the fields missed in the update are removed)
+func mergeFields(current, update map[string]*pb.Value, paths []string) map[string]*pb.Value {
+	res := map[string]*pb.Value{}
+	for name, value := range current {
+		res[name] = proto.Clone(value).(*pb.Value)
+	}
+	for _, path := range paths {
+		names := splitFieldPath(path)
+		src, dst := update, res
+		for idx, name := range names {
+			if idx == len(names)-1 {
+				if value, exists := src[name]; exists {
+					dst[name] = value
+				} else {
+					delete(dst, name)
+				}
+				break
+			}
+			next := dst[name].GetMapValue()
+			if next == nil {
+				next = &pb.MapValue{Fields: map[string]*pb.Value{}}
+				dst[name] = &pb.Value{ValueType: &pb.Value_MapValue{MapValue: next}}
+			}
+			src, dst = src[name].GetMapValue().GetFields(), next.Fields
+		}
+	}
+	return res
+}
+
+func isNullValue(v *pb.Value) bool {
+	_, ok := v.ValueType.(*pb.Value_NullValue)
+	return ok
+}
+
+func sameType(a, b *pb.Value) bool {
+	return typeOrder(a) == typeOrder(b)
+}
+
+// the order of the types of the values in Firestore
+func typeOrder(v *pb.Value) int {
+	switch v.GetValueType().(type) {
+	case *pb.Value_NullValue:
+		return 0
+	case *pb.Value_BooleanValue:
+		return 1
+	case *pb.Value_IntegerValue, *pb.Value_DoubleValue:
+		return 2
+	case *pb.Value_TimestampValue:
+		return 3
+	case *pb.Value_StringValue:
+		return 4
+	case *pb.Value_BytesValue:
+		return 5
+	case *pb.Value_ReferenceValue:
+		return 6
+	case *pb.Value_GeoPointValue:
+		return 7
+	case *pb.Value_ArrayValue:
+		return 8
+	case *pb.Value_MapValue:
+		return 9
+	}
+	return 0
+}
+
+func compareValues(a, b *pb.Value) int {
+	if a == nil || b == nil {
+		return compareInts(typeOrder(a), typeOrder(b))
+	}
+	if cmp := compareInts(typeOrder(a), typeOrder(b)); cmp != 0 {
+		return cmp
+	}
+	switch av := a.ValueType.(type) {
+	case *pb.Value_BooleanValue:
+		return compareBools(av.BooleanValue, b.GetBooleanValue())
+	case *pb.Value_IntegerValue, *pb.Value_DoubleValue:
+		return compareFloats(numberOf(a), numberOf(b))
+	case *pb.Value_TimestampValue:
+		return compareTimes(av.TimestampValue.AsTime(), b.GetTimestampValue().AsTime())
+	case *pb.Value_StringValue:
+		return strings.Compare(av.StringValue, b.GetStringValue())
+	case *pb.Value_BytesValue:
+		return strings.Compare(string(av.BytesValue), string(b.GetBytesValue()))
+	case *pb.Value_ReferenceValue:
+		return strings.Compare(av.ReferenceValue, b.GetReferenceValue())
+	case *pb.Value_ArrayValue:
+		x, y := av.ArrayValue.GetValues(), b.GetArrayValue().GetValues()
+		for idx := 0; idx < len(x) && idx < len(y); idx++ {
+			if cmp := compareValues(x[idx], y[idx]); cmp != 0 {
+				return cmp
+			}
+		}
+		return compareInts(len(x), len(y))
+	}
+	if proto.Equal(a, b) {
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func numberOf(v *pb.Value) float64 {
+	if i, ok := v.ValueType.(*pb.Value_IntegerValue); ok {
+		return float64(i.IntegerValue)
+	}
+	return v.GetDoubleValue()
+}
+
+func compareInts(a, b int) int {
+	return compareFloats(float64(a), float64(b))
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	}
+	return 1
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,194 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// DefaultBaseURL the URL of Telegram Bot API
+const DefaultBaseURL = "https://api.telegram.org"
+
+type httpClientLogger struct {
+	*zap.Logger
+}
+
+func (l *httpClientLogger) Printf(msg string, args ...interface{}) {
+	l.Debug(fmt.Sprintf(msg, args...))
+}
+
+// NewAPI returns the client of Telegram Bot API (baseURL is DefaultBaseURL by default).
+func NewAPI(baseURL, botToken string) *API {
+	l := zap.L().Named("telegram_api")
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = &httpClientLogger{l.Named("http")}
+	// the limited requests (429) are retried by the notifier after retry_after
+	httpClient.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			return false, nil
+		}
+		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &API{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		botToken: botToken,
+		client:   httpClient.StandardClient(),
+		log:      l,
+	}
+}
+
+type API struct {
+	baseURL  string
+	botToken string
+	client   *http.Client
+	log      *zap.Logger
+}
+
+type Chat struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title,omitempty"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+}
+
+type User struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot,omitempty"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+type Message struct {
+	MessageID       int64    `json:"message_id"`
+	From            *User    `json:"from,omitempty"`
+	SenderChat      *Chat    `json:"sender_chat,omitempty"`
+	Chat            Chat     `json:"chat"`
+	Date            int64    `json:"date"`
+	EditDate        int64    `json:"edit_date,omitempty"`
+	Text            string   `json:"text,omitempty"`
+	Caption         string   `json:"caption,omitempty"`
+	ReplyToMessage  *Message `json:"reply_to_message,omitempty"`
+	ForwardFrom     *User    `json:"forward_from,omitempty"`
+	ForwardFromChat *Chat    `json:"forward_from_chat,omitempty"`
+	// the sizes of the photo (the largest last)
+	Photo     []File `json:"photo,omitempty"`
+	Document  *File  `json:"document,omitempty"`
+	Audio     *File  `json:"audio,omitempty"`
+	Video     *File  `json:"video,omitempty"`
+	Voice     *File  `json:"voice,omitempty"`
+	VideoNote *File  `json:"video_note,omitempty"`
+	Animation *File  `json:"animation,omitempty"`
+	Sticker   *File  `json:"sticker,omitempty"`
+}
+
+// File the common fields of the media of the message (the photo, the document, the voice, etc).
+type File struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	Duration     int    `json:"duration,omitempty"`
+	Emoji        string `json:"emoji,omitempty"`
+}
+
+// Error the unsuccessful response of Bot API.
+type Error struct {
+	Code        int
+	Description string
+	// the wait before the next request (for 429 Too Many Requests)
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("telegram: %d %s", e.Code, e.Description)
+}
+
+// SendMessage sends the message in the HTML format to the chat (ID or @username of the channel).
+func (a *API) SendMessage(ctx context.Context, chatID, text string) (*Message, error) {
+	req := map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	res := &Message{}
+	err := a.doRequest(ctx, "sendMessage", req, res)
+	return res, err
+}
+
+func (a *API) doRequest(ctx context.Context, method string, in, out interface{}) error {
+	dat, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/bot"+a.botToken+"/"+method, bytes.NewReader(dat))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		// the error contains the URL with the token
+		return fmt.Errorf("telegram: failed request %s", method)
+	}
+	defer res.Body.Close()
+
+	dat, err = ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	a.log.Debug("API request", zap.String("status", res.Status), zap.String("method", method))
+
+	resp := &struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}{}
+	if err := json.Unmarshal(dat, resp); err != nil {
+		return fmt.Errorf("telegram: %s: got status %d", method, res.StatusCode)
+	}
+	if !resp.OK {
+		a.log.Debug("Unsuccessful response", zap.String("status", res.Status), zap.String("method", method), zap.String("body_raw", string(dat)))
+		apiErr := &Error{Code: resp.ErrorCode, Description: resp.Description}
+		if resp.Parameters.RetryAfter > 0 {
+			apiErr.RetryAfter = time.Duration(resp.Parameters.RetryAfter) * time.Second
+			return &notify.RetryAfterError{After: apiErr.RetryAfter, Err: apiErr}
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, out); err != nil {
+		return fmt.Errorf("telegram: failed decode response of %s: %w", method, err)
+	}
+	return nil
+}
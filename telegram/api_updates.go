@@ -0,0 +1,50 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+)
+
+// the max number of the updates of the page
+const updatesLimit = 100
+
+// Update the incoming update of the bot (only the messages and the posts of the channels are requested).
+type Update struct {
+	UpdateID          int64    `json:"update_id"`
+	Message           *Message `json:"message,omitempty"`
+	EditedMessage     *Message `json:"edited_message,omitempty"`
+	ChannelPost       *Message `json:"channel_post,omitempty"`
+	EditedChannelPost *Message `json:"edited_channel_post,omitempty"`
+}
+
+// GetMessage returns the message or the post of the update (nil for the other updates).
+func (u *Update) GetMessage() *Message {
+	switch {
+	case u.Message != nil:
+		return u.Message
+	case u.EditedMessage != nil:
+		return u.EditedMessage
+	case u.ChannelPost != nil:
+		return u.ChannelPost
+	}
+	return u.EditedChannelPost
+}
+
+// GetUpdates returns the updates after offset (the updates before offset are confirmed and removed by Telegram).
+// Not available while the webhook of the bot is set.
+func (a *API) GetUpdates(ctx context.Context, offset int64) ([]Update, error) {
+	req := map[string]interface{}{
+		"offset":          offset,
+		"limit":           updatesLimit,
+		"timeout":         0,
+		"allowed_updates": []string{"message", "edited_message", "channel_post", "edited_channel_post"},
+	}
+	res := []Update{}
+	err := a.doRequest(ctx, "getUpdates", req, &res)
+	return res, err
+}
+
+// BotID returns ID of the bot from the token.
+func (a *API) BotID() string {
+	return strings.SplitN(a.botToken, ":", 2)[0]
+}
@@ -0,0 +1,264 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+	"go.uber.org/zap"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// the max number of the retries of the limited request
+const maxRateLimitRetries = 3
+
+// NewBackup returns the backup of the chats of Telegram into the storage.
+func NewBackup(api *API, store *Storage) *Backup {
+	return &Backup{
+		api:   api,
+		store: store,
+		log:   zap.L().Named("telegram_backup"),
+		sleep: func(ctx context.Context, d time.Duration) error {
+			select {
+			case <-time.After(d):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+type Backup struct {
+	api   *API
+	store *Storage
+	log   *zap.Logger
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// BackupUpdates saves the messages of the new updates of the bot and returns the number of the saved messages
+// (the next call continues from the last saved update).
+//
+// Bot API keeps the updates only for 24 hours, the bot receives the messages of the private chats with the bot
+// and the messages of the groups and the channels with the bot (see the privacy mode of the bot).
+func (b *Backup) BackupUpdates(ctx context.Context) (int, error) {
+	status := b.store.GetStateOfLoadBot(ctx, b.api.BotID())
+	total := 0
+	for {
+		changes, err := b.fetchUpdates(ctx, status.LastUpdateID)
+		if err != nil {
+			return total, err
+		}
+		if len(changes.Messages) == 0 && changes.LastUpdateID == status.LastUpdateID {
+			return total, nil
+		}
+
+		if err := b.saveChanges(ctx, changes); err != nil {
+			// the cursor is not moved - the updates are requested again by the next call
+			return total, err
+		}
+		total += len(changes.Messages)
+
+		// the updates before the cursor are confirmed by the next request
+		status.LastUpdateID = changes.LastUpdateID
+		if err := b.store.UpsertLoadStatusOfBot(ctx, status); err != nil {
+			return total, fmt.Errorf("failed to upsert the status of the backup: %w", err)
+		}
+	}
+}
+
+// ImportDesktop saves the chats of the export of Telegram Desktop (see ParseDesktopExport)
+// and returns the number of the saved messages (the messages with the same ID in the chat are overwritten).
+func (b *Backup) ImportDesktop(ctx context.Context, chats []DesktopChat) (int, error) {
+	total := 0
+	for idx := range chats {
+		changes := desktopChanges(&chats[idx])
+		if err := b.saveChanges(ctx, changes); err != nil {
+			return total, err
+		}
+		total += len(changes.Messages)
+		b.log.Info("imported the chat", zap.String("chat_id", changes.Chats[0].ID), zap.String("name", chats[idx].Name),
+			zap.Int("num_messages", len(changes.Messages)))
+	}
+	return total, nil
+}
+
+// the new chats and the messages of the backup
+type backupChanges struct {
+	Chats    []*BackupChat
+	Messages []*BackupMessage
+	// the last update of the page (for the changes from the bot)
+	LastUpdateID int64
+}
+
+func (b *Backup) saveChanges(ctx context.Context, changes *backupChanges) error {
+	for _, chat := range changes.Chats {
+		err := b.store.UpsertChat(ctx, chat)
+		warnErrorIf(b.log, err, "failed to upsert the chat", "chat_id", chat.ID)
+	}
+	for _, msg := range changes.Messages {
+		if err := b.store.UpsertMessage(ctx, msg); err != nil {
+			return fmt.Errorf("failed to upsert message %d of chat %q: %w", msg.MessageID, msg.ChatID, err)
+		}
+	}
+	return nil
+}
+
+// returns the page of the messages of the updates after the last saved update
+// (the edited messages are deduplicated by the last version)
+func (b *Backup) fetchUpdates(ctx context.Context, lastUpdateID int64) (*backupChanges, error) {
+	offset := int64(0)
+	if lastUpdateID > 0 {
+		offset = lastUpdateID + 1
+	}
+	updates := []Update{}
+	err := b.withRetry(ctx, func() (err error) {
+		updates, err = b.api.GetUpdates(ctx, offset)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updates: %w", err)
+	}
+
+	res := &backupChanges{LastUpdateID: lastUpdateID}
+	chats := map[string]bool{}
+	messages := map[string]int{}
+	for _, update := range updates {
+		if update.UpdateID > res.LastUpdateID {
+			res.LastUpdateID = update.UpdateID
+		}
+		msg := update.GetMessage()
+		if msg == nil {
+			continue
+		}
+		chat := BackupChatFromAPI(&msg.Chat)
+		if !chats[chat.ID] {
+			chats[chat.ID] = true
+			res.Chats = append(res.Chats, chat)
+		}
+		model := BackupMessageFromAPI(msg)
+		key := model.ChatID + "/" + model.ModelID()
+		if idx, exists := messages[key]; exists {
+			res.Messages[idx] = model
+			continue
+		}
+		messages[key] = len(res.Messages)
+		res.Messages = append(res.Messages, model)
+	}
+	return res, nil
+}
+
+// calls the request again after the wait of Telegram if the request is limited
+func (b *Backup) withRetry(ctx context.Context, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		limited := &notify.RetryAfterError{}
+		if !errors.As(err, &limited) || attempt >= maxRateLimitRetries {
+			return err
+		}
+		b.log.Debug("the request is limited", zap.Duration("retry_after", limited.After))
+		if err := b.sleep(ctx, limited.After); err != nil {
+			return err
+		}
+	}
+}
+
+// BackupChatFromAPI returns the model of the chat.
+func BackupChatFromAPI(in *Chat) *BackupChat {
+	chat := &BackupChat{
+		Type:      in.Type,
+		Title:     in.Title,
+		Username:  in.Username,
+		FirstName: in.FirstName,
+		LastName:  in.LastName,
+	}
+	chat.SetModelID(strconv.FormatInt(in.ID, 10))
+	return chat
+}
+
+// BackupMessageFromAPI returns the model of the message of the chat.
+func BackupMessageFromAPI(in *Message) *BackupMessage {
+	msg := &BackupMessage{
+		ChatID:    strconv.FormatInt(in.Chat.ID, 10),
+		MessageID: in.MessageID,
+		Source:    SourceBot,
+		Text:      in.Text,
+		PostedAt:  timestamppb.New(time.Unix(in.Date, 0)),
+	}
+	if msg.Text == "" {
+		msg.Text = in.Caption
+	}
+	switch {
+	case in.From != nil:
+		msg.FromID = strconv.FormatInt(in.From.ID, 10)
+		msg.FromName = userName(in.From)
+	case in.SenderChat != nil:
+		msg.FromID = strconv.FormatInt(in.SenderChat.ID, 10)
+		msg.FromName = BackupChatFromAPI(in.SenderChat).GetName()
+	}
+	if in.ReplyToMessage != nil {
+		msg.ReplyToID = in.ReplyToMessage.MessageID
+	}
+	switch {
+	case in.ForwardFrom != nil:
+		msg.ForwardFrom = userName(in.ForwardFrom)
+	case in.ForwardFromChat != nil:
+		msg.ForwardFrom = BackupChatFromAPI(in.ForwardFromChat).GetName()
+	}
+	if in.EditDate > 0 {
+		msg.EditedAt = timestamppb.New(time.Unix(in.EditDate, 0))
+	}
+
+	if len(in.Photo) > 0 {
+		// the largest size of the photo
+		msg.Media = append(msg.Media, mediaFromAPI("photo", &in.Photo[len(in.Photo)-1]))
+	}
+	for _, item := range []struct {
+		Type string
+		File *File
+	}{
+		{"document", in.Document},
+		{"audio", in.Audio},
+		{"video", in.Video},
+		{"voice", in.Voice},
+		{"video_note", in.VideoNote},
+		{"animation", in.Animation},
+		{"sticker", in.Sticker},
+	} {
+		// the animation is sent also as the document
+		if item.File != nil && !(item.Type == "document" && in.Animation != nil) {
+			msg.Media = append(msg.Media, mediaFromAPI(item.Type, item.File))
+		}
+	}
+	return msg
+}
+
+func mediaFromAPI(mediaType string, in *File) Media {
+	return Media{
+		Type:     mediaType,
+		FileID:   in.FileID,
+		FileName: in.FileName,
+		MimeType: in.MimeType,
+		FileSize: in.FileSize,
+		Width:    in.Width,
+		Height:   in.Height,
+		Duration: in.Duration,
+	}
+}
+
+func userName(in *User) string {
+	if name := strings.TrimSpace(in.FirstName + " " + in.LastName); name != "" {
+		return name
+	}
+	return "@" + in.Username
+}
+
+func warnErrorIf(l *zap.Logger, err error, msg string, pairs ...interface{}) {
+	if err != nil {
+		l.With(zap.Error(err)).Sugar().Warnw(msg, pairs...)
+	}
+}
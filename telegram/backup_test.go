@@ -0,0 +1,138 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeUpdates the in-memory getUpdates of Telegram Bot API
+type fakeUpdates struct {
+	updates []Update
+	// the number of the next requests limited by 429
+	limited int
+	offsets []int64
+}
+
+func (f *fakeUpdates) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/bot"+testBotToken+"/getUpdates" {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"ok": false, "error_code": 404, "description": "Not Found"})
+		return
+	}
+	if f.limited > 0 {
+		f.limited--
+		writeJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+			"ok": false, "error_code": 429, "description": "Too Many Requests: retry after 2",
+			"parameters": map[string]int{"retry_after": 2},
+		})
+		return
+	}
+
+	req := &struct {
+		Offset int64 `json:"offset"`
+	}{}
+	json.NewDecoder(r.Body).Decode(req)
+	f.offsets = append(f.offsets, req.Offset)
+	list := []Update{}
+	for _, update := range f.updates {
+		if update.UpdateID >= req.Offset {
+			list = append(list, update)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true, "result": list})
+}
+
+func TestBackup_FetchUpdates(t *testing.T) {
+	ctx := context.Background()
+	chat := Chat{ID: 42, Type: "private", FirstName: "John", LastName: "Doe"}
+	from := &User{ID: 42, FirstName: "John", LastName: "Doe"}
+	fake := &fakeUpdates{limited: 1, updates: []Update{
+		{UpdateID: 10, Message: &Message{MessageID: 1, From: from, Chat: chat, Date: 1643709600, Text: "hello"}},
+		{UpdateID: 11, Message: &Message{MessageID: 2, From: from, Chat: chat, Date: 1643709660, Caption: "screen",
+			Photo: []File{{FileID: "small", Width: 90}, {FileID: "large", Width: 1280, FileSize: 100}}}},
+		{UpdateID: 12, EditedMessage: &Message{MessageID: 1, From: from, Chat: chat, Date: 1643709600, EditDate: 1643709700, Text: "hello!"}},
+	}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	b := NewBackup(NewAPI(srv.URL, testBotToken), nil)
+	sleeps := []time.Duration{}
+	b.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+
+	changes, err := b.fetchUpdates(ctx, 9)
+	if err != nil {
+		t.Fatalf("fetchUpdates(): %v", err)
+	}
+	if changes.LastUpdateID != 12 || len(changes.Chats) != 1 || changes.Chats[0].ID != "42" || changes.Chats[0].GetName() != "John Doe" {
+		t.Errorf("changes %+v", changes)
+	}
+	if len(changes.Messages) != 2 {
+		t.Fatalf("messages %+v", changes.Messages)
+	}
+	if msg := changes.Messages[0]; msg.Text != "hello!" || msg.EditedAt == nil || msg.FromName != "John Doe" || msg.ModelID() != "1" {
+		t.Errorf("edited message %+v", msg)
+	}
+	wantMedia := []Media{{Type: "photo", FileID: "large", Width: 1280, FileSize: 100}}
+	if msg := changes.Messages[1]; msg.Text != "screen" || !reflect.DeepEqual(msg.Media, wantMedia) {
+		t.Errorf("photo message %+v", msg)
+	}
+	if !reflect.DeepEqual(fake.offsets, []int64{10}) || !reflect.DeepEqual(sleeps, []time.Duration{2 * time.Second}) {
+		t.Errorf("offsets %v, sleeps %v", fake.offsets, sleeps)
+	}
+
+	// the first backup of the bot
+	if _, err := b.fetchUpdates(ctx, 0); err != nil || fake.offsets[1] != 0 {
+		t.Errorf("fetchUpdates(): %v, offsets %v", err, fake.offsets)
+	}
+}
+
+func TestParseDesktopExport(t *testing.T) {
+	export := `{"about": "...", "chats": {"list": [
+		{"name": "John Doe", "type": "personal_chat", "id": 42, "messages": [
+			{"id": 1, "type": "message", "date": "2022-02-01T13:00:00", "date_unixtime": "1643709600", "from": "John Doe", "from_id": "user42",
+				"text": ["see ", {"type": "link", "text": "https://example.com"}, "!"]},
+			{"id": 2, "type": "message", "date": "2022-02-01T13:01:00", "date_unixtime": "1643709660", "from": "Me", "from_id": "user7",
+				"reply_to_message_id": 1, "file": "voice_messages/audio_1.ogg", "media_type": "voice_message", "mime_type": "audio/ogg", "duration_seconds": 3, "text": ""}
+		]},
+		{"name": "News", "type": "public_channel", "id": 1234567890, "messages": [
+			{"id": 5, "type": "service", "date": "2022-02-01T13:00:00", "date_unixtime": "1643709600", "actor": "News", "actor_id": "channel1234567890", "action": "pin_message", "text": ""},
+			{"id": 6, "type": "unsupported"}
+		]}
+	]}}`
+	chats, err := ParseDesktopExport(strings.NewReader(export))
+	if err != nil || len(chats) != 2 {
+		t.Fatalf("ParseDesktopExport(): %v, %+v", err, chats)
+	}
+
+	private := desktopChanges(&chats[0])
+	if chat := private.Chats[0]; chat.ID != "42" || chat.Type != "private" || chat.GetName() != "John Doe" {
+		t.Errorf("chat %+v", chat)
+	}
+	if msg := private.Messages[0]; msg.Text != "see https://example.com!" || msg.FromID != "42" || !msg.PostedAt.AsTime().Equal(time.Unix(1643709600, 0)) {
+		t.Errorf("message %+v", msg)
+	}
+	wantMedia := []Media{{Type: "voice", Path: "voice_messages/audio_1.ogg", MimeType: "audio/ogg", Duration: 3}}
+	if msg := private.Messages[1]; msg.ReplyToID != 1 || !reflect.DeepEqual(msg.Media, wantMedia) {
+		t.Errorf("message %+v", msg)
+	}
+
+	channel := desktopChanges(&chats[1])
+	if chat := channel.Chats[0]; chat.ID != "-1001234567890" || chat.Type != "channel" {
+		t.Errorf("chat %+v", chat)
+	}
+	if len(channel.Messages) != 1 || channel.Messages[0].Action != "pin_message" || channel.Messages[0].FromID != "-1001234567890" {
+		t.Errorf("messages %+v", channel.Messages)
+	}
+
+	single, err := ParseDesktopExport(strings.NewReader(`{"name": "Saved Messages", "type": "saved_messages", "id": 7, "messages": []}`))
+	if err != nil || len(single) != 1 || single[0].ID != 7 {
+		t.Errorf("ParseDesktopExport() single chat: %v, %+v", err, single)
+	}
+}
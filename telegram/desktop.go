@@ -0,0 +1,201 @@
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// the offset of ID of the supergroups and the channels in Bot API (-100<id>)
+const channelIDOffset = 1000000000000
+
+// DesktopChat the chat of the export of Telegram Desktop (the machine-readable JSON).
+type DesktopChat struct {
+	ID       int64            `json:"id"`
+	Name     string           `json:"name"`
+	Type     string           `json:"type"`
+	Messages []DesktopMessage `json:"messages"`
+}
+
+type DesktopMessage struct {
+	ID               int64       `json:"id"`
+	Type             string      `json:"type"`
+	Date             string      `json:"date"`
+	DateUnixtime     string      `json:"date_unixtime"`
+	Edited           string      `json:"edited"`
+	EditedUnixtime   string      `json:"edited_unixtime"`
+	From             string      `json:"from"`
+	FromID           string      `json:"from_id"`
+	Actor            string      `json:"actor"`
+	ActorID          string      `json:"actor_id"`
+	Action           string      `json:"action"`
+	ReplyToMessageID int64       `json:"reply_to_message_id"`
+	ForwardedFrom    string      `json:"forwarded_from"`
+	Text             DesktopText `json:"text"`
+	Photo            string      `json:"photo"`
+	File             string      `json:"file"`
+	FileName         string      `json:"file_name"`
+	MediaType        string      `json:"media_type"`
+	MimeType         string      `json:"mime_type"`
+	Width            int         `json:"width"`
+	Height           int         `json:"height"`
+	DurationSeconds  int         `json:"duration_seconds"`
+}
+
+// DesktopText the text of the message (the string or the list of the strings and the entities).
+type DesktopText string
+
+func (t *DesktopText) UnmarshalJSON(dat []byte) error {
+	text := ""
+	if err := json.Unmarshal(dat, &text); err == nil {
+		*t = DesktopText(text)
+		return nil
+	}
+
+	parts := []json.RawMessage{}
+	if err := json.Unmarshal(dat, &parts); err != nil {
+		return err
+	}
+	buf := &strings.Builder{}
+	for _, part := range parts {
+		entity := &struct {
+			Text string `json:"text"`
+		}{}
+		if err := json.Unmarshal(part, &text); err == nil {
+			buf.WriteString(text)
+		} else if err := json.Unmarshal(part, entity); err == nil {
+			buf.WriteString(entity.Text)
+		}
+	}
+	*t = DesktopText(buf.String())
+	return nil
+}
+
+// ParseDesktopExport returns the chats of result.json of the export of Telegram Desktop
+// (the export of all chats or the export of the single chat).
+func ParseDesktopExport(r io.Reader) ([]DesktopChat, error) {
+	export := &struct {
+		DesktopChat
+		Chats struct {
+			List []DesktopChat `json:"list"`
+		} `json:"chats"`
+	}{}
+	if err := json.NewDecoder(r).Decode(export); err != nil {
+		return nil, err
+	}
+	if len(export.Chats.List) > 0 {
+		return export.Chats.List, nil
+	}
+	if export.DesktopChat.ID != 0 {
+		return []DesktopChat{export.DesktopChat}, nil
+	}
+	return nil, errors.New("not found chats in the export")
+}
+
+// returns the chat and the messages of the export (ID of the chat as in Bot API)
+func desktopChanges(in *DesktopChat) *backupChanges {
+	chat := &BackupChat{Title: in.Name}
+	id := in.ID
+	switch in.Type {
+	case "personal_chat", "bot_chat", "saved_messages":
+		chat.Type, chat.Title, chat.FirstName = "private", "", in.Name
+	case "private_group":
+		chat.Type, id = "group", -id
+	case "private_supergroup", "public_supergroup":
+		chat.Type, id = "supergroup", -channelIDOffset-id
+	case "private_channel", "public_channel":
+		chat.Type, id = "channel", -channelIDOffset-id
+	default:
+		chat.Type = in.Type
+	}
+	chat.SetModelID(strconv.FormatInt(id, 10))
+
+	res := &backupChanges{Chats: []*BackupChat{chat}}
+	for idx := range in.Messages {
+		if msg := &in.Messages[idx]; msg.Type == "message" || msg.Type == "service" {
+			res.Messages = append(res.Messages, BackupMessageFromDesktop(chat.ID, msg))
+		}
+	}
+	return res
+}
+
+// BackupMessageFromDesktop returns the model of the message of the export of Telegram Desktop.
+func BackupMessageFromDesktop(chatID string, in *DesktopMessage) *BackupMessage {
+	msg := &BackupMessage{
+		ChatID:      chatID,
+		MessageID:   in.ID,
+		Source:      SourceDesktop,
+		FromID:      desktopPeerID(in.FromID),
+		FromName:    in.From,
+		Text:        string(in.Text),
+		Action:      in.Action,
+		ReplyToID:   in.ReplyToMessageID,
+		ForwardFrom: in.ForwardedFrom,
+		PostedAt:    timestamppb.New(desktopTime(in.DateUnixtime, in.Date)),
+	}
+	if in.Type == "service" {
+		msg.FromID, msg.FromName = desktopPeerID(in.ActorID), in.Actor
+	}
+	if in.Edited != "" || in.EditedUnixtime != "" {
+		msg.EditedAt = timestamppb.New(desktopTime(in.EditedUnixtime, in.Edited))
+	}
+
+	if in.Photo != "" {
+		msg.Media = append(msg.Media, Media{Type: "photo", Path: in.Photo, Width: in.Width, Height: in.Height})
+	}
+	if in.File != "" {
+		media := Media{
+			Type:     "document",
+			Path:     in.File,
+			FileName: in.FileName,
+			MimeType: in.MimeType,
+			Width:    in.Width,
+			Height:   in.Height,
+			Duration: in.DurationSeconds,
+		}
+		switch in.MediaType {
+		case "voice_message":
+			media.Type = "voice"
+		case "video_message":
+			media.Type = "video_note"
+		case "video_file":
+			media.Type = "video"
+		case "audio_file":
+			media.Type = "audio"
+		case "":
+		default:
+			media.Type = in.MediaType
+		}
+		msg.Media = append(msg.Media, media)
+	}
+	return msg
+}
+
+// returns ID of the user or the channel as in Bot API (eg user123 or channel123)
+func desktopPeerID(in string) string {
+	switch {
+	case strings.HasPrefix(in, "user"):
+		return strings.TrimPrefix(in, "user")
+	case strings.HasPrefix(in, "channel"):
+		id, err := strconv.ParseInt(strings.TrimPrefix(in, "channel"), 10, 64)
+		if err != nil {
+			return in
+		}
+		return strconv.FormatInt(-channelIDOffset-id, 10)
+	}
+	return in
+}
+
+// returns the time by the unixtime (the new exports) or by the local time of the export
+func desktopTime(unixtime, local string) time.Time {
+	if sec, err := strconv.ParseInt(unixtime, 10, 64); err == nil {
+		return time.Unix(sec, 0)
+	}
+	res, _ := time.ParseInLocation("2006-01-02T15:04:05", local, time.Local)
+	return res
+}
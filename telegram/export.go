@@ -0,0 +1,200 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// the formats of the export of the chat
+const (
+	ExportMarkdown = "markdown"
+	ExportHTML     = "html"
+)
+
+// ExportFormats all formats of the export.
+var ExportFormats = []string{ExportMarkdown, ExportHTML}
+
+// ExportedChat the chat with the messages (the oldest first).
+type ExportedChat struct {
+	ID       string
+	Name     string
+	Type     string
+	Messages []*ExportedMessage
+}
+
+type ExportedMessage struct {
+	ID          int64
+	PostedAt    time.Time
+	From        string
+	Text        string
+	Action      string
+	ReplyToID   int64
+	ForwardFrom string
+	Edited      bool
+	Media       []Media
+}
+
+// ExportChat returns the chat of the backup (by ID) for the export.
+func (s *Storage) ExportChat(ctx context.Context, chatID string) (*ExportedChat, error) {
+	chat := s.GetChat(ctx, chatID)
+	if !chat.Exists() {
+		return nil, fmt.Errorf("not found chat %q in the backup", chatID)
+	}
+	return NewExportedChat(chat, s.ListMessages(ctx, chatID)), nil
+}
+
+// NewExportedChat returns the chat for the export.
+func NewExportedChat(chat *BackupChat, messages []*BackupMessage) *ExportedChat {
+	res := &ExportedChat{
+		ID:       chat.ID,
+		Name:     chat.GetName(),
+		Type:     chat.Type,
+		Messages: []*ExportedMessage{},
+	}
+	for _, msg := range messages {
+		exported := &ExportedMessage{
+			ID:          msg.MessageID,
+			From:        msg.FromName,
+			Text:        msg.Text,
+			Action:      msg.Action,
+			ReplyToID:   msg.ReplyToID,
+			ForwardFrom: msg.ForwardFrom,
+			Edited:      msg.EditedAt != nil,
+			Media:       msg.Media,
+		}
+		if msg.PostedAt != nil {
+			exported.PostedAt = msg.PostedAt.AsTime().UTC()
+		}
+		if exported.From == "" {
+			exported.From = msg.FromID
+		}
+		res.Messages = append(res.Messages, exported)
+	}
+	return res
+}
+
+// Search returns the chat only with the messages containing the query
+// (case-insensitive, in the text, the sender and the names of the files).
+func (c *ExportedChat) Search(query string) *ExportedChat {
+	res := &ExportedChat{ID: c.ID, Name: c.Name, Type: c.Type, Messages: []*ExportedMessage{}}
+	query = strings.ToLower(strings.TrimSpace(query))
+	for _, msg := range c.Messages {
+		if strings.Contains(strings.ToLower(msg.searchText()), query) {
+			res.Messages = append(res.Messages, msg)
+		}
+	}
+	return res
+}
+
+func (m *ExportedMessage) searchText() string {
+	parts := []string{m.From, m.Text, m.Action, m.ForwardFrom}
+	for _, media := range m.Media {
+		parts = append(parts, media.FileName, media.Path)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Render writes the chat in the format (see ExportFormats).
+func (c *ExportedChat) Render(w io.Writer, format string) error {
+	switch format {
+	case ExportMarkdown:
+		return c.renderMarkdown(w)
+	case ExportHTML:
+		return htmlTemplate.Execute(w, c)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// the messages are grouped by the days (the headers and the anchors of the messages are searchable by grep)
+func (c *ExportedChat) renderMarkdown(w io.Writer) error {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "# %s\n", c.Name)
+	lastDay := ""
+	for _, msg := range c.Messages {
+		if day := msg.PostedAt.Format("2006-01-02"); day != lastDay {
+			lastDay = day
+			fmt.Fprintf(buf, "\n## %s\n", day)
+		}
+		fmt.Fprintf(buf, "\n**%s** · %s · #%d", msg.From, msg.PostedAt.Format("15:04"), msg.ID)
+		if msg.Edited {
+			buf.WriteString(" (edited)")
+		}
+		buf.WriteString("\n")
+		if msg.ReplyToID != 0 {
+			fmt.Fprintf(buf, "↩ reply to #%d\n", msg.ReplyToID)
+		}
+		if msg.ForwardFrom != "" {
+			fmt.Fprintf(buf, "↪ forwarded from %s\n", msg.ForwardFrom)
+		}
+		if msg.Action != "" {
+			fmt.Fprintf(buf, "_%s_\n", msg.Action)
+		}
+		if msg.Text != "" {
+			buf.WriteString(msg.Text + "\n")
+		}
+		for _, media := range msg.Media {
+			buf.WriteString(formatMedia(media) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// returns the type and the name (or the path) of the media
+func formatMedia(media Media) string {
+	name := media.FileName
+	if name == "" {
+		name = media.Path
+	}
+	if name == "" {
+		return "📎 " + media.Type
+	}
+	return fmt.Sprintf("📎 %s: %s", media.Type, name)
+}
+
+var htmlTemplate = template.Must(template.New("chat").Funcs(template.FuncMap{
+	"day":   func(in time.Time) string { return in.Format("2006-01-02") },
+	"clock": func(in time.Time) string { return in.Format("15:04") },
+	"media": formatMedia,
+	"lines": func(in string) []string {
+		return strings.Split(in, "\n")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; }
+.message { margin: 1em 0; }
+.meta { color: #666; font-size: 0.9em; }
+#search { width: 100%; padding: 0.5em; font-size: 1em; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<input id="search" type="search" placeholder="Search">
+{{$day := ""}}{{range .Messages}}{{if ne (day .PostedAt) $day}}{{$day = day .PostedAt}}<h2 class="day">{{$day}}</h2>
+{{end}}<div class="message" id="m{{.ID}}">
+<div><b>{{.From}}</b> <a class="meta" href="#m{{.ID}}">{{clock .PostedAt}}</a>{{if .Edited}} <span class="meta">(edited)</span>{{end}}</div>
+{{if .ReplyToID}}<div class="meta">↩ <a href="#m{{.ReplyToID}}">reply to #{{.ReplyToID}}</a></div>
+{{end}}{{with .ForwardFrom}}<div class="meta">↪ forwarded from {{.}}</div>
+{{end}}{{with .Action}}<div class="meta"><i>{{.}}</i></div>
+{{end}}{{with .Text}}<div>{{range $idx, $line := lines .}}{{if $idx}}<br>{{end}}{{$line}}{{end}}</div>
+{{end}}{{range .Media}}<div class="meta">{{media .}}</div>
+{{end}}</div>
+{{end}}<script>
+document.getElementById("search").addEventListener("input", function (e) {
+  var query = e.target.value.toLowerCase();
+  document.querySelectorAll(".message").forEach(function (el) {
+    el.style.display = el.textContent.toLowerCase().indexOf(query) >= 0 ? "" : "none";
+  });
+});
+</script>
+</body>
+</html>
+`))
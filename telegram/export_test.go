@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestExportedChat_Render(t *testing.T) {
+	chat := &BackupChat{Type: "private", FirstName: "John"}
+	chat.SetModelID("42")
+	at := func(sec int64) *timestamppb.Timestamp { return timestamppb.New(time.Unix(sec, 0)) }
+	export := NewExportedChat(chat, []*BackupMessage{
+		{MessageID: 1, FromName: "John", Text: "hello <world>\nsecond line", PostedAt: at(1643709600)},
+		{MessageID: 2, FromID: "7", ReplyToID: 1, PostedAt: at(1643709660), EditedAt: at(1643709700),
+			Media: []Media{{Type: "document", FileName: "report.pdf"}}},
+		{MessageID: 3, FromName: "John", ForwardFrom: "News", Text: "tomorrow", PostedAt: at(1643796000)},
+	})
+
+	buf := &bytes.Buffer{}
+	if err := export.Render(buf, ExportMarkdown); err != nil {
+		t.Fatalf("Render(): %v", err)
+	}
+	want := `# John
+
+## 2022-02-01
+
+**John** · 10:00 · #1
+hello <world>
+second line
+
+**7** · 10:01 · #2 (edited)
+↩ reply to #1
+📎 document: report.pdf
+
+## 2022-02-02
+
+**John** · 10:00 · #3
+↪ forwarded from News
+tomorrow
+`
+	if buf.String() != want {
+		t.Errorf("markdown:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	found := export.Search("REPORT")
+	if len(found.Messages) != 1 || found.Messages[0].ID != 2 {
+		t.Errorf("Search() %+v", found.Messages)
+	}
+
+	buf.Reset()
+	if err := export.Search("hello").Render(buf, ExportHTML); err != nil {
+		t.Fatalf("Render(): %v", err)
+	}
+	for _, part := range []string{
+		`<h2 class="day">2022-02-01</h2>`,
+		`<div class="message" id="m1">`,
+		"hello &lt;world&gt;<br>second line",
+		`<input id="search"`,
+	} {
+		if !strings.Contains(buf.String(), part) {
+			t.Errorf("html does not contain %q:\n%s", part, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "tomorrow") {
+		t.Errorf("html contains the not found message:\n%s", buf.String())
+	}
+
+	if err := export.Render(buf, "pdf"); err == nil {
+		t.Error("Render() with unknown format without error")
+	}
+}
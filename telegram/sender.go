@@ -0,0 +1,117 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+)
+
+// MessengerName name of the messenger in the spec of sync (notify[].messenger)
+const MessengerName = "telegram"
+
+// the limits of Bot API (about one message per second to the chat and 30 messages per second in total)
+const (
+	chatInterval = time.Second
+	interval     = time.Second / 30
+)
+
+// the max length of the text of the message
+const maxMessageLength = 4096
+
+// NewNotifier returns the notifier to the chats of Telegram with the limits of Bot API.
+func NewNotifier(api *API) *notify.Notifier {
+	return notify.NewNotifier(NewSender(api), notify.Options{ChatInterval: chatInterval, Interval: interval})
+}
+
+// NewSender returns the sender of the notifications to the chats of Telegram.
+func NewSender(api *API) *Sender {
+	return &Sender{api: api}
+}
+
+type Sender struct {
+	api *API
+}
+
+var _ notify.Sender = (*Sender)(nil)
+
+func (s *Sender) Send(ctx context.Context, chatID string, events []*notify.Event) error {
+	_, err := s.api.SendMessage(ctx, chatID, FormatEvents(events))
+	return err
+}
+
+// FormatEvents returns the text of the message in the HTML format (the digest grouped by the tasks if more than one event).
+func FormatEvents(events []*notify.Event) string {
+	if len(events) == 1 {
+		return formatTask(events[0]) + "\n" + formatEvent(events[0])
+	}
+
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "<b>Digest: %d changes</b>\n", len(events))
+	lastTask := ""
+	for _, event := range events {
+		if task := event.Rule + "\n" + event.URL + "\n" + event.Title; task != lastTask {
+			lastTask = task
+			buf.WriteString("\n" + formatTask(event) + "\n")
+		}
+		buf.WriteString("• " + formatEvent(event) + "\n")
+	}
+	text := strings.TrimSuffix(buf.String(), "\n")
+	if len(text) > maxMessageLength {
+		// cuts by the lines to keep the tags closed
+		text = text[:strings.LastIndex(text[:maxMessageLength-4], "\n")] + "\n..."
+	}
+	return text
+}
+
+// returns the name of the task with the link and the rule
+func formatTask(event *notify.Event) string {
+	title := "<b>" + html.EscapeString(event.Title) + "</b>"
+	if event.URL != "" {
+		title = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(event.URL), title)
+	}
+	if event.MirrorURL != "" {
+		title += fmt.Sprintf(` (<a href="%s">mirror</a>)`, html.EscapeString(event.MirrorURL))
+	}
+	if event.Rule != "" {
+		title += " <i>(" + html.EscapeString(event.Rule) + ")</i>"
+	}
+	return title
+}
+
+func formatEvent(event *notify.Event) string {
+	from, to := html.EscapeString(valueOrNone(event.From)), html.EscapeString(valueOrNone(event.To))
+	text := ""
+	switch event.Kind {
+	case notify.EventStatus:
+		text = fmt.Sprintf("status: %s → <b>%s</b>", from, to)
+	case notify.EventAssignees:
+		text = fmt.Sprintf("assignees: %s → <b>%s</b>", from, to)
+	case notify.EventDueDate:
+		text = fmt.Sprintf("due date: %s → <b>%s</b>", from, to)
+	case notify.EventEstimate:
+		text = fmt.Sprintf("estimate: %s → <b>%s</b>", from, to)
+	case notify.EventMirrorAdded:
+		text = "mirror task created"
+	case notify.EventUnlinked:
+		text = "mirror tasks unlinked"
+	case notify.EventApproval:
+		text = "mirror task awaits approval"
+	default:
+		text = html.EscapeString(event.Kind)
+	}
+	if event.Details != "" {
+		text += ": " + html.EscapeString(event.Details)
+	}
+	return text
+}
+
+func valueOrNone(in string) string {
+	if in == "" {
+		return "none"
+	}
+	return in
+}
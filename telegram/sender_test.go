@@ -0,0 +1,127 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gebv/asap-tools/notify"
+)
+
+const testBotToken = "123:test"
+
+// fakeBotAPI the in-memory Telegram Bot API (only sendMessage to the known chats)
+type fakeBotAPI struct {
+	mu       sync.Mutex
+	messages map[string][]string
+	// the number of the next requests limited by 429
+	limited int
+}
+
+func (f *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path != "/bot"+testBotToken+"/sendMessage" {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"ok": false, "error_code": 404, "description": "Not Found"})
+		return
+	}
+	if f.limited > 0 {
+		f.limited--
+		writeJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+			"ok": false, "error_code": 429, "description": "Too Many Requests: retry after 1",
+			"parameters": map[string]int{"retry_after": 1},
+		})
+		return
+	}
+
+	req := &struct {
+		ChatID    string `json:"chat_id"`
+		Text      string `json:"text"`
+		ParseMode string `json:"parse_mode"`
+	}{}
+	json.NewDecoder(r.Body).Decode(req)
+	if _, exists := f.messages[req.ChatID]; !exists || req.ParseMode != "HTML" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"ok": false, "error_code": 400, "description": "Bad Request: chat not found"})
+		return
+	}
+	f.messages[req.ChatID] = append(f.messages[req.ChatID], req.Text)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true, "result": map[string]interface{}{
+		"message_id": len(f.messages[req.ChatID]), "chat": map[string]interface{}{"id": -100, "type": "group"}, "text": req.Text,
+	}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, in interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(in)
+}
+
+func newTestAPI(t *testing.T) (*API, *fakeBotAPI) {
+	fake := &fakeBotAPI{messages: map[string][]string{"-100": {}}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return NewAPI(srv.URL, testBotToken), fake
+}
+
+func TestSender(t *testing.T) {
+	ctx := context.Background()
+	api, fake := newTestAPI(t)
+	n := NewNotifier(api)
+
+	event := &notify.Event{Kind: notify.EventStatus, Rule: "qa", Title: "Fix <login>", URL: "https://app.clickup.com/t/abc", From: "open", To: "in progress"}
+	if err := n.Notify(ctx, "-100", event, false); err != nil {
+		t.Fatalf("Notify(): %v", err)
+	}
+	for _, kind := range []string{notify.EventDueDate, notify.EventUnlinked} {
+		if err := n.Notify(ctx, "-100", &notify.Event{Kind: kind, Rule: "qa", Title: "Fix <login>", Details: "mirror task deleted"}, true); err != nil {
+			t.Fatalf("Notify(): %v", err)
+		}
+	}
+	// the limited message is retried
+	fake.limited = 1
+	if err := n.Flush(ctx); err != nil {
+		t.Fatalf("Flush(): %v", err)
+	}
+
+	want := []string{
+		`<a href="https://app.clickup.com/t/abc"><b>Fix &lt;login&gt;</b></a> <i>(qa)</i>
+status: open → <b>in progress</b>`,
+		`<b>Digest: 2 changes</b>
+
+<b>Fix &lt;login&gt;</b> <i>(qa)</i>
+• due date: none → <b>none</b>: mirror task deleted
+• mirror tasks unlinked: mirror task deleted`,
+	}
+	got := fake.messages["-100"]
+	if len(got) != len(want) {
+		t.Fatalf("sent %d messages, want %d: %q", len(got), len(want), got)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Errorf("message %d = %q, want %q", idx, got[idx], want[idx])
+		}
+	}
+
+	err := n.Notify(ctx, "-200", event, false)
+	apiErr := &Error{}
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest {
+		t.Errorf("Notify() to the unknown chat: %v, want the error of Bot API", err)
+	}
+}
+
+func TestFormatEvents_MaxLength(t *testing.T) {
+	events := []*notify.Event{}
+	for idx := 0; idx < 200; idx++ {
+		events = append(events, &notify.Event{Kind: notify.EventStatus, Title: "task", From: strings.Repeat("a", 20), To: "b"})
+	}
+	text := FormatEvents(events)
+	if len(text) > maxMessageLength || !strings.HasSuffix(text, "\n...") {
+		t.Errorf("FormatEvents() returns %d chars with suffix %q", len(text), text[len(text)-10:])
+	}
+}
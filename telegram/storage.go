@@ -0,0 +1,222 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gebv/asap-tools/storage"
+	"go.uber.org/zap"
+)
+
+type StoreModel = storage.Model
+
+func NewStorage(s *storage.Storage) *Storage {
+	return &Storage{
+		Storage: s,
+		log:     zap.L().Named("telegram_storage"),
+	}
+}
+
+// the backup of the chats
+//
+// - method Get<ModelName> - returns model by ID
+// - method GetStateOf<ModelName> - returns status of loading of changes for the model
+// - method Upsert<ModelName> - create or overwrite model by ID
+type Storage struct {
+	*storage.Storage
+	log *zap.Logger
+}
+
+var (
+	BackupChatModel                 = (*BackupChat)(nil)
+	_                    StoreModel = (*BackupChat)(nil)
+	BackupMessageModel              = (*BackupMessage)(nil)
+	_                    StoreModel = (*BackupMessage)(nil)
+	LoadStatusOfBotModel            = (*LoadStatusOfBot)(nil)
+	_                    StoreModel = (*LoadStatusOfBot)(nil)
+)
+
+// a new model instance and call GetModel
+func (s *Storage) GetChat(ctx context.Context, modelID string) *BackupChat {
+	model := storage.NewWithID(BackupChatModel, modelID).(*BackupChat)
+	s.GetModel(ctx, model)
+	return model
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertChat(ctx context.Context, model *BackupChat) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// AllChats returns the chats of the backup.
+func (s *Storage) AllChats(ctx context.Context) []*BackupChat {
+	iter := s.FirestoreClient().Collection(BackupChatModel.CollectionName()).Documents(ctx)
+	res := s.Iterate(iter, BackupChatModel)
+	list := []*BackupChat{}
+	for idx := range res {
+		list = append(list, res[idx].(*BackupChat))
+	}
+	return list
+}
+
+// alias to UpsertModel (the message with the same ID in the chat is overwritten)
+func (s *Storage) UpsertMessage(ctx context.Context, model *BackupMessage) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// ListMessages returns the messages of the chat (the oldest first).
+func (s *Storage) ListMessages(ctx context.Context, chatID string) []*BackupMessage {
+	factory := &BackupMessage{ChatID: chatID}
+
+	iter := s.FirestoreClient().Collection(factory.CollectionName()).
+		OrderBy("PostedAt", firestore.Asc).Documents(ctx)
+	res := s.Iterate(iter, factory)
+
+	list := []*BackupMessage{}
+	for idx := range res {
+		msg := res[idx].(*BackupMessage)
+		msg.ChatID = chatID
+		list = append(list, msg)
+	}
+	return list
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertLoadStatusOfBot(ctx context.Context, model *LoadStatusOfBot) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// a new model instance and call GetModel
+func (s *Storage) GetStateOfLoadBot(ctx context.Context, botID string) *LoadStatusOfBot {
+	model := &LoadStatusOfBot{BotID: botID}
+	s.GetModel(ctx, model)
+	return model
+}
+
+// BackupChat the chat of the backup (ID is ID of the chat in Bot API).
+type BackupChat struct {
+	storage.StdModel
+	// private, group, supergroup, channel
+	Type      string
+	Title     string
+	Username  string
+	FirstName string
+	LastName  string
+}
+
+func (*BackupChat) NewModel() StoreModel {
+	return &BackupChat{}
+}
+
+func (*BackupChat) CollectionName() string {
+	return "telegram_chats"
+}
+
+// GetName returns the title of the chat or the name of the user of the private chat.
+func (c *BackupChat) GetName() string {
+	switch {
+	case c.Title != "":
+		return c.Title
+	case c.FirstName != "" || c.LastName != "":
+		return strings.TrimSpace(c.FirstName + " " + c.LastName)
+	case c.Username != "":
+		return "@" + c.Username
+	}
+	return c.ID
+}
+
+// the sources of the messages of the backup
+const (
+	SourceBot     = "bot"
+	SourceDesktop = "desktop"
+)
+
+// BackupMessage the message of the chat.
+// Stored in the subcollection of the chat.
+type BackupMessage struct {
+	storage.ModelCustomID
+	ChatID    string `firestore:"-"`
+	MessageID int64  `firestore:"-"`
+
+	// see SourceBot, SourceDesktop
+	Source   string
+	FromID   string
+	FromName string
+	// the text or the caption of the media
+	Text string
+	// the action of the service message (eg pin_message, see Telegram Desktop export)
+	Action      string
+	ReplyToID   int64
+	ForwardFrom string
+	Media       []Media
+	PostedAt    *storage.Timestamp
+	EditedAt    *storage.Timestamp
+}
+
+// Media the metadata of the media of the message (the files are not downloaded).
+type Media struct {
+	// photo, document, audio, video, voice, video_note, animation, sticker
+	Type string
+	// ID of the file in Bot API (for the messages from the bot)
+	FileID string
+	// the path of the file in the export (for the messages from Telegram Desktop)
+	Path     string
+	FileName string
+	MimeType string
+	FileSize int64
+	Width    int
+	Height   int
+	Duration int
+}
+
+func (*BackupMessage) NewModel() StoreModel {
+	return &BackupMessage{}
+}
+
+func (m *BackupMessage) CollectionName() string {
+	return BackupChatModel.CollectionName() + "/" + m.ChatID + "/messages"
+}
+
+func (m *BackupMessage) SetModelID(in string) {
+	id, err := strconv.ParseInt(in, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid format ID %q for %T", in, m))
+	}
+	m.MessageID = id
+}
+
+func (m *BackupMessage) ModelID() string {
+	return strconv.FormatInt(m.MessageID, 10)
+}
+
+// LoadStatusOfBot the cursor of the updates of the bot.
+type LoadStatusOfBot struct {
+	storage.ModelCustomID
+	BotID string `firestore:"-"`
+	// the last saved update
+	LastUpdateID int64
+}
+
+func (m *LoadStatusOfBot) NewModel() StoreModel {
+	return &LoadStatusOfBot{}
+}
+
+func (m *LoadStatusOfBot) SetModelID(in string) {
+	const prefix = "bot:"
+	if strings.HasPrefix(in, prefix) {
+		m.BotID = in[len(prefix):]
+	} else {
+		panic(fmt.Sprintf("Invalid format ID %q for %T", in, m))
+	}
+}
+
+func (m *LoadStatusOfBot) ModelID() string {
+	return fmt.Sprintf("bot:%s", m.BotID)
+}
+
+func (LoadStatusOfBot) CollectionName() string {
+	return "telegram_load_status_of_bots"
+}
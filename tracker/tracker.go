@@ -0,0 +1,276 @@
+// Package tracker describes the task trackers (ClickUp, GitHub Issues, Notion, ...) independently of the provider,
+// so the sync engine works with the tasks of any provider by the references (provider name and task ID).
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotFound returns the provider if the task is not found (deleted or not accessible).
+var ErrNotFound = errors.New("tracker: task not found")
+
+// Capability the feature supported by the provider.
+type Capability uint
+
+const (
+	CapComments Capability = 1 << iota
+	// the comment can be assigned to the member
+	CapAssignedComments
+	CapTags
+	CapAssignees
+	CapStatus
+	CapPriority
+	CapDueDate
+	CapStartDate
+	CapEstimate
+	CapSubtasks
+	CapChecklists
+	CapAttachments
+	CapTimeTracking
+	// the description is in the markdown format
+	CapMarkdown
+	// the new task can be linked with another task of the same provider
+	CapLinks
+)
+
+// Has returns true if all capabilities are supported.
+func (c Capability) Has(in Capability) bool {
+	return c&in == in
+}
+
+// the fields of the task for the updates
+const (
+	FieldName        = "name"
+	FieldDescription = "description"
+	FieldStatus      = "status"
+	FieldPriority    = "priority"
+	FieldTags        = "tags"
+	FieldAssignees   = "assignees"
+	FieldDueDate     = "due_date"
+	FieldStartDate   = "start_date"
+	FieldEstimate    = "estimate"
+	// the open or closed state of the task (see Task.Closed)
+	FieldState = "state"
+	// the milestone of the task (eg the sprint)
+	FieldMilestone = "milestone"
+	// the parent task of the subtask (see Task.Parent)
+	FieldParent = "parent"
+	// the archived state of the task (see Task.Archived)
+	FieldArchived = "archived"
+)
+
+// TaskRef the reference to the task of the provider.
+type TaskRef struct {
+	Provider string
+	ID       string
+}
+
+// String returns the reference in the format <provider>:<ID>.
+func (r TaskRef) String() string {
+	return r.Provider + ":" + r.ID
+}
+
+func (r TaskRef) IsZero() bool {
+	return r.Provider == "" && r.ID == ""
+}
+
+// ParseTaskRef parses the reference in the format <provider>:<ID>.
+func ParseTaskRef(in string) (TaskRef, error) {
+	args := strings.SplitN(in, ":", 2)
+	if len(args) != 2 || args[0] == "" || args[1] == "" {
+		return TaskRef{}, fmt.Errorf("invalid task reference %q, expected <provider>:<id>", in)
+	}
+	return TaskRef{Provider: args[0], ID: args[1]}, nil
+}
+
+// Location the place of the tasks in the provider (eg ClickUp list, GitHub repository, Notion database).
+type Location struct {
+	Provider string
+	ID       string
+	Name     string
+	// the settings of the fields in the location by the field (eg the names of the properties of the Notion database)
+	Properties map[string]string
+}
+
+// Property returns the setting of the field in the location.
+func (l Location) Property(field string) string {
+	return l.Properties[field]
+}
+
+// Member the user of the provider (the email may be empty if the provider does not share it).
+type Member struct {
+	ID    string
+	Email string
+	Name  string
+}
+
+// Task the task of the provider (the fields not supported by the provider are empty).
+type Task struct {
+	Ref TaskRef
+	// the key of the task in the location (eg the number of the GitHub issue), the ID if the provider has no keys
+	Key      string
+	Location Location
+	// the parent task (for the subtasks)
+	Parent      *TaskRef
+	Name        string
+	Description string
+	Status      string
+	Closed      bool
+	// the task is hidden without the closing (if the provider supports the archive)
+	Archived   bool
+	PriorityID *int
+	Tags       []string
+	Assignees  []Member
+	DueDate    *time.Time
+	StartDate  *time.Time
+	// the time estimate in milliseconds
+	TimeEstimateMs *int64
+	Milestone      string
+	// the tasks linked with the task (the new task is linked if the provider supports CapLinks)
+	Links     []TaskRef
+	URL       string
+	UpdatedAt time.Time
+}
+
+// AssigneeEmails returns the emails of the assignees (the assignees without the email are skipped).
+func (t *Task) AssigneeEmails() []string {
+	res := []string{}
+	for _, member := range t.Assignees {
+		if member.Email != "" {
+			res = append(res, strings.ToLower(member.Email))
+		}
+	}
+	return res
+}
+
+// Comment the comment of the task.
+type Comment struct {
+	ID     string
+	Author Member
+	Body   string
+	// the member assigned to the comment (if the provider supports CapAssignedComments)
+	AssignTo  *Member
+	URL       string
+	CreatedAt time.Time
+}
+
+// Provider the task tracker.
+// The tasks are found by the key in the location (eg the number of the issue in the GitHub repository).
+type Provider interface {
+	// Name returns the name of the provider in the references (eg "clickup")
+	Name() string
+	Capabilities() Capability
+	// Fields returns the fields of the tasks in the location supported by the provider (see Field* constants)
+	Fields(location Location) []string
+
+	// GetTask returns the task by the key or ErrNotFound
+	GetTask(ctx context.Context, location Location, key string) (*Task, error)
+	// CreateTask creates the task in the location and returns the new task
+	CreateTask(ctx context.Context, location Location, task *Task) (*Task, error)
+	// UpdateTask updates only the specified fields of the task by the key (see Field* constants) and returns the updated task
+	UpdateTask(ctx context.Context, location Location, task *Task, fields []string) (*Task, error)
+
+	// ListComments returns the comments of the task (the oldest first)
+	ListComments(ctx context.Context, location Location, key string) ([]*Comment, error)
+	AddComment(ctx context.Context, location Location, key string, comment *Comment) (*Comment, error)
+
+	// ListMembers returns the members with access to the location
+	ListMembers(ctx context.Context, location Location) ([]*Member, error)
+}
+
+// Normalizer is implemented by the providers which store the values with less precision (eg the dates without the time).
+// The values of the task from another provider are normalized before the comparison with the values of the task of the provider.
+type Normalizer interface {
+	NormalizeTask(location Location, task *Task)
+}
+
+// NewRegistry returns the registry with the providers.
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: map[string]Provider{}}
+	for _, provider := range providers {
+		r.Register(provider)
+	}
+	return r
+}
+
+// Registry the providers by name.
+type Registry struct {
+	providers map[string]Provider
+}
+
+// Register adds the provider (replaces the provider with the same name).
+func (r *Registry) Register(provider Provider) {
+	r.providers[provider.Name()] = provider
+}
+
+// Provider returns the provider by name or nil if not registered.
+func (r *Registry) Provider(name string) Provider {
+	if r == nil {
+		return nil
+	}
+	return r.providers[name]
+}
+
+// ProviderFor returns the provider of the location.
+func (r *Registry) ProviderFor(location Location) (Provider, error) {
+	provider := r.Provider(location.Provider)
+	if provider == nil {
+		return nil, fmt.Errorf("not registered provider %q of the location %q", location.Provider, location.ID)
+	}
+	return provider, nil
+}
+
+// GetTask returns the task by the key in the location.
+func (r *Registry) GetTask(ctx context.Context, location Location, key string) (*Task, error) {
+	provider, err := r.ProviderFor(location)
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetTask(ctx, location, key)
+}
+
+// CreateTask creates the task in the location.
+func (r *Registry) CreateTask(ctx context.Context, location Location, task *Task) (*Task, error) {
+	provider, err := r.ProviderFor(location)
+	if err != nil {
+		return nil, err
+	}
+	return provider.CreateTask(ctx, location, task)
+}
+
+// UpdateTask updates only the specified fields of the task by the key in the location.
+func (r *Registry) UpdateTask(ctx context.Context, location Location, task *Task, fields []string) (*Task, error) {
+	provider, err := r.ProviderFor(location)
+	if err != nil {
+		return nil, err
+	}
+	return provider.UpdateTask(ctx, location, task, fields)
+}
+
+// ListComments returns the comments of the task by the key in the location (the oldest first).
+func (r *Registry) ListComments(ctx context.Context, location Location, key string) ([]*Comment, error) {
+	provider, err := r.ProviderFor(location)
+	if err != nil {
+		return nil, err
+	}
+	if !provider.Capabilities().Has(CapComments) {
+		return nil, fmt.Errorf("provider %q does not support the comments", provider.Name())
+	}
+	return provider.ListComments(ctx, location, key)
+}
+
+// AddComment sends the comment to the task by the key in the location.
+func (r *Registry) AddComment(ctx context.Context, location Location, key string, comment *Comment) (*Comment, error) {
+	provider, err := r.ProviderFor(location)
+	if err != nil {
+		return nil, err
+	}
+	if !provider.Capabilities().Has(CapComments) {
+		return nil, fmt.Errorf("provider %q does not support the comments", provider.Name())
+	}
+	return provider.AddComment(ctx, location, key, comment)
+}
@@ -0,0 +1,70 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTaskRef(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    TaskRef
+		wantErr bool
+	}{
+		{in: "clickup:abc123", want: TaskRef{Provider: "clickup", ID: "abc123"}},
+		{in: "notion:d9824bdc:1", want: TaskRef{Provider: "notion", ID: "d9824bdc:1"}},
+		{in: "abc123", wantErr: true},
+		{in: "clickup:", wantErr: true},
+		{in: ":abc123", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTaskRef(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTaskRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTaskRef() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && got.String() != tt.in {
+				t.Errorf("String() = %q, want %q", got.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestCapability_Has(t *testing.T) {
+	caps := CapComments | CapTags
+	if !caps.Has(CapComments) || !caps.Has(CapComments|CapTags) {
+		t.Error("Has() must return true for the supported capabilities")
+	}
+	if caps.Has(CapComments | CapAssignees) {
+		t.Error("Has() must return false if any capability is not supported")
+	}
+}
+
+type noCommentsProvider struct {
+	Provider
+}
+
+func (noCommentsProvider) Name() string             { return "readonly" }
+func (noCommentsProvider) Capabilities() Capability { return CapStatus }
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry(noCommentsProvider{})
+
+	if registry.Provider("readonly") == nil {
+		t.Error("Provider() must return the registered provider")
+	}
+	if _, err := registry.GetTask(ctx, Location{Provider: "unknown"}, "1"); err == nil {
+		t.Error("GetTask() for the not registered provider must return error")
+	}
+	if _, err := registry.AddComment(ctx, Location{Provider: "readonly"}, "1", &Comment{Body: "text"}); err == nil {
+		t.Error("AddComment() for the provider without the comments must return error")
+	}
+	var nilRegistry *Registry
+	if nilRegistry.Provider("readonly") != nil {
+		t.Error("Provider() of the nil registry must return nil")
+	}
+}
@@ -0,0 +1,57 @@
+// Package trackertest provides the fake HTTP API and the common tests for the providers of the tasks (see tracker.Provider).
+//
+// The provider test implements only the handlers of its API (the in-memory tasks and comments),
+// the server checks the authorization and serializes the requests.
+package trackertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// NewServer returns the URL of the fake API stopped after the test.
+// The handler is called one by one only for the authorized requests, otherwise responds 401 Unauthorized.
+func NewServer(t testing.TB, authorized func(r *http.Request) bool, handler http.HandlerFunc) string {
+	mu := sync.Mutex{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// HeaderAuth returns the check of the authorization by the value of the header (eg "Authorization: Bearer <token>").
+func HeaderAuth(name, value string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		return r.Header.Get(name) == value
+	}
+}
+
+// WriteJSON writes the response in JSON with the status.
+func WriteJSON(w http.ResponseWriter, status int, in interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(in)
+}
+
+// Page returns the bounds of the page of the list (from the offset, the size items) limited by the length of the list.
+func Page(length, offset, size int) (from, to int) {
+	from, to = offset, offset+size
+	if from > length {
+		from = length
+	}
+	if to > length {
+		to = length
+	}
+	return from, to
+}
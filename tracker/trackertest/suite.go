@@ -0,0 +1,172 @@
+package trackertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gebv/asap-tools/tracker"
+)
+
+// Suite the common tests of the provider with the fake API.
+type Suite struct {
+	Provider tracker.Provider
+	Location tracker.Location
+	// the values of the new task and the changes of the task (the values of all fields of the location accepted by the fake API)
+	Task, Update *tracker.Task
+	// removes the task from the fake API (eg the deleted or transferred issue)
+	Remove func(task *tracker.Task)
+	// the number of the comments in the page of the API and the author (ID) of the comments added by the provider
+	PageSize      int
+	CommentAuthor string
+}
+
+// Run runs the tests of the tasks and the comments.
+func (s *Suite) Run(t *testing.T) {
+	t.Run("Tasks", s.testTasks)
+	t.Run("Comments", s.testComments)
+}
+
+func (s *Suite) testTasks(t *testing.T) {
+	ctx := context.Background()
+	fields := s.Provider.Fields(s.Location)
+
+	created, err := s.Provider.CreateTask(ctx, s.Location, s.normalized(s.Task))
+	if err != nil {
+		t.Fatalf("CreateTask(): %v", err)
+	}
+	if created.Ref.Provider != s.Provider.Name() || created.Ref.ID == "" || created.Key == "" || created.URL == "" {
+		t.Errorf("CreateTask() = %+v, want the reference, the key and the URL", created)
+	}
+	want := fieldsOf(s.normalized(s.Task), fields)
+	if got := fieldsOf(created, fields); !reflect.DeepEqual(got, want) {
+		t.Errorf("CreateTask() = %+v, want %+v", got, want)
+	}
+
+	got, err := s.Provider.GetTask(ctx, s.Location, created.Key)
+	if err != nil {
+		t.Fatalf("GetTask(): %v", err)
+	}
+	if got.Ref != created.Ref || got.Key != created.Key || got.URL != created.URL {
+		t.Errorf("GetTask() = %+v, want the task %+v", got, created)
+	}
+	if got := fieldsOf(got, fields); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTask() = %+v, want %+v", got, want)
+	}
+
+	// updates only the specified fields (all except the name)
+	changes := s.normalized(s.Update)
+	changes.Ref, changes.Key, changes.URL = created.Ref, created.Key, created.URL
+	changes.Name = created.Name
+	want = fieldsOf(changes, fields)
+	changes.Name = "ignored"
+	updated, err := s.Provider.UpdateTask(ctx, s.Location, changes, without(fields, tracker.FieldName))
+	if err != nil {
+		t.Fatalf("UpdateTask(): %v", err)
+	}
+	if got := fieldsOf(updated, fields); !reflect.DeepEqual(got, want) {
+		t.Errorf("UpdateTask() = %+v, want %+v", got, want)
+	}
+	got, err = s.Provider.GetTask(ctx, s.Location, created.Key)
+	if err != nil {
+		t.Fatalf("GetTask(): %v", err)
+	}
+	if got := fieldsOf(got, fields); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTask() after the update = %+v, want %+v", got, want)
+	}
+
+	s.Remove(created)
+	if _, err := s.Provider.GetTask(ctx, s.Location, created.Key); !errors.Is(err, tracker.ErrNotFound) {
+		t.Errorf("GetTask() for the removed task: %v, want %v", err, tracker.ErrNotFound)
+	}
+}
+
+func (s *Suite) testComments(t *testing.T) {
+	ctx := context.Background()
+
+	task, err := s.Provider.CreateTask(ctx, s.Location, &tracker.Task{Name: "Task"})
+	if err != nil {
+		t.Fatalf("CreateTask(): %v", err)
+	}
+
+	// more than one page
+	for idx := 0; idx < s.PageSize+1; idx++ {
+		if _, err := s.Provider.AddComment(ctx, s.Location, task.Key, &tracker.Comment{Body: fmt.Sprint("comment ", idx)}); err != nil {
+			t.Fatalf("AddComment(): %v", err)
+		}
+	}
+
+	list, err := s.Provider.ListComments(ctx, s.Location, task.Key)
+	if err != nil {
+		t.Fatalf("ListComments(): %v", err)
+	}
+	if len(list) != s.PageSize+1 {
+		t.Fatalf("ListComments() returns %d comments, want %d", len(list), s.PageSize+1)
+	}
+	first, last := list[0], list[s.PageSize]
+	if first.ID == "" || first.Body != "comment 0" || first.Author.ID != s.CommentAuthor || last.Body != fmt.Sprint("comment ", s.PageSize) {
+		t.Errorf("ListComments() = [%+v ... %+v]", first, last)
+	}
+
+	s.Remove(task)
+	if _, err := s.Provider.AddComment(ctx, s.Location, task.Key, &tracker.Comment{Body: "comment"}); !errors.Is(err, tracker.ErrNotFound) {
+		t.Errorf("AddComment() for the removed task: %v, want %v", err, tracker.ErrNotFound)
+	}
+}
+
+// returns the copy of the task normalized by the provider (see tracker.Normalizer)
+func (s *Suite) normalized(task *tracker.Task) *tracker.Task {
+	res := *task
+	if normalizer, ok := s.Provider.(tracker.Normalizer); ok {
+		normalizer.NormalizeTask(s.Location, &res)
+	}
+	return &res
+}
+
+// returns the values of the fields of the task (the empty lists as nil)
+func fieldsOf(task *tracker.Task, fields []string) *tracker.Task {
+	res := &tracker.Task{}
+	for _, field := range fields {
+		switch field {
+		case tracker.FieldName:
+			res.Name = task.Name
+		case tracker.FieldDescription:
+			res.Description = task.Description
+		case tracker.FieldTags:
+			if len(task.Tags) > 0 {
+				res.Tags = task.Tags
+			}
+		case tracker.FieldAssignees:
+			for _, member := range task.Assignees {
+				res.Assignees = append(res.Assignees, tracker.Member{ID: member.ID})
+			}
+		case tracker.FieldState:
+			res.Closed = task.Closed
+		case tracker.FieldStatus:
+			res.Status = task.Status
+		case tracker.FieldDueDate:
+			res.DueDate = task.DueDate
+		case tracker.FieldStartDate:
+			res.StartDate = task.StartDate
+		case tracker.FieldEstimate:
+			res.TimeEstimateMs = task.TimeEstimateMs
+		case tracker.FieldPriority:
+			res.PriorityID = task.PriorityID
+		case tracker.FieldMilestone:
+			res.Milestone = task.Milestone
+		}
+	}
+	return res
+}
+
+func without(list []string, value string) []string {
+	res := []string{}
+	for _, item := range list {
+		if item != value {
+			res = append(res, item)
+		}
+	}
+	return res
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gebv/asap-tools/storage"
+	"go.uber.org/zap"
+)
+
+type StoreModel = storage.Model
+
+func NewStorage(s *storage.Storage) *Storage {
+	return &Storage{
+		Storage: s,
+		log:     zap.L().Named("webhook_storage"),
+	}
+}
+
+// the log of the deliveries
+//
+// - method Get<ModelName> - returns model by ID
+// - method Upsert<ModelName> - create or overwrite model by ID
+type Storage struct {
+	*storage.Storage
+	log *zap.Logger
+}
+
+var (
+	DeliveryModel            = (*Delivery)(nil)
+	_             StoreModel = (*Delivery)(nil)
+)
+
+// a new model instance and call GetModel
+func (s *Storage) GetDelivery(ctx context.Context, modelID string) *Delivery {
+	model := storage.NewWithID(DeliveryModel, modelID).(*Delivery)
+	s.GetModel(ctx, model)
+	return model
+}
+
+// alias to UpsertModel
+func (s *Storage) UpsertDelivery(ctx context.Context, model *Delivery) error {
+	return s.UpsertModel(ctx, model)
+}
+
+// FailedDeliveries returns the failed deliveries (the oldest first).
+func (s *Storage) FailedDeliveries(ctx context.Context) []*Delivery {
+	iter := s.FirestoreClient().Collection(DeliveryModel.CollectionName()).
+		Where("Status", "==", DeliveryFailed).Documents(ctx)
+	res := s.Iterate(iter, DeliveryModel)
+	list := []*Delivery{}
+	for idx := range res {
+		list = append(list, res[idx].(*Delivery))
+	}
+	// sorted in memory (the query by the status and the order by the time require the composite index)
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.AsTime().Before(list[j].CreatedAt.AsTime())
+	})
+	return list
+}
+
+// the statuses of the delivery
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Delivery the log of the delivery of the event to the endpoint (ID is ID of the payload).
+type Delivery struct {
+	storage.StdModel
+	URL       string
+	EventType string
+	Rule      string
+	// the body of the request (the same for the redeliveries)
+	Body string
+	// see DeliveryPending, DeliveryDelivered, DeliveryFailed
+	Status string
+	// the number of the attempts of all deliveries
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	CreatedAt      *storage.Timestamp
+	DeliveredAt    *storage.Timestamp
+}
+
+func (*Delivery) NewModel() StoreModel {
+	return &Delivery{}
+}
+
+func (*Delivery) CollectionName() string {
+	return "webhook_deliveries"
+}
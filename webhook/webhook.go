@@ -0,0 +1,348 @@
+// Package webhook sends the events of the tasks as JSON to the HTTP endpoints
+// with the signature of the body, the retries and the log of the deliveries.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+	"go.uber.org/zap"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// the headers of the request of the delivery
+const (
+	// HMAC-SHA256 of the body by the secret of the endpoint (hex, as ClickUp signs the webhooks)
+	SignatureHeader = "X-Signature"
+	EventHeader     = "X-Asap-Event"
+	DeliveryHeader  = "X-Asap-Delivery"
+)
+
+// the types of the events
+const (
+	EventMirrorCreated          = "mirror.created"
+	EventMirrorUnlinked         = "mirror.unlinked"
+	EventMirrorApprovalRequired = "mirror.approval_required"
+	EventTaskStatusChanged      = "task.status_changed"
+	EventTaskAssigneesChanged   = "task.assignees_changed"
+	EventTaskDueDateChanged     = "task.due_date_changed"
+	EventTaskEstimateChanged    = "task.estimate_changed"
+)
+
+// the types of the events by the kinds of the events of the notifications
+var eventTypes = map[string]string{
+	notify.EventMirrorAdded: EventMirrorCreated,
+	notify.EventUnlinked:    EventMirrorUnlinked,
+	notify.EventApproval:    EventMirrorApprovalRequired,
+	notify.EventStatus:      EventTaskStatusChanged,
+	notify.EventAssignees:   EventTaskAssigneesChanged,
+	notify.EventDueDate:     EventTaskDueDateChanged,
+	notify.EventEstimate:    EventTaskEstimateChanged,
+}
+
+// EventTypes all types of the events.
+var EventTypes = []string{
+	EventMirrorCreated, EventMirrorUnlinked, EventMirrorApprovalRequired,
+	EventTaskStatusChanged, EventTaskAssigneesChanged, EventTaskDueDateChanged, EventTaskEstimateChanged,
+}
+
+// EventType returns the type of the event by the kind of the event of the notifications ("" if unknown).
+func EventType(kind string) string {
+	return eventTypes[kind]
+}
+
+// the defaults of the retries of the delivery
+const (
+	defaultMaxAttempts = 3
+	defaultMinBackoff  = time.Second
+	defaultTimeout     = 10 * time.Second
+)
+
+// Endpoint the receiver of the events.
+type Endpoint struct {
+	URL string
+	// the secret of the signature (the body is not signed if empty)
+	Secret string
+}
+
+// Payload the body of the request of the delivery.
+type Payload struct {
+	// ID of the delivery (the same for the redeliveries)
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	// the name of the rule of the sync
+	Rule      string       `json:"rule,omitempty"`
+	Task      *PayloadTask `json:"task,omitempty"`
+	MirrorURL string       `json:"mirror_url,omitempty"`
+	// the values of the changed field (eg the statuses)
+	From    string `json:"from,omitempty"`
+	To      string `json:"to,omitempty"`
+	Details string `json:"details,omitempty"`
+}
+
+type PayloadTask struct {
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// NewPayload returns the payload of the event (nil if the kind of the event is unknown).
+func NewPayload(event *notify.Event) *Payload {
+	eventType := EventType(event.Kind)
+	if eventType == "" {
+		return nil
+	}
+	res := &Payload{
+		ID:        newDeliveryID(),
+		Type:      eventType,
+		CreatedAt: event.At.UTC(),
+		Rule:      event.Rule,
+		MirrorURL: event.MirrorURL,
+		From:      event.From,
+		To:        event.To,
+		Details:   event.Details,
+	}
+	if event.TaskID != "" || event.URL != "" {
+		res.Task = &PayloadTask{ID: event.TaskID, Title: event.Title, URL: event.URL}
+	}
+	if res.CreatedAt.IsZero() {
+		res.CreatedAt = time.Now().UTC()
+	}
+	return res
+}
+
+// Sign returns the signature of the body by the secret (HMAC-SHA256 in hex).
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ExpandSecret returns the secret with the environment variables (eg ${WEBHOOK_SECRET}).
+func ExpandSecret(in string) string {
+	return os.ExpandEnv(in)
+}
+
+// Options of the retries of the delivery.
+type Options struct {
+	// the max number of the attempts of the delivery, 3 by default
+	MaxAttempts int
+	// the wait before the second attempt (doubled for the next attempts), 1 second by default
+	MinBackoff time.Duration
+	// the timeout of the request, 10 seconds by default
+	Timeout time.Duration
+}
+
+// NewDispatcher returns the dispatcher of the events (the deliveries are logged into the storage if not nil).
+func NewDispatcher(store *Storage, opts Options) *Dispatcher {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultMinBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	d := &Dispatcher{
+		client: &http.Client{Timeout: opts.Timeout},
+		opts:   opts,
+		log:    zap.L().Named("webhook"),
+		now:    time.Now,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			select {
+			case <-time.After(d):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+	// the typed nil is not the nil interface
+	if store != nil {
+		d.store = store
+	}
+	return d
+}
+
+type Dispatcher struct {
+	client *http.Client
+	// the log of the deliveries (see Storage)
+	store deliveryStore
+	opts  Options
+	log   *zap.Logger
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu sync.Mutex
+	// the queued deliveries (sent by Flush)
+	pending []queuedDelivery
+}
+
+type queuedDelivery struct {
+	endpoint Endpoint
+	delivery *Delivery
+}
+
+// the log of the deliveries (see Storage)
+type deliveryStore interface {
+	UpsertDelivery(ctx context.Context, model *Delivery) error
+}
+
+// Dispatch sends the payload to the endpoint with the retries and logs the delivery.
+func (d *Dispatcher) Dispatch(ctx context.Context, endpoint Endpoint, payload *Payload) error {
+	delivery, err := d.newDelivery(endpoint, payload)
+	if err != nil {
+		return err
+	}
+	return d.deliver(ctx, endpoint, delivery)
+}
+
+// Enqueue logs the pending delivery of the payload to the endpoint (sent by Flush),
+// so the sync of the tasks is not blocked by the retries of the slow endpoints.
+func (d *Dispatcher) Enqueue(ctx context.Context, endpoint Endpoint, payload *Payload) error {
+	delivery, err := d.newDelivery(endpoint, payload)
+	if err != nil {
+		return err
+	}
+	delivery.Status = DeliveryPending
+	if d.store != nil {
+		if err := d.store.UpsertDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	d.mu.Lock()
+	d.pending = append(d.pending, queuedDelivery{endpoint: endpoint, delivery: delivery})
+	d.mu.Unlock()
+	return nil
+}
+
+// Flush sends the queued deliveries. Returns the first error (the other deliveries are sent).
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	d.mu.Lock()
+	pending := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+
+	var firstErr error
+	for _, item := range pending {
+		if err := d.deliver(ctx, item.endpoint, item.delivery); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Redeliver sends the logged delivery again with the same body and ID (eg the failed delivery).
+func (d *Dispatcher) Redeliver(ctx context.Context, endpoint Endpoint, delivery *Delivery) error {
+	return d.deliver(ctx, endpoint, delivery)
+}
+
+func (d *Dispatcher) newDelivery(endpoint Endpoint, payload *Payload) (*Delivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	delivery := &Delivery{
+		URL:       endpoint.URL,
+		EventType: payload.Type,
+		Rule:      payload.Rule,
+		Body:      string(body),
+		CreatedAt: timestamppb.New(d.now()),
+	}
+	delivery.SetModelID(payload.ID)
+	return delivery, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, endpoint Endpoint, delivery *Delivery) error {
+	var err error
+	backoff := d.opts.MinBackoff
+	for attempt := 1; attempt <= d.opts.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			if err := d.sleep(ctx, backoff); err != nil {
+				return err
+			}
+			backoff *= 2
+		}
+		var retry bool
+		retry, err = d.send(ctx, endpoint, delivery)
+		delivery.Attempts++
+		if err == nil || !retry {
+			break
+		}
+		d.log.Debug("Retry of the delivery", zap.String("delivery_id", delivery.ID), zap.Int("attempt", attempt), zap.Error(err))
+	}
+
+	delivery.Status = DeliveryDelivered
+	delivery.LastError = ""
+	delivery.DeliveredAt = timestamppb.New(d.now())
+	if err != nil {
+		delivery.Status = DeliveryFailed
+		delivery.LastError = err.Error()
+		delivery.DeliveredAt = nil
+	}
+	if d.store != nil {
+		logErr := d.store.UpsertDelivery(ctx, delivery)
+		warnErrorIf(d.log, logErr, "failed to upsert the delivery", "delivery_id", delivery.ID, "url", delivery.URL)
+	}
+	if err != nil {
+		return fmt.Errorf("failed delivery %q of %s to %q: %w", delivery.ID, delivery.EventType, delivery.URL, err)
+	}
+	return nil
+}
+
+// returns true if the failed request can be retried (the network errors, 429 and 5xx)
+func (d *Dispatcher) send(ctx context.Context, endpoint Endpoint, delivery *Delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader([]byte(delivery.Body)))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, []byte(delivery.Body)))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	delivery.ResponseStatus = res.StatusCode
+	if res.StatusCode >= 300 {
+		retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		return retry, fmt.Errorf("got status %d", res.StatusCode)
+	}
+	return false, nil
+}
+
+// returns the random ID of the delivery
+func newDeliveryID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func warnErrorIf(l *zap.Logger, err error, msg string, pairs ...interface{}) {
+	if err != nil {
+		l.With(zap.Error(err)).Sugar().Warnw(msg, pairs...)
+	}
+}
@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gebv/asap-tools/notify"
+)
+
+// fakeEndpoint the receiver of the webhooks (verifies the signature, the statuses of the next responses)
+type fakeEndpoint struct {
+	mu       sync.Mutex
+	secret   string
+	statuses []int
+	received []*Payload
+}
+
+func (f *fakeEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(r.Body)
+	if r.Header.Get(SignatureHeader) != Sign(f.secret, body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if len(f.statuses) > 0 {
+		status := f.statuses[0]
+		f.statuses = f.statuses[1:]
+		if status >= 300 {
+			w.WriteHeader(status)
+			return
+		}
+	}
+	payload := &Payload{}
+	json.Unmarshal(body, payload)
+	if r.Header.Get(EventHeader) != payload.Type || r.Header.Get(DeliveryHeader) != payload.ID {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.received = append(f.received, payload)
+}
+
+// memDeliveries the in-memory log of the deliveries
+type memDeliveries map[string]Delivery
+
+func (m memDeliveries) UpsertDelivery(ctx context.Context, model *Delivery) error {
+	m[model.ID] = *model
+	return nil
+}
+
+func newTestDispatcher(t *testing.T, fake *fakeEndpoint) (*Dispatcher, memDeliveries, *[]time.Duration, string) {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	d := NewDispatcher(nil, Options{})
+	deliveries := memDeliveries{}
+	d.store = deliveries
+	sleeps := &[]time.Duration{}
+	d.sleep = func(ctx context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return nil
+	}
+	return d, deliveries, sleeps, srv.URL
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeEndpoint{secret: "s3cret", statuses: []int{http.StatusBadGateway, http.StatusOK}}
+	d, deliveries, sleeps, url := newTestDispatcher(t, fake)
+
+	event := &notify.Event{Kind: notify.EventStatus, Rule: "qa", TaskID: "abc", Title: "Fix login", URL: "https://app.clickup.com/t/abc",
+		From: "open", To: "in progress", At: time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)}
+	payload := NewPayload(event)
+	if err := d.Dispatch(ctx, Endpoint{URL: url, Secret: "s3cret"}, payload); err != nil {
+		t.Fatalf("Dispatch(): %v", err)
+	}
+	want := &Payload{ID: payload.ID, Type: EventTaskStatusChanged, CreatedAt: event.At, Rule: "qa",
+		Task: &PayloadTask{ID: "abc", Title: "Fix login", URL: "https://app.clickup.com/t/abc"}, From: "open", To: "in progress"}
+	if len(fake.received) != 1 || !reflect.DeepEqual(fake.received[0], want) {
+		t.Errorf("received %+v, want %+v", fake.received, want)
+	}
+	if delivery := deliveries[payload.ID]; delivery.Status != DeliveryDelivered || delivery.Attempts != 2 || delivery.ResponseStatus != http.StatusOK {
+		t.Errorf("delivery %+v", delivery)
+	}
+	if !reflect.DeepEqual(*sleeps, []time.Duration{time.Second}) {
+		t.Errorf("sleeps %v", *sleeps)
+	}
+
+	// the client errors are not retried, the failed delivery is redelivered with the same body
+	fake.statuses = []int{http.StatusNotFound}
+	payload = NewPayload(&notify.Event{Kind: notify.EventMirrorAdded, Rule: "qa", MirrorURL: "https://app.clickup.com/t/def"})
+	if err := d.Dispatch(ctx, Endpoint{URL: url, Secret: "s3cret"}, payload); err == nil {
+		t.Fatal("Dispatch() without error")
+	}
+	delivery := deliveries[payload.ID]
+	if delivery.Status != DeliveryFailed || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusNotFound || delivery.LastError == "" {
+		t.Errorf("failed delivery %+v", delivery)
+	}
+	if err := d.Redeliver(ctx, Endpoint{URL: url, Secret: "s3cret"}, &delivery); err != nil {
+		t.Fatalf("Redeliver(): %v", err)
+	}
+	if got := deliveries[payload.ID]; got.Status != DeliveryDelivered || got.Attempts != 2 || got.LastError != "" {
+		t.Errorf("redelivery %+v", got)
+	}
+	if len(fake.received) != 2 || fake.received[1].ID != payload.ID || fake.received[1].Type != EventMirrorCreated {
+		t.Errorf("received %+v", fake.received)
+	}
+
+	// the invalid signature
+	if err := d.Dispatch(ctx, Endpoint{URL: url, Secret: "other"}, NewPayload(event)); err == nil {
+		t.Error("Dispatch() with invalid secret without error")
+	}
+}
+
+func TestNewPayload_UnknownKind(t *testing.T) {
+	if payload := NewPayload(&notify.Event{Kind: "priority"}); payload != nil {
+		t.Errorf("NewPayload() = %+v", payload)
+	}
+	for _, kind := range notify.EventKinds {
+		if EventType(kind) == "" {
+			t.Errorf("not found type of the event %q", kind)
+		}
+	}
+}
+
+func TestDispatcher_Enqueue(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeEndpoint{secret: "s3cret"}
+	d, deliveries, _, url := newTestDispatcher(t, fake)
+
+	payload := NewPayload(&notify.Event{Kind: notify.EventMirrorAdded, Rule: "qa", MirrorURL: "https://app.clickup.com/t/def"})
+	if err := d.Enqueue(ctx, Endpoint{URL: url, Secret: "s3cret"}, payload); err != nil {
+		t.Fatalf("Enqueue(): %v", err)
+	}
+	// the delivery is logged as pending and not sent before the flush
+	if len(fake.received) != 0 {
+		t.Errorf("received %+v before the flush", fake.received)
+	}
+	if delivery := deliveries[payload.ID]; delivery.Status != DeliveryPending || delivery.Attempts != 0 {
+		t.Errorf("queued delivery %+v", delivery)
+	}
+
+	if err := d.Flush(ctx); err != nil {
+		t.Fatalf("Flush(): %v", err)
+	}
+	if len(fake.received) != 1 || fake.received[0].ID != payload.ID {
+		t.Errorf("received %+v", fake.received)
+	}
+	if delivery := deliveries[payload.ID]; delivery.Status != DeliveryDelivered || delivery.Attempts != 1 {
+		t.Errorf("delivery %+v", delivery)
+	}
+
+	// the queue is empty after the flush
+	if err := d.Flush(ctx); err != nil || len(fake.received) != 1 {
+		t.Errorf("the second Flush() = %v, received %d", err, len(fake.received))
+	}
+}